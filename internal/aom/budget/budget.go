@@ -0,0 +1,242 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package budget
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"strconv"
+	"u-control/uc-aom/internal/aom/utils"
+	"u-control/uc-aom/internal/aom/yaml"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/docker/go-units"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	memLimitKey        = "memLimit"
+	composeMemLimitKey = "mem_limit"
+	cpusKey            = "cpus"
+	composeServiceType = "docker-compose"
+)
+
+// Resources used by the services of an add-on.
+type Resources struct {
+	MemoryBytes uint64 // memory limit in bytes
+	MilliCPUs   uint64 // cpu limit in thousandths of a cpu
+}
+
+// Resources assumed for a service which does not declare its own limits.
+// The memory limit of the service defaults, which docker-compose enforces, takes precedence over MemLimit.
+type Defaults struct {
+	Cpus     float64 `json:"cpus"`
+	MemLimit string  `json:"memLimit"` // e.g. 128m
+}
+
+// Budget of the device resources which are available for add-ons.
+// The remaining resources are reserved for the device, e.g. the real-time PLC runtime.
+// A value of zero disables the corresponding limit.
+type Budget struct {
+	MemoryBytes      uint64   `json:"memoryBytes"`      // total memory for all add-ons
+	Cpus             float64  `json:"cpus"`             // total cpus for all add-ons
+	MaxRunningAddOns int      `json:"maxRunningAddOns"` // maximum number of add-ons
+	Defaults         Defaults `json:"defaults"`         // platform defaults per service
+}
+
+// Represents an add-on which does not fit into the resource budget.
+type ExceededError struct {
+	message string
+
+	// Exceeded resource, one of memory, cpus or addOns
+	Resource string
+
+	// Amount of the resource the add-on requests
+	Requested uint64
+
+	// Amount of the resource which is still available
+	Available uint64
+}
+
+func (r *ExceededError) Error() string {
+	return r.message
+}
+
+// Reads the resource budget from path.
+// A missing file results in an unlimited budget.
+func LoadBudget(readFile utils.ReadFileFunc, path string) (*Budget, error) {
+	content, err := readFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Debugf("No resource budget file at '%s'", path)
+			return &Budget{}, nil
+		}
+		return nil, err
+	}
+
+	budget := &Budget{}
+	if err := json.Unmarshal(content, budget); err != nil {
+		return nil, fmt.Errorf("Invalid resource budget file '%s': %w", path, err)
+	}
+	if _, err := budget.Defaults.memoryBytes(); err != nil {
+		return nil, fmt.Errorf("Invalid resource budget file '%s': invalid default %s: %w", path, memLimitKey, err)
+	}
+	return budget, nil
+}
+
+// Returns true if none of the limits is enabled.
+func (b *Budget) IsUnlimited() bool {
+	return b.MemoryBytes == 0 && b.Cpus == 0 && b.MaxRunningAddOns == 0
+}
+
+// Returns the resources of the docker-compose services of the manifest as they are deployed with the compose defaults.
+// Services without cpus are accounted with the platform defaults,
+// as are services without a memory limit, neither declared nor injected by the compose defaults.
+func (b *Budget) ResourcesOf(addOnManifest *manifest.Root, composeDefaults *yaml.ServiceDefaults) (Resources, error) {
+	defaultMemoryBytes, err := b.Defaults.memoryBytes()
+	if err != nil {
+		return Resources{}, fmt.Errorf("Invalid default %s: %w", memLimitKey, err)
+	}
+
+	resources := Resources{}
+	for name, service := range addOnManifest.Services {
+		if service.Type != composeServiceType {
+			continue
+		}
+
+		config := yaml.ServiceConfigWithDefaults(service, composeDefaults)
+		key, memLimit := lookupConfig(config, memLimitKey, composeMemLimitKey)
+		memoryBytes, err := parseMemLimit(memLimit, defaultMemoryBytes)
+		if err != nil {
+			return Resources{}, fmt.Errorf("Invalid %s of service '%s': %w", key, name, err)
+		}
+
+		milliCPUs, err := parseCpus(config[cpusKey], toMilliCPUs(b.Defaults.Cpus))
+		if err != nil {
+			return Resources{}, fmt.Errorf("Invalid %s of service '%s': %w", cpusKey, name, err)
+		}
+
+		resources.MemoryBytes += memoryBytes
+		resources.MilliCPUs += milliCPUs
+	}
+	return resources, nil
+}
+
+// Returns an ExceededError if the requested resources of the add-on identified by title
+// do not fit into the budget next to the already allocated resources of the other add-ons.
+func (b *Budget) Check(title string, allocated []Resources, requested Resources) error {
+	if b.MaxRunningAddOns > 0 && len(allocated)+1 > b.MaxRunningAddOns {
+		message := fmt.Sprintf("Cannot install '%s'. The device allows at most %d add-ons.", title, b.MaxRunningAddOns)
+		return &ExceededError{message: message, Resource: "addOns", Requested: 1, Available: 0}
+	}
+
+	return b.checkResources(title, allocated, requested, Resources{})
+}
+
+// Returns an ExceededError if the update of the add-on identified by title from the installed to the requested resources
+// does not fit into the budget next to the already allocated resources of the other add-ons.
+// Only an increase of a resource is checked, so an update which requires no more than the installed version
+// is permitted even if the device is already over budget, e.g. because the budget was lowered.
+func (b *Budget) CheckUpdate(title string, allocated []Resources, requested Resources, installed Resources) error {
+	return b.checkResources(title, allocated, requested, installed)
+}
+
+func (b *Budget) checkResources(title string, allocated []Resources, requested Resources, installed Resources) error {
+	total := Resources{}
+	for _, resources := range allocated {
+		total.MemoryBytes += resources.MemoryBytes
+		total.MilliCPUs += resources.MilliCPUs
+	}
+
+	if b.MemoryBytes > 0 && requested.MemoryBytes > installed.MemoryBytes && total.MemoryBytes+requested.MemoryBytes > b.MemoryBytes {
+		available := remaining(b.MemoryBytes, total.MemoryBytes)
+		message := fmt.Sprintf("Cannot install '%s'. It requires %s of memory, but only %s of the add-on memory budget are available.",
+			title, units.BytesSize(float64(requested.MemoryBytes)), units.BytesSize(float64(available)))
+		return &ExceededError{message: message, Resource: "memory", Requested: requested.MemoryBytes, Available: available}
+	}
+
+	budgetMilliCPUs := toMilliCPUs(b.Cpus)
+	if budgetMilliCPUs > 0 && requested.MilliCPUs > installed.MilliCPUs && total.MilliCPUs+requested.MilliCPUs > budgetMilliCPUs {
+		available := remaining(budgetMilliCPUs, total.MilliCPUs)
+		message := fmt.Sprintf("Cannot install '%s'. It requires %.3f cpus, but only %.3f cpus of the add-on cpu budget are available.",
+			title, float64(requested.MilliCPUs)/1000, float64(available)/1000)
+		return &ExceededError{message: message, Resource: "cpus", Requested: requested.MilliCPUs, Available: available}
+	}
+
+	return nil
+}
+
+// Returns the memory limit in bytes, zero if no default is set.
+func (d *Defaults) memoryBytes() (uint64, error) {
+	if d.MemLimit == "" {
+		return 0, nil
+	}
+	return parseMemLimit(d.MemLimit, 0)
+}
+
+// Returns the first of the given keys with its value, the manifest declares the compose options in camel or snake case.
+func lookupConfig(config map[string]interface{}, keys ...string) (string, interface{}) {
+	for _, key := range keys {
+		if value, ok := config[key]; ok {
+			return key, value
+		}
+	}
+	return keys[0], nil
+}
+
+func parseMemLimit(value interface{}, fallback uint64) (uint64, error) {
+	switch v := value.(type) {
+	case nil:
+		return fallback, nil
+	case float64:
+		return uint64(v), nil
+	case int:
+		return uint64(v), nil
+	case string:
+		bytes, err := units.RAMInBytes(v)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(bytes), nil
+	default:
+		return 0, fmt.Errorf("unsupported value '%v'", v)
+	}
+}
+
+func parseCpus(value interface{}, fallback uint64) (uint64, error) {
+	switch v := value.(type) {
+	case nil:
+		return fallback, nil
+	case float64:
+		return toMilliCPUs(v), nil
+	case int:
+		return toMilliCPUs(float64(v)), nil
+	case string:
+		cpus, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, err
+		}
+		return toMilliCPUs(cpus), nil
+	default:
+		return 0, fmt.Errorf("unsupported value '%v'", v)
+	}
+}
+
+func toMilliCPUs(cpus float64) uint64 {
+	if cpus <= 0 {
+		return 0
+	}
+	return uint64(math.Round(cpus * 1000))
+}
+
+func remaining(limit uint64, used uint64) uint64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
@@ -0,0 +1,201 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package budget_test
+
+import (
+	"io/fs"
+	"testing"
+	"u-control/uc-aom/internal/aom/budget"
+	"u-control/uc-aom/internal/aom/yaml"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadBudget(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return []byte(`{ "memoryBytes": 536870912, "cpus": 1.5, "maxRunningAddOns": 4, "defaults": { "cpus": 0.25, "memLimit": "64m" } }`), nil
+	}
+
+	// Act
+	uut, err := budget.LoadBudget(readFile, "")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, &budget.Budget{
+		MemoryBytes:      536870912,
+		Cpus:             1.5,
+		MaxRunningAddOns: 4,
+		Defaults:         budget.Defaults{Cpus: 0.25, MemLimit: "64m"},
+	}, uut)
+}
+
+func TestLoadBudgetInvalidDefaultMemLimit(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return []byte(`{ "memoryBytes": 536870912, "defaults": { "memLimit": "lots" } }`), nil
+	}
+
+	// Act
+	_, err := budget.LoadBudget(readFile, "")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestLoadBudgetMissingFile(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return nil, fs.ErrNotExist
+	}
+
+	// Act
+	uut, err := budget.LoadBudget(readFile, "")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, uut.IsUnlimited())
+}
+
+func TestResourcesOf(t *testing.T) {
+	// Arrange
+	uut := &budget.Budget{Defaults: budget.Defaults{Cpus: 0.25}}
+	addOnManifest := &manifest.Root{
+		Services: map[string]*manifest.Service{
+			"declared":     {Type: "docker-compose", Config: map[string]interface{}{"mem_limit": "256m", "cpus": 0.5}},
+			"declaredText": {Type: "docker-compose", Config: map[string]interface{}{"memLimit": float64(1024), "cpus": "1"}},
+			"undeclared":   {Type: "docker-compose", Config: map[string]interface{}{"image": "test"}},
+		},
+	}
+
+	// Act
+	resources, err := uut.ResourcesOf(addOnManifest, nil)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, budget.Resources{MemoryBytes: 256*1024*1024 + 1024, MilliCPUs: 1750}, resources)
+}
+
+func TestResourcesOfWithComposeDefaults(t *testing.T) {
+	// Arrange
+	uut := &budget.Budget{}
+	addOnManifest := &manifest.Root{
+		Services: map[string]*manifest.Service{
+			"declared":   {Type: "docker-compose", Config: map[string]interface{}{"memLimit": "256m"}},
+			"undeclared": {Type: "docker-compose", Config: map[string]interface{}{"image": "test"}},
+		},
+	}
+
+	// Act
+	resources, err := uut.ResourcesOf(addOnManifest, &yaml.ServiceDefaults{MemLimit: "128m"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(384*1024*1024), resources.MemoryBytes)
+}
+
+func TestResourcesOfWithDefaultMemLimit(t *testing.T) {
+	// Arrange
+	uut := &budget.Budget{Defaults: budget.Defaults{MemLimit: "64m"}}
+	addOnManifest := &manifest.Root{
+		Services: map[string]*manifest.Service{
+			"declared":   {Type: "docker-compose", Config: map[string]interface{}{"memLimit": "256m"}},
+			"undeclared": {Type: "docker-compose", Config: map[string]interface{}{"image": "test"}},
+		},
+	}
+
+	// Act
+	withoutComposeDefaults, err := uut.ResourcesOf(addOnManifest, nil)
+	withComposeDefaults, composeErr := uut.ResourcesOf(addOnManifest, &yaml.ServiceDefaults{MemLimit: "128m"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, composeErr)
+	assert.Equal(t, uint64(320*1024*1024), withoutComposeDefaults.MemoryBytes)
+	assert.Equal(t, uint64(384*1024*1024), withComposeDefaults.MemoryBytes)
+}
+
+func TestResourcesOfInvalidMemLimit(t *testing.T) {
+	testCases := map[string]string{
+		"memLimit":  "memLimit",
+		"mem_limit": "mem_limit",
+	}
+
+	for name, key := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			uut := &budget.Budget{}
+			addOnManifest := &manifest.Root{
+				Services: map[string]*manifest.Service{
+					"invalid": {Type: "docker-compose", Config: map[string]interface{}{key: "lots"}},
+				},
+			}
+
+			// Act
+			_, err := uut.ResourcesOf(addOnManifest, nil)
+
+			// Assert
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "Invalid "+key+" ")
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	uut := &budget.Budget{MemoryBytes: 1000, Cpus: 2, MaxRunningAddOns: 3}
+	allocated := []budget.Resources{{MemoryBytes: 400, MilliCPUs: 500}, {MemoryBytes: 400, MilliCPUs: 500}}
+
+	testCases := []struct {
+		name             string
+		allocated        []budget.Resources
+		requested        budget.Resources
+		expectedResource string
+	}{
+		{name: "fits", allocated: allocated, requested: budget.Resources{MemoryBytes: 200, MilliCPUs: 1000}},
+		{name: "memory exceeded", allocated: allocated, requested: budget.Resources{MemoryBytes: 201}, expectedResource: "memory"},
+		{name: "cpus exceeded", allocated: allocated, requested: budget.Resources{MilliCPUs: 1001}, expectedResource: "cpus"},
+		{name: "add-ons exceeded", allocated: append(allocated, budget.Resources{}), requested: budget.Resources{}, expectedResource: "addOns"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := uut.Check("Test add-on", tc.allocated, tc.requested)
+
+			// Assert
+			if tc.expectedResource == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			exceeded, ok := err.(*budget.ExceededError)
+			if !ok {
+				t.Fatalf("Expected ExceededError but got %v", err)
+			}
+			assert.Equal(t, tc.expectedResource, exceeded.Resource)
+			assert.Contains(t, exceeded.Error(), "Test add-on")
+		})
+	}
+}
+
+func TestCheckUpdate(t *testing.T) {
+	// Arrange
+	uut := &budget.Budget{MemoryBytes: 1000, Cpus: 1, MaxRunningAddOns: 1}
+	overBudget := []budget.Resources{{MemoryBytes: 900, MilliCPUs: 900}}
+	installed := budget.Resources{MemoryBytes: 200, MilliCPUs: 200}
+
+	// Act
+	unchangedErr := uut.CheckUpdate("Test add-on", overBudget, installed, installed)
+	increasedErr := uut.CheckUpdate("Test add-on", overBudget, budget.Resources{MemoryBytes: 201, MilliCPUs: 200}, installed)
+
+	// Assert
+	assert.Nil(t, unchangedErr)
+	exceeded, ok := increasedErr.(*budget.ExceededError)
+	if !ok {
+		t.Fatalf("Expected ExceededError but got %v", increasedErr)
+	}
+	assert.Equal(t, "memory", exceeded.Resource)
+}
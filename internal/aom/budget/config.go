@@ -0,0 +1,11 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package budget
+
+import "u-control/uc-aom/internal/pkg/utils"
+
+var (
+	RESOURCE_BUDGET_PATH = utils.GetEnv("RESOURCE_BUDGET_PATH", "/usr/share/uc-aom/resource-budget.json")
+)
@@ -5,8 +5,13 @@
 package catalogue
 
 import (
+	"context"
 	"io"
 	"u-control/uc-aom/internal/pkg/manifest"
+	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
 )
 
 type CatalogueAddOn struct {
@@ -16,13 +21,36 @@ type CatalogueAddOn struct {
 	Version string
 	// The manifest of the AddOn
 	Manifest manifest.Root
+	// Set if the manifest only holds the summary of the package annotations,
+	// the full manifest is validated once the AddOn is pulled.
+	IsSummary bool
+	// The markdown readme and changelog the manifest references,
+	// only read if the AddOn is pulled from the remote catalogue.
+	Readme    string
+	Changelog string
 }
 
-// Decorates a CatalogueAddOn with docker images
-type CatalogueAddOnWithImages struct {
+// Describes the disk space required by the docker images of an add-on.
+type DiskFootprint struct {
 	// Estimated install size in bytes
 	EstimatedInstallSize uint64
 
+	// Cumulative size of the docker image archives in bytes
+	ArchiveSize uint64
+
+	// Descriptors of the docker image archives
+	Archives []ocispec.Descriptor
+
+	// Uncompressed layers of all docker images,
+	// nil if the add-on package does not provide them.
+	DockerImageLayers []oraswrapper.DockerImageLayer
+}
+
+// Decorates a CatalogueAddOn with docker images
+type CatalogueAddOnWithImages struct {
+	// Disk space required by the docker images
+	DiskFootprint DiskFootprint
+
 	// An add-on instance
 	AddOn CatalogueAddOn
 
@@ -32,19 +60,48 @@ type CatalogueAddOnWithImages struct {
 	DockerImageData []io.Reader
 }
 
+// Releases the docker image data once the install is finished.
+// Cached docker image data is removed from the cache if the add-on has been installed
+// and kept otherwise, so that the next attempt does not download it again.
+func (a *CatalogueAddOnWithImages) ReleaseDockerImageData(installed bool) {
+	for _, image := range a.DockerImageData {
+		if cached, ok := image.(interface{ Remove() error }); ok && installed {
+			if err := cached.Remove(); err != nil {
+				log.Warnf("Unable to remove the cached docker image: %v", err)
+			}
+			continue
+		}
+		if closer, ok := image.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
 type RemoteAddOnCatalogue interface {
 	// Returns the names of all AddOns associated with the remote catalogue.
 	// An AddOn name is a unique identifier.
 	GetAddOnNames() ([]string, error)
 
-	// Returns the versions for the AddOn identified by name, in ascending order.
-	GetAddOnVersions(name string) ([]string, error)
+	// Returns the versions for the AddOn identified by name offered by its release channel, in ascending order.
+	// Fetching stops when the context is done.
+	GetAddOnVersions(ctx context.Context, name string) ([]string, error)
 
 	// Returns the AddOn identified by name with the given version from the remote catalogue.
-	GetAddOn(name string, version string) (CatalogueAddOn, error)
+	// Fetching, and waiting for another fetch of the same AddOn, stops when the context is done.
+	GetAddOn(ctx context.Context, name string, version string) (CatalogueAddOn, error)
+
+	// Fetches and returns the manifest of the AddOn identified by name and version,
+	// without changing the assets of the AddOn in the remote catalogue.
+	// Fetching stops when the context is done.
+	FetchManifest(ctx context.Context, name string, version string) (*manifest.Root, error)
 
 	// Returns the latest version of all AddOns from the remote catalogue.
-	GetLatestAddOns() ([]*CatalogueAddOn, error)
+	// Fetching stops when the context is done.
+	GetLatestAddOns(ctx context.Context) ([]*CatalogueAddOn, error)
+
+	// Refreshes the latest versions of all AddOns, which are otherwise refreshed in the background.
+	// Fetching stops when the context is done.
+	Refresh(ctx context.Context) error
 }
 
 type LocalAddOnCatalogue interface {
@@ -66,4 +123,12 @@ type LocalAddOnCatalogue interface {
 
 	// Fetches and returns the manifest for the add-on identified by name and version.
 	FetchManifest(name string, version string) (*manifest.Root, error)
+
+	// Fetches and returns the disk footprint for the add-on identified by name and version,
+	// without pulling its docker images.
+	FetchDiskFootprint(name string, version string) (DiskFootprint, error)
+
+	// Returns the disk space in bytes used by the manifest and assets
+	// of the add-on identified by name in the local catalogue.
+	GetAddOnAssetsSize(name string) (uint64, error)
 }
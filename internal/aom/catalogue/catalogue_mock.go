@@ -8,6 +8,7 @@
 package catalogue
 
 import (
+	"context"
 	"u-control/uc-aom/internal/pkg/manifest"
 
 	"github.com/stretchr/testify/mock"
@@ -41,3 +42,47 @@ func (m CatalogueMock) FetchManifest(name string, version string) (*manifest.Roo
 	args := m.Called(name, version)
 	return args.Get(0).(*manifest.Root), args.Error(1)
 }
+
+func (m CatalogueMock) GetAddOnAssetsSize(name string) (uint64, error) {
+	args := m.Called(name)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m CatalogueMock) FetchDiskFootprint(name string, version string) (DiskFootprint, error) {
+	args := m.Called(name, version)
+	return args.Get(0).(DiskFootprint), args.Error(1)
+}
+
+type RemoteCatalogueMock struct {
+	mock.Mock
+}
+
+func (m *RemoteCatalogueMock) GetAddOnNames() ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *RemoteCatalogueMock) GetAddOnVersions(ctx context.Context, name string) ([]string, error) {
+	args := m.Called(name)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *RemoteCatalogueMock) GetAddOn(ctx context.Context, name string, version string) (CatalogueAddOn, error) {
+	args := m.Called(name, version)
+	return args.Get(0).(CatalogueAddOn), args.Error(1)
+}
+
+func (m *RemoteCatalogueMock) FetchManifest(ctx context.Context, name string, version string) (*manifest.Root, error) {
+	args := m.Called(name, version)
+	return args.Get(0).(*manifest.Root), args.Error(1)
+}
+
+func (m *RemoteCatalogueMock) GetLatestAddOns(ctx context.Context) ([]*CatalogueAddOn, error) {
+	args := m.Called()
+	return args.Get(0).([]*CatalogueAddOn), args.Error(1)
+}
+
+func (m *RemoteCatalogueMock) Refresh(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogue
+
+import (
+	"regexp"
+	"strings"
+	"u-control/uc-aom/internal/pkg/manifest"
+)
+
+// Matches the add-on version of a changelog section heading, e.g. "## 1.2.0-1" or "## [1.2.0-1] - 2023-05-01"
+var changelogHeadingRegexp = regexp.MustCompile(`^##\s+\[?v?([0-9]+\.[0-9]+\.[0-9]+[^\s\]]*)`)
+
+// ChangelogBetween returns the sections of the markdown changelog of the versions
+// after fromVersion up to and including toVersion, the sections up to toVersion if fromVersion is empty.
+// Sections are introduced by a level 2 heading which starts with the add-on version,
+// the text before the first section and sections without a version are omitted.
+func ChangelogBetween(changelog string, fromVersion string, toVersion string) string {
+	var excerpt strings.Builder
+	include := false
+	for _, line := range strings.SplitAfter(changelog, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			include = false
+			if match := changelogHeadingRegexp.FindStringSubmatch(line); match != nil {
+				version := match[1]
+				include = manifest.GreaterThanOrEqual(toVersion, version) && (fromVersion == "" || manifest.GreaterThan(version, fromVersion))
+			}
+		}
+		if include {
+			excerpt.WriteString(line)
+		}
+	}
+	return strings.TrimSpace(excerpt.String())
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogue_test
+
+import (
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testChangelog = `# Changelog
+
+## [Unreleased]
+- Upcoming feature
+
+## [1.2.0-1] - 2023-05-01
+- Added a dashboard
+
+## 1.1.0-2
+- Fixed the settings
+
+### Security
+- Updated the base image
+
+## v1.1.0-1
+- Initial release
+`
+
+func TestChangelogBetween(t *testing.T) {
+	testCases := map[string]struct {
+		fromVersion string
+		toVersion   string
+		expected    string
+	}{
+		"not installed": {"", "1.1.0-2", "## 1.1.0-2\n- Fixed the settings\n\n### Security\n- Updated the base image\n\n## v1.1.0-1\n- Initial release"},
+		"update":        {"1.1.0-1", "1.2.0-1", "## [1.2.0-1] - 2023-05-01\n- Added a dashboard\n\n## 1.1.0-2\n- Fixed the settings\n\n### Security\n- Updated the base image"},
+		"same version":  {"1.2.0-1", "1.2.0-1", ""},
+		"downgrade":     {"1.2.0-1", "1.1.0-1", ""},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			excerpt := catalogue.ChangelogBetween(testChangelog, testCase.fromVersion, testCase.toVersion)
+
+			// Assert
+			assert.Equal(t, testCase.expected, excerpt)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogue
+
+import (
+	"context"
+	"sync"
+)
+
+// Maximum number of repositories which are fetched from the registry at the same time.
+const maxConcurrentFetches = 4
+
+// Mutual exclusion per key, e.g. per repository name.
+// The zero value is ready to use.
+type keyedMutex struct {
+	mutexes sync.Map
+}
+
+// Locks the mutex of the key and returns the function to unlock it.
+func (k *keyedMutex) lock(key string) func() {
+	unlock, _ := k.lockContext(context.Background(), key)
+	return unlock
+}
+
+// Locks the mutex of the key and returns the function to unlock it,
+// or returns the error of the context if it is done before the mutex is locked.
+func (k *keyedMutex) lockContext(ctx context.Context, key string) (func(), error) {
+	value, _ := k.mutexes.LoadOrStore(key, make(chan struct{}, 1))
+	mutex := value.(chan struct{})
+	select {
+	case mutex <- struct{}{}:
+		return func() { <-mutex }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Runs functions in the background on at most a fixed number of goroutines.
+// A function is skipped if all goroutines are busy or a function of the same key is still running,
+// the caller is expected to try again later.
+type backgroundRunner struct {
+	slots   chan struct{}
+	running sync.Map
+}
+
+func newBackgroundRunner(workers int) *backgroundRunner {
+	return &backgroundRunner{slots: make(chan struct{}, workers)}
+}
+
+// Runs fn in the background and returns true, or returns false if fn was skipped.
+func (b *backgroundRunner) tryRun(key string, fn func()) bool {
+	if _, running := b.running.LoadOrStore(key, struct{}{}); running {
+		return false
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+	default:
+		b.running.Delete(key)
+		return false
+	}
+
+	go func() {
+		defer func() {
+			<-b.slots
+			b.running.Delete(key)
+		}()
+		fn()
+	}()
+	return true
+}
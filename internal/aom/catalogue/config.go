@@ -1,12 +1,23 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
 package catalogue
 
-import "u-control/uc-aom/internal/pkg/utils"
+import (
+	"path"
+	"u-control/uc-aom/internal/aom/config"
+	"u-control/uc-aom/internal/pkg/utils"
+)
 
 var (
 	ASSETS_TMP_PATH     = utils.GetEnv("ASSETS_TMP_PATH", "/var/run/uc-aom")
 	ASSETS_INSTALL_PATH = utils.GetEnv("ASSETS_INSTALL_PATH", "/var/lib/uc-aom")
+
+	REMOTE_CATALOGUE_CACHE_PATH = utils.GetEnv("REMOTE_CATALOGUE_CACHE_PATH", path.Join(config.UC_AOM_CACHE_DIRECTORY, "catalogue"))
+	// Duration after which the cached versions of the remote catalogue are refreshed in the background
+	REMOTE_CATALOGUE_CACHE_TTL = utils.GetEnv("REMOTE_CATALOGUE_CACHE_TTL", "15m")
+
+	// Release channel of the device and per add-on, the device is on the stable channel if the file does not exist
+	RELEASE_CHANNELS_PATH = utils.GetEnv("RELEASE_CHANNELS_PATH", "/var/lib/uc-aom/release-channels.json")
 )
@@ -0,0 +1,63 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogue
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Replaces destination with the directory source in one step,
+// so that readers of destination never find it missing or partially written.
+// Afterwards destination is a symbolic link to source, which is moved next to destination,
+// and the directory destination linked to before is removed.
+func swapDirectory(source string, destination string) error {
+	dir, name := filepath.Split(destination)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	// Reserves a unique name for the source, the caller serializes the swaps of destination.
+	generation, err := os.MkdirTemp(dir, "."+name+"-")
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(generation); err != nil {
+		return err
+	}
+	if err := os.Rename(source, generation); err != nil {
+		return err
+	}
+
+	previous, linkErr := os.Readlink(destination)
+	if linkErr != nil {
+		// A directory written before the directories were swapped is replaced without the guarantee once.
+		if err := os.RemoveAll(destination); err != nil {
+			os.RemoveAll(generation)
+			return err
+		}
+	}
+
+	link := generation + ".link"
+	if err := os.Symlink(filepath.Base(generation), link); err != nil {
+		os.RemoveAll(generation)
+		return err
+	}
+	if err := os.Rename(link, destination); err != nil {
+		os.Remove(link)
+		os.RemoveAll(generation)
+		return err
+	}
+
+	if linkErr == nil && isGenerationOf(name, previous) {
+		os.RemoveAll(filepath.Join(dir, previous))
+	}
+	return nil
+}
+
+func isGenerationOf(name string, target string) bool {
+	return filepath.Base(target) == target && strings.HasPrefix(target, "."+name+"-")
+}
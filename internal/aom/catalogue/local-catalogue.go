@@ -5,6 +5,7 @@
 package catalogue
 
 import (
+	"context"
 	"errors"
 	"io"
 	"io/fs"
@@ -13,8 +14,11 @@ import (
 	"strings"
 	"u-control/uc-aom/internal/aom/manifest"
 	"u-control/uc-aom/internal/aom/registry"
+	"u-control/uc-aom/internal/aom/utils"
 	model "u-control/uc-aom/internal/pkg/manifest"
+	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -49,7 +53,8 @@ func (c *localAddOnCatalogue) FetchManifest(name string, version string) (*model
 	}
 
 	processor := registry.NewUcImageLayerProcessor(accumulator.action)
-	_, err = c.addOnRegistry.Pull(name, version, processor)
+	_, err = c.addOnRegistry.Pull(context.Background(), name, version, processor)
+	accumulator.closeImageReaders()
 	if err != nil {
 		return nil, err
 	}
@@ -70,23 +75,46 @@ func (c *localAddOnCatalogue) PullAddOn(name string, version string) (CatalogueA
 		destination: destination,
 	}
 
-	processor := registry.NewAcceptAllManifestLayerProcessor(accumulator.action)
-	estimatedInstallSize, err := c.addOnRegistry.Pull(name, version, processor)
+	processor := registry.NewLayerDescriptorRecorder(registry.NewAcceptAllManifestLayerProcessor(accumulator.action))
+	estimatedInstallSize, err := c.addOnRegistry.Pull(context.Background(), name, version, processor)
 	if err != nil {
+		accumulator.closeImageReaders()
 		return CatalogueAddOnWithImages{}, err
 	}
+	if recorder, ok := c.addOnRegistry.(registry.OriginRecorder); ok {
+		if err := recorder.RecordOrigin(name); err != nil {
+			log.Warnf("Failed to record the catalogue source of '%s': %v", name, err)
+		}
+	}
 	addOn, err := c.GetAddOn(name)
 	if err != nil {
+		accumulator.closeImageReaders()
 		return CatalogueAddOnWithImages{}, err
 	}
 
 	return CatalogueAddOnWithImages{
-			AddOn:                addOn,
-			DockerImageData:      accumulator.imageReaders,
-			EstimatedInstallSize: estimatedInstallSize},
+			AddOn:           addOn,
+			DockerImageData: accumulator.imageReaders,
+			DiskFootprint:   newDiskFootprint(estimatedInstallSize, processor.Descriptors)},
 		nil
 }
 
+func (c *localAddOnCatalogue) FetchDiskFootprint(name string, version string) (DiskFootprint, error) {
+	log.Tracef("LocalCatalogue.FetchDiskFootprint('%s', '%s')", name, version)
+	processor := registry.NewLayerDescriptorRecorder(registry.NewAcceptNoneManifestLayerProcessor(nil))
+	estimatedInstallSize, err := c.addOnRegistry.Pull(context.Background(), name, version, processor)
+	if err != nil {
+		return DiskFootprint{}, err
+	}
+
+	return newDiskFootprint(estimatedInstallSize, processor.Descriptors), nil
+}
+
+func (c *localAddOnCatalogue) GetAddOnAssetsSize(name string) (uint64, error) {
+	log.Tracef("LocalCatalogue.GetAddOnAssetsSize('%s')", name)
+	return utils.DirectorySize(c.getInstallLocation(name))
+}
+
 func (c *localAddOnCatalogue) DeleteAddOn(name string) error {
 	log.Tracef("LocalCatalogue.DeleteAddOn('%s')", name)
 	location := c.getInstallLocation(name)
@@ -148,3 +176,39 @@ func (p *dockerImageAccumulator) action(src io.Reader, mediaType string) {
 		log.Error("WriteUcManifestContent() error =", err)
 	}
 }
+
+// Closes the docker image readers which are not handed over to the install, e.g. the opened files of a staged package.
+func (p *dockerImageAccumulator) closeImageReaders() {
+	for _, reader := range p.imageReaders {
+		if closer, ok := reader.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+	p.imageReaders = nil
+}
+
+func newDiskFootprint(estimatedInstallSize uint64, descriptors []ocispec.Descriptor) DiskFootprint {
+	footprint := DiskFootprint{EstimatedInstallSize: estimatedInstallSize}
+	dockerImageLayers := make([]oraswrapper.DockerImageLayer, 0)
+	allLayersKnown := true
+
+	for _, descriptor := range descriptors {
+		if registry.IsUcImageLayerMediaType(descriptor.MediaType) {
+			continue
+		}
+
+		footprint.ArchiveSize += uint64(descriptor.Size)
+		footprint.Archives = append(footprint.Archives, descriptor)
+		layers, ok := oraswrapper.ParseDockerImageLayersAnnotation(descriptor.Annotations)
+		if !ok {
+			allLayersKnown = false
+			continue
+		}
+		dockerImageLayers = append(dockerImageLayers, layers...)
+	}
+
+	if allLayersKnown {
+		footprint.DockerImageLayers = dockerImageLayers
+	}
+	return footprint
+}
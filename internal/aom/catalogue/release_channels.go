@@ -0,0 +1,219 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogue
+
+import (
+	"fmt"
+	"sync"
+	"u-control/uc-aom/internal/aom/utils"
+	model "u-control/uc-aom/internal/pkg/manifest"
+)
+
+// ReleaseChannel selects the versions of an add-on which the remote catalogue offers.
+type ReleaseChannel string
+
+const (
+	// Releases only
+	ReleaseChannelStable ReleaseChannel = "stable"
+
+	// Releases and release candidates
+	ReleaseChannelRC ReleaseChannel = "rc"
+
+	// Releases, release candidates and beta versions
+	ReleaseChannelBeta ReleaseChannel = "beta"
+
+	// All versions
+	ReleaseChannelAlpha ReleaseChannel = "alpha"
+)
+
+// Pre-releases offered per channel, the empty pre-release is a release.
+var preReleasesOfChannel = map[ReleaseChannel][]string{
+	ReleaseChannelStable: {""},
+	ReleaseChannelRC:     {"", "rc"},
+	ReleaseChannelBeta:   {"", "rc", "beta"},
+}
+
+// Returns whether the channel offers the add-on version.
+// Versions which are no add-on versions are never offered, as their pre-release is unknown.
+func (c ReleaseChannel) Offers(addOnVersion string) bool {
+	preRelease, err := model.PreReleaseOf(addOnVersion)
+	if err != nil {
+		return false
+	}
+	if c == ReleaseChannelAlpha {
+		return true
+	}
+
+	for _, offered := range preReleasesOfChannel[c] {
+		if preRelease == offered {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the versions offered by the channel, keeping their order.
+func (c ReleaseChannel) Filter(addOnVersions []string) []string {
+	offered := make([]string, 0, len(addOnVersions))
+	for _, addOnVersion := range addOnVersions {
+		if c.Offers(addOnVersion) {
+			offered = append(offered, addOnVersion)
+		}
+	}
+	return offered
+}
+
+func (c ReleaseChannel) isValid() bool {
+	return c == ReleaseChannelAlpha || preReleasesOfChannel[c] != nil
+}
+
+// Represents an unknown release channel.
+type InvalidReleaseChannelError struct {
+	message string
+}
+
+func (e *InvalidReleaseChannelError) Error() string {
+	return e.message
+}
+
+// ReleaseChannelProvider provides the release channel per add-on.
+type ReleaseChannelProvider interface {
+	// Returns the channel of the add-on identified by name.
+	ChannelOf(name string) ReleaseChannel
+}
+
+// ReleaseChannelManager manages the release channel of the device and the overrides per add-on.
+type ReleaseChannelManager interface {
+	ReleaseChannelProvider
+
+	// Returns the channel of the device, which applies to add-ons without an override.
+	DefaultChannel() ReleaseChannel
+
+	// Returns the channels which override the channel of the device, per add-on name.
+	AddOnChannels() map[string]ReleaseChannel
+
+	// Sets the channel of the device.
+	SetDefaultChannel(channel ReleaseChannel) error
+
+	// Sets the channel of the add-on identified by name, an empty channel removes the override.
+	SetAddOnChannel(name string, channel ReleaseChannel) error
+}
+
+type releaseChannelSettings struct {
+	Default ReleaseChannel            `json:"default"`
+	AddOns  map[string]ReleaseChannel `json:"addOns,omitempty"`
+}
+
+// ReleaseChannelStore persists the release channels as JSON file.
+type ReleaseChannelStore struct {
+	mutex     sync.RWMutex
+	path      string
+	writeFile utils.WriteFileFunc
+	settings  releaseChannelSettings
+}
+
+// NewReleaseChannelStore reads the release channels at path.
+// The device is on the stable channel if the file does not exist.
+func NewReleaseChannelStore(readFile utils.ReadFileFunc, writeFile utils.WriteFileFunc, path string) (*ReleaseChannelStore, error) {
+	s := &ReleaseChannelStore{
+		path:      path,
+		writeFile: writeFile,
+		settings:  releaseChannelSettings{Default: ReleaseChannelStable, AddOns: make(map[string]ReleaseChannel)},
+	}
+
+	exists, err := utils.ReadJSONFile(readFile, path, "release channels", &s.settings)
+	if err != nil || !exists {
+		return s, err
+	}
+	if !s.settings.Default.isValid() {
+		return nil, fmt.Errorf("Invalid release channel '%s' in '%s'", s.settings.Default, path)
+	}
+	if s.settings.AddOns == nil {
+		s.settings.AddOns = make(map[string]ReleaseChannel)
+	}
+	for name, channel := range s.settings.AddOns {
+		if !channel.isValid() {
+			return nil, fmt.Errorf("Invalid release channel '%s' of '%s' in '%s'", channel, name, path)
+		}
+	}
+	return s, nil
+}
+
+func (s *ReleaseChannelStore) ChannelOf(name string) ReleaseChannel {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if channel, ok := s.settings.AddOns[name]; ok {
+		return channel
+	}
+	return s.settings.Default
+}
+
+func (s *ReleaseChannelStore) DefaultChannel() ReleaseChannel {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.settings.Default
+}
+
+func (s *ReleaseChannelStore) AddOnChannels() map[string]ReleaseChannel {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	channels := make(map[string]ReleaseChannel, len(s.settings.AddOns))
+	for name, channel := range s.settings.AddOns {
+		channels[name] = channel
+	}
+	return channels
+}
+
+func (s *ReleaseChannelStore) SetDefaultChannel(channel ReleaseChannel) error {
+	if !channel.isValid() {
+		return &InvalidReleaseChannelError{message: fmt.Sprintf("Unknown release channel '%s'", channel)}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.settings.Default
+	s.settings.Default = channel
+	if err := s.save(); err != nil {
+		s.settings.Default = previous
+		return err
+	}
+	return nil
+}
+
+func (s *ReleaseChannelStore) SetAddOnChannel(name string, channel ReleaseChannel) error {
+	if name == "" {
+		return &InvalidReleaseChannelError{message: "The add-on name is required"}
+	}
+	if channel != "" && !channel.isValid() {
+		return &InvalidReleaseChannelError{message: fmt.Sprintf("Unknown release channel '%s'", channel)}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, existed := s.settings.AddOns[name]
+	if channel == "" {
+		delete(s.settings.AddOns, name)
+	} else {
+		s.settings.AddOns[name] = channel
+	}
+	if err := s.save(); err != nil {
+		if existed {
+			s.settings.AddOns[name] = previous
+		} else {
+			delete(s.settings.AddOns, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// MUST be called under the write lock.
+func (s *ReleaseChannelStore) save() error {
+	return utils.WriteJSONFile(s.writeFile, s.path, s.settings, 0644)
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogue_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseChannelOffers(t *testing.T) {
+	versions := []string{"1.0.0-1", "1.0.0-1-rc.1", "1.0.0-beta.2-1", "1.0.0-1-alpha.1", "latest"}
+	testCases := map[catalogue.ReleaseChannel][]string{
+		catalogue.ReleaseChannelStable: {"1.0.0-1"},
+		catalogue.ReleaseChannelRC:     {"1.0.0-1", "1.0.0-1-rc.1"},
+		catalogue.ReleaseChannelBeta:   {"1.0.0-1", "1.0.0-1-rc.1", "1.0.0-beta.2-1"},
+		catalogue.ReleaseChannelAlpha:  {"1.0.0-1", "1.0.0-1-rc.1", "1.0.0-beta.2-1", "1.0.0-1-alpha.1"},
+	}
+
+	for channel, expected := range testCases {
+		t.Run(string(channel), func(t *testing.T) {
+			// Act
+			got := channel.Filter(versions)
+
+			// Assert
+			assert.Equal(t, expected, got)
+		})
+	}
+}
+
+func TestReleaseChannelStorePersistsChannels(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "release-channels.json")
+	uut, err := catalogue.NewReleaseChannelStore(os.ReadFile, os.WriteFile, path)
+	assert.NoError(t, err)
+
+	// Act
+	defaultChannel := uut.ChannelOf("add-on")
+	assert.NoError(t, uut.SetDefaultChannel(catalogue.ReleaseChannelRC))
+	assert.NoError(t, uut.SetAddOnChannel("add-on", catalogue.ReleaseChannelBeta))
+	assert.NoError(t, uut.SetAddOnChannel("other", catalogue.ReleaseChannelAlpha))
+	assert.NoError(t, uut.SetAddOnChannel("other", ""))
+	restarted, err := catalogue.NewReleaseChannelStore(os.ReadFile, os.WriteFile, path)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, catalogue.ReleaseChannelStable, defaultChannel)
+	assert.Equal(t, catalogue.ReleaseChannelRC, restarted.DefaultChannel())
+	assert.Equal(t, catalogue.ReleaseChannelBeta, restarted.ChannelOf("add-on"))
+	assert.Equal(t, catalogue.ReleaseChannelRC, restarted.ChannelOf("other"))
+	assert.Equal(t, map[string]catalogue.ReleaseChannel{"add-on": catalogue.ReleaseChannelBeta}, restarted.AddOnChannels())
+}
+
+func TestReleaseChannelStoreErrors(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "release-channels.json")
+	uut, _ := catalogue.NewReleaseChannelStore(os.ReadFile, os.WriteFile, path)
+	os.WriteFile(filepath.Join(filepath.Dir(path), "invalid.json"), []byte(`{"default": "nightly"}`), 0644)
+
+	// Act
+	unknownErr := uut.SetDefaultChannel("nightly")
+	missingNameErr := uut.SetAddOnChannel("", catalogue.ReleaseChannelBeta)
+	_, invalidFileErr := catalogue.NewReleaseChannelStore(os.ReadFile, os.WriteFile, filepath.Join(filepath.Dir(path), "invalid.json"))
+
+	// Assert
+	assert.IsType(t, &catalogue.InvalidReleaseChannelError{}, unknownErr)
+	assert.IsType(t, &catalogue.InvalidReleaseChannelError{}, missingNameErr)
+	assert.Error(t, invalidFileErr)
+	assert.NoFileExists(t, path)
+}
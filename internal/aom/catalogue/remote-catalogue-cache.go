@@ -0,0 +1,251 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogue
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	model "u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/docker/docker/daemon/graphdriver/copy"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	remoteCatalogueIndexFilename = "index.json"
+	remoteCatalogueAssetsDirname = "assets"
+)
+
+// Latest version of an add-on in the remote catalogue.
+type remoteCatalogueEntry struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Digest of the manifest the version refers to
+	Digest string `json:"digest"`
+	// Summary from the package annotations, nil if the package does not provide one
+	Summary *model.AddOnSummary `json:"summary,omitempty"`
+}
+
+type remoteCatalogueIndex struct {
+	RefreshedAt time.Time               `json:"refreshedAt"`
+	AddOns      []*remoteCatalogueEntry `json:"addOns"`
+}
+
+// RemoteCatalogueCache persists the latest versions of the remote catalogue on disk.
+// The manifest and assets of an add-on are kept by the digest of its manifest,
+// so they are only pulled again if the content of the version changes.
+type RemoteCatalogueCache struct {
+	// Location where the cache is persisted.
+	Root string
+
+	// Duration after which the cached versions are refreshed.
+	TTL time.Duration
+
+	mutex   sync.Mutex
+	index   *remoteCatalogueIndex
+	refresh *pendingRefresh
+
+	// Serializes the removal of cached assets with restoring and storing them.
+	assetsMutex sync.RWMutex
+}
+
+// Refresh of the cache which is running, done is closed once err is set.
+type pendingRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// NewRemoteCatalogueCache creates an instance of RemoteCatalogueCache
+func NewRemoteCatalogueCache(root string, ttl time.Duration) *RemoteCatalogueCache {
+	return &RemoteCatalogueCache{Root: root, TTL: ttl}
+}
+
+// Returns the cached entries and whether they are older than the TTL.
+// The entries are nil if the catalogue was never cached.
+func (c *RemoteCatalogueCache) entries() ([]*remoteCatalogueEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.index == nil {
+		index, err := c.readIndex()
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				log.Warnf("Ignoring remote catalogue cache: %v", err)
+			}
+			return nil, true
+		}
+		c.index = index
+	}
+
+	stale := time.Since(c.index.RefreshedAt) > c.TTL
+	return c.index.AddOns, stale
+}
+
+// Replaces the cached entries and removes the assets which are no longer referenced.
+func (c *RemoteCatalogueCache) store(entries []*remoteCatalogueEntry) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	index := &remoteCatalogueIndex{RefreshedAt: time.Now(), AddOns: entries}
+	if err := c.writeIndex(index); err != nil {
+		return err
+	}
+	c.index = index
+	c.pruneAssets(entries)
+	return nil
+}
+
+// Marks the start of a refresh and returns it.
+// Returns false if a refresh is already running, in that case the running refresh is returned.
+func (c *RemoteCatalogueCache) beginRefresh() (*pendingRefresh, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.refresh != nil {
+		return c.refresh, false
+	}
+	c.refresh = &pendingRefresh{done: make(chan struct{})}
+	return c.refresh, true
+}
+
+// Marks the end of the running refresh with its result.
+func (c *RemoteCatalogueCache) endRefresh(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.refresh.err = err
+	close(c.refresh.done)
+	c.refresh = nil
+}
+
+// Copies the cached assets of the digest to destination.
+// Returns false if the digest is not cached.
+func (c *RemoteCatalogueCache) restoreAssets(digest string, destination string) (bool, error) {
+	c.assetsMutex.RLock()
+	defer c.assetsMutex.RUnlock()
+
+	source := c.assetsPath(digest)
+	if _, err := os.Stat(source); err != nil {
+		return false, nil
+	}
+
+	// Copy next to the destination first, so that the destination is replaced in one step.
+	if err := os.MkdirAll(filepath.Dir(destination), os.ModePerm); err != nil {
+		return false, err
+	}
+	scratch, err := os.MkdirTemp(filepath.Dir(destination), ".restore-")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(scratch)
+
+	if err := copy.DirCopy(source, scratch, copy.Content, false); err != nil {
+		return false, err
+	}
+	if err := swapDirectory(scratch, destination); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Returns whether the assets of the digest are cached.
+func (c *RemoteCatalogueCache) hasAssets(digest string) bool {
+	c.assetsMutex.RLock()
+	defer c.assetsMutex.RUnlock()
+
+	_, err := os.Stat(c.assetsPath(digest))
+	return err == nil
+}
+
+// Copies the assets at source into the cache for the digest.
+func (c *RemoteCatalogueCache) storeAssets(digest string, source string) error {
+	c.assetsMutex.Lock()
+	defer c.assetsMutex.Unlock()
+
+	// The assets directory of an add-on is a symbolic link to its content.
+	source, err := filepath.EvalSymlinks(source)
+	if err != nil {
+		return err
+	}
+
+	destination := c.assetsPath(digest)
+	if err := os.MkdirAll(filepath.Dir(destination), os.ModePerm); err != nil {
+		return err
+	}
+
+	// Copy into a temporary location first, so that an interrupted copy is never restored.
+	tmp := destination + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	if err := copy.DirCopy(source, tmp, copy.Content, false); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	os.RemoveAll(destination)
+	return os.Rename(tmp, destination)
+}
+
+func (c *RemoteCatalogueCache) assetsPath(digest string) string {
+	return filepath.Join(c.Root, remoteCatalogueAssetsDirname, strings.ReplaceAll(digest, ":", "-"))
+}
+
+func (c *RemoteCatalogueCache) pruneAssets(entries []*remoteCatalogueEntry) {
+	c.assetsMutex.Lock()
+	defer c.assetsMutex.Unlock()
+
+	referenced := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		referenced[c.assetsPath(entry.Digest)] = true
+	}
+
+	cached, err := filepath.Glob(filepath.Join(c.Root, remoteCatalogueAssetsDirname, "*"))
+	if err != nil {
+		return
+	}
+	for _, path := range cached {
+		if !referenced[path] {
+			log.Tracef("Removing unreferenced remote catalogue assets '%s'", path)
+			os.RemoveAll(path)
+		}
+	}
+}
+
+func (c *RemoteCatalogueCache) readIndex() (*remoteCatalogueIndex, error) {
+	content, err := os.ReadFile(filepath.Join(c.Root, remoteCatalogueIndexFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	index := &remoteCatalogueIndex{}
+	if err := json.Unmarshal(content, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func (c *RemoteCatalogueCache) writeIndex(index *remoteCatalogueIndex) error {
+	if err := os.MkdirAll(c.Root, os.ModePerm); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(c.Root, remoteCatalogueIndexFilename)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
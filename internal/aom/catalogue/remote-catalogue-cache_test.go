@@ -0,0 +1,229 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogue_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/registry"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetLatestAddOnsFromPersistentCache(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	cacheRoot := t.TempDir()
+	mockRegistry := &registry.MockRegistry{}
+	mockManifestReader := &mockManifestReader{}
+	setupRegistryWithLatestVersion(mockRegistry, root, "repo", "0.2.0-1", "sha256:aaa")
+	mockManifestReader.On("ReadManifestFrom", filepath.Join(root, "repo")).Return(&manifest.Root{Version: "0.2.0-1"}, nil)
+
+	uut := catalogue.NewCachedORASRemoteAddOnCatalogue(root, mockRegistry, mockManifestReader, catalogue.NewRemoteCatalogueCache(cacheRoot, time.Hour))
+	restarted := catalogue.NewCachedORASRemoteAddOnCatalogue(root, mockRegistry, mockManifestReader, catalogue.NewRemoteCatalogueCache(cacheRoot, time.Hour))
+
+	// Act
+	first, firstErr := uut.GetLatestAddOns(context.Background())
+	os.RemoveAll(filepath.Join(root, "repo"))
+	second, secondErr := restarted.GetLatestAddOns(context.Background())
+
+	// Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+	assert.Equal(t, first, second)
+	assert.Len(t, second, 1)
+	assert.Equal(t, "0.2.0-1", second[0].Version)
+	assert.FileExists(t, filepath.Join(root, "repo", "logo.png"))
+	mockRegistry.AssertNumberOfCalls(t, "Repositories", 1)
+	mockRegistry.AssertNumberOfCalls(t, "Pull", 1)
+}
+
+func TestRefreshPullsChangedDigest(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	cacheRoot := t.TempDir()
+	mockManifestReader := &mockManifestReader{}
+	mockManifestReader.On("ReadManifestFrom", filepath.Join(root, "repo")).Return(&manifest.Root{}, nil)
+
+	previousRegistry := &registry.MockRegistry{}
+	setupRegistryWithLatestVersion(previousRegistry, root, "repo", "0.2.0-1", "sha256:aaa")
+	previous := catalogue.NewCachedORASRemoteAddOnCatalogue(root, previousRegistry, mockManifestReader, catalogue.NewRemoteCatalogueCache(cacheRoot, time.Hour))
+	previous.GetLatestAddOns(context.Background())
+
+	mockRegistry := &registry.MockRegistry{}
+	setupRegistryWithLatestVersion(mockRegistry, root, "repo", "0.3.0-1", "sha256:bbb")
+	uut := catalogue.NewCachedORASRemoteAddOnCatalogue(root, mockRegistry, mockManifestReader, catalogue.NewRemoteCatalogueCache(cacheRoot, time.Hour))
+
+	// Act
+	refreshErr := uut.Refresh(context.Background())
+	got, err := uut.GetLatestAddOns(context.Background())
+
+	// Assert
+	assert.Nil(t, refreshErr)
+	assert.Nil(t, err)
+	assert.Len(t, got, 1)
+	assert.Equal(t, "0.3.0-1", got[0].Version)
+	assert.NoDirExists(t, filepath.Join(cacheRoot, "assets", "sha256-aaa"))
+	assert.DirExists(t, filepath.Join(cacheRoot, "assets", "sha256-bbb"))
+	mockRegistry.AssertExpectations(t)
+}
+
+func TestRefreshRemoteRegistryConnectionProblem(t *testing.T) {
+	// Arrange
+	mockRegistry := &registry.MockRegistry{}
+	mockRegistry.On("Repositories").Return([]string{}, errors.New("NETWORK_ERROR"))
+	uut := catalogue.NewCachedORASRemoteAddOnCatalogue(t.TempDir(), mockRegistry, nil, catalogue.NewRemoteCatalogueCache(t.TempDir(), time.Hour))
+
+	// Act
+	err := uut.Refresh(context.Background())
+
+	// Assert
+	assert.IsType(t, &catalogue.RemoteRegistryConnectionError{}, err)
+}
+
+func TestRefreshWaitsForRunningRefresh(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	mockRegistry := &registry.MockRegistry{}
+	mockManifestReader := &mockManifestReader{}
+	setupRegistryWithLatestVersion(mockRegistry, root, "repo", "0.2.0-1", "sha256:aaa")
+	listed := make(chan struct{})
+	release := make(chan struct{})
+	mockRegistry.ExpectedCalls[0].Run(func(args mock.Arguments) {
+		close(listed)
+		<-release
+	})
+	uut := catalogue.NewCachedORASRemoteAddOnCatalogue(root, mockRegistry, mockManifestReader, catalogue.NewRemoteCatalogueCache(t.TempDir(), time.Hour))
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	runningErr := make(chan error)
+	go func() { runningErr <- uut.Refresh(context.Background()) }()
+	<-listed
+	waitingErr := uut.Refresh(canceled)
+	close(release)
+
+	// Assert
+	assert.ErrorIs(t, waitingErr, context.Canceled)
+	assert.Nil(t, <-runningErr)
+	mockRegistry.AssertNumberOfCalls(t, "Repositories", 1)
+}
+
+func TestGetLatestAddOnsRestoresAssetsOnce(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	cacheRoot := t.TempDir()
+	mockRegistry := &registry.MockRegistry{}
+	mockManifestReader := &mockManifestReader{}
+	setupRegistryWithLatestVersion(mockRegistry, root, "repo", "0.2.0-1", "sha256:aaa")
+	mockManifestReader.On("ReadManifestFrom", filepath.Join(root, "repo")).Return(&manifest.Root{Version: "0.2.0-1"}, nil)
+	previous := catalogue.NewCachedORASRemoteAddOnCatalogue(root, mockRegistry, mockManifestReader, catalogue.NewRemoteCatalogueCache(cacheRoot, time.Hour))
+	previous.GetLatestAddOns(context.Background())
+	uut := catalogue.NewCachedORASRemoteAddOnCatalogue(root, mockRegistry, mockManifestReader, catalogue.NewRemoteCatalogueCache(cacheRoot, time.Hour))
+
+	// Act
+	_, firstErr := uut.GetLatestAddOns(context.Background())
+	marker := filepath.Join(root, "repo", "marker")
+	os.WriteFile(marker, []byte{}, 0644)
+	_, secondErr := uut.GetLatestAddOns(context.Background())
+
+	// Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+	assert.FileExists(t, marker)
+	mockRegistry.AssertNumberOfCalls(t, "Pull", 1)
+}
+
+func TestGetLatestAddOnsFromSummary(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	cacheRoot := t.TempDir()
+	mockRegistry := &registry.MockRegistry{}
+	mockManifestReader := &mockManifestReader{}
+	summary := &manifest.AddOnSummary{Title: "Summary", Version: "0.2.0-1", ManifestVersion: manifest.ValidManifestVersion}
+	mockRegistry.On("Repositories").Return([]string{"repo"}, nil)
+	mockRegistry.On("Tags", "repo").Return([]string{"0.2.0-1"}, nil)
+	mockRegistry.On("Summary", "repo", "0.2.0-1").Return("sha256:aaa", summary, nil)
+	writeAssets := func(args mock.Arguments) {
+		scratch, _ := filepath.Glob(filepath.Join(root, ".scratch-*"))
+		os.WriteFile(filepath.Join(scratch[0], "logo.png"), []byte("logo"), 0644)
+	}
+	mockRegistry.On("Pull", "repo", "0.2.0-1", mock.AnythingOfType("*registry.ucImageLayerProcessor")).Run(writeAssets).Return(uint64(0), nil)
+	mockManifestReader.On("ReadManifestFrom", filepath.Join(root, "repo")).Return(&manifest.Root{Title: "Pulled", Version: "0.2.0-1"}, nil)
+	uut := catalogue.NewCachedORASRemoteAddOnCatalogue(root, mockRegistry, mockManifestReader, catalogue.NewRemoteCatalogueCache(cacheRoot, time.Hour))
+
+	// Act
+	got, err := uut.GetLatestAddOns(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, got, 1)
+	assert.True(t, got[0].IsSummary)
+	assert.Equal(t, "Summary", got[0].Manifest.Title)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(cacheRoot, "assets", "sha256-aaa"))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// The assets are pulled in the background, so the next listing reads the pulled manifest.
+	got, err = uut.GetLatestAddOns(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, got, 1)
+	assert.False(t, got[0].IsSummary)
+	assert.Equal(t, "Pulled", got[0].Manifest.Title)
+	mockRegistry.AssertNumberOfCalls(t, "Pull", 1)
+}
+
+func TestGetLatestAddOnsPullsManifestOfInvalidSummary(t *testing.T) {
+	testCases := map[string]*manifest.AddOnSummary{
+		"without title":     {Version: "0.2.0-1", ManifestVersion: manifest.ValidManifestVersion},
+		"invalid version":   {Title: "Summary", Version: "latest", ManifestVersion: manifest.ValidManifestVersion},
+		"different version": {Title: "Summary", Version: "0.1.0-1", ManifestVersion: manifest.ValidManifestVersion},
+	}
+
+	for name, summary := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			root := t.TempDir()
+			mockRegistry := &registry.MockRegistry{}
+			mockManifestReader := &mockManifestReader{}
+			mockRegistry.On("Repositories").Return([]string{"repo"}, nil)
+			mockRegistry.On("Tags", "repo").Return([]string{"0.2.0-1"}, nil)
+			mockRegistry.On("Summary", "repo", "0.2.0-1").Return("sha256:aaa", summary, nil)
+			mockRegistry.On("Pull", "repo", "0.2.0-1", mock.AnythingOfType("*registry.ucImageLayerProcessor")).Return(uint64(0), nil)
+			mockManifestReader.On("ReadManifestFrom", filepath.Join(root, "repo")).Return(&manifest.Root{Title: "Pulled", Version: "0.2.0-1"}, nil)
+			uut := catalogue.NewCachedORASRemoteAddOnCatalogue(root, mockRegistry, mockManifestReader, catalogue.NewRemoteCatalogueCache(t.TempDir(), time.Hour))
+
+			// Act
+			got, err := uut.GetLatestAddOns(context.Background())
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Len(t, got, 1)
+			assert.False(t, got[0].IsSummary)
+			assert.Equal(t, "Pulled", got[0].Manifest.Title)
+		})
+	}
+}
+
+func setupRegistryWithLatestVersion(mockRegistry *registry.MockRegistry, root string, repository string, version string, digest string) {
+	mockRegistry.On("Repositories").Return([]string{repository}, nil)
+	mockRegistry.On("Tags", repository).Return([]string{"0.1.0-1", version}, nil)
+	mockRegistry.On("Summary", repository, version).Return(digest, nil, nil)
+	// The catalogue pulls into a scratch directory below its root
+	writeAssets := func(args mock.Arguments) {
+		scratch, _ := filepath.Glob(filepath.Join(root, ".scratch-*"))
+		os.WriteFile(filepath.Join(scratch[0], "logo.png"), []byte(version), 0644)
+	}
+	mockRegistry.On("Pull", repository, version, mock.AnythingOfType("*registry.ucImageLayerProcessor")).Run(writeAssets).Return(uint64(0), nil)
+}
@@ -5,16 +5,20 @@
 package catalogue
 
 import (
+	"context"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"u-control/uc-aom/internal/aom/manifest"
 	"u-control/uc-aom/internal/aom/registry"
+	"u-control/uc-aom/internal/aom/utils"
 	model "u-control/uc-aom/internal/pkg/manifest"
 
+	"github.com/opencontainers/go-digest"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -24,75 +28,401 @@ type ORASRemoteAddOnCatalogue struct {
 
 	manifestReader model.ManifestFileReader
 	registry       registry.AddOnRegistry
+
+	// Persistent cache of the latest add-ons, nil if every call queries the registry.
+	cache *RemoteCatalogueCache
+
+	// Serializes the access to the assets directory of an add-on.
+	repositoryLocks keyedMutex
+
+	// Digest of the cached assets in the assets directory per add-on name,
+	// so the cached assets are only restored once.
+	restoredDigests sync.Map
+
+	// Release channels which filter the offered versions, nil if all versions are offered.
+	releaseChannels ReleaseChannelProvider
+
+	// Pulls the assets of summary entries in the background.
+	prefetches *backgroundRunner
 }
 
 // NewORASRemoteAddOnCatalogue creates an instance of ORASRemoteAddOnCatalogue
 func NewORASRemoteAddOnCatalogue(root string, registry registry.AddOnRegistry, manifestReader model.ManifestFileReader) *ORASRemoteAddOnCatalogue {
-	return &ORASRemoteAddOnCatalogue{Root: root, manifestReader: manifestReader, registry: registry}
+	return &ORASRemoteAddOnCatalogue{Root: root, manifestReader: manifestReader, registry: registry, prefetches: newBackgroundRunner(maxConcurrentFetches)}
+}
+
+// NewCachedORASRemoteAddOnCatalogue creates an instance of ORASRemoteAddOnCatalogue
+// which serves the latest add-ons from the given cache.
+func NewCachedORASRemoteAddOnCatalogue(root string, registry registry.AddOnRegistry, manifestReader model.ManifestFileReader, cache *RemoteCatalogueCache) *ORASRemoteAddOnCatalogue {
+	return &ORASRemoteAddOnCatalogue{Root: root, manifestReader: manifestReader, registry: registry, cache: cache, prefetches: newBackgroundRunner(maxConcurrentFetches)}
+}
+
+// Offers only the versions of the release channel per add-on.
+func (catalogue *ORASRemoteAddOnCatalogue) UseReleaseChannels(releaseChannels ReleaseChannelProvider) {
+	catalogue.releaseChannels = releaseChannels
 }
 
 func (catalogue *ORASRemoteAddOnCatalogue) GetAddOnNames() ([]string, error) {
 	log.Trace("RemoteCatalogue.GetAddOnNames()")
-	return catalogue.registry.Repositories()
+	return catalogue.registry.Repositories(context.Background())
 }
 
-func (catalogue *ORASRemoteAddOnCatalogue) GetAddOnVersions(name string) ([]string, error) {
+func (catalogue *ORASRemoteAddOnCatalogue) GetAddOnVersions(ctx context.Context, name string) ([]string, error) {
 	log.Tracef("RemoteCatalogue.GetAddOnVersions(%s)", name)
-	versions, err := catalogue.registry.Tags(name)
+	return catalogue.addOnVersions(ctx, name)
+}
+
+func (catalogue *ORASRemoteAddOnCatalogue) addOnVersions(ctx context.Context, name string) ([]string, error) {
+	versions, err := catalogue.registry.Tags(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
 	sort.Sort(model.ByAddOnVersion(versions))
-	return versions, nil
+	if catalogue.releaseChannels == nil {
+		return versions, nil
+	}
+
+	return catalogue.releaseChannels.ChannelOf(name).Filter(versions), nil
 }
 
-func (catalogue *ORASRemoteAddOnCatalogue) GetAddOn(name string, version string) (CatalogueAddOn, error) {
+func (catalogue *ORASRemoteAddOnCatalogue) GetAddOn(ctx context.Context, name string, version string) (CatalogueAddOn, error) {
 	log.Tracef("RemoteCatalogue.GetAddOn('%s', '%s')", name, version)
-	destination := filepath.Join(catalogue.Root, name)
+	unlock, err := catalogue.repositoryLocks.lockContext(ctx, name)
+	if err != nil {
+		return CatalogueAddOn{}, err
+	}
+	defer unlock()
+	return catalogue.pullAddOn(ctx, name, version)
+}
+
+// Pulls the manifest into a scratch directory, which is removed afterwards,
+// so the assets of the listed version are kept, e.g. while other versions are checked for updates.
+func (catalogue *ORASRemoteAddOnCatalogue) FetchManifest(ctx context.Context, name string, version string) (*model.Root, error) {
+	log.Tracef("RemoteCatalogue.FetchManifest('%s', '%s')", name, version)
+	scratch, err := os.MkdirTemp(catalogue.Root, ".scratch-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(scratch)
+
+	processor := registry.NewUcImageLayerProcessor(catalogue.action(scratch))
+	if _, err := catalogue.registry.Pull(ctx, name, version, processor); err != nil {
+		return nil, err
+	}
+	return catalogue.manifestReader.ReadManifestFrom(scratch)
+}
 
-	err := os.RemoveAll(destination)
+// Pulls the add-on into a scratch directory first, so that the assets directory
+// of the add-on is replaced in one step and never missing or partially written,
+// neither for the catalogue nor for the readers of the logo and screenshots.
+// The caller must hold the lock of the repository.
+func (catalogue *ORASRemoteAddOnCatalogue) pullAddOn(ctx context.Context, name string, version string) (CatalogueAddOn, error) {
+	scratch, err := os.MkdirTemp(catalogue.Root, ".scratch-")
 	if err != nil {
 		return CatalogueAddOn{}, err
 	}
-	processor := registry.NewUcImageLayerProcessor(catalogue.action(destination))
-	_, err = catalogue.registry.Pull(name, version, processor)
+	defer os.RemoveAll(scratch)
+
+	processor := registry.NewUcImageLayerProcessor(catalogue.action(scratch))
+	_, err = catalogue.registry.Pull(ctx, name, version, processor)
 	if err != nil {
 		return CatalogueAddOn{}, err
 	}
 
+	destination := filepath.Join(catalogue.Root, name)
+	catalogue.restoredDigests.Delete(name)
+	if err := swapDirectory(scratch, destination); err != nil {
+		return CatalogueAddOn{}, err
+	}
+
 	manifest, err := catalogue.manifestReader.ReadManifestFrom(destination)
 	if err != nil {
 		return CatalogueAddOn{}, err
 	}
-	return CatalogueAddOn{Name: name, Version: version, Manifest: *manifest}, nil
+	return CatalogueAddOn{
+		Name:      name,
+		Version:   version,
+		Manifest:  *manifest,
+		Readme:    readDocument(destination, manifest.Readme),
+		Changelog: readDocument(destination, manifest.Changelog),
+	}, nil
 }
 
-func (catalogue *ORASRemoteAddOnCatalogue) GetLatestAddOns() ([]*CatalogueAddOn, error) {
+// Returns the content of the markdown document in the assets directory, empty if the add-on has none.
+func readDocument(destination string, filename string) string {
+	if filename == "" || filepath.Base(filename) != filename {
+		return ""
+	}
+	content, err := os.ReadFile(filepath.Join(destination, filename))
+	if err != nil {
+		log.Warnf("Unable to read the document '%s': %v", filename, err)
+		return ""
+	}
+	return string(content)
+}
+
+// Returns the latest version of all add-ons, the repositories are fetched concurrently.
+// Returns the error of the context if it is done before all repositories were fetched.
+func (catalogue *ORASRemoteAddOnCatalogue) GetLatestAddOns(ctx context.Context) ([]*CatalogueAddOn, error) {
 	log.Trace("RemoteCatalogue.GetLatestAddOns()")
-	repositories, err := catalogue.GetAddOnNames()
+	if catalogue.cache == nil {
+		return catalogue.fetchLatestAddOns(ctx)
+	}
+
+	entries, stale := catalogue.cache.entries()
+	if entries == nil {
+		if err := catalogue.Refresh(ctx); err != nil {
+			return nil, err
+		}
+		entries, _ = catalogue.cache.entries()
+	} else if stale {
+		go catalogue.refreshInBackground()
+	}
+
+	results := make([]*CatalogueAddOn, len(entries))
+	err := utils.ForEachConcurrently(ctx, maxConcurrentFetches, len(entries), func(i int) {
+		catalogueAddOn, err := catalogue.getCachedAddOn(ctx, entries[i])
+		if err != nil {
+			log.Warnf("Failed to fetch AddOn from repository '%s': %v", entries[i].Name, err)
+			return
+		}
+		results[i] = &catalogueAddOn
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withoutMissingAddOns(results), nil
+}
+
+// Refreshes the cached latest versions of all add-ons from the registry.
+// If a refresh is already running, waits for it and returns its result instead of starting another one.
+// Does nothing if the catalogue is not cached.
+func (catalogue *ORASRemoteAddOnCatalogue) Refresh(ctx context.Context) error {
+	log.Trace("RemoteCatalogue.Refresh()")
+	if catalogue.cache == nil {
+		return nil
+	}
+
+	refresh, started := catalogue.cache.beginRefresh()
+	if !started {
+		select {
+		case <-refresh.done:
+			return refresh.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	err := catalogue.refresh(ctx)
+	catalogue.cache.endRefresh(err)
+	return err
+}
+
+// The caller must have begun the refresh of the cache.
+func (catalogue *ORASRemoteAddOnCatalogue) refresh(ctx context.Context) error {
+	repositories, err := catalogue.registry.Repositories(ctx)
+	if err != nil {
+		return NewRemoteRegistryConnectionError(err)
+	}
+
+	results := make([]*remoteCatalogueEntry, len(repositories))
+	err = utils.ForEachConcurrently(ctx, maxConcurrentFetches, len(repositories), func(i int) {
+		repo := repositories[i]
+		versions, err := catalogue.addOnVersions(ctx, repo)
+		if err != nil {
+			log.Tracef("Skipping repository '%s': %v", repo, err)
+			return
+		}
+		if len(versions) == 0 {
+			log.Tracef("Skipping repository '%s': no version is offered", repo)
+			return
+		}
+
+		latest := versions[len(versions)-1]
+		digest, summary, err := catalogue.registry.Summary(ctx, repo, latest)
+		if err != nil {
+			log.Warnf("Failed to resolve AddOn '%s' in version '%s': %v", repo, latest, err)
+			return
+		}
+		results[i] = &remoteCatalogueEntry{Name: repo, Version: latest, Digest: digest, Summary: summary}
+	})
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*remoteCatalogueEntry, 0, len(results))
+	for _, entry := range results {
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return catalogue.cache.store(entries)
+}
+
+// Refreshes the cache unless a refresh is already running.
+func (catalogue *ORASRemoteAddOnCatalogue) refreshInBackground() {
+	if _, started := catalogue.cache.beginRefresh(); !started {
+		return
+	}
+
+	err := catalogue.refresh(context.Background())
+	catalogue.cache.endRefresh(err)
+	if err != nil {
+		log.Warnf("Failed to refresh the remote catalogue: %v", err)
+	}
+}
+
+// Returns the add-on of the entry, the manifest is only pulled if its digest is not cached.
+// If the package provides a valid summary, the add-on is returned from the summary instead
+// and the assets are pulled in the background.
+func (catalogue *ORASRemoteAddOnCatalogue) getCachedAddOn(ctx context.Context, entry *remoteCatalogueEntry) (CatalogueAddOn, error) {
+	unlock := catalogue.repositoryLocks.lock(entry.Name)
+	defer unlock()
+
+	destination := filepath.Join(catalogue.Root, entry.Name)
+	restored := catalogue.isRestored(entry)
+	if !restored {
+		var err error
+		restored, err = catalogue.cache.restoreAssets(entry.Digest, destination)
+		if err != nil {
+			log.Warnf("Failed to restore cached assets of '%s': %v", entry.Name, err)
+		}
+		if restored {
+			catalogue.restoredDigests.Store(entry.Name, entry.Digest)
+		}
+	}
+
+	if !restored {
+		if catalogue.isValidSummary(entry) {
+			catalogue.prefetches.tryRun(entry.Digest, func() { catalogue.prefetchAssets(entry) })
+			return catalogue.summaryAddOn(entry, destination), nil
+		}
+		return catalogue.pullAndCacheAddOn(ctx, entry, destination)
+	}
+
+	manifest, err := catalogue.manifestReader.ReadManifestFrom(destination)
+	if err != nil {
+		return CatalogueAddOn{}, err
+	}
+	return CatalogueAddOn{Name: entry.Name, Version: entry.Version, Manifest: *manifest}, nil
+}
+
+// Returns whether the assets directory of the entry holds the cached assets of its digest.
+// The caller must hold the lock of the repository.
+func (catalogue *ORASRemoteAddOnCatalogue) isRestored(entry *remoteCatalogueEntry) bool {
+	restoredDigest, ok := catalogue.restoredDigests.Load(entry.Name)
+	return ok && restoredDigest == entry.Digest
+}
+
+// Returns whether the entry holds a summary which can be listed instead of the manifest.
+func (catalogue *ORASRemoteAddOnCatalogue) isValidSummary(entry *remoteCatalogueEntry) bool {
+	if entry.Summary == nil {
+		return false
+	}
+	if err := entry.Summary.Validate(); err != nil {
+		log.Debugf("Ignoring the summary of AddOn '%s': %v", entry.Name, err)
+		return false
+	}
+	if entry.Summary.Version != entry.Version {
+		log.Debugf("Ignoring the summary of AddOn '%s' in version '%s' for version '%s'", entry.Name, entry.Summary.Version, entry.Version)
+		return false
+	}
+	return true
+}
+
+// The caller must hold the lock of the repository.
+func (catalogue *ORASRemoteAddOnCatalogue) pullAndCacheAddOn(ctx context.Context, entry *remoteCatalogueEntry, destination string) (CatalogueAddOn, error) {
+	catalogueAddOn, err := catalogue.pullAddOn(ctx, entry.Name, entry.Version)
+	if err != nil {
+		return CatalogueAddOn{}, err
+	}
+
+	if err := catalogue.cache.storeAssets(entry.Digest, destination); err != nil {
+		log.Warnf("Failed to cache assets of '%s': %v", entry.Name, err)
+		return catalogueAddOn, nil
+	}
+	catalogue.restoredDigests.Store(entry.Name, entry.Digest)
+	return catalogueAddOn, nil
+}
+
+// Pulls the assets of the entry into the cache, unless they are already cached.
+// Only called by the background runner of the prefetches, so at most maxConcurrentFetches pulls run at once.
+func (catalogue *ORASRemoteAddOnCatalogue) prefetchAssets(entry *remoteCatalogueEntry) {
+	unlock := catalogue.repositoryLocks.lock(entry.Name)
+	defer unlock()
+
+	if catalogue.cache.hasAssets(entry.Digest) {
+		return
+	}
+
+	destination := filepath.Join(catalogue.Root, entry.Name)
+	if _, err := catalogue.pullAndCacheAddOn(context.Background(), entry, destination); err != nil {
+		log.Warnf("Failed to prefetch AddOn '%s' in version '%s': %v", entry.Name, entry.Version, err)
+	}
+}
+
+// Returns the add-on of the entry from its summary.
+// The logo of the previously pulled assets is kept if its content did not change,
+// otherwise the logo is omitted until the assets are pulled.
+// The caller must hold the lock of the repository.
+func (catalogue *ORASRemoteAddOnCatalogue) summaryAddOn(entry *remoteCatalogueEntry, destination string) CatalogueAddOn {
+	manifest := entry.Summary.ToManifest()
+	if entry.Summary.LogoDigest != "" {
+		if previous, err := catalogue.manifestReader.ReadManifestFrom(destination); err == nil && previous.Logo != "" {
+			logo, err := os.ReadFile(filepath.Join(destination, previous.Logo))
+			if err == nil && digest.FromBytes(logo).String() == entry.Summary.LogoDigest {
+				manifest.Logo = previous.Logo
+			}
+		}
+	}
+	return CatalogueAddOn{Name: entry.Name, Version: entry.Version, Manifest: manifest, IsSummary: true}
+}
+
+func (catalogue *ORASRemoteAddOnCatalogue) fetchLatestAddOns(ctx context.Context) ([]*CatalogueAddOn, error) {
+	repositories, err := catalogue.registry.Repositories(ctx)
 	if err != nil {
 		return nil, NewRemoteRegistryConnectionError(err)
 	}
 
-	addOns := make([]*CatalogueAddOn, 0, len(repositories))
-	for _, repo := range repositories {
-		versions, err := catalogue.GetAddOnVersions(repo)
+	results := make([]*CatalogueAddOn, len(repositories))
+	err = utils.ForEachConcurrently(ctx, maxConcurrentFetches, len(repositories), func(i int) {
+		repo := repositories[i]
+		versions, err := catalogue.addOnVersions(ctx, repo)
 		if err != nil {
 			log.Tracef("Skipping repository '%s': %v", repo, err)
-			continue
+			return
+		}
+		if len(versions) == 0 {
+			log.Tracef("Skipping repository '%s': no version is offered", repo)
+			return
 		}
 
 		log.Tracef("Repository: %s, Tags: [%s]", repo, strings.Join(versions, ", "))
 		latest := versions[len(versions)-1]
-		catalogueAddOn, err := catalogue.GetAddOn(repo, latest)
+		unlock := catalogue.repositoryLocks.lock(repo)
+		catalogueAddOn, err := catalogue.pullAddOn(ctx, repo, latest)
+		unlock()
 		if err != nil {
 			log.Warnf("Failed to fetch AddOn from repository '%s': %v", repo, err)
-			continue
+			return
+		}
+		results[i] = &catalogueAddOn
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withoutMissingAddOns(results), nil
+}
+
+func withoutMissingAddOns(results []*CatalogueAddOn) []*CatalogueAddOn {
+	addOns := make([]*CatalogueAddOn, 0, len(results))
+	for _, addOn := range results {
+		if addOn != nil {
+			addOns = append(addOns, addOn)
 		}
-		addOns = append(addOns, &catalogueAddOn)
 	}
-	return addOns, nil
+	return addOns
 }
 
 func (catalogue *ORASRemoteAddOnCatalogue) action(destination string) func(src io.Reader, mediaType string) {
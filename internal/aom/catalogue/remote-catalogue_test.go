@@ -5,11 +5,16 @@
 package catalogue_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 	"u-control/uc-aom/internal/aom/catalogue"
 	"u-control/uc-aom/internal/aom/registry"
 	"u-control/uc-aom/internal/pkg/manifest"
@@ -87,7 +92,7 @@ func TestVersion(t *testing.T) {
 			mockRegistry.On("Tags", addOnRepositoryName).Return(testCase.tagsFromRepository, nil)
 
 			// Act
-			actual, err := uut.GetAddOnVersions(addOnRepositoryName)
+			actual, err := uut.GetAddOnVersions(context.Background(), addOnRepositoryName)
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
@@ -109,7 +114,7 @@ func TestRemoteRegistryConnectionProblem(t *testing.T) {
 	mockRegistry.On("Repositories").Return([]string{}, networkError)
 
 	// Act
-	payload, err := uut.GetLatestAddOns()
+	payload, err := uut.GetLatestAddOns(context.Background())
 
 	// Assert
 	if payload != nil {
@@ -129,7 +134,8 @@ func TestRemoteRegistryConnectionProblem(t *testing.T) {
 func TestGetLatestAddOns(t *testing.T) {
 	mockRegistry := &registry.MockRegistry{}
 	mockManifestReader := &mockManifestReader{}
-	uut := createUutWithManifestReader(mockRegistry, mockManifestReader)
+	root := t.TempDir()
+	uut := createUutWithManifestReader(root, mockRegistry, mockManifestReader)
 
 	type args struct {
 		repository string
@@ -177,12 +183,12 @@ func TestGetLatestAddOns(t *testing.T) {
 			mock.AnythingOfType("*registry.ucImageLayerProcessor")).Return(uint64(0), registryArg.pullError)
 
 		if registryArg.pullError == nil {
-			mockManifestReader.On("ReadManifestFrom", registryArg.repository).Return(&manifest.Root{Version: latestTag, Title: registryArg.repository}, nil)
+			mockManifestReader.On("ReadManifestFrom", filepath.Join(root, registryArg.repository)).Return(&manifest.Root{Version: latestTag, Title: registryArg.repository}, nil)
 		}
 	}
 
 	// act
-	got, err := uut.GetLatestAddOns()
+	got, err := uut.GetLatestAddOns(context.Background())
 
 	// assert
 	assert.Nil(t, err)
@@ -202,7 +208,183 @@ func createUut(mockRegistry registry.AddOnRegistry) *catalogue.ORASRemoteAddOnCa
 	return uut
 }
 
-func createUutWithManifestReader(mockRegistry registry.AddOnRegistry, mockManifestReader model.ManifestFileReader) *catalogue.ORASRemoteAddOnCatalogue {
-	uut := catalogue.NewORASRemoteAddOnCatalogue("", mockRegistry, mockManifestReader)
+func createUutWithManifestReader(root string, mockRegistry registry.AddOnRegistry, mockManifestReader model.ManifestFileReader) *catalogue.ORASRemoteAddOnCatalogue {
+	uut := catalogue.NewORASRemoteAddOnCatalogue(root, mockRegistry, mockManifestReader)
 	return uut
 }
+
+func TestGetLatestAddOnsCanceled(t *testing.T) {
+	// Arrange
+	mockRegistry := &registry.MockRegistry{}
+	uut := createUutWithManifestReader(t.TempDir(), mockRegistry, &mockManifestReader{})
+	mockRegistry.On("Repositories").Return([]string{"repo"}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	got, err := uut.GetLatestAddOns(ctx)
+
+	// Assert
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, context.Canceled)
+	mockRegistry.AssertNotCalled(t, "Pull", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetAddOnConcurrentlyWithSameName(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	mockRegistry := &registry.MockRegistry{}
+	mockManifestReader := &mockManifestReader{}
+	uut := createUutWithManifestReader(root, mockRegistry, mockManifestReader)
+	writeLogo := func(args mock.Arguments) {
+		scratch, _ := filepath.Glob(filepath.Join(root, ".scratch-*"))
+		for _, dir := range scratch {
+			os.WriteFile(filepath.Join(dir, "logo.png"), []byte("logo"), 0644)
+		}
+	}
+	mockRegistry.On("Pull", "repo", "0.1.0-1", mock.AnythingOfType("*registry.ucImageLayerProcessor")).Run(writeLogo).Return(uint64(0), nil)
+	mockManifestReader.On("ReadManifestFrom", filepath.Join(root, "repo")).Return(&manifest.Root{}, nil)
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := uut.GetAddOn(context.Background(), "repo", "0.1.0-1")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		assert.Nil(t, err)
+	}
+	assert.FileExists(t, filepath.Join(root, "repo", "logo.png"))
+	scratch, _ := filepath.Glob(filepath.Join(root, ".scratch-*"))
+	assert.Empty(t, scratch)
+}
+
+func TestGetAddOnSwapsAssetsDirectory(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	mockRegistry := &registry.MockRegistry{}
+	mockManifestReader := &mockManifestReader{}
+	uut := createUutWithManifestReader(root, mockRegistry, mockManifestReader)
+	writeLogo := func(args mock.Arguments) {
+		scratch, _ := filepath.Glob(filepath.Join(root, ".scratch-*"))
+		os.WriteFile(filepath.Join(scratch[0], "logo.png"), []byte(args.String(1)), 0644)
+	}
+	mockRegistry.On("Pull", "repo", mock.Anything, mock.AnythingOfType("*registry.ucImageLayerProcessor")).Run(writeLogo).Return(uint64(0), nil)
+	mockManifestReader.On("ReadManifestFrom", filepath.Join(root, "repo")).Return(&manifest.Root{}, nil)
+	// Directory written by a previous version of the catalogue
+	os.MkdirAll(filepath.Join(root, "repo"), os.ModePerm)
+
+	// Act
+	_, firstErr := uut.GetAddOn(context.Background(), "repo", "0.1.0-1")
+	_, secondErr := uut.GetAddOn(context.Background(), "repo", "0.2.0-1")
+
+	// Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+	info, err := os.Lstat(filepath.Join(root, "repo"))
+	assert.Nil(t, err)
+	assert.True(t, info.Mode()&os.ModeSymlink != 0)
+	logo, _ := os.ReadFile(filepath.Join(root, "repo", "logo.png"))
+	assert.Equal(t, "0.2.0-1", string(logo))
+	generations, _ := filepath.Glob(filepath.Join(root, ".repo-*"))
+	assert.Len(t, generations, 1)
+}
+
+func TestGetAddOnStopsWaitingWhenContextIsDone(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	mockRegistry := &registry.MockRegistry{}
+	mockManifestReader := &mockManifestReader{}
+	uut := createUutWithManifestReader(root, mockRegistry, mockManifestReader)
+	pulling := make(chan struct{})
+	release := make(chan struct{})
+	blockPull := func(args mock.Arguments) {
+		close(pulling)
+		<-release
+	}
+	mockRegistry.On("Pull", "repo", "0.1.0-1", mock.Anything).Run(blockPull).Return(uint64(0), nil).Once()
+	mockManifestReader.On("ReadManifestFrom", filepath.Join(root, "repo")).Return(&manifest.Root{}, nil)
+	go uut.GetAddOn(context.Background(), "repo", "0.1.0-1")
+	<-pulling
+	defer close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := uut.GetAddOn(ctx, "repo", "0.1.0-1")
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	mockRegistry.AssertNumberOfCalls(t, "Pull", 1)
+}
+
+// Records the context of the registry requests.
+type contextRecordingRegistry struct {
+	*registry.MockRegistry
+	contexts chan context.Context
+}
+
+func (r *contextRecordingRegistry) Tags(ctx context.Context, repository string) ([]string, error) {
+	r.contexts <- ctx
+	return r.MockRegistry.Tags(ctx, repository)
+}
+
+func TestGetLatestAddOnsPassesContextToRegistry(t *testing.T) {
+	// Arrange
+	mockRegistry := &registry.MockRegistry{}
+	mockRegistry.On("Repositories").Return([]string{"repo"}, nil)
+	mockRegistry.On("Tags", "repo").Return([]string{}, errors.New("unreachable"))
+	recordingRegistry := &contextRecordingRegistry{MockRegistry: mockRegistry, contexts: make(chan context.Context, 1)}
+	uut := createUutWithManifestReader(t.TempDir(), recordingRegistry, &mockManifestReader{})
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "request")
+
+	// Act
+	_, err := uut.GetLatestAddOns(ctx)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "request", (<-recordingRegistry.contexts).Value(key{}))
+}
+
+type releaseChannelsStub map[string]catalogue.ReleaseChannel
+
+func (s releaseChannelsStub) ChannelOf(name string) catalogue.ReleaseChannel {
+	if channel, ok := s[name]; ok {
+		return channel
+	}
+	return catalogue.ReleaseChannelStable
+}
+
+func TestGetAddOnVersionsOfReleaseChannel(t *testing.T) {
+	// Arrange
+	mockRegistry := &registry.MockRegistry{}
+	tags := []string{"1.1.0-1-rc.1", "1.0.0-1", "1.1.0-beta.1-1", "0.9.0-1"}
+	mockRegistry.On("Tags", "stable").Return(tags, nil)
+	mockRegistry.On("Tags", "beta").Return(tags, nil)
+	mockRegistry.On("Tags", "prerelease-only").Return([]string{"0.1.0-1-beta.1"}, nil)
+	uut := createUut(mockRegistry)
+	uut.UseReleaseChannels(releaseChannelsStub{"beta": catalogue.ReleaseChannelBeta})
+
+	// Act
+	stable, stableErr := uut.GetAddOnVersions(context.Background(), "stable")
+	beta, betaErr := uut.GetAddOnVersions(context.Background(), "beta")
+	prereleaseOnly, prereleaseOnlyErr := uut.GetAddOnVersions(context.Background(), "prerelease-only")
+
+	// Assert
+	assert.NoError(t, stableErr)
+	assert.NoError(t, betaErr)
+	assert.Equal(t, []string{"0.9.0-1", "1.0.0-1"}, stable)
+	assert.Equal(t, []string{"0.9.0-1", "1.0.0-1", "1.1.0-beta.1-1", "1.1.0-1-rc.1"}, beta)
+	assert.NoError(t, prereleaseOnlyErr)
+	assert.Empty(t, prereleaseOnly)
+}
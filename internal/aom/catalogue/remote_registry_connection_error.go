@@ -1,21 +1,31 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
 package catalogue
 
-import "fmt"
+import (
+	"fmt"
+	sharedRegistry "u-control/uc-aom/internal/pkg/registry"
+)
 
 // Represents a problem connecting to the remote registry.
 type RemoteRegistryConnectionError struct {
 	message string
+	failure sharedRegistry.ConnectionFailure
 }
 
 func (r *RemoteRegistryConnectionError) Error() string {
 	return r.message
 }
 
+// Returns the stage at which the connection failed, e.g. DNS, TCP, TLS or AUTH.
+func (r *RemoteRegistryConnectionError) Failure() sharedRegistry.ConnectionFailure {
+	return r.failure
+}
+
 func NewRemoteRegistryConnectionError(err error) error {
-	message := fmt.Sprintf("Unable to connect to remote registry, err =%v", err.Error())
-	return &RemoteRegistryConnectionError{message: message}
+	failure := sharedRegistry.DiagnoseConnectionFailure(err)
+	message := fmt.Sprintf("Unable to connect to remote registry (%s failure), err =%v", failure, err.Error())
+	return &RemoteRegistryConnectionError{message: message, failure: failure}
 }
@@ -5,13 +5,17 @@
 package cmd
 
 import (
+	"context"
 	"io"
 	"net"
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
+	"u-control/uc-aom/internal/aom/budget"
 	"u-control/uc-aom/internal/aom/catalogue"
 	"u-control/uc-aom/internal/aom/config"
+	"u-control/uc-aom/internal/aom/cpuisolation"
 	"u-control/uc-aom/internal/aom/dbus"
 	"u-control/uc-aom/internal/aom/docker"
 	"u-control/uc-aom/internal/aom/env"
@@ -20,21 +24,34 @@ import (
 	"u-control/uc-aom/internal/aom/iam"
 	"u-control/uc-aom/internal/aom/manifest"
 	"u-control/uc-aom/internal/aom/migrate"
+	"u-control/uc-aom/internal/aom/mirror"
 	"u-control/uc-aom/internal/aom/network"
+	"u-control/uc-aom/internal/aom/protection"
 	"u-control/uc-aom/internal/aom/registry"
 	"u-control/uc-aom/internal/aom/routes"
 	"u-control/uc-aom/internal/aom/server"
 	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/aom/servicedefaults"
 	addon_status "u-control/uc-aom/internal/aom/status"
 	"u-control/uc-aom/internal/aom/system"
+	"u-control/uc-aom/internal/aom/update"
+	catalogueindex "u-control/uc-aom/internal/pkg/catalogue-index"
 	sharedConfig "u-control/uc-aom/internal/pkg/config"
 	model "u-control/uc-aom/internal/pkg/manifest"
+	sharedRegistry "u-control/uc-aom/internal/pkg/registry"
 
 	"github.com/docker/compose/v2/pkg/compose"
 	"github.com/docker/docker/client"
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// Retries of an interrupted docker image layer download, the delay doubles after every attempt
+const (
+	blobDownloadAttempts = 5
+	blobDownloadDelay    = 2 * time.Second
 )
 
 type UcAom struct {
@@ -78,15 +95,109 @@ func (u *UcAom) Run() error {
 		log.Fatalf("Error %v", credError)
 		return credError
 	}
-	orasRegistry, err := registry.InitializeRegistry(registryCredentials)
+	dockerConfig := registry.NewDockerConfigProvider(registry.DOCKER_CONFIG_DIR)
+	credentialStore, err := registry.NewCredentialStore(os.ReadFile, os.WriteFile, registry.REGISTRY_CREDENTIALS_PATH, registry.REGISTRY_CREDENTIALS_KEY_PATH, dockerConfig)
+	if err != nil {
+		log.Fatalf("Unable to read the registry credentials: %v", err)
+	}
+
+	defaultSources := []*registry.CatalogueSource{registry.NewDefaultCatalogueSource(registryCredentials)}
+	catalogueSources, err := registry.LoadCatalogueSources(os.ReadFile, registry.CATALOGUE_SOURCES_PATH, defaultSources, credentialStore)
+	if err != nil {
+		return err
+	}
+
+	catalogueIndexPublicKey, err := registry.ReadCatalogueIndexPublicKey(os.ReadFile, registry.CATALOGUE_INDEX_PUBLIC_KEY_PATH)
+	if err != nil {
+		log.Errorf("Unable to read the catalogue index public key, catalogue indexes are not used: %v", err)
+	}
+	catalogueIndexHistory, err := registry.NewCatalogueIndexHistory(os.ReadFile, os.WriteFile, registry.CATALOGUE_INDEX_HISTORY_PATH)
+	if err != nil {
+		log.Fatalf("Unable to read the catalogue index history: %v", err)
+	}
+
+	blobCacheTTL, err := time.ParseDuration(registry.BLOB_CACHE_TTL)
+	if err != nil {
+		return err
+	}
+	blobCache := registry.NewBlobCache(registry.BLOB_CACHE_PATH, blobDownloadAttempts, blobDownloadDelay)
+	if err := blobCache.Prune(blobCacheTTL); err != nil {
+		log.Warnf("Unable to prune the blob cache: %v", err)
+	}
+
+	if mirror.MIRROR_LISTEN_ADDRESS != "" {
+		blobCache.RetainInstalledBlobs()
+	}
+	mirrorToken, err := mirror.ReadToken(os.ReadFile, mirror.MIRROR_TOKEN_PATH)
 	if err != nil {
-		log.Fatalf("Unable to initialize ORAS registry: %v", err)
+		log.Errorf("Unable to read the mirror token: %v", err)
 	}
 
-	orasRemoteRegistry := registry.NewORASAddOnRegistry(orasRegistry, localfs, runtime.GOARCH, runtime.GOOS)
-	addOnRegistry := registry.NewCodeNameAdapterRegistry(orasRemoteRegistry)
-	orasRemote := catalogue.NewORASRemoteAddOnCatalogue(catalogue.ASSETS_TMP_PATH, addOnRegistry, localfs)
-	localCatalogue := catalogue.NewLocalAddOnCatalogue(catalogue.ASSETS_INSTALL_PATH, addOnRegistry, localfs)
+	transportOptions := registry.DefaultTransportOptions()
+	newRegistry := func(source *registry.CatalogueSource, orasRegistry *remote.Registry) registry.AddOnRegistry {
+		orasRemoteRegistry := registry.NewORASAddOnRegistry(orasRegistry, localfs, runtime.GOARCH, runtime.GOOS)
+		orasRemoteRegistry.UseBlobCache(blobCache)
+		if catalogueIndexPublicKey == nil {
+			return registry.NewCodeNameAdapterRegistry(orasRemoteRegistry)
+		}
+
+		namespace := source.RepositoryPrefix
+		fetchIndex := func(ctx context.Context) (*catalogueindex.Index, error) {
+			return orasRemoteRegistry.CatalogueIndex(ctx, namespace, catalogueIndexPublicKey)
+		}
+		// The mirror of the source shares the history of the source, so it cannot serve an older index either
+		historyKey := source.ServerAddress + "/" + namespace
+		acceptIndex := func(index *catalogueindex.Index) error {
+			return catalogueIndexHistory.Accept(historyKey, index)
+		}
+		indexedRegistry := registry.NewIndexedAddOnRegistry(orasRemoteRegistry, fetchIndex, acceptIndex, runtime.GOARCH, runtime.GOOS)
+		return registry.NewCodeNameAdapterRegistry(indexedRegistry)
+	}
+	newSourceRegistry := func(source *registry.CatalogueSource) (registry.AddOnRegistry, error) {
+		orasRegistry, err := registry.InitializeRegistry(&source.Credentials, credentialStore, source.TransportOptions(transportOptions))
+		if err != nil {
+			return nil, err
+		}
+		sourceRegistry := newRegistry(source, orasRegistry)
+		if source.Mirror == "" {
+			return sourceRegistry, nil
+		}
+
+		mirrorCredentials := mirror.ClientCredentials(source.Mirror, mirrorToken)
+		mirrorTransportOptions := mirror.ClientTransportOptions(source.MirrorTransportOptions(transportOptions), mirror.MIRROR_CLIENT_CERT_PATH, mirror.MIRROR_CLIENT_KEY_PATH)
+		orasMirrorRegistry, err := registry.InitializeRegistry(mirrorCredentials, nil, mirrorTransportOptions)
+		if err != nil {
+			return nil, err
+		}
+		return registry.NewMirroredAddOnRegistry(newRegistry(source, orasMirrorRegistry), sourceRegistry), nil
+	}
+	addOnRegistry, err := registry.NewFederatedAddOnRegistry(catalogueSources, registry.CATALOGUE_SOURCES_PATH, registry.CATALOGUE_ORIGINS_PATH, os.ReadFile, os.WriteFile, credentialStore, newSourceRegistry)
+	if err != nil {
+		log.Fatalf("Unable to initialize the catalogue sources: %v", err)
+	}
+	remoteCatalogueCacheTTL, err := time.ParseDuration(catalogue.REMOTE_CATALOGUE_CACHE_TTL)
+	if err != nil {
+		return err
+	}
+	remoteCatalogueCache := catalogue.NewRemoteCatalogueCache(catalogue.REMOTE_CATALOGUE_CACHE_PATH, remoteCatalogueCacheTTL)
+	orasRemote := catalogue.NewCachedORASRemoteAddOnCatalogue(catalogue.ASSETS_TMP_PATH, addOnRegistry, localfs, remoteCatalogueCache)
+	releaseChannels, err := catalogue.NewReleaseChannelStore(os.ReadFile, os.WriteFile, catalogue.RELEASE_CHANNELS_PATH)
+	if err != nil {
+		log.Fatalf("Unable to read the release channels: %v", err)
+	}
+	orasRemote.UseReleaseChannels(releaseChannels)
+	stagingRegistry := registry.NewStagingAddOnRegistry(registry.STAGING_PATH, addOnRegistry)
+	localCatalogue := catalogue.NewLocalAddOnCatalogue(catalogue.ASSETS_INSTALL_PATH, stagingRegistry, localfs)
+	retainOriginsOfInstalledAddOns(addOnRegistry, localCatalogue)
+	retainStagedUpdatesOfInstalledAddOns(stagingRegistry, localCatalogue)
+	if mirror.MIRROR_LISTEN_ADDRESS != "" {
+		isInstalled := func(repository string) bool {
+			_, err := localCatalogue.GetAddOn(sharedRegistry.NormalizeCodeName(repository))
+			return err == nil
+		}
+		addOnMirror := mirror.NewMirror(mirror.NewRegistryUpstream(addOnRegistry.MirrorRegistries), blobCache, mirror.MIRROR_MANIFESTS_PATH, isInstalled, mirrorToken)
+		go serveMirror(addOnMirror)
+	}
 
 	writeToFile := func(name string, writeContent func(io.Writer) error) error {
 		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0644)
@@ -128,7 +239,12 @@ func (u *UcAom) Run() error {
 	composeService := compose.NewComposeService(dockerCli)
 	stackService := docker.NewStackService(dockerCli.Client(), composeService)
 
-	err = service.StopInstalledAddOns(localCatalogue, stackService)
+	protectionPolicy, err := protection.LoadPolicy(os.ReadFile, protection.PROTECTED_ADDONS_PATH)
+	if err != nil {
+		return err
+	}
+
+	err = service.StopInstalledAddOns(localCatalogue, stackService, protectionPolicy)
 	if err != nil {
 		return err
 	}
@@ -147,6 +263,21 @@ func (u *UcAom) Run() error {
 	}
 	uOSSystem := system.NewuOSSystem(catalogue.ASSETS_INSTALL_PATH)
 
+	resourceBudget, err := budget.LoadBudget(os.ReadFile, budget.RESOURCE_BUDGET_PATH)
+	if err != nil {
+		return err
+	}
+
+	serviceDefaults, err := servicedefaults.LoadPolicy(os.ReadFile, servicedefaults.SERVICE_DEFAULTS_PATH)
+	if err != nil {
+		return err
+	}
+
+	cpuPolicy, err := cpuisolation.LoadPolicy(os.ReadFile, cpuisolation.CPU_ISOLATION_PATH, runtime.NumCPU())
+	if err != nil {
+		return err
+	}
+
 	adminUser, err := uOSSystem.LookupAdminUser()
 	if err != nil {
 		return err
@@ -160,7 +291,10 @@ func (u *UcAom) Run() error {
 	if err != nil {
 		return err
 	}
-	service := service.NewService(stackService, reverseProxy, iamPermissionWriter, localCatalogue, manifestValidator, addOnEnvResolver, uOSSystem)
+	service := service.NewService(stackService, reverseProxy, iamPermissionWriter, localCatalogue, manifestValidator, addOnEnvResolver, uOSSystem, protectionPolicy, resourceBudget, serviceDefaults, cpuPolicy)
+	service.UseBlobCache(blobCache)
+	service.UseStagingArea(stagingRegistry)
+	stagingRegistry.UseDiskSpaceCheck(service.CheckStagingDiskSpace)
 	err = migrateInstalledAddOns(transactionScheduler, service, stackService, localfs, addOnEnvResolver, reverseProxy)
 	if err != nil {
 		return err
@@ -170,9 +304,9 @@ func (u *UcAom) Run() error {
 		return err
 	}
 
-	installAllDropInAddOnsInPersistenceFolder(transactionScheduler, localfs, stackService, reverseProxy, iamPermissionWriter, manifestValidator, addOnEnvResolver, uOSSystem)
+	installAllDropInAddOnsInPersistenceFolder(transactionScheduler, localfs, stackService, reverseProxy, iamPermissionWriter, manifestValidator, addOnEnvResolver, uOSSystem, protectionPolicy, resourceBudget, serviceDefaults, cpuPolicy)
 
-	fileServer := createFileServer(transactionScheduler, localfs, stackService, reverseProxy, iamPermissionWriter, manifestValidator, addOnEnvResolver, uOSSystem)
+	fileServer := createFileServer(transactionScheduler, localfs, stackService, reverseProxy, iamPermissionWriter, manifestValidator, addOnEnvResolver, uOSSystem, protectionPolicy, resourceBudget, serviceDefaults, cpuPolicy)
 
 	err = fileServer.InstallAllDropInAddOns()
 	if err != nil {
@@ -183,27 +317,107 @@ func (u *UcAom) Run() error {
 		return err
 	}
 
-	grpc_api.RegisterAddOnServiceServer(grpc_server,
-		server.NewServer(service, config.URL_ASSETS_LOCAL_ROOT, config.URL_ASSETS_REMOTE_ROOT, localCatalogue, orasRemote, iamServiceUcAomClient, iamServiceUcAuthClient, addOnStatusResolver, addOnEnvResolver, transactionScheduler))
+	updateCheckInterval, err := time.ParseDuration(update.UPDATE_CHECK_INTERVAL)
+	if err != nil {
+		return err
+	}
+	updateChecker := update.NewChecker(localCatalogue, orasRemote, service)
+	if updateCheckInterval > 0 {
+		go updateChecker.Run(context.Background(), updateCheckInterval)
+	}
+
+	updatePolicies, err := update.NewPolicyStore(os.ReadFile, os.WriteFile, update.UPDATE_POLICIES_PATH)
+	if err != nil {
+		return err
+	}
+	updateHistory, err := update.NewHistory(os.ReadFile, os.WriteFile, update.UPDATE_HISTORY_PATH, update.UPDATE_HISTORY_STATE_PATH)
+	if err != nil {
+		return err
+	}
+	updateSchedulerInterval, err := time.ParseDuration(update.UPDATE_SCHEDULER_INTERVAL)
+	if err != nil {
+		return err
+	}
+	updateProbationPeriod, err := time.ParseDuration(update.UPDATE_PROBATION_PERIOD)
+	if err != nil {
+		return err
+	}
+	transactionUpdater := update.NewTransactionUpdater(transactionScheduler, service)
+	probation := update.NewProbation(localCatalogue, addOnEnvResolver, addOnStatusResolver, stackService, transactionUpdater, updateHistory, updateProbationPeriod, update.ProbationPollInterval)
+	updateScheduler := update.NewScheduler(localCatalogue, updateChecker, updatePolicies, updateHistory, transactionUpdater, time.Now)
+	probation.UsePackageKeeper(stagingRegistry)
+	updateScheduler.UseProbation(probation)
+	if updateSchedulerInterval > 0 {
+		go updateScheduler.Run(context.Background(), updateSchedulerInterval)
+	}
+
+	addOnServer := server.NewServer(service, config.URL_ASSETS_LOCAL_ROOT, config.URL_ASSETS_REMOTE_ROOT, localCatalogue, orasRemote, addOnRegistry, credentialStore, releaseChannels, iamServiceUcAomClient, iamServiceUcAuthClient, addOnStatusResolver, addOnEnvResolver, transactionScheduler)
+	addOnServer.UseUpdateChecker(updateChecker)
+	addOnServer.UseUpdatePolicies(updatePolicies, updateHistory)
+	addOnServer.UseProbation(probation)
+	addOnServer.UseStaging(stagingRegistry)
+	grpc_api.RegisterAddOnServiceServer(grpc_server, addOnServer)
 
 	log.Infof("Server is listening on %s ...", u.grpcListener.Addr().String())
 	return grpc_server.Serve(u.grpcListener)
 }
 
-func installAllDropInAddOnsInPersistenceFolder(transactionScheduler *service.TransactionScheduler, localfs *manifest.LocalFSRepository, stackService *docker.StackService, reverseProxy *routes.ReverseProxy, iamPermissionWriter *iam.IamPermissionWriter, validator model.Validator, addOnEnvironmentResolver *env.AddOnEnvironmentResolver, system system.System) {
+// Serves the packages of the installed add-ons to other devices in the local network.
+func serveMirror(addOnMirror *mirror.Mirror) {
+	log.Infof("Mirroring the add-ons on %s", mirror.MIRROR_LISTEN_ADDRESS)
+	if err := addOnMirror.ListenAndServeTLS(mirror.MIRROR_LISTEN_ADDRESS, mirror.MIRROR_TLS_CERT_PATH, mirror.MIRROR_TLS_KEY_PATH, mirror.MIRROR_CLIENT_CA_PATH); err != nil {
+		log.Errorf("Unable to serve the mirror: %v", err)
+	}
+}
+
+// Removes the staged packages which are no update of an installed add-on.
+func retainStagedUpdatesOfInstalledAddOns(stagingRegistry *registry.StagingAddOnRegistry, localCatalogue catalogue.LocalAddOnCatalogue) {
+	installedAddOns, err := localCatalogue.GetAddOns()
+	if err != nil {
+		log.Warnf("Unable to list the installed add-ons: %v", err)
+		return
+	}
+
+	installedVersions := make(map[string]string, len(installedAddOns))
+	for _, addOn := range installedAddOns {
+		installedVersions[addOn.Name] = addOn.Version
+	}
+	if err := stagingRegistry.RetainUpdatesOf(installedVersions); err != nil {
+		log.Warnf("Unable to remove the outdated staged updates: %v", err)
+	}
+}
+
+// Forgets the catalogue sources of add-ons which are no longer installed.
+func retainOriginsOfInstalledAddOns(addOnRegistry *registry.FederatedAddOnRegistry, localCatalogue catalogue.LocalAddOnCatalogue) {
+	installedAddOns, err := localCatalogue.GetAddOns()
+	if err != nil {
+		log.Warnf("Unable to list the installed add-ons: %v", err)
+		return
+	}
+
+	names := make([]string, len(installedAddOns))
+	for i, addOn := range installedAddOns {
+		names[i] = addOn.Name
+	}
+	if err := addOnRegistry.RetainOrigins(names); err != nil {
+		log.Warnf("Unable to update the catalogue sources of the installed add-ons: %v", err)
+	}
+}
+
+func installAllDropInAddOnsInPersistenceFolder(transactionScheduler *service.TransactionScheduler, localfs *manifest.LocalFSRepository, stackService *docker.StackService, reverseProxy *routes.ReverseProxy, iamPermissionWriter *iam.IamPermissionWriter, validator model.Validator, addOnEnvironmentResolver *env.AddOnEnvironmentResolver, system system.System, protectionPolicy *protection.Policy, resourceBudget *budget.Budget, serviceDefaults *servicedefaults.Policy, cpuPolicy *cpuisolation.Policy) {
 	swUpdateWatcher := fileserver.NewSWUpdateWatcher("/tmp/swupdateprog")
 	dropInAddOnRegistry := registry.NewDropInAddOnRegistry(sharedConfig.PERSISTENCE_DROP_IN_PATH, runtime.GOARCH, runtime.GOOS, &manifest.ManifestTarGzipDecompressor{})
 	localCatalogue := catalogue.NewLocalAddOnCatalogue(catalogue.ASSETS_INSTALL_PATH, dropInAddOnRegistry, localfs)
-	serviceForDropIn := service.NewService(stackService, reverseProxy, iamPermissionWriter, localCatalogue, validator, addOnEnvironmentResolver, system)
+	serviceForDropIn := service.NewService(stackService, reverseProxy, iamPermissionWriter, localCatalogue, validator, addOnEnvironmentResolver, system, protectionPolicy, resourceBudget, serviceDefaults, cpuPolicy)
 	fileServer := fileserver.NewFileServerWithTransactionSchedular(transactionScheduler, swUpdateWatcher, serviceForDropIn, dropInAddOnRegistry, localCatalogue)
 	fileServer.InstallAllDropInAddOns()
 }
 
-func createFileServer(transactionScheduler *service.TransactionScheduler, localfs *manifest.LocalFSRepository, stackService *docker.StackService, reverseProxy *routes.ReverseProxy, iamPermissionWriter *iam.IamPermissionWriter, validator model.Validator, addOnEnvironmentResolver *env.AddOnEnvironmentResolver, system system.System) *fileserver.FileServer {
+func createFileServer(transactionScheduler *service.TransactionScheduler, localfs *manifest.LocalFSRepository, stackService *docker.StackService, reverseProxy *routes.ReverseProxy, iamPermissionWriter *iam.IamPermissionWriter, validator model.Validator, addOnEnvironmentResolver *env.AddOnEnvironmentResolver, system system.System, protectionPolicy *protection.Policy, resourceBudget *budget.Budget, serviceDefaults *servicedefaults.Policy, cpuPolicy *cpuisolation.Policy) *fileserver.FileServer {
 	swUpdateWatcher := fileserver.NewSWUpdateWatcher("/tmp/swupdateprog")
 	dropInAddOnRegistry := registry.NewDropInAddOnRegistry(sharedConfig.CACHE_DROP_IN_PATH, runtime.GOARCH, runtime.GOOS, &manifest.ManifestTarGzipDecompressor{})
 	localCatalogue := catalogue.NewLocalAddOnCatalogue(catalogue.ASSETS_INSTALL_PATH, dropInAddOnRegistry, localfs)
-	serviceForDropIn := service.NewService(stackService, reverseProxy, iamPermissionWriter, localCatalogue, validator, addOnEnvironmentResolver, system)
+	serviceForDropIn := service.NewService(stackService, reverseProxy, iamPermissionWriter, localCatalogue, validator, addOnEnvironmentResolver, system, protectionPolicy, resourceBudget, serviceDefaults, cpuPolicy)
 	fileServer := fileserver.NewFileServerWithTransactionSchedular(transactionScheduler, swUpdateWatcher, serviceForDropIn, dropInAddOnRegistry, localCatalogue)
 	return fileServer
 }
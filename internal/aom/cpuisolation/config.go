@@ -0,0 +1,11 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package cpuisolation
+
+import "u-control/uc-aom/internal/pkg/utils"
+
+var (
+	CPU_ISOLATION_PATH = utils.GetEnv("CPU_ISOLATION_PATH", "/usr/share/uc-aom/cpu-isolation.json")
+)
@@ -0,0 +1,159 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package cpuisolation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"u-control/uc-aom/internal/aom/utils"
+	"u-control/uc-aom/internal/aom/yaml"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var cpusetRegex = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*$`)
+
+// Tier of add-ons which share the cpu with the same weight.
+type Tier struct {
+	CpuShares int64 `json:"cpuShares"` // relative cpu weight, docker uses 1024 for a container without limit
+}
+
+// Override of the placement for specific add-ons, set by the administrator of the device.
+// Either Name or Pattern identifies the add-ons the override applies to.
+type Override struct {
+	utils.AddOnSelector
+	Tier   string `json:"tier,omitempty"`   // tier of the add-on instead of the default tier
+	Cpuset string `json:"cpuset,omitempty"` // cores the add-on may use instead of the non-reserved cores
+}
+
+// Policy decides on which cores and with which weight the services of an add-on run.
+// The reserved cores are kept free for the real-time tasks of the device, e.g. the PLC runtime.
+type Policy struct {
+	ReservedCpus []int           `json:"reservedCpus"` // cores add-ons must not use
+	DefaultTier  string          `json:"defaultTier"`  // tier of add-ons without override
+	Tiers        map[string]Tier `json:"tiers"`
+	AddOns       []*Override     `json:"addOns"`
+
+	cpuset string
+}
+
+// Reads the cpu isolation policy from path for a device with numCPU cores.
+// A missing file results in a policy which does not restrict the add-ons.
+func LoadPolicy(readFile utils.ReadFileFunc, path string, numCPU int) (*Policy, error) {
+	policy := &Policy{}
+	found, err := utils.ReadJSONFile(readFile, path, "cpu isolation file", policy)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		log.Debugf("No cpu isolation file at '%s'", path)
+		return policy, nil
+	}
+
+	if err := policy.compile(numCPU); err != nil {
+		return nil, fmt.Errorf("Invalid cpu isolation file '%s': %w", path, err)
+	}
+	return policy, nil
+}
+
+// Returns the cpu placement of the docker-compose services of the add-on with the given name.
+func (p *Policy) PlacementFor(name string) *yaml.CpuPlacement {
+	cpuset := p.cpuset
+	tier := p.DefaultTier
+	if override := p.find(name); override != nil {
+		if override.Cpuset != "" {
+			cpuset = override.Cpuset
+		}
+		if override.Tier != "" {
+			tier = override.Tier
+		}
+	}
+	return &yaml.CpuPlacement{Cpuset: cpuset, CpuShares: p.Tiers[tier].CpuShares}
+}
+
+func (p *Policy) find(name string) *Override {
+	for _, override := range p.AddOns {
+		if override.Matches(name) {
+			return override
+		}
+	}
+	return nil
+}
+
+func (p *Policy) compile(numCPU int) error {
+	if err := p.checkTier(p.DefaultTier); err != nil {
+		return err
+	}
+
+	for _, override := range p.AddOns {
+		if err := override.compile(); err != nil {
+			return err
+		}
+		if err := p.checkTier(override.Tier); err != nil {
+			return err
+		}
+	}
+
+	if len(p.ReservedCpus) == 0 {
+		return nil
+	}
+
+	cpuset := formatCpuset(numCPU, p.ReservedCpus)
+	if cpuset == "" {
+		// Add-ons would not be able to start at all, e.g. on a single core device
+		log.Warnf("All %d cpus are reserved, add-ons are not restricted to a cpuset", numCPU)
+		return nil
+	}
+	p.cpuset = cpuset
+	return nil
+}
+
+func (p *Policy) checkTier(tier string) error {
+	if tier == "" {
+		return nil
+	}
+	if _, ok := p.Tiers[tier]; !ok {
+		return fmt.Errorf("Unknown tier '%s'", tier)
+	}
+	return nil
+}
+
+// Returns the cores which are not reserved as ranges, e.g. 1-3,5
+func formatCpuset(numCPU int, reservedCpus []int) string {
+	reserved := make(map[int]bool, len(reservedCpus))
+	for _, cpu := range reservedCpus {
+		reserved[cpu] = true
+	}
+
+	ranges := []string{}
+	for cpu := 0; cpu < numCPU; cpu++ {
+		if reserved[cpu] {
+			continue
+		}
+		last := cpu
+		for last+1 < numCPU && !reserved[last+1] {
+			last++
+		}
+		if last == cpu {
+			ranges = append(ranges, fmt.Sprintf("%d", cpu))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", cpu, last))
+		}
+		cpu = last
+	}
+	return strings.Join(ranges, ",")
+}
+
+func (o *Override) compile() error {
+	if err := o.Compile("Cpu isolation override"); err != nil {
+		return err
+	}
+
+	if o.Cpuset != "" && !cpusetRegex.MatchString(o.Cpuset) {
+		return fmt.Errorf("Invalid cpuset '%s'", o.Cpuset)
+	}
+	return nil
+}
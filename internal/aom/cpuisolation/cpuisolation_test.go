@@ -0,0 +1,90 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package cpuisolation_test
+
+import (
+	"io/fs"
+	"testing"
+	"u-control/uc-aom/internal/aom/cpuisolation"
+	"u-control/uc-aom/internal/aom/yaml"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return []byte(`{
+			"reservedCpus": [0, 4],
+			"defaultTier": "normal",
+			"tiers": { "normal": { "cpuShares": 512 }, "high": { "cpuShares": 1024 } },
+			"addOns": [
+				{ "pattern": "(?i)\\bcodesys\\b", "tier": "high", "cpuset": "0-5" }
+			]
+		}`), nil
+	}
+
+	// Act
+	policy, err := cpuisolation.LoadPolicy(readFile, "", 6)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, &yaml.CpuPlacement{Cpuset: "1-3,5", CpuShares: 512}, policy.PlacementFor("test-uc-addon-pkg"))
+	assert.Equal(t, &yaml.CpuPlacement{Cpuset: "0-5", CpuShares: 1024}, policy.PlacementFor("test-uc-addon-codesys-pkg"))
+}
+
+func TestLoadPolicyMissingFile(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return nil, fs.ErrNotExist
+	}
+
+	// Act
+	policy, err := cpuisolation.LoadPolicy(readFile, "", 4)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, &yaml.CpuPlacement{}, policy.PlacementFor("test-uc-addon-pkg"))
+}
+
+func TestLoadPolicyAllCpusReserved(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return []byte(`{ "reservedCpus": [0] }`), nil
+	}
+
+	// Act
+	policy, err := cpuisolation.LoadPolicy(readFile, "", 1)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, &yaml.CpuPlacement{}, policy.PlacementFor("test-uc-addon-pkg"))
+}
+
+func TestLoadPolicyInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown default tier", `{ "defaultTier": "normal" }`},
+		{"unknown override tier", `{ "addOns": [{ "name": "test-uc-addon-pkg", "tier": "high" }] }`},
+		{"invalid override cpuset", `{ "addOns": [{ "name": "test-uc-addon-pkg", "cpuset": "all" }] }`},
+		{"override without name", `{ "addOns": [{ "cpuset": "1" }] }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			readFile := func(string) ([]byte, error) {
+				return []byte(tt.content), nil
+			}
+
+			// Act
+			_, err := cpuisolation.LoadPolicy(readFile, "", 2)
+
+			// Assert
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package docker
+
+import (
+	"context"
+	"u-control/uc-aom/internal/aom/utils"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/docker/api/types"
+)
+
+// Disk space used by the docker objects of a stack in bytes.
+type StackDiskUsage struct {
+	// Size of the docker images, including layers shared with other images
+	ImageBytes uint64
+
+	// Part of ImageBytes that is shared with other images
+	SharedImageBytes uint64
+
+	// Size of the volumes managed by docker
+	VolumeBytes uint64
+
+	// Size of the directories of local-public volumes
+	PublicVolumeBytes uint64
+}
+
+// Snapshot of the disk space used by all docker images and volumes.
+type DiskUsage struct {
+	images  []*types.ImageSummary
+	volumes []*types.Volume
+}
+
+// Return a snapshot of the disk space used by all docker images and volumes.
+func (s *StackService) DiskUsage() (*DiskUsage, error) {
+	options := types.DiskUsageOptions{Types: []types.DiskUsageObject{types.ImageObject, types.VolumeObject}}
+	usage, err := s.cli.DiskUsage(context.Background(), options)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskUsage{images: usage.Images, volumes: usage.Volumes}, nil
+}
+
+// Return the disk space used by the stack identified by stackName and its docker images.
+// Volumes are assigned to the stack by the compose project label.
+// The size of local-public volumes is determined from their directories,
+// because docker does not provide usage data for volumes of plugin drivers.
+func (d *DiskUsage) StackUsage(stackName string, imageReferences ...string) (*StackDiskUsage, error) {
+	usage := &StackDiskUsage{}
+
+	references := make(map[string]bool)
+	for _, reference := range imageReferences {
+		references[reference] = true
+	}
+
+	for _, image := range d.images {
+		if !containsAny(image.RepoTags, references) {
+			continue
+		}
+		usage.ImageBytes += positiveOrZero(image.Size)
+		usage.SharedImageBytes += positiveOrZero(image.SharedSize)
+	}
+
+	projectName := normalizeStackName(stackName)
+	for _, volume := range d.volumes {
+		if volume.Labels[api.ProjectLabel] != projectName {
+			continue
+		}
+
+		if volume.UsageData != nil && volume.UsageData.Size >= 0 {
+			usage.VolumeBytes += uint64(volume.UsageData.Size)
+			continue
+		}
+
+		if isLocalPublicVolumeDriver(volume.Driver) {
+			size, err := utils.DirectorySize(volume.Mountpoint)
+			if err != nil {
+				return nil, err
+			}
+			usage.PublicVolumeBytes += size
+		}
+	}
+
+	return usage, nil
+}
+
+func isLocalPublicVolumeDriver(driver string) bool {
+	return driver == manifest.LocalPublicVolumeDriverName || driver == manifest.LocalPublicVolumeAccessDriverName
+}
+
+func containsAny(values []string, lookup map[string]bool) bool {
+	for _, value := range values {
+		if lookup[value] {
+			return true
+		}
+	}
+	return false
+}
+
+func positiveOrZero(value int64) uint64 {
+	if value < 0 {
+		return 0
+	}
+	return uint64(value)
+}
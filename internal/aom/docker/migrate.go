@@ -24,14 +24,25 @@ type StackMigrator interface {
 	MigrateStack(name string, version string, manifest *model.Root, settings ...*model.Setting) error
 }
 
-func NewStackMigrator(stackService StackServiceAPI) StackMigrator {
+// ComposeOptionsResolver resolves the device options of the docker-compose services of an add-on.
+type ComposeOptionsResolver interface {
+	// ComposeDefaultsFor returns the defaults for options the services omit.
+	ComposeDefaultsFor(name string) *yaml.ServiceDefaults
+
+	// CpuPlacementFor returns the cpu placement enforced on the services.
+	CpuPlacementFor(name string) *yaml.CpuPlacement
+}
+
+func NewStackMigrator(stackService StackServiceAPI, composeOptions ComposeOptionsResolver) StackMigrator {
 	return &stackMigrator{stackService: stackService,
-		connectToPortainer: v0_1_stack.ConnectToPortainer}
+		connectToPortainer: v0_1_stack.ConnectToPortainer,
+		composeOptions:     composeOptions}
 }
 
 type stackMigrator struct {
 	stackService       StackServiceAPI
 	connectToPortainer func() (portainer.PortainerClientService, error)
+	composeOptions     ComposeOptionsResolver
 }
 
 func (m *stackMigrator) MigrateStack(name string, versionToMigrate string, manifest *model.Root, settings ...*model.Setting) error {
@@ -64,7 +75,14 @@ func (m *stackMigrator) MigrateStack(name string, versionToMigrate string, manif
 			manifest.Settings["environmentVariables"] = settings
 		}
 
-		dockerCompose, err := yaml.GetDockerComposeFromManifest(manifest)
+		var defaults *yaml.ServiceDefaults
+		var placement *yaml.CpuPlacement
+		if m.composeOptions != nil {
+			defaults = m.composeOptions.ComposeDefaultsFor(name)
+			placement = m.composeOptions.CpuPlacementFor(name)
+		}
+
+		dockerCompose, err := yaml.GetDockerComposeFromManifest(manifest, defaults, placement)
 		if err != nil {
 			return err
 		}
@@ -260,7 +260,7 @@ func Test_stackMigrator_MigrateStackWithSettings(t *testing.T) {
 		},
 	}
 
-	wantdockerCompose, err := yaml.GetDockerComposeFromManifest(wantManifest)
+	wantdockerCompose, err := yaml.GetDockerComposeFromManifest(wantManifest, nil, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, wantdockerCompose, gotDockerCompose)
 
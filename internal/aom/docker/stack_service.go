@@ -49,6 +49,12 @@ type StackServiceAPI interface {
 	StartupStackNonBlocking(stackName string) error
 	StopStack(stackName string) error
 	VolumeInspect(volumeID string) (types.Volume, error)
+
+	// Return the diff IDs of the layers of all locally available docker images.
+	ListImageLayers() ([]string, error)
+
+	// Return a snapshot of the disk space used by all docker images and volumes.
+	DiskUsage() (*DiskUsage, error)
 }
 
 // Interface for the docker client that is passed into the stack service
@@ -59,6 +65,9 @@ type DockerClient interface {
 	VolumeRemove(context context.Context, volumeName string, force bool) error
 	VolumeInspect(ctx context.Context, volumeID string) (types.Volume, error)
 	ImageLoad(context context.Context, image io.Reader, quiet bool) (types.ImageLoadResponse, error)
+	ImageList(context context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
+	ImageInspectWithRaw(context context.Context, imageID string) (types.ImageInspect, []byte, error)
+	DiskUsage(context context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
 }
 
 // Interface for the compose service that is passed into the stack service
@@ -71,8 +80,9 @@ type Compose interface {
 }
 
 type ContainerInfo struct {
-	Config *Config `json:"Config"`
-	State  *State  `json:"State"`
+	Config       *Config `json:"Config"`
+	State        *State  `json:"State"`
+	RestartCount int     `json:"RestartCount"`
 }
 
 type State struct {
@@ -335,6 +345,28 @@ func (s *StackService) ImportDockerImage(input io.Reader) error {
 	return err
 }
 
+// Return the diff IDs of the layers of all locally available docker images.
+func (s *StackService) ListImageLayers() ([]string, error) {
+	ctx := context.Background()
+	images, err := s.cli.ImageList(ctx, types.ImageListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([]string, 0)
+	for _, image := range images {
+		inspect, _, err := s.cli.ImageInspectWithRaw(ctx, image.ID)
+		if err != nil {
+			if client.IsErrNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		layers = append(layers, inspect.RootFS.Layers...)
+	}
+	return layers, nil
+}
+
 // Normalize the stackname based on docker compose spec
 func normalizeStackName(stackName string) string {
 	return loader.NormalizeProjectName(stackName)
@@ -76,6 +76,16 @@ func (r *MockStackService) VolumeInspect(volumeID string) (types.Volume, error)
 	return args.Get(0).(types.Volume), args.Error(1)
 }
 
+func (r *MockStackService) ListImageLayers() ([]string, error) {
+	args := r.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (r *MockStackService) DiskUsage() (*DiskUsage, error) {
+	args := r.Called()
+	return args.Get(0).(*DiskUsage), args.Error(1)
+}
+
 type DockerClientMock struct {
 	mock.Mock
 	CalledListContainerOptions types.ContainerListOptions
@@ -135,6 +145,21 @@ func (d *DockerClientMock) ImageLoad(ctx context.Context, input io.Reader, quiet
 	return types.ImageLoadResponse{}, args.Error(1)
 }
 
+func (d *DockerClientMock) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	args := d.Called(options)
+	return args.Get(0).([]types.ImageSummary), args.Error(1)
+}
+
+func (d *DockerClientMock) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	args := d.Called(imageID)
+	return args.Get(0).(types.ImageInspect), nil, args.Error(1)
+}
+
+func (d *DockerClientMock) DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+	args := d.Called(options)
+	return args.Get(0).(types.DiskUsage), args.Error(1)
+}
+
 type ComposeMock struct {
 	mock.Mock
 	Project *composeTypes.Project
@@ -8,9 +8,12 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"u-control/uc-aom/internal/aom/docker"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/errdefs"
 )
 
@@ -223,6 +226,71 @@ func TestImportDockerImage(t *testing.T) {
 	dockerClient.AssertExpectations(t)
 }
 
+func TestListImageLayers(t *testing.T) {
+	// arrange
+	uut := createUut()
+	images := []types.ImageSummary{{ID: "sha256:image1"}, {ID: "sha256:image2"}}
+	dockerClient.On("ImageList", types.ImageListOptions{All: true}).Return(images, nil)
+	dockerClient.On("ImageInspectWithRaw", "sha256:image1").Return(types.ImageInspect{RootFS: types.RootFS{Layers: []string{"sha256:base"}}}, nil)
+	dockerClient.On("ImageInspectWithRaw", "sha256:image2").Return(types.ImageInspect{RootFS: types.RootFS{Layers: []string{"sha256:base", "sha256:app"}}}, nil)
+
+	// act
+	layers, err := uut.ListImageLayers()
+
+	// assert
+	if err != nil {
+		t.Fatalf("Error %v", err)
+	}
+
+	expectedLayers := []string{"sha256:base", "sha256:base", "sha256:app"}
+	if fmt.Sprint(layers) != fmt.Sprint(expectedLayers) {
+		t.Errorf("Expected layers %v but got %v", expectedLayers, layers)
+	}
+
+	dockerClient.AssertExpectations(t)
+}
+
+func TestDiskUsageOfStack(t *testing.T) {
+	// arrange
+	uut := createUut()
+	publicVolumeDirectory := t.TempDir()
+	if err := os.WriteFile(filepath.Join(publicVolumeDirectory, "data"), make([]byte, 7), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	options := types.DiskUsageOptions{Types: []types.DiskUsageObject{types.ImageObject, types.VolumeObject}}
+	dockerClient.On("DiskUsage", options).Return(types.DiskUsage{
+		Images: []*types.ImageSummary{
+			{RepoTags: []string{"test/app:1.0.0"}, Size: 100, SharedSize: 60},
+			{RepoTags: []string{"test/other:1.0.0"}, Size: 1000, SharedSize: 60},
+		},
+		Volumes: []*types.Volume{
+			{Name: "test-stack_data", Driver: "local", Labels: map[string]string{"com.docker.compose.project": "test-stack"}, UsageData: &types.VolumeUsageData{Size: 10}},
+			{Name: "test-stack_public", Driver: "local-public", Mountpoint: publicVolumeDirectory, Labels: map[string]string{"com.docker.compose.project": "test-stack"}, UsageData: &types.VolumeUsageData{Size: -1}},
+			{Name: "other_data", Driver: "local", Labels: map[string]string{"com.docker.compose.project": "other"}, UsageData: &types.VolumeUsageData{Size: 500}},
+		},
+	}, nil)
+
+	// act
+	diskUsage, err := uut.DiskUsage()
+	if err != nil {
+		t.Fatalf("Error %v", err)
+	}
+	stackUsage, err := diskUsage.StackUsage("test-stack", "test/app:1.0.0")
+
+	// assert
+	if err != nil {
+		t.Fatalf("Error %v", err)
+	}
+
+	expectedUsage := docker.StackDiskUsage{ImageBytes: 100, SharedImageBytes: 60, VolumeBytes: 10, PublicVolumeBytes: 7}
+	if *stackUsage != expectedUsage {
+		t.Errorf("Expected usage %+v but got %+v", expectedUsage, *stackUsage)
+	}
+
+	dockerClient.AssertExpectations(t)
+}
+
 var composeYaml = `
 version: "2"
 services:
@@ -139,7 +139,8 @@ func (s *FileServer) StopSWUpdateWatcher() {
 // Install all AddOns from the drop-in folder
 func (s *FileServer) InstallAllDropInAddOns() error {
 	log.Trace("InstallAllDropInAddOns()")
-	addOnsRepositoriesInDropInFolder, err := s.dropInAddOnRegistry.Repositories()
+	ctx := context.Background()
+	addOnsRepositoriesInDropInFolder, err := s.dropInAddOnRegistry.Repositories(ctx)
 	if err != nil {
 		return err
 	}
@@ -150,7 +151,7 @@ func (s *FileServer) InstallAllDropInAddOns() error {
 	}
 
 	for _, repository := range addOnsRepositoriesInDropInFolder {
-		tags, err := s.dropInAddOnRegistry.Tags(repository)
+		tags, err := s.dropInAddOnRegistry.Tags(ctx, repository)
 		if err != nil {
 			log.Errorf("Failed to read tags: %v", err)
 			continue
@@ -163,7 +164,7 @@ func (s *FileServer) InstallAllDropInAddOns() error {
 		sort.Sort(manifest.ByAddOnVersion(tags))
 		version := tags[len(tags)-1]
 
-		defer s.dropInAddOnRegistry.Delete(repository, version)
+		defer s.dropInAddOnRegistry.Delete(ctx, repository, version)
 
 		err = s.addOnCreator.CreateAddOnRoutine(repository, version)
 		if errors.Is(err, service.ErrorAddOnAlreadyInstalled) {
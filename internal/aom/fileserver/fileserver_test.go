@@ -5,6 +5,7 @@
 package fileserver_test
 
 import (
+	"context"
 	"errors"
 	"io"
 	"testing"
@@ -23,22 +24,33 @@ type registryMock struct {
 	mock.Mock
 }
 
-func (m registryMock) Tags(repository string) ([]string, error) {
+func (m registryMock) Tags(ctx context.Context, repository string) ([]string, error) {
 	args := m.Called(repository)
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (m registryMock) Repositories() ([]string, error) {
+func (m registryMock) Digest(ctx context.Context, repository string, tag string) (string, error) {
+	args := m.Called(repository, tag)
+	return args.String(0), args.Error(1)
+}
+
+func (m registryMock) Summary(ctx context.Context, repository string, tag string) (string, *manifest.AddOnSummary, error) {
+	args := m.Called(repository, tag)
+	summary, _ := args.Get(1).(*manifest.AddOnSummary)
+	return args.String(0), summary, args.Error(2)
+}
+
+func (m registryMock) Repositories(ctx context.Context) ([]string, error) {
 	args := m.Called()
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (m registryMock) Pull(repository string, tag string, processor registry.ImageManifestLayerProcessor) (uint64, error) {
+func (m registryMock) Pull(ctx context.Context, repository string, tag string, processor registry.ImageManifestLayerProcessor) (uint64, error) {
 	args := m.Called(repository, tag, processor)
 	return args.Get(0).(uint64), args.Error(1)
 }
 
-func (m registryMock) Delete(repository string, tag string) error {
+func (m registryMock) Delete(ctx context.Context, repository string, tag string) error {
 	args := m.Called(repository, tag)
 	return args.Error(0)
 }
@@ -370,6 +382,7 @@ func TestFileServer_InstallAllDropInAddOnsUpdateGreaterVersionTransaction(t *tes
 	ts.On("PullAddOn", "abc", "0.2.0-1").Return(addon, nil)
 	ts.On("Validate", mock.Anything).Return(nil)
 	ts.On("FetchManifest", addon.AddOn.Name, addon.AddOn.Version).Return(&addon.AddOn.Manifest, nil)
+	ts.On("FetchDiskFootprint", addon.AddOn.Name, addon.AddOn.Version).Return(catalogue.DiskFootprint{}, nil)
 	ts.MockStackService.On("CreateStackWithDockerCompose", "abc", mock.AnythingOfType("string"), mock.Anything).Return(nil)
 	ts.On("IamPermissionWriterWrite", mock.Anything, mock.Anything).Return(nil)
 	ts.On("AvailableSpaceInBytes").Return(uint64(1), nil)
@@ -428,6 +441,7 @@ func TestFileServer_InstallAllDropInAddOnsUpdateGreaterVersionWithSettingsTransa
 	ts.On("PullAddOn", "abc", "0.2.0-1").Return(addon, nil)
 	ts.On("Validate", mock.Anything).Return(nil)
 	ts.On("FetchManifest", addon.AddOn.Name, "0.2.0-1").Return(&addon.AddOn.Manifest, nil)
+	ts.On("FetchDiskFootprint", addon.AddOn.Name, "0.2.0-1").Return(catalogue.DiskFootprint{}, nil)
 	envSettingsAfter := make([]*manifest.Setting, 2)
 	envSettingsAfter[0] = manifest.NewSettings("param1", "param1", false).WithTextBoxValue("aaa")
 	envSettingsAfter[1] = manifest.NewSettings("param3", "param3", false).WithTextBoxValue("xyz")
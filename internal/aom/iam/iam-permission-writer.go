@@ -7,6 +7,7 @@ package iam
 import (
 	"fmt"
 	"io"
+	"strings"
 	"text/template"
 
 	log "github.com/sirupsen/logrus"
@@ -73,6 +74,15 @@ func NewIamPermissionWriter(
 		deleteFile:         deleteFile}
 }
 
+// Renders the permission file without writing it, returns its filepath and content.
+func (w *IamPermissionWriter) Render(iamPermission *IamPermission) (string, string, error) {
+	var content strings.Builder
+	if err := w.permissionTemplate.Execute(&content, iamPermission); err != nil {
+		return "", "", err
+	}
+	return w.getPermissionsFilepath(iamPermission.Id), content.String(), nil
+}
+
 func (w *IamPermissionWriter) Create(iamPermission *IamPermission) error {
 	log.Tracef("IamPermissionWriter/Create")
 	filepath := w.getPermissionsFilepath(iamPermission.Id)
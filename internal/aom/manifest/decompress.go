@@ -30,6 +30,9 @@ type ManifestLayerContent struct {
 
 	// Name of the logo
 	LogoName string
+
+	// Raw content of the further assets the manifest references, e.g. screenshots, readme and changelog, by file name
+	Assets map[string][]byte `json:",omitempty"`
 }
 
 // Interface to decompress the manifest layer blob
@@ -69,6 +72,7 @@ func readDataFromTgz(tgz io.Reader) (*tar.Reader, error) {
 func readLogoAndManifestFromTar(tarReader *tar.Reader) (*ManifestLayerContent, error) {
 
 	manifestDecompressLayer := &ManifestLayerContent{}
+	files := make(map[string][]byte)
 	for {
 		header, err := tarReader.Next()
 		if err == io.EOF {
@@ -84,17 +88,45 @@ func readLogoAndManifestFromTar(tarReader *tar.Reader) (*ManifestLayerContent, e
 				return nil, err
 			}
 		} else {
-			// the logo is the only other accepted file
-			manifestDecompressLayer.LogoName = header.Name
-			manifestDecompressLayer.Logo, err = ioutil.ReadAll(tarReader)
+			files[header.Name], err = ioutil.ReadAll(tarReader)
 			if err != nil {
 				return nil, err
 			}
 		}
 	}
+
+	logoName := logoNameOf(manifestDecompressLayer.Manifest, files)
+	for name, content := range files {
+		if name == logoName {
+			manifestDecompressLayer.LogoName = name
+			manifestDecompressLayer.Logo = content
+			continue
+		}
+		if manifestDecompressLayer.Assets == nil {
+			manifestDecompressLayer.Assets = make(map[string][]byte)
+		}
+		manifestDecompressLayer.Assets[name] = content
+	}
 	return manifestDecompressLayer, nil
 }
 
+// Returns the name of the logo referenced by the manifest.
+// Packages which only contain the manifest and the logo may reference the logo under another name,
+// in which case the only other file is the logo.
+func logoNameOf(manifestRaw []byte, files map[string][]byte) string {
+	if root, err := manifest.NewFromBytes(manifestRaw); err == nil {
+		if _, ok := files[root.Logo]; ok {
+			return root.Logo
+		}
+	}
+	if len(files) == 1 {
+		for name := range files {
+			return name
+		}
+	}
+	return ""
+}
+
 // WriteUcManifestContentToDestination the reader, add hash to logo and extract to destination.
 func WriteUcManifestContentToDestination(reader io.Reader, destination string) error {
 	manifestLayer, err := UnmarshalDecompressedContent(reader)
@@ -109,7 +141,12 @@ func WriteUcManifestContentToDestination(reader io.Reader, destination string) e
 
 	newLogoName := addHashToFilename(manifestLayer.LogoName, hash)
 
-	maifestRawChanged, err := changeLogoInManifest(manifestLayer.Manifest, newLogoName)
+	assets, err := hashedAssetsOf(manifestLayer)
+	if err != nil {
+		return err
+	}
+
+	maifestRawChanged, err := changeAssetsInManifest(manifestLayer.Manifest, newLogoName, assets)
 	if err != nil {
 		return err
 	}
@@ -130,9 +167,52 @@ func WriteUcManifestContentToDestination(reader io.Reader, destination string) e
 		}
 		return err
 	}
+	for _, asset := range assets {
+		if err = utils.WriteFileToDestination(asset.name, asset.content, destination); err != nil {
+			if err := os.RemoveAll(destination); err != nil {
+				return err
+			}
+			return err
+		}
+	}
 	return nil
 }
 
+type hashedAsset struct {
+	// Name of the asset within the package
+	originalName string
+
+	// Name of the asset on disk, images carry the hash of their content like the logo
+	name    string
+	content []byte
+}
+
+// Returns the further assets of the manifest layer, assets with a path are skipped.
+func hashedAssetsOf(manifestLayer *ManifestLayerContent) ([]hashedAsset, error) {
+	assets := make([]hashedAsset, 0, len(manifestLayer.Assets))
+	for name, content := range manifestLayer.Assets {
+		if filepath.Base(name) != name || name == "." || name == ".." {
+			log.Warnf("Skipping the asset '%s', which is not located next to the manifest", name)
+			continue
+		}
+
+		asset := hashedAsset{originalName: name, name: name, content: content}
+		if !isMarkdownFile(name) {
+			hash, err := utils.GetShortSHA1HashFrom(content)
+			if err != nil {
+				return nil, err
+			}
+			asset.name = addHashToFilename(name, hash)
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+func isMarkdownFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".md")
+}
+
 func UnmarshalDecompressedContent(reader io.Reader) (*ManifestLayerContent, error) {
 	content, err := io.ReadAll(reader)
 	if err != nil {
@@ -154,11 +234,21 @@ func addHashToFilename(filename string, hash string) string {
 	return newFilename
 }
 
-func changeLogoInManifest(manifestRaw []byte, newLogoName string) ([]byte, error) {
+func changeAssetsInManifest(manifestRaw []byte, newLogoName string, assets []hashedAsset) ([]byte, error) {
 	manifest, err := manifest.NewFromBytes(manifestRaw)
 	if err != nil {
 		return nil, err
 	}
 	manifest.Logo = newLogoName
+
+	newNames := make(map[string]string, len(assets))
+	for _, asset := range assets {
+		newNames[asset.originalName] = asset.name
+	}
+	for i, screenshot := range manifest.Screenshots {
+		if newName, ok := newNames[screenshot]; ok {
+			manifest.Screenshots[i] = newName
+		}
+	}
 	return manifest.ToBytes()
 }
@@ -77,6 +77,61 @@ func TestDecompress_ShallAddHashValueToLogoFile(t *testing.T) {
 	}
 }
 
+func TestDecompress_ShallWriteScreenshotsAndDocuments(t *testing.T) {
+	// arrange
+	logoTarFile := createLogo("logo.png")
+	screenshotTarFile := tarArchiveFile{Name: "dashboard.png", Content: []byte("screenshot-content")}
+	readmeTarFile := tarArchiveFile{Name: "README.md", Content: []byte("# Test add-on")}
+	addOnManifest := model.Root{Logo: logoTarFile.Name, Screenshots: []string{screenshotTarFile.Name}, Readme: readmeTarFile.Name}
+	manifestContent, _ := json.Marshal(addOnManifest)
+	manifestTarFile := tarArchiveFile{Name: config.UcImageManifestFilename, Content: manifestContent}
+
+	reader := createManifestLogoTarReader(t, &screenshotTarFile, &manifestTarFile, &readmeTarFile, &logoTarFile)
+	tempDir := t.TempDir()
+	decompressor := manifest.ManifestTarGzipDecompressor{}
+
+	// act
+	rc, err := decompressor.Decompress(reader)
+	if err != nil {
+		t.Fatalf("Decompress(), Unexpected error %v", err)
+	}
+
+	if err := manifest.WriteUcManifestContentToDestination(rc, tempDir); err != nil {
+		t.Fatalf("Decompress() failed! %v", err)
+	}
+
+	// assert
+	manifestRaw, err := os.ReadFile(filepath.Join(tempDir, config.UcImageManifestFilename))
+	if err != nil {
+		t.Fatalf("os.ReadFile(manifest): %v", err)
+	}
+	gotManifest, err := model.NewFromBytes(manifestRaw)
+	if err != nil {
+		t.Fatalf("Couldn't read decompressed manifest.")
+	}
+
+	logoHash, _ := utils.GetShortSHA1HashFrom(logoTarFile.Content)
+	if gotManifest.Logo != "logo-"+logoHash+".png" {
+		t.Errorf("Unexpected logo %s", gotManifest.Logo)
+	}
+
+	screenshotHash, _ := utils.GetShortSHA1HashFrom(screenshotTarFile.Content)
+	expectedScreenshot := "dashboard-" + screenshotHash + ".png"
+	if len(gotManifest.Screenshots) != 1 || gotManifest.Screenshots[0] != expectedScreenshot {
+		t.Fatalf("Expected screenshots [%s] but got %v", expectedScreenshot, gotManifest.Screenshots)
+	}
+	if content, err := os.ReadFile(filepath.Join(tempDir, expectedScreenshot)); err != nil || string(content) != string(screenshotTarFile.Content) {
+		t.Errorf("Unexpected screenshot content %s, %v", content, err)
+	}
+
+	if gotManifest.Readme != readmeTarFile.Name {
+		t.Errorf("Expected readme %s but got %s", readmeTarFile.Name, gotManifest.Readme)
+	}
+	if content, err := os.ReadFile(filepath.Join(tempDir, readmeTarFile.Name)); err != nil || string(content) != string(readmeTarFile.Content) {
+		t.Errorf("Unexpected readme content %s, %v", content, err)
+	}
+}
+
 func TestDecompress_ShallReturnErrorIfManifestNotExist(t *testing.T) {
 	// arrange
 	logoTarFile := createLogo("logo.png")
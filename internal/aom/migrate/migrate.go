@@ -48,7 +48,7 @@ func NewInstallAddOnMigrator(root string,
 	envResolver env.EnvResolver,
 	reverseProxy routes.ReverseProxyCreater) Migrator {
 	localFSAdapter := &localFSRegistryAdapter{root: root, localfs: localfs}
-	stackMigrator := docker.NewStackMigrator(stackService)
+	stackMigrator := docker.NewStackMigrator(stackService, service)
 	routesMigrator := routes.NewReverseProxyMigrator(reverseProxy)
 
 	versionResolver := &aomVersionResolver{
@@ -0,0 +1,32 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package mirror
+
+import (
+	"u-control/uc-aom/internal/pkg/utils"
+)
+
+var (
+	// Address the mirror listens on, e.g. ":5000". The device does not mirror its add-ons if empty.
+	MIRROR_LISTEN_ADDRESS = utils.GetEnv("MIRROR_LISTEN_ADDRESS", "")
+
+	// Manifests which the mirror served, so that it serves them while the upstream registries are unreachable
+	MIRROR_MANIFESTS_PATH = utils.GetEnv("MIRROR_MANIFESTS_PATH", "/var/lib/uc-aom/mirror-manifests")
+
+	// Certificate and key the mirror is served with, the mirror is only served with TLS.
+	MIRROR_TLS_CERT_PATH = utils.GetEnv("MIRROR_TLS_CERT_PATH", "/var/lib/uc-aom/mirror/tls.crt")
+	MIRROR_TLS_KEY_PATH  = utils.GetEnv("MIRROR_TLS_KEY_PATH", "/var/lib/uc-aom/mirror/tls.key")
+
+	// PEM file of the certificate authorities whose client certificates the mirror accepts, ignored if the file does not exist.
+	MIRROR_CLIENT_CA_PATH = utils.GetEnv("MIRROR_CLIENT_CA_PATH", "/var/lib/uc-aom/mirror/client-ca.pem")
+
+	// Device token which the mirror accepts and which is presented to the mirrors of the catalogue sources,
+	// ignored if the file does not exist. The mirror is not served without a token or client certificate authorities.
+	MIRROR_TOKEN_PATH = utils.GetEnv("MIRROR_TOKEN_PATH", "/var/lib/uc-aom/mirror/token")
+
+	// Client certificate and key presented to the mirrors of the catalogue sources, ignored if the certificate does not exist.
+	MIRROR_CLIENT_CERT_PATH = utils.GetEnv("MIRROR_CLIENT_CERT_PATH", "/var/lib/uc-aom/mirror/client.crt")
+	MIRROR_CLIENT_KEY_PATH  = utils.GetEnv("MIRROR_CLIENT_KEY_PATH", "/var/lib/uc-aom/mirror/client.key")
+)
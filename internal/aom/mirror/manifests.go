@@ -0,0 +1,169 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const manifestsFileSuffix = ".json"
+
+type storedManifest struct {
+	Descriptor ocispec.Descriptor `json:"descriptor"`
+	Content    []byte             `json:"content"`
+}
+
+type repositoryManifests struct {
+	// Digest per tag
+	Tags map[string]digest.Digest `json:"tags"`
+
+	// Manifest per digest
+	Manifests map[digest.Digest]storedManifest `json:"manifests"`
+}
+
+// manifestStore persists the manifests the mirror served per repository,
+// each repository in its own file named after the escaped repository.
+type manifestStore struct {
+	mutex sync.Mutex
+	root  string
+}
+
+func newManifestStore(root string) *manifestStore {
+	return &manifestStore{root: root}
+}
+
+// Returns the manifest of the repository identified by reference, a tag or a digest.
+func (s *manifestStore) Resolve(repository string, reference string) (ocispec.Descriptor, []byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	manifests, err := s.read(repository)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, false
+	}
+	dgst := digest.Digest(reference)
+	if tagged, ok := manifests.Tags[reference]; ok {
+		dgst = tagged
+	}
+	manifest, ok := manifests.Manifests[dgst]
+	return manifest.Descriptor, manifest.Content, ok
+}
+
+// Remembers the manifest of the repository identified by reference.
+func (s *manifestStore) Record(repository string, reference string, desc ocispec.Descriptor, content []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	manifests, err := s.read(repository)
+	if err != nil {
+		return err
+	}
+	_, known := manifests.Manifests[desc.Digest]
+	if known && (isDigest(reference) || manifests.Tags[reference] == desc.Digest) {
+		return nil
+	}
+	manifests.Manifests[desc.Digest] = storedManifest{Descriptor: desc, Content: content}
+	if !isDigest(reference) {
+		manifests.Tags[reference] = desc.Digest
+	}
+
+	serialized, err := json.Marshal(manifests)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.root, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(s.pathOf(repository), serialized, 0644)
+}
+
+// Returns the repositories with stored manifests.
+func (s *manifestStore) Repositories() ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries, err := os.ReadDir(s.root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	repositories := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), manifestsFileSuffix) {
+			continue
+		}
+		repository, err := url.PathUnescape(strings.TrimSuffix(entry.Name(), manifestsFileSuffix))
+		if err != nil {
+			continue
+		}
+		repositories = append(repositories, repository)
+	}
+	sort.Strings(repositories)
+	return repositories, nil
+}
+
+// Returns the tags of the repository, ErrNotFound if no manifest of the repository is stored.
+func (s *manifestStore) Tags(repository string) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	manifests, err := s.read(repository)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifests.Manifests) == 0 {
+		return nil, ErrNotFound
+	}
+
+	tags := make([]string, 0, len(manifests.Tags))
+	for tag := range manifests.Tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// MUST be called under the lock.
+func (s *manifestStore) read(repository string) (*repositoryManifests, error) {
+	manifests := &repositoryManifests{}
+	content, err := os.ReadFile(s.pathOf(repository))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, manifests); err != nil {
+			return nil, err
+		}
+	}
+	if manifests.Tags == nil {
+		manifests.Tags = make(map[string]digest.Digest)
+	}
+	if manifests.Manifests == nil {
+		manifests.Manifests = make(map[digest.Digest]storedManifest)
+	}
+	return manifests, nil
+}
+
+func (s *manifestStore) pathOf(repository string) string {
+	return filepath.Join(s.root, url.PathEscape(repository)+manifestsFileSuffix)
+}
+
+func isDigest(reference string) bool {
+	return digest.Digest(reference).Validate() == nil
+}
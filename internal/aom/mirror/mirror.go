@@ -0,0 +1,303 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"u-control/uc-aom/internal/aom/registry"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+// Upper bound of the size of a manifest served by the mirror
+const maxManifestSize = 4 * 1024 * 1024
+
+// Error codes of the OCI distribution specification
+const (
+	errorCodeBlobUnknown     = "BLOB_UNKNOWN"
+	errorCodeDigestInvalid   = "DIGEST_INVALID"
+	errorCodeManifestUnknown = "MANIFEST_UNKNOWN"
+	errorCodeNameInvalid     = "NAME_INVALID"
+	errorCodeNameUnknown     = "NAME_UNKNOWN"
+	errorCodeUnauthorized    = "UNAUTHORIZED"
+	errorCodeUnsupported     = "UNSUPPORTED"
+)
+
+// Returns whether the mirror serves the repository, e.g. because its add-on is installed.
+type ServedFunc func(repository string) bool
+
+// Mirror serves the add-on packages of the upstream registries read-only over the OCI distribution API,
+// so that devices without internet access are able to pull them from a device which has.
+// Blobs are pulled through the blob cache, which also keeps the blobs of the installed add-ons.
+// Manifests are stored, so that they are served while the upstream registries are unreachable.
+// Only the repositories for which isServed returns true are served, and only to authenticated clients.
+type Mirror struct {
+	upstream  Upstream
+	cache     *registry.BlobCache
+	manifests *manifestStore
+	isServed  ServedFunc
+	token     string
+}
+
+// NewMirror creates a mirror of the upstream, which stores the served manifests in manifestsRoot.
+// Clients authenticate with the token or with a client certificate, the token is not accepted if empty.
+func NewMirror(upstream Upstream, cache *registry.BlobCache, manifestsRoot string, isServed ServedFunc, token string) *Mirror {
+	return &Mirror{upstream: upstream, cache: cache, manifests: newManifestStore(manifestsRoot), isServed: isServed, token: token}
+}
+
+func (m *Mirror) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("Mirror: %s %s", r.Method, r.URL.Path)
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	if !m.isAuthorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="uc-aom mirror"`)
+		writeError(w, http.StatusUnauthorized, errorCodeUnauthorized, "The mirror requires the device token or a client certificate")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, errorCodeUnsupported, "The mirror is read-only")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2")
+	if path == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	if path == "" || path == "/" {
+		writeJSON(w, r, struct{}{})
+		return
+	}
+	if path == "/_catalog" {
+		m.serveCatalog(w, r)
+		return
+	}
+
+	path = strings.TrimPrefix(path, "/")
+	if repository := strings.TrimSuffix(path, "/tags/list"); repository != path {
+		m.serveTags(w, r, repository)
+		return
+	}
+	if repository, reference, ok := cut(path, "/manifests/"); ok {
+		m.serveManifest(w, r, repository, reference)
+		return
+	}
+	if repository, reference, ok := cut(path, "/blobs/"); ok {
+		m.serveBlob(w, r, repository, reference)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (m *Mirror) serveCatalog(w http.ResponseWriter, r *http.Request) {
+	repositories, err := m.upstream.Repositories(r.Context())
+	if err != nil {
+		log.Debugf("Mirror: serving the stored repositories: %v", err)
+		repositories, err = m.manifests.Repositories()
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, errorCodeUnsupported, err.Error())
+		return
+	}
+
+	served := make([]string, 0, len(repositories))
+	for _, repository := range repositories {
+		if m.isServed(repository) {
+			served = append(served, repository)
+		}
+	}
+	writeJSON(w, r, struct {
+		Repositories []string `json:"repositories"`
+	}{after(served, r.URL.Query().Get("last"))})
+}
+
+func (m *Mirror) serveTags(w http.ResponseWriter, r *http.Request, repository string) {
+	if !isValidRepository(repository) {
+		writeError(w, http.StatusBadRequest, errorCodeNameInvalid, fmt.Sprintf("Invalid repository '%s'", repository))
+		return
+	}
+	if !m.isServed(repository) {
+		writeError(w, http.StatusNotFound, errorCodeNameUnknown, fmt.Sprintf("Unknown repository '%s'", repository))
+		return
+	}
+
+	tags, err := m.upstream.Tags(r.Context(), repository)
+	if err != nil {
+		log.Debugf("Mirror: serving the stored tags of '%s': %v", repository, err)
+		tags, err = m.manifests.Tags(repository)
+	}
+	if err != nil {
+		writeError(w, http.StatusNotFound, errorCodeNameUnknown, fmt.Sprintf("Unknown repository '%s'", repository))
+		return
+	}
+	sort.Strings(tags)
+	writeJSON(w, r, struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}{repository, after(tags, r.URL.Query().Get("last"))})
+}
+
+func (m *Mirror) serveManifest(w http.ResponseWriter, r *http.Request, repository string, reference string) {
+	if !isValidRepository(repository) {
+		writeError(w, http.StatusBadRequest, errorCodeNameInvalid, fmt.Sprintf("Invalid repository '%s'", repository))
+		return
+	}
+	if !m.isServed(repository) {
+		writeError(w, http.StatusNotFound, errorCodeNameUnknown, fmt.Sprintf("Unknown repository '%s'", repository))
+		return
+	}
+
+	desc, content, err := m.fetchManifest(r, repository, reference)
+	if err != nil {
+		log.Debugf("Mirror: serving the stored manifest '%s:%s': %v", repository, reference, err)
+		var ok bool
+		desc, content, ok = m.manifests.Resolve(repository, reference)
+		if !ok {
+			writeError(w, http.StatusNotFound, errorCodeManifestUnknown, fmt.Sprintf("Unknown manifest '%s:%s'", repository, reference))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", desc.MediaType)
+	w.Header().Set("Docker-Content-Digest", desc.Digest.String())
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(content)
+}
+
+// Fetches the manifest from the upstream, verifies and stores it.
+func (m *Mirror) fetchManifest(r *http.Request, repository string, reference string) (ocispec.Descriptor, []byte, error) {
+	desc, reader, err := m.upstream.FetchManifest(r.Context(), repository, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	defer reader.Close()
+
+	if desc.Size > maxManifestSize {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("The manifest exceeds %d bytes", maxManifestSize)
+	}
+	content, err := io.ReadAll(io.LimitReader(reader, desc.Size))
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	if int64(len(content)) != desc.Size || desc.Digest.Validate() != nil || desc.Digest.Algorithm().FromBytes(content) != desc.Digest {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("The content of %s does not match its digest", desc.Digest)
+	}
+
+	if err := m.manifests.Record(repository, reference, desc, content); err != nil {
+		log.Warnf("Mirror: unable to store the manifest '%s:%s': %v", repository, reference, err)
+	}
+	return desc, content, nil
+}
+
+func (m *Mirror) serveBlob(w http.ResponseWriter, r *http.Request, repository string, reference string) {
+	if !isValidRepository(repository) {
+		writeError(w, http.StatusBadRequest, errorCodeNameInvalid, fmt.Sprintf("Invalid repository '%s'", repository))
+		return
+	}
+	if !m.isServed(repository) {
+		writeError(w, http.StatusNotFound, errorCodeNameUnknown, fmt.Sprintf("Unknown repository '%s'", repository))
+		return
+	}
+	dgst, err := digest.Parse(reference)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errorCodeDigestInvalid, fmt.Sprintf("Invalid digest '%s'", reference))
+		return
+	}
+
+	blob, err := m.cache.Open(dgst)
+	if errors.Is(err, fs.ErrNotExist) {
+		desc, fetcher, resolveErr := m.upstream.ResolveBlob(r.Context(), repository, dgst)
+		if resolveErr != nil {
+			log.Debugf("Mirror: unable to resolve the blob %s of '%s': %v", dgst, repository, resolveErr)
+			writeError(w, http.StatusNotFound, errorCodeBlobUnknown, fmt.Sprintf("Unknown blob %s", dgst))
+			return
+		}
+		blob, err = m.cache.Fetch(r.Context(), fetcher, desc)
+	}
+	if err != nil {
+		log.Errorf("Mirror: unable to serve the blob %s of '%s': %v", dgst, repository, err)
+		writeError(w, http.StatusNotFound, errorCodeBlobUnknown, fmt.Sprintf("Unavailable blob %s", dgst))
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", dgst.String())
+	http.ServeContent(w, r, "", time.Time{}, blob)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, value interface{}) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, errorCodeUnsupported, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(content)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	type distributionError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	content, _ := json.Marshal(struct {
+		Errors []distributionError `json:"errors"`
+	}{[]distributionError{{Code: code, Message: message}}})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(content)
+}
+
+// Splits the path at the last separator.
+func cut(path string, separator string) (string, string, bool) {
+	index := strings.LastIndex(path, separator)
+	if index < 0 {
+		return "", "", false
+	}
+	return path[:index], path[index+len(separator):], true
+}
+
+func isValidRepository(repository string) bool {
+	if repository == "" {
+		return false
+	}
+	for _, component := range strings.Split(repository, "/") {
+		if component == "" || component == "." || component == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the sorted values after last, all values if last is empty.
+func after(values []string, last string) []string {
+	if last == "" {
+		return values
+	}
+	index := sort.SearchStrings(values, last)
+	if index < len(values) && values[index] == last {
+		index++
+	}
+	return values[index:]
+}
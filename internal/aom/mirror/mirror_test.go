@@ -0,0 +1,315 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package mirror_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"u-control/uc-aom/internal/aom/mirror"
+	"u-control/uc-aom/internal/aom/registry"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/content"
+	orasRegistry "oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+type blobFetcher map[digest.Digest][]byte
+
+func (f blobFetcher) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(f[target.Digest])), nil
+}
+
+// Upstream with a single add-on package, which fails once it is offline
+type upstreamFake struct {
+	offline  bool
+	manifest []byte
+	blobs    blobFetcher
+	fetches  int
+}
+
+func newUpstreamFake() *upstreamFake {
+	layer := []byte("docker image layer")
+	layerDesc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer, Digest: digest.FromBytes(layer), Size: int64(len(layer))}
+	manifest, _ := json.Marshal(ocispec.Manifest{Layers: []ocispec.Descriptor{layerDesc}})
+	return &upstreamFake{manifest: manifest, blobs: blobFetcher{layerDesc.Digest: layer}}
+}
+
+func (u *upstreamFake) layer() ocispec.Descriptor {
+	var manifest ocispec.Manifest
+	json.Unmarshal(u.manifest, &manifest)
+	return manifest.Layers[0]
+}
+
+func (u *upstreamFake) Repositories(ctx context.Context) ([]string, error) {
+	if u.offline {
+		return nil, errors.New("offline")
+	}
+	return []string{"test-uc-addon"}, nil
+}
+
+func (u *upstreamFake) Tags(ctx context.Context, repository string) ([]string, error) {
+	if u.offline || repository != "test-uc-addon" {
+		return nil, mirror.ErrNotFound
+	}
+	return []string{"1.0.0-1", "1.1.0-1"}, nil
+}
+
+func (u *upstreamFake) FetchManifest(ctx context.Context, repository string, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
+	if u.offline || repository != "test-uc-addon" || reference != "1.0.0-1" {
+		return ocispec.Descriptor{}, nil, mirror.ErrNotFound
+	}
+	desc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromBytes(u.manifest), Size: int64(len(u.manifest))}
+	return desc, io.NopCloser(bytes.NewReader(u.manifest)), nil
+}
+
+func (u *upstreamFake) ResolveBlob(ctx context.Context, repository string, dgst digest.Digest) (ocispec.Descriptor, content.Fetcher, error) {
+	if u.offline {
+		return ocispec.Descriptor{}, nil, mirror.ErrNotFound
+	}
+	blob, ok := u.blobs[dgst]
+	if !ok {
+		return ocispec.Descriptor{}, nil, mirror.ErrNotFound
+	}
+	u.fetches++
+	return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer, Digest: dgst, Size: int64(len(blob))}, u.blobs, nil
+}
+
+const testToken = "device-token"
+
+func isTestRepository(repository string) bool {
+	return repository == "test-uc-addon"
+}
+
+func createMirrorTestServer(t *testing.T, upstream mirror.Upstream) *httptest.Server {
+	cache := registry.NewBlobCache(t.TempDir(), 1, time.Millisecond)
+	server := httptest.NewTLSServer(mirror.NewMirror(upstream, cache, t.TempDir(), isTestRepository, testToken))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newMirrorClient(t *testing.T, server *httptest.Server) orasRegistry.Repository {
+	repository, err := remote.NewRepository(strings.TrimPrefix(server.URL, "https://") + "/test-uc-addon")
+	assert.NoError(t, err)
+	repository.Client = &auth.Client{
+		Client: server.Client(),
+		Credential: func(ctx context.Context, registry string) (auth.Credential, error) {
+			return auth.Credential{Username: "uc-aom", Password: testToken}, nil
+		},
+	}
+	return repository
+}
+
+func newMirrorRequest(method string, url string) *http.Request {
+	request, _ := http.NewRequest(method, url, nil)
+	request.SetBasicAuth("uc-aom", testToken)
+	return request
+}
+
+func TestMirrorPullsThroughUpstream(t *testing.T) {
+	// Arrange
+	upstream := newUpstreamFake()
+	server := createMirrorTestServer(t, upstream)
+	client := newMirrorClient(t, server)
+	ctx := context.Background()
+
+	// Act
+	manifestDesc, manifestReader, manifestErr := client.FetchReference(ctx, "1.0.0-1")
+	tags, tagsErr := orasRegistry.Tags(ctx, client)
+	layer, layerErr := content.FetchAll(ctx, client, upstream.layer())
+	_, cachedLayerErr := content.FetchAll(ctx, client, upstream.layer())
+
+	// Assert
+	assert.NoError(t, manifestErr)
+	assert.NoError(t, tagsErr)
+	assert.NoError(t, layerErr)
+	assert.NoError(t, cachedLayerErr)
+	manifest, _ := io.ReadAll(manifestReader)
+	assert.Equal(t, upstream.manifest, manifest)
+	assert.Equal(t, digest.FromBytes(upstream.manifest), manifestDesc.Digest)
+	assert.Equal(t, []string{"1.0.0-1", "1.1.0-1"}, tags)
+	assert.Equal(t, upstream.blobs[upstream.layer().Digest], layer)
+	assert.Equal(t, 1, upstream.fetches)
+}
+
+func TestMirrorServesPulledPackagesWhileUpstreamIsOffline(t *testing.T) {
+	// Arrange
+	upstream := newUpstreamFake()
+	server := createMirrorTestServer(t, upstream)
+	client := newMirrorClient(t, server)
+	ctx := context.Background()
+	_, reader, err := client.FetchReference(ctx, "1.0.0-1")
+	assert.NoError(t, err)
+	reader.Close()
+	_, err = content.FetchAll(ctx, client, upstream.layer())
+	assert.NoError(t, err)
+	upstream.offline = true
+
+	// Act
+	_, manifestReader, manifestErr := client.FetchReference(ctx, "1.0.0-1")
+	tags, tagsErr := orasRegistry.Tags(ctx, client)
+	layer, layerErr := content.FetchAll(ctx, client, upstream.layer())
+	_, _, unknownErr := client.FetchReference(ctx, "1.1.0-1")
+
+	// Assert
+	assert.NoError(t, manifestErr)
+	assert.NoError(t, tagsErr)
+	assert.NoError(t, layerErr)
+	assert.Error(t, unknownErr)
+	manifest, _ := io.ReadAll(manifestReader)
+	assert.Equal(t, upstream.manifest, manifest)
+	assert.Equal(t, []string{"1.0.0-1"}, tags)
+	assert.Equal(t, upstream.blobs[upstream.layer().Digest], layer)
+}
+
+func TestMirrorServesBlobRanges(t *testing.T) {
+	// Arrange
+	upstream := newUpstreamFake()
+	server := createMirrorTestServer(t, upstream)
+	request := newMirrorRequest(http.MethodGet, server.URL+"/v2/test-uc-addon/blobs/"+upstream.layer().Digest.String())
+	request.Header.Set("Range", "bytes=7-")
+
+	// Act
+	response, err := server.Client().Do(request)
+
+	// Assert
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, http.StatusPartialContent, response.StatusCode)
+	assert.Equal(t, "image layer", string(body))
+}
+
+func TestMirrorRejectsUnsupportedRequests(t *testing.T) {
+	// Arrange
+	server := createMirrorTestServer(t, newUpstreamFake())
+	testCases := map[string]struct {
+		method string
+		path   string
+		status int
+	}{
+		"push":            {http.MethodPost, "/v2/test-uc-addon/blobs/uploads/", http.StatusMethodNotAllowed},
+		"unknown blob":    {http.MethodGet, "/v2/test-uc-addon/blobs/" + digest.FromString("unknown").String(), http.StatusNotFound},
+		"invalid digest":  {http.MethodGet, "/v2/test-uc-addon/blobs/sha256:invalid", http.StatusBadRequest},
+		"invalid name":    {http.MethodGet, "/v2/../manifests/1.0.0-1", http.StatusBadRequest},
+		"unknown version": {http.MethodGet, "/v2/test-uc-addon/manifests/2.0.0-1", http.StatusNotFound},
+		"not installed":   {http.MethodGet, "/v2/other-uc-addon/tags/list", http.StatusNotFound},
+		"not the api":     {http.MethodGet, "/index.html", http.StatusNotFound},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			response, err := server.Client().Do(newMirrorRequest(testCase.method, server.URL+testCase.path))
+
+			// Assert
+			assert.NoError(t, err)
+			response.Body.Close()
+			assert.Equal(t, testCase.status, response.StatusCode)
+		})
+	}
+}
+
+func TestMirrorRequiresAuthentication(t *testing.T) {
+	// Arrange
+	server := createMirrorTestServer(t, newUpstreamFake())
+	path := server.URL + "/v2/test-uc-addon/tags/list"
+	wrongToken, _ := http.NewRequest(http.MethodGet, path, nil)
+	wrongToken.SetBasicAuth("uc-aom", "wrong")
+	withoutToken, _ := http.NewRequest(http.MethodGet, path, nil)
+
+	for name, request := range map[string]*http.Request{"wrong token": wrongToken, "without token": withoutToken} {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			response, err := server.Client().Do(request)
+
+			// Assert
+			assert.NoError(t, err)
+			response.Body.Close()
+			assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+			assert.NotEmpty(t, response.Header.Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestMirrorRejectsPlainHTTP(t *testing.T) {
+	// Arrange
+	cache := registry.NewBlobCache(t.TempDir(), 1, time.Millisecond)
+	server := httptest.NewServer(mirror.NewMirror(newUpstreamFake(), cache, t.TempDir(), isTestRepository, testToken))
+	defer server.Close()
+
+	// Act
+	response, err := server.Client().Do(newMirrorRequest(http.MethodGet, server.URL+"/v2/test-uc-addon/tags/list"))
+
+	// Assert
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestMirrorListsOnlyServedRepositories(t *testing.T) {
+	// Arrange
+	server := createMirrorTestServer(t, newUpstreamFake())
+	cache := registry.NewBlobCache(t.TempDir(), 1, time.Millisecond)
+	isNothingServed := func(repository string) bool { return false }
+	unserved := httptest.NewTLSServer(mirror.NewMirror(newUpstreamFake(), cache, t.TempDir(), isNothingServed, testToken))
+	defer unserved.Close()
+
+	// Act
+	served, servedErr := server.Client().Do(newMirrorRequest(http.MethodGet, server.URL+"/v2/_catalog"))
+	none, noneErr := unserved.Client().Do(newMirrorRequest(http.MethodGet, unserved.URL+"/v2/_catalog"))
+
+	// Assert
+	assert.NoError(t, servedErr)
+	assert.NoError(t, noneErr)
+	defer served.Body.Close()
+	defer none.Body.Close()
+	servedBody, _ := io.ReadAll(served.Body)
+	noneBody, _ := io.ReadAll(none.Body)
+	assert.JSONEq(t, `{"repositories":["test-uc-addon"]}`, string(servedBody))
+	assert.JSONEq(t, `{"repositories":[]}`, string(noneBody))
+}
+
+func TestMirrorIsNotServedWithoutClientAuthentication(t *testing.T) {
+	// Arrange
+	cache := registry.NewBlobCache(t.TempDir(), 1, time.Millisecond)
+	uut := mirror.NewMirror(newUpstreamFake(), cache, t.TempDir(), isTestRepository, "")
+	root := t.TempDir()
+
+	// Act
+	err := uut.ListenAndServeTLS("127.0.0.1:0", filepath.Join(root, "tls.crt"), filepath.Join(root, "tls.key"), filepath.Join(root, "client-ca.pem"))
+
+	// Assert
+	assert.ErrorIs(t, err, mirror.ErrNoClientAuthentication)
+}
+
+func TestReadToken(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(path, []byte("device-token\n"), 0600)
+
+	// Act
+	token, err := mirror.ReadToken(os.ReadFile, path)
+	missing, missingErr := mirror.ReadToken(os.ReadFile, path+".missing")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, missingErr)
+	assert.Equal(t, "device-token", token)
+	assert.Empty(t, missing)
+}
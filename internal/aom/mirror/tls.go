@@ -0,0 +1,94 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package mirror
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"u-control/uc-aom/internal/aom/registry"
+	"u-control/uc-aom/internal/aom/utils"
+	sharedRegistry "u-control/uc-aom/internal/pkg/registry"
+)
+
+// Username the device token is presented with, the mirror only checks the token.
+const tokenUsername = "uc-aom"
+
+// Returned if the mirror is neither able to authenticate its clients by a device token nor by a client certificate.
+var ErrNoClientAuthentication = errors.New("The mirror requires a device token or client certificate authorities")
+
+// Reads the device token from the file at path, the token is empty if the file does not exist.
+func ReadToken(readFile utils.ReadFileFunc, path string) (string, error) {
+	content, err := readFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// Serves the mirror with TLS on address.
+// Clients authenticate with the device token or with a client certificate issued by the authorities of clientCAPath,
+// which is ignored if the file does not exist.
+func (m *Mirror) ListenAndServeTLS(address string, certPath string, keyPath string, clientCAPath string) error {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	bundle, err := os.ReadFile(clientCAPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("The client CA bundle '%s' does not contain a PEM encoded certificate", clientCAPath)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	if m.token == "" && tlsConfig.ClientCAs == nil {
+		return ErrNoClientAuthentication
+	}
+
+	server := &http.Server{Addr: address, Handler: m, TLSConfig: tlsConfig}
+	return server.ListenAndServeTLS(certPath, keyPath)
+}
+
+// Returns whether the request is sent with TLS by a client
+// which presents a verified client certificate or the device token.
+func (m *Mirror) isAuthorized(r *http.Request) bool {
+	if r.TLS == nil {
+		return false
+	}
+	if len(r.TLS.VerifiedChains) > 0 {
+		return true
+	}
+	if m.token == "" {
+		return false
+	}
+	_, password, ok := r.BasicAuth()
+	return ok && subtle.ConstantTimeCompare([]byte(password), []byte(m.token)) == 1
+}
+
+// Returns the credentials of the mirror at address, which present the device token.
+// The username is set without a token as well, so that the mirror is reached with TLS.
+func ClientCredentials(address string, token string) *registry.Credentials {
+	return &registry.Credentials{ServerAddress: address, Username: tokenUsername, Password: token}
+}
+
+// Returns the options extended by the client certificate of the device, unless the certificate does not exist.
+func ClientTransportOptions(options sharedRegistry.TransportOptions, certPath string, keyPath string) sharedRegistry.TransportOptions {
+	if _, err := os.Stat(certPath); err == nil {
+		options.ClientCertPath = certPath
+		options.ClientKeyPath = keyPath
+	}
+	return options
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package mirror
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Returned if no upstream registry provides the requested content.
+var ErrNotFound = errors.New("Not found in the upstream registries")
+
+// Upstream provides the add-on packages the mirror pulls through.
+type Upstream interface {
+	// Returns the repositories of all upstream registries, each repository only once.
+	Repositories(ctx context.Context) ([]string, error)
+
+	// Returns the tags of the repository.
+	Tags(ctx context.Context, repository string) ([]string, error)
+
+	// Returns the manifest of the repository identified by reference, a tag or a digest.
+	FetchManifest(ctx context.Context, repository string, reference string) (ocispec.Descriptor, io.ReadCloser, error)
+
+	// Returns the descriptor of the blob of the repository and the fetcher of its content.
+	ResolveBlob(ctx context.Context, repository string, dgst digest.Digest) (ocispec.Descriptor, content.Fetcher, error)
+}
+
+// Returns the registries which may store the repository, in the order they are tried.
+// Returns all registries if the repository is empty.
+type RegistriesFunc func(repository string) []registry.Registry
+
+type registryUpstream struct {
+	registriesOf RegistriesFunc
+}
+
+// NewRegistryUpstream pulls through the registries, the first registry which provides the content wins.
+func NewRegistryUpstream(registriesOf RegistriesFunc) Upstream {
+	return &registryUpstream{registriesOf: registriesOf}
+}
+
+func (u *registryUpstream) Repositories(ctx context.Context) ([]string, error) {
+	registries := u.registriesOf("")
+	unique := make(map[string]bool)
+	lastError := ErrNotFound
+	succeeded := false
+	for _, upstream := range registries {
+		err := upstream.Repositories(ctx, "", func(repositories []string) error {
+			for _, repository := range repositories {
+				unique[repository] = true
+			}
+			return nil
+		})
+		if err != nil {
+			log.Debugf("Mirror: unable to list the repositories of an upstream registry: %v", err)
+			lastError = err
+			continue
+		}
+		succeeded = true
+	}
+	if !succeeded {
+		return nil, lastError
+	}
+
+	repositories := make([]string, 0, len(unique))
+	for repository := range unique {
+		repositories = append(repositories, repository)
+	}
+	sort.Strings(repositories)
+	return repositories, nil
+}
+
+func (u *registryUpstream) Tags(ctx context.Context, repository string) ([]string, error) {
+	var tags []string
+	err := u.firstOf(ctx, repository, func(target registry.Repository) (err error) {
+		tags, err = registry.Tags(ctx, target)
+		return err
+	})
+	return tags, err
+}
+
+func (u *registryUpstream) FetchManifest(ctx context.Context, repository string, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
+	var desc ocispec.Descriptor
+	var reader io.ReadCloser
+	err := u.firstOf(ctx, repository, func(target registry.Repository) (err error) {
+		desc, reader, err = target.Manifests().FetchReference(ctx, reference)
+		return err
+	})
+	return desc, reader, err
+}
+
+func (u *registryUpstream) ResolveBlob(ctx context.Context, repository string, dgst digest.Digest) (ocispec.Descriptor, content.Fetcher, error) {
+	var desc ocispec.Descriptor
+	var fetcher content.Fetcher
+	err := u.firstOf(ctx, repository, func(target registry.Repository) (err error) {
+		desc, err = target.Blobs().Resolve(ctx, dgst.String())
+		fetcher = target.Blobs()
+		return err
+	})
+	return desc, fetcher, err
+}
+
+// Calls the operation with the repository of the upstream registries until it succeeds.
+func (u *registryUpstream) firstOf(ctx context.Context, repository string, operation func(target registry.Repository) error) error {
+	lastError := ErrNotFound
+	for _, upstream := range u.registriesOf(repository) {
+		target, err := upstream.Repository(ctx, repository)
+		if err == nil {
+			err = operation(target)
+		}
+		if err == nil {
+			return nil
+		}
+		log.Debugf("Mirror: upstream registry failed for repository '%s': %v", repository, err)
+		lastError = err
+	}
+	return lastError
+}
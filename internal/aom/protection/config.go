@@ -0,0 +1,11 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package protection
+
+import "u-control/uc-aom/internal/pkg/utils"
+
+var (
+	PROTECTED_ADDONS_PATH = utils.GetEnv("PROTECTED_ADDONS_PATH", "/usr/share/uc-aom/protected-addons.json")
+)
@@ -0,0 +1,138 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package protection
+
+import (
+	"u-control/uc-aom/internal/aom/utils"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Operation that can be performed on an installed add-on.
+type Operation string
+
+const (
+	Update    Operation = "update"
+	Configure Operation = "configure"
+	Delete    Operation = "delete"
+	Stop      Operation = "stop"
+)
+
+// All operations that can be restricted for a protected add-on.
+var AllOperations = []Operation{Update, Configure, Delete, Stop}
+
+// Entry of the device-side allow-list.
+// Either Name or Pattern identifies the add-ons the entry applies to.
+type Entry struct {
+	utils.AddOnSelector
+	AllowedOperations []Operation `json:"allowedOperations,omitempty"` // operations that are permitted on the add-on
+	TrustManifest     bool        `json:"trustManifest,omitempty"`     // honour the protection declared in the manifest of the add-on
+}
+
+// Policy decides which operations are permitted on an installed add-on.
+// The protection declared in a manifest is only honoured for add-ons of an entry which trusts the manifest,
+// otherwise any add-on could make itself undeletable.
+type Policy struct {
+	entries []*Entry
+}
+
+// Creates a new Policy with the given device-side entries.
+func NewPolicy(entries ...*Entry) (*Policy, error) {
+	for _, entry := range entries {
+		if err := entry.compile(); err != nil {
+			return nil, err
+		}
+	}
+	return &Policy{entries: entries}, nil
+}
+
+// Reads the device-side allow-list from path.
+// A missing file results in a policy without device-side entries.
+func LoadPolicy(readFile utils.ReadFileFunc, path string) (*Policy, error) {
+	entries := []*Entry{}
+	found, err := utils.ReadJSONFile(readFile, path, "protected add-ons file", &entries)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		log.Debugf("No protected add-ons file at '%s'", path)
+	}
+	return NewPolicy(entries...)
+}
+
+// Returns the operations that are permitted on the add-on with the given name and manifest.
+func (p *Policy) AllowedOperations(name string, addOnManifest *manifest.Root) []Operation {
+	entry := p.find(name)
+	if entry == nil {
+		return AllOperations
+	}
+
+	if entry.TrustManifest && addOnManifest != nil && addOnManifest.Protection != nil {
+		return knownOperations(addOnManifest.Protection.AllowedOperations)
+	}
+
+	if entry.TrustManifest && entry.AllowedOperations == nil {
+		return AllOperations
+	}
+
+	return entry.AllowedOperations
+}
+
+// Returns true if the operation is permitted on the add-on with the given name and manifest.
+func (p *Policy) IsAllowed(name string, addOnManifest *manifest.Root, operation Operation) bool {
+	for _, allowed := range p.AllowedOperations(name, addOnManifest) {
+		if allowed == operation {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) find(name string) *Entry {
+	for _, entry := range p.entries {
+		if entry.Matches(name) {
+			return entry
+		}
+	}
+	return nil
+}
+
+func (e *Entry) compile() error {
+	if err := e.Compile("Protected add-on entry"); err != nil {
+		return err
+	}
+
+	if e.AllowedOperations != nil {
+		operations := make([]string, 0, len(e.AllowedOperations))
+		for _, operation := range e.AllowedOperations {
+			operations = append(operations, string(operation))
+		}
+		e.AllowedOperations = knownOperations(operations)
+	}
+	return nil
+}
+
+// Returns the known operations of the list, operations which cannot be restricted are ignored.
+func knownOperations(operations []string) []Operation {
+	known := make([]Operation, 0, len(operations))
+	for _, operation := range operations {
+		if !isKnownOperation(Operation(operation)) {
+			log.Debugf("Ignoring unknown protected operation '%s'", operation)
+			continue
+		}
+		known = append(known, Operation(operation))
+	}
+	return known
+}
+
+func isKnownOperation(operation Operation) bool {
+	for _, known := range AllOperations {
+		if known == operation {
+			return true
+		}
+	}
+	return false
+}
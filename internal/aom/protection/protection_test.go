@@ -0,0 +1,95 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package protection_test
+
+import (
+	"io/fs"
+	"testing"
+	"u-control/uc-aom/internal/aom/protection"
+	"u-control/uc-aom/internal/aom/utils"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return []byte(`[{ "pattern": "(?i)\\bcodesys\\b", "allowedOperations": ["stop", "update", "restart"] }]`), nil
+	}
+
+	// Act
+	policy, err := protection.LoadPolicy(readFile, "")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []protection.Operation{protection.Stop, protection.Update}, policy.AllowedOperations("test-uc-addon-codesys-pkg", nil))
+	assert.Equal(t, protection.AllOperations, policy.AllowedOperations("test-uc-addon-pkg", nil))
+}
+
+func TestLoadPolicyMissingFile(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return nil, fs.ErrNotExist
+	}
+
+	// Act
+	policy, err := protection.LoadPolicy(readFile, "")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, policy.IsAllowed("test-uc-addon-codesys-pkg", nil, protection.Delete))
+}
+
+func TestLoadPolicyInvalidEntry(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return []byte(`[{ "allowedOperations": ["update"] }]`), nil
+	}
+
+	// Act
+	_, err := protection.LoadPolicy(readFile, "")
+
+	// Assert
+	assert.NotNil(t, err)
+}
+
+func TestAllowedOperationsFromTrustedManifest(t *testing.T) {
+	// Arrange
+	policy, _ := protection.NewPolicy(&protection.Entry{AddOnSelector: utils.AddOnSelector{Name: "test-uc-addon-pkg"}, TrustManifest: true})
+	addOnManifest := &manifest.Root{Protection: &manifest.Protection{AllowedOperations: []string{"update", "stop", "restart"}}}
+
+	// Act
+	allowed := policy.AllowedOperations("test-uc-addon-pkg", addOnManifest)
+	unprotected := policy.AllowedOperations("test-uc-addon-pkg", &manifest.Root{})
+
+	// Assert
+	assert.Equal(t, []protection.Operation{protection.Update, protection.Stop}, allowed)
+	assert.Equal(t, protection.AllOperations, unprotected)
+}
+
+func TestIgnoresProtectionOfUntrustedManifest(t *testing.T) {
+	// Arrange
+	policy, _ := protection.NewPolicy()
+	addOnManifest := &manifest.Root{Protection: &manifest.Protection{AllowedOperations: []string{"update"}}}
+
+	// Act
+	isDeleteAllowed := policy.IsAllowed("test-uc-addon-pkg", addOnManifest, protection.Delete)
+
+	// Assert
+	assert.True(t, isDeleteAllowed)
+}
+
+func TestDeviceEntryTakesPrecedenceOverManifest(t *testing.T) {
+	// Arrange
+	policy, _ := protection.NewPolicy(&protection.Entry{AddOnSelector: utils.AddOnSelector{Name: "test-uc-addon-pkg"}, AllowedOperations: []protection.Operation{protection.Configure}})
+	addOnManifest := &manifest.Root{Protection: &manifest.Protection{AllowedOperations: []string{"update"}}}
+
+	// Act
+	allowed := policy.AllowedOperations("test-uc-addon-pkg", addOnManifest)
+
+	// Assert
+	assert.Equal(t, []protection.Operation{protection.Configure}, allowed)
+}
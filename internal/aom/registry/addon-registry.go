@@ -4,18 +4,33 @@
 
 package registry
 
-// Define the registry interface that stores the information about add-ons
+import (
+	"context"
+	"u-control/uc-aom/internal/pkg/manifest"
+)
+
+// Define the registry interface that stores the information about add-ons.
+// The requests to the registry are canceled when the context is done.
 type AddOnRegistry interface {
 	// Return all names of add-on repositories
-	Repositories() ([]string, error)
+	Repositories(ctx context.Context) ([]string, error)
 
 	// Return all tags/versions of the provided add-on repository
-	Tags(repository string) ([]string, error)
+	Tags(ctx context.Context, repository string) ([]string, error)
+
+	// Return the digest of the manifest the tag of the add-on repository refers to.
+	// The digest changes whenever the content of the tag changes.
+	Digest(ctx context.Context, repository string, tag string) (string, error)
+
+	// Return the digest of the manifest the tag of the add-on repository refers to
+	// and the summary of the add-on from the annotations of the package, the tag is resolved once for both.
+	// The summary is nil if the package does not provide one.
+	Summary(ctx context.Context, repository string, tag string) (string, *manifest.AddOnSummary, error)
 
 	// Fetch all data associated with the app uniquely identified by the repository and tag.
 	// Returns the estimated install size in bytes and an error which describes the success status.
-	Pull(repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error)
+	Pull(ctx context.Context, repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error)
 
 	// Delete an add-on from the registry
-	Delete(repository string, tag string) error
+	Delete(ctx context.Context, repository string, tag string) error
 }
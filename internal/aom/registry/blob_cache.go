@@ -0,0 +1,288 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/content"
+)
+
+// Suffix of blobs which are not completely downloaded yet
+const partialBlobSuffix = ".partial"
+
+// Upper bound of the delay between two download attempts
+const maxBlobDownloadDelay = 2 * time.Minute
+
+// BlobCache keeps downloaded blobs addressed by their digest.
+// An interrupted download is resumed with a HTTP range request, if the registry supports it,
+// so that a failed install continues the download on its next attempt.
+type BlobCache struct {
+	root     string
+	attempts int
+	delay    time.Duration
+
+	// Keeps the blobs of installed add-ons, e.g. to serve them to other devices
+	retain bool
+
+	// Serializes the download per digest, a lock is removed once it is released by all holders
+	mutex sync.Mutex
+	locks map[digest.Digest]*blobLock
+}
+
+type blobLock struct {
+	sync.Mutex
+	holders int
+}
+
+// NewBlobCache creates a blob cache in root, which retries a failed download up to attempts times.
+// The delay between two attempts starts with delay and doubles after every attempt.
+func NewBlobCache(root string, attempts int, delay time.Duration) *BlobCache {
+	return &BlobCache{root: root, attempts: attempts, delay: delay, locks: make(map[digest.Digest]*blobLock)}
+}
+
+// Keeps the blobs once their add-on is installed, until they are pruned.
+func (c *BlobCache) RetainInstalledBlobs() {
+	c.retain = true
+}
+
+// Returns the cached blob with the digest, fs.ErrNotExist if it is not cached completely.
+func (c *BlobCache) Open(dgst digest.Digest) (*CachedBlob, error) {
+	if err := dgst.Validate(); err != nil {
+		return nil, err
+	}
+
+	unlock := c.lock(dgst)
+	defer unlock()
+	return c.openCachedBlob(c.pathOf(dgst))
+}
+
+// Returns whether the blob of the descriptor is cached completely, so that it does not occupy additional disk space.
+func (c *BlobCache) Contains(desc ocispec.Descriptor) bool {
+	if err := desc.Digest.Validate(); err != nil {
+		return false
+	}
+	info, err := os.Stat(c.pathOf(desc.Digest))
+	return err == nil && info.Size() == desc.Size
+}
+
+// Returns the content of the blob, which is downloaded from the fetcher unless it is cached.
+// The content is verified against the digest of the descriptor before it is returned.
+func (c *BlobCache) Fetch(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (*CachedBlob, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		return nil, err
+	}
+
+	unlock := c.lock(desc.Digest)
+	defer unlock()
+
+	path := c.pathOf(desc.Digest)
+	if info, err := os.Stat(path); err == nil && info.Size() == desc.Size {
+		log.Tracef("BlobCache: %s is cached", desc.Digest)
+		return c.openCachedBlob(path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	delay := c.delay
+	var err error
+	for attempt := 1; attempt <= c.attempts; attempt++ {
+		err = c.download(ctx, fetcher, desc, path)
+		if err == nil {
+			return c.openCachedBlob(path)
+		}
+		log.Warnf("BlobCache: download %d of %s failed: %v", attempt, desc.Digest, err)
+		if attempt == c.attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxBlobDownloadDelay {
+			delay = maxBlobDownloadDelay
+		}
+	}
+	return nil, fmt.Errorf("Unable to download %s: %w", desc.Digest, err)
+}
+
+// Removes the blobs and partial downloads which were not used since maxAge.
+func (c *BlobCache) Prune(maxAge time.Duration) error {
+	deadline := time.Now().Add(-maxAge)
+	err := filepath.WalkDir(c.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(deadline) {
+			log.Tracef("BlobCache: pruning %s", path)
+			return os.Remove(path)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Downloads the missing content of the blob, verifies it and moves it to path.
+func (c *BlobCache) download(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor, path string) error {
+	partialPath := path + partialBlobSuffix
+	partial, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer partial.Close()
+
+	offset, err := partial.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if offset > desc.Size {
+		offset = 0
+	}
+
+	reader, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	offset = resumeAt(reader, offset)
+	if err := partial.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := partial.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if offset > 0 {
+		log.Infof("BlobCache: resuming download of %s at %d of %d bytes", desc.Digest, offset, desc.Size)
+	}
+
+	if _, err := io.Copy(partial, io.LimitReader(reader, desc.Size-offset)); err != nil {
+		return err
+	}
+
+	if err := verifyBlob(partial, desc); err != nil {
+		partial.Close()
+		os.Remove(partialPath)
+		return err
+	}
+	if err := partial.Sync(); err != nil {
+		return err
+	}
+	return os.Rename(partialPath, path)
+}
+
+// Seeks the reader to the offset, if it supports range requests.
+// Returns the offset the download is resumed at, 0 if the download starts from scratch.
+func resumeAt(reader io.Reader, offset int64) int64 {
+	if offset == 0 {
+		return 0
+	}
+
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return 0
+	}
+	if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+		log.Warnf("BlobCache: unable to resume the download: %v", err)
+		return 0
+	}
+	return offset
+}
+
+func verifyBlob(file *os.File, desc ocispec.Descriptor) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	verifier := desc.Digest.Verifier()
+	size, err := io.Copy(verifier, file)
+	if err != nil {
+		return err
+	}
+	if size != desc.Size || !verifier.Verified() {
+		return fmt.Errorf("The content of %s does not match its digest", desc.Digest)
+	}
+	return nil
+}
+
+func (c *BlobCache) pathOf(dgst digest.Digest) string {
+	return filepath.Join(c.root, dgst.Algorithm().String(), dgst.Encoded())
+}
+
+func (c *BlobCache) lock(dgst digest.Digest) func() {
+	c.mutex.Lock()
+	lock, ok := c.locks[dgst]
+	if !ok {
+		lock = &blobLock{}
+		c.locks[dgst] = lock
+	}
+	lock.holders++
+	c.mutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(c.locks, dgst)
+		}
+	}
+}
+
+// Opens the blob at path and marks it as used, so that it is not pruned.
+func (c *BlobCache) openCachedBlob(path string) (*CachedBlob, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		log.Warnf("BlobCache: unable to mark %s as used: %v", path, err)
+	}
+	return &CachedBlob{File: file, retain: c.retain}, nil
+}
+
+// CachedBlob reads a blob of the BlobCache.
+type CachedBlob struct {
+	*os.File
+	retain bool
+}
+
+// Closes the blob and removes it from the cache, once its content is installed.
+// The blob is kept if the cache retains installed blobs.
+func (b *CachedBlob) Remove() error {
+	b.Close()
+	if b.retain {
+		return nil
+	}
+	return os.Remove(b.Name())
+}
@@ -0,0 +1,195 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"u-control/uc-aom/internal/aom/registry"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+var errConnectionReset = errors.New("connection reset by peer")
+
+// Reader which supports range requests like the blob readers of a registry
+type seekableBlobReader struct {
+	*bytes.Reader
+	seekedTo int64
+}
+
+func (r *seekableBlobReader) Seek(offset int64, whence int) (int64, error) {
+	r.seekedTo = offset
+	return r.Reader.Seek(offset, whence)
+}
+
+func (r *seekableBlobReader) Close() error {
+	return nil
+}
+
+// Reader which fails instead of reaching the end of the blob
+type interruptedBlobReader struct {
+	reader io.Reader
+}
+
+func (r *interruptedBlobReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err == io.EOF {
+		return n, errConnectionReset
+	}
+	return n, err
+}
+
+func (r *interruptedBlobReader) Close() error {
+	return nil
+}
+
+type blobFetcherFake struct {
+	readers []io.ReadCloser
+	calls   int
+}
+
+func (f *blobFetcherFake) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	reader := f.readers[f.calls]
+	f.calls++
+	return reader, nil
+}
+
+func newTestBlob(content []byte) ocispec.Descriptor {
+	return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer, Digest: digest.FromBytes(content), Size: int64(len(content))}
+}
+
+func TestBlobCacheResumesInterruptedDownload(t *testing.T) {
+	// Arrange
+	content := bytes.Repeat([]byte("layer"), 1000)
+	desc := newTestBlob(content)
+	resumed := &seekableBlobReader{Reader: bytes.NewReader(content)}
+	fetcher := &blobFetcherFake{readers: []io.ReadCloser{
+		&interruptedBlobReader{reader: bytes.NewReader(content[:1200])},
+		resumed,
+	}}
+	uut := registry.NewBlobCache(t.TempDir(), 2, time.Millisecond)
+
+	// Act
+	blob, err := uut.Fetch(context.Background(), fetcher, desc)
+
+	// Assert
+	assert.NoError(t, err)
+	defer blob.Close()
+	got, _ := io.ReadAll(blob)
+	assert.Equal(t, content, got)
+	assert.Equal(t, int64(1200), resumed.seekedTo)
+	assert.Equal(t, 2, fetcher.calls)
+}
+
+func TestBlobCacheRestartsDownloadWithoutRangeSupport(t *testing.T) {
+	// Arrange
+	content := bytes.Repeat([]byte("layer"), 1000)
+	desc := newTestBlob(content)
+	fetcher := &blobFetcherFake{readers: []io.ReadCloser{
+		&interruptedBlobReader{reader: bytes.NewReader(content[:1200])},
+		io.NopCloser(bytes.NewReader(content)),
+	}}
+	uut := registry.NewBlobCache(t.TempDir(), 2, time.Millisecond)
+
+	// Act
+	blob, err := uut.Fetch(context.Background(), fetcher, desc)
+
+	// Assert
+	assert.NoError(t, err)
+	defer blob.Close()
+	got, _ := io.ReadAll(blob)
+	assert.Equal(t, content, got)
+}
+
+func TestBlobCacheReturnsCachedBlob(t *testing.T) {
+	// Arrange
+	content := []byte("layer")
+	desc := newTestBlob(content)
+	fetcher := &blobFetcherFake{readers: []io.ReadCloser{io.NopCloser(bytes.NewReader(content))}}
+	uut := registry.NewBlobCache(t.TempDir(), 1, time.Millisecond)
+	first, err := uut.Fetch(context.Background(), fetcher, desc)
+	assert.NoError(t, err)
+	first.Close()
+
+	// Act
+	blob, err := uut.Fetch(context.Background(), fetcher, desc)
+
+	// Assert
+	assert.NoError(t, err)
+	got, _ := io.ReadAll(blob)
+	assert.Equal(t, content, got)
+	assert.Equal(t, 1, fetcher.calls)
+	assert.NoError(t, blob.Remove())
+	assert.NoFileExists(t, blob.Name())
+}
+
+func TestBlobCacheContains(t *testing.T) {
+	// Arrange
+	content := []byte("layer")
+	desc := newTestBlob(content)
+	fetcher := &blobFetcherFake{readers: []io.ReadCloser{io.NopCloser(bytes.NewReader(content))}}
+	uut := registry.NewBlobCache(t.TempDir(), 1, time.Millisecond)
+	containedBeforeFetch := uut.Contains(desc)
+	blob, err := uut.Fetch(context.Background(), fetcher, desc)
+	assert.NoError(t, err)
+	blob.Close()
+
+	// Act
+	contained := uut.Contains(desc)
+
+	// Assert
+	assert.False(t, containedBeforeFetch)
+	assert.True(t, contained)
+	assert.False(t, uut.Contains(newTestBlob([]byte("other"))))
+}
+
+func TestBlobCacheRejectsContentNotMatchingDigest(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	desc := newTestBlob([]byte("layer"))
+	fetcher := &blobFetcherFake{readers: []io.ReadCloser{io.NopCloser(bytes.NewReader([]byte("other")))}}
+	uut := registry.NewBlobCache(root, 1, time.Millisecond)
+
+	// Act
+	blob, err := uut.Fetch(context.Background(), fetcher, desc)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, blob)
+	entries, _ := os.ReadDir(filepath.Join(root, "sha256"))
+	assert.Empty(t, entries)
+}
+
+func TestBlobCachePrune(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	outdated := filepath.Join(root, "sha256", "outdated.partial")
+	recent := filepath.Join(root, "sha256", "recent")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(outdated), os.ModePerm))
+	assert.NoError(t, os.WriteFile(outdated, []byte("layer"), 0644))
+	assert.NoError(t, os.WriteFile(recent, []byte("layer"), 0644))
+	lastWeek := time.Now().Add(-7 * 24 * time.Hour)
+	assert.NoError(t, os.Chtimes(outdated, lastWeek, lastWeek))
+	uut := registry.NewBlobCache(root, 1, time.Millisecond)
+
+	// Act
+	err := uut.Prune(24 * time.Hour)
+	missingRootErr := registry.NewBlobCache(filepath.Join(root, "missing"), 1, time.Millisecond).Prune(time.Hour)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, missingRootErr)
+	assert.NoFileExists(t, outdated)
+	assert.FileExists(t, recent)
+}
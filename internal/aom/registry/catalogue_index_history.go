@@ -0,0 +1,67 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"u-control/uc-aom/internal/aom/utils"
+	catalogueindex "u-control/uc-aom/internal/pkg/catalogue-index"
+)
+
+// Returned if a catalogue index was created before the last accepted index of its source,
+// e.g. if an outdated index is replayed to withhold updates.
+var ErrStaleCatalogueIndex = errors.New("The catalogue index is older than the last accepted catalogue index")
+
+// CatalogueIndexHistory remembers the creation time of the last accepted catalogue index per source,
+// so that an index is never replaced by an older one, not even after a restart.
+type CatalogueIndexHistory struct {
+	mutex     sync.Mutex
+	path      string
+	writeFile utils.WriteFileFunc
+	created   map[string]time.Time
+}
+
+// NewCatalogueIndexHistory reads the history at path, the history is empty if the file does not exist.
+func NewCatalogueIndexHistory(readFile utils.ReadFileFunc, writeFile utils.WriteFileFunc, path string) (*CatalogueIndexHistory, error) {
+	h := &CatalogueIndexHistory{path: path, writeFile: writeFile, created: make(map[string]time.Time)}
+
+	if _, err := utils.ReadJSONFile(readFile, path, "catalogue index history", &h.created); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Accepts the index of the source unless it is older than the last accepted index of the source.
+func (h *CatalogueIndexHistory) Accept(source string, index *catalogueindex.Index) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	last, ok := h.created[source]
+	if ok && index.Created.Before(last) {
+		return fmt.Errorf("%w: created %s, last accepted %s", ErrStaleCatalogueIndex, index.Created.Format(time.RFC3339), last.Format(time.RFC3339))
+	}
+	if ok && index.Created.Equal(last) {
+		return nil
+	}
+
+	h.created[source] = index.Created
+	if err := h.save(); err != nil {
+		if ok {
+			h.created[source] = last
+		} else {
+			delete(h.created, source)
+		}
+		return err
+	}
+	return nil
+}
+
+// MUST be called under the lock.
+func (h *CatalogueIndexHistory) save() error {
+	return utils.WriteJSONFile(h.writeFile, h.path, h.created, 0644)
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"u-control/uc-aom/internal/aom/registry"
+	catalogueindex "u-control/uc-aom/internal/pkg/catalogue-index"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogueIndexHistoryRejectsOlderIndex(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "catalogue-index-history.json")
+	created := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	uut, _ := registry.NewCatalogueIndexHistory(os.ReadFile, os.WriteFile, path)
+	uut.Accept("registry:5000/", &catalogueindex.Index{Created: created})
+
+	// Act
+	restarted, restartErr := registry.NewCatalogueIndexHistory(os.ReadFile, os.WriteFile, path)
+	sameErr := restarted.Accept("registry:5000/", &catalogueindex.Index{Created: created})
+	olderErr := restarted.Accept("registry:5000/", &catalogueindex.Index{Created: created.Add(-time.Hour)})
+	otherSourceErr := restarted.Accept("mirror:5000/", &catalogueindex.Index{Created: created.Add(-time.Hour)})
+	newerErr := restarted.Accept("registry:5000/", &catalogueindex.Index{Created: created.Add(time.Hour)})
+
+	// Assert
+	assert.NoError(t, restartErr)
+	assert.NoError(t, sameErr)
+	assert.ErrorIs(t, olderErr, registry.ErrStaleCatalogueIndex)
+	assert.NoError(t, otherSourceErr)
+	assert.NoError(t, newerErr)
+}
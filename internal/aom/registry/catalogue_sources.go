@@ -0,0 +1,188 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"u-control/uc-aom/internal/aom/utils"
+	sharedRegistry "u-control/uc-aom/internal/pkg/registry"
+)
+
+const DefaultCatalogueSourceName = "weidmueller"
+
+// CatalogueSource is a registry which contributes add-ons to the remote catalogue.
+type CatalogueSource struct {
+	// Unique name of the source
+	Name string `json:"name"`
+
+	// Address and credentials of the registry
+	Credentials
+
+	// Sources with a higher priority win if several sources provide the same repository
+	Priority int `json:"priority"`
+
+	// Only repositories starting with the prefix are taken from the source, empty for all
+	RepositoryPrefix string `json:"repositoryPrefix,omitempty"`
+
+	// Disabled sources are kept but do not contribute to the catalogue
+	Enabled bool `json:"enabled"`
+
+	// Proxy of the source, overrides the proxy of all registries if set
+	Proxy string `json:"proxy,omitempty"`
+
+	// Hosts which are not reached through the proxy of the source, with the semantics of NO_PROXY
+	NoProxy string `json:"noProxy,omitempty"`
+
+	// Address of a device in the local network which mirrors the source, it is tried before the registry
+	Mirror string `json:"mirror,omitempty"`
+}
+
+// Represents an invalid catalogue source.
+type InvalidCatalogueSourceError struct {
+	message string
+}
+
+func (e *InvalidCatalogueSourceError) Error() string {
+	return e.message
+}
+
+// Returned if no catalogue source exists with the requested name.
+var ErrCatalogueSourceNotFound = errors.New("Catalogue source not found")
+
+// Returns the transport options of the source, which override the proxy of the given defaults.
+func (s *CatalogueSource) TransportOptions(defaults sharedRegistry.TransportOptions) sharedRegistry.TransportOptions {
+	if s.Proxy != "" {
+		defaults.Proxy = s.Proxy
+		defaults.NoProxy = s.NoProxy
+	}
+	return defaults
+}
+
+// Returns the transport options of the mirror of the source, which is not reached through the proxy of the source.
+func (s *CatalogueSource) MirrorTransportOptions(defaults sharedRegistry.TransportOptions) sharedRegistry.TransportOptions {
+	options := s.TransportOptions(defaults)
+	if options.Proxy == "" {
+		return options
+	}
+	if options.NoProxy == "" {
+		options.NoProxy = s.Mirror
+	} else {
+		options.NoProxy += "," + s.Mirror
+	}
+	return options
+}
+
+// Returns whether the source provides the repository.
+func (s *CatalogueSource) matches(repository string) bool {
+	return strings.HasPrefix(repository, s.RepositoryPrefix)
+}
+
+func validateCatalogueSource(source *CatalogueSource) error {
+	if source.Name == "" {
+		return &InvalidCatalogueSourceError{message: "The catalogue source requires a name"}
+	}
+	if err := validateCredentials(&source.Credentials); err != nil {
+		return &InvalidCatalogueSourceError{message: fmt.Sprintf("Invalid credentials of catalogue source '%s': %v", source.Name, err)}
+	}
+	if source.Proxy != "" {
+		if proxy, err := url.Parse(source.Proxy); err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return &InvalidCatalogueSourceError{message: fmt.Sprintf("Invalid proxy '%s' of catalogue source '%s'", source.Proxy, source.Name)}
+		}
+	}
+	if source.Mirror != "" {
+		if mirror, err := url.Parse("http://" + source.Mirror); err != nil || mirror.Host != source.Mirror {
+			return &InvalidCatalogueSourceError{message: fmt.Sprintf("Invalid mirror '%s' of catalogue source '%s', expected host and port", source.Mirror, source.Name)}
+		}
+	}
+	return nil
+}
+
+// Reads the catalogue sources from the file at path, their passwords and tokens from the credentials.
+// Returns the default sources if the file does not exist. credentials may be nil.
+func LoadCatalogueSources(readFile utils.ReadFileFunc, path string, defaultSources []*CatalogueSource, credentials CredentialProvider) ([]*CatalogueSource, error) {
+	var sources []*CatalogueSource
+	exists, err := utils.ReadJSONFile(readFile, path, "catalogue sources", &sources)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return defaultSources, nil
+	}
+
+	names := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		if credentials != nil {
+			source.restoreSecretsFrom(credentials)
+		}
+		if err := validateCatalogueSource(source); err != nil {
+			return nil, err
+		}
+		if names[source.Name] {
+			return nil, &InvalidCatalogueSourceError{message: fmt.Sprintf("Duplicate catalogue source '%s'", source.Name)}
+		}
+		names[source.Name] = true
+	}
+	return sources, nil
+}
+
+// Writes the catalogue sources without their passwords and tokens to the file at path.
+// The passwords and tokens are stored in the credentials, which keep them encrypted.
+// Sources persisted in plaintext by earlier versions are migrated on their first change.
+func SaveCatalogueSources(writeFile utils.WriteFileFunc, path string, sources []*CatalogueSource, credentials RegistryCredentialManager) error {
+	persisted := make([]CatalogueSource, len(sources))
+	for i, source := range sources {
+		if err := storeSecretsOf(source, credentials); err != nil {
+			return err
+		}
+		persisted[i] = *source
+		persisted[i].Password = ""
+		persisted[i].IdentityToken = ""
+		persisted[i].RegistryToken = ""
+	}
+
+	return utils.WriteJSONFile(writeFile, path, persisted, 0600)
+}
+
+// Stores the password and tokens of the source in the credentials, unless they are stored already.
+func storeSecretsOf(source *CatalogueSource, credentials RegistryCredentialManager) error {
+	if source.IsInsecureServer() {
+		return nil
+	}
+	if stored, ok := credentials.Credentials(source.ServerAddress); ok && *stored == source.Credentials {
+		return nil
+	}
+	return credentials.Set(source.Credentials)
+}
+
+// Takes the password and tokens of the source from the credentials of its server address,
+// unless the source holds them itself or the credentials are of another user.
+func (s *CatalogueSource) restoreSecretsFrom(credentials CredentialProvider) {
+	if s.Password != "" || s.IdentityToken != "" || s.RegistryToken != "" {
+		return
+	}
+	stored, ok := credentials.Credentials(s.ServerAddress)
+	if !ok || stored.Username != s.Username {
+		return
+	}
+	s.Password = stored.Password
+	s.IdentityToken = stored.IdentityToken
+	s.RegistryToken = stored.RegistryToken
+}
+
+// Returns the source created from the credentials of registrycredentials.json.
+func NewDefaultCatalogueSource(credentials *Credentials) *CatalogueSource {
+	return &CatalogueSource{Name: DefaultCatalogueSourceName, Credentials: *credentials, Enabled: true}
+}
+
+// Sorts the sources by priority, highest first. Sources with the same priority keep their order.
+func sortByPriority(sources []*CatalogueSource) {
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Priority > sources[j].Priority
+	})
+}
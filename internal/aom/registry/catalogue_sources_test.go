@@ -0,0 +1,104 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry_test
+
+import (
+	"io/fs"
+	"testing"
+	"u-control/uc-aom/internal/aom/registry"
+	sharedRegistry "u-control/uc-aom/internal/pkg/registry"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCatalogueSourcesDefaultsIfMissing(t *testing.T) {
+	// Arrange
+	defaultSources := []*registry.CatalogueSource{registry.NewDefaultCatalogueSource(&registry.Credentials{ServerAddress: "registry:5000"})}
+	readFile := func(path string) ([]byte, error) { return nil, fs.ErrNotExist }
+
+	// Act
+	sources, err := registry.LoadCatalogueSources(readFile, "", defaultSources, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, defaultSources, sources)
+	assert.Equal(t, registry.DefaultCatalogueSourceName, sources[0].Name)
+	assert.True(t, sources[0].Enabled)
+}
+
+func TestLoadCatalogueSources(t *testing.T) {
+	// Arrange
+	content := `[
+		{"name": "weidmueller", "serveraddress": "registry:5000", "enabled": true},
+		{"name": "company", "serveraddress": "company:5000", "username": "user", "password": "secret", "priority": 10, "repositoryPrefix": "company/", "enabled": true}
+	]`
+	readFile := func(path string) ([]byte, error) { return []byte(content), nil }
+
+	// Act
+	sources, err := registry.LoadCatalogueSources(readFile, "", nil, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, sources, 2)
+	assert.Equal(t, "company", sources[1].Name)
+	assert.Equal(t, "user", sources[1].Username)
+	assert.Equal(t, 10, sources[1].Priority)
+	assert.Equal(t, "company/", sources[1].RepositoryPrefix)
+}
+
+func TestLoadCatalogueSourcesInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"duplicate name":       `[{"name": "a", "serveraddress": "a:5000"}, {"name": "a", "serveraddress": "b:5000"}]`,
+		"missing name":         `[{"serveraddress": "a:5000"}]`,
+		"missing address":      `[{"name": "a"}]`,
+		"username only":        `[{"name": "a", "serveraddress": "a:5000", "username": "user"}]`,
+		"proxy without scheme": `[{"name": "a", "serveraddress": "a:5000", "proxy": "proxy:3128"}]`,
+		"mirror with scheme":   `[{"name": "a", "serveraddress": "a:5000", "mirror": "http://mirror:5000"}]`,
+		"malformed json file":  `{`,
+	}
+
+	for name, content := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			readFile := func(path string) ([]byte, error) { return []byte(content), nil }
+
+			// Act
+			sources, err := registry.LoadCatalogueSources(readFile, "", nil, nil)
+
+			// Assert
+			assert.Error(t, err)
+			assert.Nil(t, sources)
+		})
+	}
+}
+
+func TestCatalogueSourceOverridesProxy(t *testing.T) {
+	// Arrange
+	defaults := sharedRegistry.TransportOptions{Proxy: "http://proxy:3128", NoProxy: "localhost", CABundlePath: "/ca.pem"}
+	withProxy := &registry.CatalogueSource{Proxy: "http://company-proxy:8080", NoProxy: "mirror.local"}
+	withoutProxy := &registry.CatalogueSource{}
+
+	// Act
+	overridden := withProxy.TransportOptions(defaults)
+	inherited := withoutProxy.TransportOptions(defaults)
+
+	// Assert
+	assert.Equal(t, sharedRegistry.TransportOptions{Proxy: "http://company-proxy:8080", NoProxy: "mirror.local", CABundlePath: "/ca.pem"}, overridden)
+	assert.Equal(t, defaults, inherited)
+}
+
+func TestCatalogueSourceMirrorBypassesProxy(t *testing.T) {
+	// Arrange
+	defaults := sharedRegistry.TransportOptions{Proxy: "http://proxy:3128", NoProxy: "localhost", CABundlePath: "/ca.pem"}
+	source := &registry.CatalogueSource{Mirror: "192.168.0.10:5000"}
+
+	// Act
+	options := source.MirrorTransportOptions(defaults)
+	withoutProxy := source.MirrorTransportOptions(sharedRegistry.TransportOptions{})
+
+	// Assert
+	assert.Equal(t, sharedRegistry.TransportOptions{Proxy: "http://proxy:3128", NoProxy: "localhost,192.168.0.10:5000", CABundlePath: "/ca.pem"}, options)
+	assert.Equal(t, sharedRegistry.TransportOptions{}, withoutProxy)
+}
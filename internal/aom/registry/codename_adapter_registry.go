@@ -5,8 +5,12 @@
 package registry
 
 import (
+	"context"
 	"errors"
+	"u-control/uc-aom/internal/pkg/manifest"
 	sharedRegistry "u-control/uc-aom/internal/pkg/registry"
+
+	"oras.land/oras-go/v2/registry"
 )
 
 var repositoryNotFoundError = errors.New("Not Found")
@@ -21,8 +25,8 @@ func NewCodeNameAdapterRegistry(registy AddOnRegistry) AddOnRegistry {
 }
 
 // Returns all normalized repositories known to this ORAS registry.
-func (r *codeNameAdapterRegistry) Repositories() ([]string, error) {
-	respositoriesWithCodeName, err := r.registry.Repositories()
+func (r *codeNameAdapterRegistry) Repositories(ctx context.Context) ([]string, error) {
+	respositoriesWithCodeName, err := r.registry.Repositories(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -35,34 +39,70 @@ func (r *codeNameAdapterRegistry) Repositories() ([]string, error) {
 }
 
 // fetch the repository with code name before calling the registry tags
-func (r *codeNameAdapterRegistry) Tags(repository string) ([]string, error) {
-	repositoryWithCodeName, err := r.getRepositoryWithCodeName(repository)
+func (r *codeNameAdapterRegistry) Tags(ctx context.Context, repository string) ([]string, error) {
+	repositoryWithCodeName, err := r.getRepositoryWithCodeName(ctx, repository)
 	if err != nil {
 		return make([]string, 0), err
 	}
-	return r.registry.Tags(repositoryWithCodeName)
+	return r.registry.Tags(ctx, repositoryWithCodeName)
+}
+
+// fetch the repository with code name before calling the registry digest
+func (r *codeNameAdapterRegistry) Digest(ctx context.Context, repository string, tag string) (string, error) {
+	repositoryWithCodeName, err := r.getRepositoryWithCodeName(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	return r.registry.Digest(ctx, repositoryWithCodeName, tag)
+}
+
+// fetch the repository with code name before calling the registry summary
+func (r *codeNameAdapterRegistry) Summary(ctx context.Context, repository string, tag string) (string, *manifest.AddOnSummary, error) {
+	repositoryWithCodeName, err := r.getRepositoryWithCodeName(ctx, repository)
+	if err != nil {
+		return "", nil, err
+	}
+	return r.registry.Summary(ctx, repositoryWithCodeName, tag)
 }
 
 // fetch the repositoty with code name before calling the registry pull
-func (r *codeNameAdapterRegistry) Pull(repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
-	repositoryWithCodeName, err := r.getRepositoryWithCodeName(repository)
+func (r *codeNameAdapterRegistry) Pull(ctx context.Context, repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
+	repositoryWithCodeName, err := r.getRepositoryWithCodeName(ctx, repository)
 	if err != nil {
 		return uint64(0), err
 	}
-	return r.registry.Pull(repositoryWithCodeName, tag, processor)
+	return r.registry.Pull(ctx, repositoryWithCodeName, tag, processor)
+}
+
+// fetch the repository with code name before resolving the signed digest of the registry
+func (r *codeNameAdapterRegistry) SignedDigest(ctx context.Context, repository string, tag string) (string, error) {
+	resolver, ok := r.registry.(SignedDigestResolver)
+	if !ok {
+		return "", ErrUnsignedTag
+	}
+	repositoryWithCodeName, err := r.getRepositoryWithCodeName(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	return resolver.SignedDigest(ctx, repositoryWithCodeName, tag)
 }
 
 // fetch the repository with code name before calling the registry delete
-func (r *codeNameAdapterRegistry) Delete(repository string, tag string) error {
-	repositoryWithCodeName, err := r.getRepositoryWithCodeName(repository)
+func (r *codeNameAdapterRegistry) Delete(ctx context.Context, repository string, tag string) error {
+	repositoryWithCodeName, err := r.getRepositoryWithCodeName(ctx, repository)
 	if err != nil {
 		return err
 	}
-	return r.registry.Delete(repositoryWithCodeName, tag)
+	return r.registry.Delete(ctx, repositoryWithCodeName, tag)
+}
+
+// Returns the registry of the decorated registry, whose repositories keep their code names.
+func (r *codeNameAdapterRegistry) MirrorRegistry() registry.Registry {
+	return mirrorRegistryOf(r.registry)
 }
 
-func (r *codeNameAdapterRegistry) getRepositoryWithCodeName(name string) (string, error) {
-	respositoriesWithCodeName, err := r.registry.Repositories()
+func (r *codeNameAdapterRegistry) getRepositoryWithCodeName(ctx context.Context, name string) (string, error) {
+	respositoriesWithCodeName, err := r.registry.Repositories(ctx)
 	if err != nil {
 		return "", err
 	}
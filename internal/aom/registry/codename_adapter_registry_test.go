@@ -5,6 +5,7 @@
 package registry_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"u-control/uc-aom/internal/aom/registry"
@@ -46,7 +47,7 @@ func TestRepositories_WithCodeNameRepository(t *testing.T) {
 			mockRegistry.On("Repositories").Return([]string{tc.codeName}, nil)
 
 			// Act
-			got, err := uut.Repositories()
+			got, err := uut.Repositories(context.Background())
 
 			// Assert
 			assert.Nil(t, err)
@@ -98,7 +99,7 @@ func TestTags_WithCodeNameRepository(t *testing.T) {
 			uut := createUutWithRegistry(mockRegistry)
 
 			// Act
-			_, err := uut.Tags(tc.normalizedName)
+			_, err := uut.Tags(context.Background(), tc.normalizedName)
 
 			// Assert
 			assert.Nil(t, err)
@@ -152,7 +153,7 @@ func TestPull_WithCodeNameRepository(t *testing.T) {
 
 			// Act
 			ilp := registry.NewUcImageLayerProcessor(nil)
-			_, err := uut.Pull(tc.normalizedName, tc.versions[0], ilp)
+			_, err := uut.Pull(context.Background(), tc.normalizedName, tc.versions[0], ilp)
 
 			// Assert
 			assert.Nil(t, err)
@@ -200,7 +201,7 @@ func TestDelete_WithCodeNameRepository(t *testing.T) {
 			uut := createUutWithRegistry(mockRegistry)
 
 			// Act
-			err := uut.Delete(tc.normalizedName, tc.version)
+			err := uut.Delete(context.Background(), tc.normalizedName, tc.version)
 
 			// Assert
 			assert.Nil(t, err)
@@ -217,7 +218,7 @@ func TestShallReturnNotFoundIfRepositoryCanNotBeFound(t *testing.T) {
 	uut := createUutWithRegistry(mockRegistry)
 
 	// Act
-	result, err := uut.Tags(repository)
+	result, err := uut.Tags(context.Background(), repository)
 
 	// Assert
 	assert.Empty(t, result)
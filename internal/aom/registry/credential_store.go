@@ -0,0 +1,281 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"u-control/uc-aom/internal/aom/utils"
+
+	dockerconfig "github.com/docker/cli/cli/config"
+)
+
+// Size of the AES-256 key which encrypts the stored credentials
+const credentialsKeySize = 32
+
+// CredentialProvider provides the credentials of registries at runtime.
+type CredentialProvider interface {
+	// Returns the credentials of the server address, false if none are known.
+	Credentials(serverAddress string) (*Credentials, bool)
+}
+
+// RegistryCredentialManager manages the credentials of registries at runtime.
+type RegistryCredentialManager interface {
+	CredentialProvider
+
+	// Returns the stored credentials ordered by server address.
+	List() []Credentials
+
+	// Stores the credentials, replacing the credentials of the same server address.
+	Set(credentials Credentials) error
+
+	// Deletes the stored credentials of the server address.
+	Delete(serverAddress string) error
+}
+
+// Returned if no credentials are stored for the server address.
+var ErrRegistryCredentialsNotFound = errors.New("Registry credentials not found")
+
+// Represents invalid registry credentials.
+type InvalidRegistryCredentialsError struct {
+	message string
+}
+
+func (e *InvalidRegistryCredentialsError) Error() string {
+	return e.message
+}
+
+// CredentialStore keeps the registry credentials encrypted with AES-GCM at path.
+// Credentials which are not stored are looked up in the docker config, if it is given.
+//
+// The key is stored on the same device, readable by root only. The encryption is therefore only an obfuscation
+// which keeps the credentials out of backups and copies of the credentials file which do not include the key,
+// it does not protect the credentials against anyone who can read the files of the device.
+type CredentialStore struct {
+	mutex        sync.RWMutex
+	path         string
+	key          []byte
+	writeFile    utils.WriteFileFunc
+	dockerConfig CredentialProvider
+	credentials  map[string]*Credentials
+}
+
+// NewCredentialStore reads the encrypted credentials at path with the key at keyPath.
+// The key is created if it does not exist. dockerConfig may be nil.
+func NewCredentialStore(readFile utils.ReadFileFunc, writeFile utils.WriteFileFunc, path string, keyPath string, dockerConfig CredentialProvider) (*CredentialStore, error) {
+	key, err := readOrCreateCredentialsKey(readFile, writeFile, keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &CredentialStore{
+		path:         path,
+		key:          key,
+		writeFile:    writeFile,
+		dockerConfig: dockerConfig,
+		credentials:  make(map[string]*Credentials),
+	}
+
+	encrypted, err := readFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := decrypt(key, encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decrypt the registry credentials '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(content, &s.credentials); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *CredentialStore) Credentials(serverAddress string) (*Credentials, bool) {
+	s.mutex.RLock()
+	credentials, ok := s.credentials[serverAddress]
+	s.mutex.RUnlock()
+	if ok {
+		copied := *credentials
+		return &copied, true
+	}
+
+	if s.dockerConfig != nil {
+		return s.dockerConfig.Credentials(serverAddress)
+	}
+	return nil, false
+}
+
+func (s *CredentialStore) List() []Credentials {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := make([]Credentials, 0, len(s.credentials))
+	for _, credentials := range s.credentials {
+		list = append(list, *credentials)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ServerAddress < list[j].ServerAddress
+	})
+	return list
+}
+
+func (s *CredentialStore) Set(credentials Credentials) error {
+	if err := validateCredentials(&credentials); err != nil {
+		return &InvalidRegistryCredentialsError{message: err.Error()}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, existed := s.credentials[credentials.ServerAddress]
+	s.credentials[credentials.ServerAddress] = &credentials
+	if err := s.save(); err != nil {
+		if existed {
+			s.credentials[credentials.ServerAddress] = previous
+		} else {
+			delete(s.credentials, credentials.ServerAddress)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *CredentialStore) Delete(serverAddress string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, ok := s.credentials[serverAddress]
+	if !ok {
+		return ErrRegistryCredentialsNotFound
+	}
+
+	delete(s.credentials, serverAddress)
+	if err := s.save(); err != nil {
+		s.credentials[serverAddress] = previous
+		return err
+	}
+	return nil
+}
+
+// MUST be called under the write lock.
+func (s *CredentialStore) save() error {
+	content, err := json.Marshal(s.credentials)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := encrypt(s.key, content)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileCreatingDirectory(s.writeFile, s.path, encrypted, 0600)
+}
+
+// dockerConfigProvider looks up credentials in the docker config.json,
+// including the configured credential stores and helpers.
+type dockerConfigProvider struct {
+	dir string
+}
+
+// NewDockerConfigProvider provides the credentials of the docker config in dir.
+// The config is read on every lookup, so that a `docker login` takes effect immediately.
+func NewDockerConfigProvider(dir string) CredentialProvider {
+	return &dockerConfigProvider{dir: dir}
+}
+
+func (p *dockerConfigProvider) Credentials(serverAddress string) (*Credentials, bool) {
+	if _, err := os.Stat(filepath.Join(p.dir, dockerconfig.ConfigFileName)); err != nil {
+		return nil, false
+	}
+
+	configFile, err := dockerconfig.Load(p.dir)
+	if err != nil {
+		return nil, false
+	}
+
+	authConfig, err := configFile.GetAuthConfig(serverAddress)
+	if err != nil {
+		return nil, false
+	}
+
+	credentials := &Credentials{
+		ServerAddress: serverAddress,
+		Username:      authConfig.Username,
+		Password:      authConfig.Password,
+		IdentityToken: authConfig.IdentityToken,
+		RegistryToken: authConfig.RegistryToken,
+	}
+	if credentials.IsInsecureServer() {
+		return nil, false
+	}
+	return credentials, true
+}
+
+func readOrCreateCredentialsKey(readFile utils.ReadFileFunc, writeFile utils.WriteFileFunc, path string) ([]byte, error) {
+	key, err := readFile(path)
+	if err == nil {
+		if len(key) != credentialsKeySize {
+			return nil, fmt.Errorf("Invalid registry credentials key '%s'", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	key = make([]byte, credentialsKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, utils.WriteFileCreatingDirectory(writeFile, path, key, 0600)
+}
+
+func encrypt(key []byte, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(key []byte, ciphertext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("Ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"u-control/uc-aom/internal/aom/registry"
+	sharedRegistry "u-control/uc-aom/internal/pkg/registry"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type credentialStoreTestSetup struct {
+	path    string
+	keyPath string
+}
+
+func newCredentialStoreTestSetup(t *testing.T) *credentialStoreTestSetup {
+	root := t.TempDir()
+	return &credentialStoreTestSetup{path: filepath.Join(root, "registry-credentials.enc"), keyPath: filepath.Join(root, "registry-credentials.key")}
+}
+
+func (s *credentialStoreTestSetup) create(t *testing.T, dockerConfig registry.CredentialProvider) *registry.CredentialStore {
+	uut, err := registry.NewCredentialStore(os.ReadFile, os.WriteFile, s.path, s.keyPath, dockerConfig)
+	assert.NoError(t, err)
+	return uut
+}
+
+func TestCredentialStoreEncryptsCredentials(t *testing.T) {
+	// Arrange
+	setup := newCredentialStoreTestSetup(t)
+	uut := setup.create(t, nil)
+
+	// Act
+	err := uut.Set(registry.Credentials{ServerAddress: "registry:5000", Username: "user", Password: "secret"})
+	restarted := setup.create(t, nil)
+	credentials, ok := restarted.Credentials("registry:5000")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "secret", credentials.Password)
+	assert.Len(t, restarted.List(), 1)
+
+	content, _ := os.ReadFile(setup.path)
+	assert.NotContains(t, string(content), "secret")
+	for _, path := range []string{setup.path, setup.keyPath} {
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+}
+
+func TestCredentialStoreRotatesToken(t *testing.T) {
+	// Arrange
+	setup := newCredentialStoreTestSetup(t)
+	uut := setup.create(t, nil)
+	uut.Set(registry.Credentials{ServerAddress: "registry:5000", IdentityToken: "expired"})
+
+	// Act
+	err := uut.Set(registry.Credentials{ServerAddress: "registry:5000", IdentityToken: "refreshed"})
+
+	// Assert
+	assert.NoError(t, err)
+	credentials, _ := uut.Credentials("registry:5000")
+	assert.Equal(t, "refreshed", credentials.IdentityToken)
+	assert.False(t, credentials.IsInsecureServer())
+}
+
+func TestCredentialStoreErrors(t *testing.T) {
+	// Arrange
+	setup := newCredentialStoreTestSetup(t)
+	uut := setup.create(t, nil)
+
+	// Act
+	invalidErr := uut.Set(registry.Credentials{ServerAddress: "registry:5000", Username: "user"})
+	notFoundErr := uut.Delete("registry:5000")
+
+	// Assert
+	assert.IsType(t, &registry.InvalidRegistryCredentialsError{}, invalidErr)
+	assert.ErrorIs(t, notFoundErr, registry.ErrRegistryCredentialsNotFound)
+	assert.NoFileExists(t, setup.path)
+}
+
+func TestCredentialStoreFallsBackToDockerConfig(t *testing.T) {
+	// Arrange
+	dockerConfigDir := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte("docker-user:docker-secret"))
+	config := `{"auths": {"registry:5000": {"auth": "` + auth + `"}}}`
+	os.WriteFile(filepath.Join(dockerConfigDir, "config.json"), []byte(config), 0600)
+	setup := newCredentialStoreTestSetup(t)
+	uut := setup.create(t, registry.NewDockerConfigProvider(dockerConfigDir))
+
+	// Act
+	fromDockerConfig, dockerConfigOk := uut.Credentials("registry:5000")
+	_, unknownOk := uut.Credentials("other:5000")
+	uut.Set(registry.Credentials{ServerAddress: "registry:5000", Username: "user", Password: "secret"})
+	fromStore, _ := uut.Credentials("registry:5000")
+
+	// Assert
+	assert.True(t, dockerConfigOk)
+	assert.Equal(t, "docker-user", fromDockerConfig.Username)
+	assert.Equal(t, "docker-secret", fromDockerConfig.Password)
+	assert.False(t, unknownOk)
+	assert.Equal(t, "user", fromStore.Username)
+}
+
+func TestInitializeRegistryUsesRotatedCredentials(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "rotated" {
+			w.Header().Set("Www-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	serverAddress := strings.TrimPrefix(server.URL, "http://")
+
+	setup := newCredentialStoreTestSetup(t)
+	store := setup.create(t, nil)
+	store.Set(registry.Credentials{ServerAddress: serverAddress, Username: "user", Password: "expired"})
+	reg, err := registry.InitializeRegistry(&registry.Credentials{ServerAddress: serverAddress}, store, sharedRegistry.TransportOptions{})
+	assert.NoError(t, err)
+	reg.PlainHTTP = true
+
+	// Act
+	expiredErr := reg.Ping(context.Background())
+	store.Set(registry.Credentials{ServerAddress: serverAddress, Username: "user", Password: "rotated"})
+	rotatedErr := reg.Ping(context.Background())
+
+	// Assert
+	assert.Error(t, expiredErr)
+	assert.NoError(t, rotatedErr)
+}
+
+func TestInitializeRegistryDecidesSchemePerRequest(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	serverAddress := strings.TrimPrefix(server.URL, "http://")
+
+	setup := newCredentialStoreTestSetup(t)
+	store := setup.create(t, nil)
+	reg, err := registry.InitializeRegistry(&registry.Credentials{ServerAddress: serverAddress}, store, sharedRegistry.TransportOptions{})
+	assert.NoError(t, err)
+
+	// Act
+	anonymousErr := reg.Ping(context.Background())
+	store.Set(registry.Credentials{ServerAddress: serverAddress, Username: "user", Password: "secret"})
+	authenticatedErr := reg.Ping(context.Background())
+
+	// Assert
+	assert.NoError(t, anonymousErr)
+	assert.Error(t, authenticatedErr, "credentials are only sent with TLS")
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
 )
 
 // Reads the contents of the file at path and returns the result or an error.
@@ -20,6 +22,12 @@ type Credentials struct {
 	Username      string `json:"username,omitempty"`
 	Password      string `json:"password,omitempty"`
 	ServerAddress string `json:"serveraddress,omitempty"`
+
+	// OAuth2 refresh token which is exchanged for access tokens, replaces username and password
+	IdentityToken string `json:"identitytoken,omitempty"`
+
+	// Bearer token which is sent to the registry as is
+	RegistryToken string `json:"registrytoken,omitempty"`
 }
 
 // Functions checks if credentials for a custom/dev registry were provided.
@@ -79,9 +87,18 @@ func convertCredentials(content []byte) (*Credentials, error) {
 	return &credentials, nil
 }
 
-// Checks if username and password are set.
+// Checks if neither username and password nor a token are set.
 func (c *Credentials) IsInsecureServer() bool {
-	return len(c.Username) == 0 && len(c.Password) == 0
+	return len(c.Username) == 0 && len(c.Password) == 0 && len(c.IdentityToken) == 0 && len(c.RegistryToken) == 0
+}
+
+func (c *Credentials) toAuthCredential() auth.Credential {
+	return auth.Credential{
+		Username:     c.Username,
+		Password:     c.Password,
+		RefreshToken: c.IdentityToken,
+		AccessToken:  c.RegistryToken,
+	}
 }
 
 func hasOverwriteCredentialsAt(path string) bool {
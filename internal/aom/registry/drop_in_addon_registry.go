@@ -6,6 +6,7 @@ package registry
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -15,7 +16,9 @@ import (
 	"path/filepath"
 	aom_manifest "u-control/uc-aom/internal/aom/manifest"
 	"u-control/uc-aom/internal/pkg/config"
+	"u-control/uc-aom/internal/pkg/manifest"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	log "github.com/sirupsen/logrus"
 )
@@ -46,7 +49,7 @@ func NewDropInAddOnRegistry(root string, architecture string, os string, decompr
 // Read the artifacts from the drop in registry
 // Artifacts can be filtered by mediatype via predicate
 // Action is called on the artifacts that pass the filter
-func (r *DropInAddOnRegistry) Pull(repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
+func (r *DropInAddOnRegistry) Pull(ctx context.Context, repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
 	log.Tracef("DropInAddOnRegistry.Pull('%s', '%s')", repository, tag)
 	basePathAddOn := path.Join(r.root, repository, tag)
 	imageManifestPaths, err := findFilesWith(basePathAddOn, config.UcImageManifestDescriptorFilename)
@@ -96,7 +99,7 @@ func (r *DropInAddOnRegistry) Pull(repository string, tag string, processor Imag
 }
 
 // Delete an add-on repository from the drop-in registry
-func (r *DropInAddOnRegistry) Delete(repository string, tag string) error {
+func (r *DropInAddOnRegistry) Delete(ctx context.Context, repository string, tag string) error {
 	log.Tracef("DropInAddOnRegistry.Delete('%s', '%s')", repository, tag)
 	if repository == "" || tag == "" {
 		// fail silently
@@ -108,18 +111,50 @@ func (r *DropInAddOnRegistry) Delete(repository string, tag string) error {
 }
 
 // Returns the repositories in the drop-in registry
-func (r *DropInAddOnRegistry) Repositories() ([]string, error) {
+func (r *DropInAddOnRegistry) Repositories(ctx context.Context) ([]string, error) {
 	log.Tracef("DropInAddOnRegistry.Repositories()")
 	return getDirectoriesIn(r.root)
 }
 
 // Returns the tags from the repository
-func (r *DropInAddOnRegistry) Tags(repository string) ([]string, error) {
+func (r *DropInAddOnRegistry) Tags(ctx context.Context, repository string) ([]string, error) {
 	log.Tracef("DropInAddOnRegistry.Tags('%s')", repository)
 	path := path.Join(r.root, repository)
 	return getDirectoriesIn(path)
 }
 
+// Returns the digest of the first image manifest of the tag which can be pulled
+func (r *DropInAddOnRegistry) Digest(ctx context.Context, repository string, tag string) (string, error) {
+	log.Tracef("DropInAddOnRegistry.Digest('%s', '%s')", repository, tag)
+	basePathAddOn := path.Join(r.root, repository, tag)
+	imageManifestPaths, err := findFilesWith(basePathAddOn, config.UcImageManifestDescriptorFilename)
+	if err != nil {
+		return "", err
+	}
+
+	var lastError error = fs.ErrNotExist
+	for _, path := range imageManifestPaths {
+		if _, err := r.validateImageManifestAt(path); err != nil {
+			lastError = err
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return digest.FromBytes(content).String(), nil
+	}
+
+	return "", lastError
+}
+
+// Drop-in packages only contain the image manifests, so they never provide a summary
+func (r *DropInAddOnRegistry) Summary(ctx context.Context, repository string, tag string) (string, *manifest.AddOnSummary, error) {
+	digest, err := r.Digest(ctx, repository, tag)
+	return digest, nil, err
+}
+
 func (r *DropInAddOnRegistry) checkIfCanBePulled(config *ocispec.Image) bool {
 	return config.Architecture == r.architecture && config.OS == r.os
 }
@@ -5,6 +5,7 @@
 package registry_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -136,7 +137,7 @@ func TestRepositories(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// Act
-	repositories, err := testDropInRegistry.Repositories()
+	repositories, err := testDropInRegistry.Repositories(context.Background())
 
 	// Assert
 	if err != nil {
@@ -163,7 +164,7 @@ func TestRepositoriesShallOnlyReturnDirectories(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// Act
-	repositories, err := testDropInRegistry.Repositories()
+	repositories, err := testDropInRegistry.Repositories(context.Background())
 
 	// Assert
 	if err != nil {
@@ -192,7 +193,7 @@ func TestRepositoriesWithSubfolder(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// act
-	repositories, err := testDropInRegistry.Repositories()
+	repositories, err := testDropInRegistry.Repositories(context.Background())
 
 	// Assert
 	if err != nil {
@@ -214,7 +215,7 @@ func TestRepositoriesEmptyDirectory(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// Act
-	repositories, err := testDropInRegistry.Repositories()
+	repositories, err := testDropInRegistry.Repositories(context.Background())
 
 	// Assert
 	if err != nil {
@@ -239,7 +240,7 @@ func TestTag(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// act
-	tags, err := testDropInRegistry.Tags(testRepository)
+	tags, err := testDropInRegistry.Tags(context.Background(), testRepository)
 
 	// Assert
 	if err != nil {
@@ -259,7 +260,7 @@ func TestDelete(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// Act
-	err := testDropInRegistry.Delete(testRepository, testTag)
+	err := testDropInRegistry.Delete(context.Background(), testRepository, testTag)
 
 	// Assert
 	if err != nil {
@@ -278,7 +279,7 @@ func TestDeleteEmptyDirectory(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// Act
-	err := testDropInRegistry.Delete("", "")
+	err := testDropInRegistry.Delete(context.Background(), "", "")
 
 	// Assert
 	if err != nil {
@@ -292,7 +293,7 @@ func TestDeleteNotRemoveRoot(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// Act
-	err := testDropInRegistry.Delete("", "")
+	err := testDropInRegistry.Delete(context.Background(), "", "")
 
 	// Assert
 	if err != nil {
@@ -310,7 +311,7 @@ func TestDeleteNotRemoveRootIfRepositoryDoesNotExist(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// Act
-	err := testDropInRegistry.Delete("test", "1.0")
+	err := testDropInRegistry.Delete(context.Background(), "test", "1.0")
 
 	// Assert
 	if err != nil {
@@ -354,7 +355,7 @@ func TestPull(t *testing.T) {
 	decompressor.WithFetchContent([]byte(manifestFileContent))
 
 	// Act
-	_, err := testDropInRegistry.Pull(testRepository, testTag, registry.NewAcceptAllManifestLayerProcessor(action))
+	_, err := testDropInRegistry.Pull(context.Background(), testRepository, testTag, registry.NewAcceptAllManifestLayerProcessor(action))
 
 	// Assert
 	if err != nil {
@@ -386,7 +387,7 @@ func TestPullMissingFolder(t *testing.T) {
 	action := func(src io.Reader, mediaType string) {}
 
 	// Act
-	_, err := testDropInRegistry.Pull(testRepository, testTag, registry.NewAcceptAllManifestLayerProcessor(action))
+	_, err := testDropInRegistry.Pull(context.Background(), testRepository, testTag, registry.NewAcceptAllManifestLayerProcessor(action))
 
 	// Assert
 	if err == nil {
@@ -414,7 +415,7 @@ func TestPullShouldNotCallActionIfPredicateReturnsFalse(t *testing.T) {
 	createImageManifestJson(t, addOnBasePath)
 
 	// Act
-	_, err := testDropInRegistry.Pull(testRepository, testTag, registry.NewAcceptNoneManifestLayerProcessor(action))
+	_, err := testDropInRegistry.Pull(context.Background(), testRepository, testTag, registry.NewAcceptNoneManifestLayerProcessor(action))
 
 	// Assert
 	if err != nil {
@@ -459,7 +460,7 @@ func TestPullShouldNotCallActionForAddOnManifestIfPredicateReturnsFalse(t *testi
 	createImageManifestJson(t, addOnBasePath)
 
 	// Act
-	_, err := testDropInRegistry.Pull(testRepository, testTag, registry.NewAllExceptUcImageLayerProcessor(action))
+	_, err := testDropInRegistry.Pull(context.Background(), testRepository, testTag, registry.NewAllExceptUcImageLayerProcessor(action))
 
 	// Assert
 	if err != nil {
@@ -489,7 +490,7 @@ func TestPullEmptyRepository(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// Act
-	_, err := testDropInRegistry.Pull(testRepository, testTag, registry.NewAcceptNoneManifestLayerProcessor(action))
+	_, err := testDropInRegistry.Pull(context.Background(), testRepository, testTag, registry.NewAcceptNoneManifestLayerProcessor(action))
 
 	// Assert
 	if err == nil {
@@ -524,7 +525,7 @@ func TestPullCantOpenFile(t *testing.T) {
 	}).Return(true)
 	processor.On("Action", mock.Anything, mock.AnythingOfType("string"))
 
-	_, err = testDropInRegistry.Pull(testRepository, testTag, processor)
+	_, err = testDropInRegistry.Pull(context.Background(), testRepository, testTag, processor)
 
 	// Assert
 	if err == nil {
@@ -564,7 +565,7 @@ func TestPullOnlyValidArchitectureAndOS(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// Act
-	_, err := testDropInRegistry.Pull(testRepository, testTag, registry.NewAcceptAllManifestLayerProcessor(action))
+	_, err := testDropInRegistry.Pull(context.Background(), testRepository, testTag, registry.NewAcceptAllManifestLayerProcessor(action))
 
 	// Assert
 	if !errors.Is(err, registry.ErrWrongArchOrOS) {
@@ -591,7 +592,7 @@ func TestPullShallReturnErrorIfConfigIsInvalid(t *testing.T) {
 	testDropInRegistry := createUut(testDir)
 
 	// Act
-	_, err := testDropInRegistry.Pull(testRepository, testTag, registry.NewAcceptAllManifestLayerProcessor(action))
+	_, err := testDropInRegistry.Pull(context.Background(), testRepository, testTag, registry.NewAcceptAllManifestLayerProcessor(action))
 
 	// Assert
 	if !errors.Is(err, registry.ErrReadImageConfig) {
@@ -0,0 +1,419 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"u-control/uc-aom/internal/aom/utils"
+	"u-control/uc-aom/internal/pkg/manifest"
+	sharedRegistry "u-control/uc-aom/internal/pkg/registry"
+
+	log "github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Creates the registry of a catalogue source.
+type NewSourceRegistryFunc func(source *CatalogueSource) (AddOnRegistry, error)
+
+// CatalogueSourceManager manages the sources of a federated catalogue.
+type CatalogueSourceManager interface {
+	// Returns all sources, ordered by priority.
+	Sources() []CatalogueSource
+
+	// Adds the source or replaces the source with the same name.
+	SetSource(source CatalogueSource) error
+
+	// Deletes the source with the name.
+	DeleteSource(name string) error
+
+	// Returns the name of the source the installed add-on was pulled from, empty if unknown.
+	OriginOf(repository string) string
+}
+
+// OriginRecorder is implemented by registries which serve add-ons from several sources.
+type OriginRecorder interface {
+	// Remembers the source which served the last pull of the repository,
+	// so that the repository is pulled from the same source in the future.
+	RecordOrigin(repository string) error
+}
+
+type sourceRegistry struct {
+	source   *CatalogueSource
+	registry AddOnRegistry
+}
+
+// FederatedAddOnRegistry merges the repositories of several catalogue sources.
+// If several sources provide a repository, the source with the highest priority serves it,
+// unless the add-on was installed from another source.
+type FederatedAddOnRegistry struct {
+	mutex       sync.RWMutex
+	sourcesPath string
+	originsPath string
+	readFile    utils.ReadFileFunc
+	writeFile   utils.WriteFileFunc
+	credentials RegistryCredentialManager
+	newRegistry NewSourceRegistryFunc
+
+	// All sources ordered by priority
+	sources []*CatalogueSource
+
+	// Registries of the enabled sources ordered by priority
+	registries []*sourceRegistry
+
+	// Persisted source per installed repository
+	origins map[string]string
+
+	// Source which served the last pull per repository
+	pulledFrom map[string]string
+}
+
+// NewFederatedAddOnRegistry creates the registry of the given sources.
+// The sources are persisted at sourcesPath once they are changed, their passwords and tokens in the credential store.
+// The origins of the installed add-ons are persisted at originsPath.
+func NewFederatedAddOnRegistry(sources []*CatalogueSource, sourcesPath string, originsPath string, readFile utils.ReadFileFunc, writeFile utils.WriteFileFunc, credentials RegistryCredentialManager, newRegistry NewSourceRegistryFunc) (*FederatedAddOnRegistry, error) {
+	r := &FederatedAddOnRegistry{
+		sourcesPath: sourcesPath,
+		originsPath: originsPath,
+		readFile:    readFile,
+		writeFile:   writeFile,
+		credentials: credentials,
+		newRegistry: newRegistry,
+		origins:     make(map[string]string),
+		pulledFrom:  make(map[string]string),
+	}
+
+	if err := r.readOrigins(); err != nil {
+		return nil, err
+	}
+
+	sources = append([]*CatalogueSource{}, sources...)
+	if err := r.applySources(sources); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Returns the repositories of all enabled sources, each repository only once.
+// Returns an error only if no source could be queried.
+func (r *FederatedAddOnRegistry) Repositories(ctx context.Context) ([]string, error) {
+	r.mutex.RLock()
+	registries := r.registries
+	r.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	repositories := make([]string, 0)
+	var lastError error
+	succeeded := false
+	for _, candidate := range registries {
+		names, err := candidate.registry.Repositories(ctx)
+		if err != nil {
+			log.Warnf("Unable to list repositories of catalogue source '%s': %v", candidate.source.Name, err)
+			lastError = err
+			continue
+		}
+		succeeded = true
+
+		for _, name := range names {
+			if seen[name] || !candidate.source.matches(name) {
+				continue
+			}
+			seen[name] = true
+			repositories = append(repositories, name)
+		}
+	}
+
+	if !succeeded && lastError != nil {
+		return make([]string, 0), lastError
+	}
+	return repositories, nil
+}
+
+// Returns the tags of the repository from the first source which provides it.
+func (r *FederatedAddOnRegistry) Tags(ctx context.Context, repository string) ([]string, error) {
+	var tags []string
+	_, err := r.firstOf(repository, func(registry AddOnRegistry) (err error) {
+		tags, err = registry.Tags(ctx, repository)
+		return err
+	})
+	return tags, err
+}
+
+// Returns the digest from the first source which provides the repository.
+func (r *FederatedAddOnRegistry) Digest(ctx context.Context, repository string, tag string) (string, error) {
+	var digest string
+	_, err := r.firstOf(repository, func(registry AddOnRegistry) (err error) {
+		digest, err = registry.Digest(ctx, repository, tag)
+		return err
+	})
+	return digest, err
+}
+
+// Returns the digest and summary from the first source which provides the repository.
+func (r *FederatedAddOnRegistry) Summary(ctx context.Context, repository string, tag string) (string, *manifest.AddOnSummary, error) {
+	var digest string
+	var summary *manifest.AddOnSummary
+	_, err := r.firstOf(repository, func(registry AddOnRegistry) (err error) {
+		digest, summary, err = registry.Summary(ctx, repository, tag)
+		return err
+	})
+	return digest, summary, err
+}
+
+// Pulls from the first source which provides the repository and remembers the source.
+func (r *FederatedAddOnRegistry) Pull(ctx context.Context, repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
+	var size uint64
+	source, err := r.firstOf(repository, func(registry AddOnRegistry) (err error) {
+		size, err = registry.Pull(ctx, repository, tag, processor)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	r.pulledFrom[repository] = source
+	r.mutex.Unlock()
+	return size, nil
+}
+
+func (r *FederatedAddOnRegistry) Delete(ctx context.Context, repository string, tag string) error {
+	_, err := r.firstOf(repository, func(registry AddOnRegistry) error {
+		return registry.Delete(ctx, repository, tag)
+	})
+	return err
+}
+
+// Remembers the source which served the last pull of the repository.
+func (r *FederatedAddOnRegistry) RecordOrigin(repository string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	source, ok := r.pulledFrom[repository]
+	if !ok || r.origins[repository] == source {
+		return nil
+	}
+	r.origins[repository] = source
+	return r.writeOrigins()
+}
+
+// Forgets the origins of all repositories which are not retained, e.g. of uninstalled add-ons.
+func (r *FederatedAddOnRegistry) RetainOrigins(repositories []string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	retained := make(map[string]bool, len(repositories))
+	for _, repository := range repositories {
+		retained[repository] = true
+	}
+
+	changed := false
+	for repository := range r.origins {
+		if !retained[repository] {
+			delete(r.origins, repository)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return r.writeOrigins()
+}
+
+// Returns the OCI registries which may store the repository, in the order they are pulled from.
+// Returns the registries of all enabled sources if the repository is empty.
+func (r *FederatedAddOnRegistry) MirrorRegistries(repository string) []registry.Registry {
+	var candidates []*sourceRegistry
+	if repository == "" {
+		r.mutex.RLock()
+		candidates = r.registries
+		r.mutex.RUnlock()
+	} else {
+		candidates = r.candidatesFor(sharedRegistry.NormalizeCodeName(repository))
+	}
+
+	registries := make([]registry.Registry, 0, len(candidates))
+	for _, candidate := range candidates {
+		if mirrorRegistry := mirrorRegistryOf(candidate.registry); mirrorRegistry != nil {
+			registries = append(registries, mirrorRegistry)
+		}
+	}
+	return registries
+}
+
+func (r *FederatedAddOnRegistry) OriginOf(repository string) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.origins[repository]
+}
+
+func (r *FederatedAddOnRegistry) Sources() []CatalogueSource {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	sources := make([]CatalogueSource, len(r.sources))
+	for i, source := range r.sources {
+		sources[i] = *source
+	}
+	return sources
+}
+
+// Adds or replaces the source and persists all sources.
+// The password of a replaced source is kept if it is omitted and the username did not change.
+func (r *FederatedAddOnRegistry) SetSource(source CatalogueSource) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, existing := range r.sources {
+		if existing.Name == source.Name && source.Password == "" && source.Username == existing.Username {
+			source.Password = existing.Password
+		}
+	}
+	if err := validateCatalogueSource(&source); err != nil {
+		return err
+	}
+
+	sources := make([]*CatalogueSource, 0, len(r.sources)+1)
+	replaced := false
+	for _, existing := range r.sources {
+		if existing.Name != source.Name {
+			sources = append(sources, existing)
+			continue
+		}
+		sources = append(sources, &source)
+		replaced = true
+	}
+	if !replaced {
+		sources = append(sources, &source)
+	}
+	return r.updateSources(sources)
+}
+
+// Deletes the source and persists the remaining sources.
+// Add-ons installed from the source are pulled from the other sources in the future.
+func (r *FederatedAddOnRegistry) DeleteSource(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	sources := make([]*CatalogueSource, 0, len(r.sources))
+	for _, existing := range r.sources {
+		if existing.Name != name {
+			sources = append(sources, existing)
+		}
+	}
+	if len(sources) == len(r.sources) {
+		return ErrCatalogueSourceNotFound
+	}
+	return r.updateSources(sources)
+}
+
+// MUST be called under the write lock.
+func (r *FederatedAddOnRegistry) updateSources(sources []*CatalogueSource) error {
+	previous := r.sources
+	if err := r.applySources(sources); err != nil {
+		return err
+	}
+
+	if err := SaveCatalogueSources(r.writeFile, r.sourcesPath, r.sources, r.credentials); err != nil {
+		if rollbackErr := r.applySources(previous); rollbackErr != nil {
+			return fmt.Errorf("%w, unable to restore the previous catalogue sources: %v", err, rollbackErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Creates the registries of the enabled sources.
+func (r *FederatedAddOnRegistry) applySources(sources []*CatalogueSource) error {
+	sortByPriority(sources)
+
+	registries := make([]*sourceRegistry, 0, len(sources))
+	for _, source := range sources {
+		if !source.Enabled {
+			continue
+		}
+		registry, err := r.newRegistry(source)
+		if err != nil {
+			return &InvalidCatalogueSourceError{message: fmt.Sprintf("Unable to initialize catalogue source '%s': %v", source.Name, err)}
+		}
+		registries = append(registries, &sourceRegistry{source: source, registry: registry})
+	}
+
+	r.sources = sources
+	r.registries = registries
+	return nil
+}
+
+// Calls the operation with the registries which may provide the repository until it succeeds.
+// Returns the name of the source which succeeded.
+// If the repository was installed from a source which is still enabled, only that source is called,
+// so that an add-on is never replaced by the add-on of the same name from another source.
+func (r *FederatedAddOnRegistry) firstOf(repository string, operation func(registry AddOnRegistry) error) (string, error) {
+	candidates := r.candidatesFor(repository)
+	if len(candidates) == 0 {
+		return "", repositoryNotFoundError
+	}
+
+	if origin := r.OriginOf(repository); origin != "" && candidates[0].source.Name == origin {
+		if err := operation(candidates[0].registry); err != nil {
+			return "", fmt.Errorf("Catalogue source '%s' of the installed add-on failed: %w", origin, err)
+		}
+		return origin, nil
+	}
+
+	var lastError error
+	for _, candidate := range candidates {
+		err := operation(candidate.registry)
+		if err == nil {
+			return candidate.source.Name, nil
+		}
+		log.Tracef("Catalogue source '%s' failed for repository '%s': %v", candidate.source.Name, repository, err)
+		lastError = err
+	}
+	return "", lastError
+}
+
+func (r *FederatedAddOnRegistry) candidatesFor(repository string) []*sourceRegistry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	origin := r.origins[repository]
+	candidates := make([]*sourceRegistry, 0, len(r.registries))
+	for _, candidate := range r.registries {
+		if !candidate.source.matches(repository) {
+			continue
+		}
+		if candidate.source.Name == origin {
+			candidates = append([]*sourceRegistry{candidate}, candidates...)
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+func (r *FederatedAddOnRegistry) readOrigins() error {
+	content, err := r.readFile(r.originsPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, &r.origins)
+}
+
+// MUST be called under the write lock.
+func (r *FederatedAddOnRegistry) writeOrigins() error {
+	content, err := json.Marshal(r.origins)
+	if err != nil {
+		return err
+	}
+	return r.writeFile(r.originsPath, content, 0644)
+}
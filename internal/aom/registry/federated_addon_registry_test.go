@@ -0,0 +1,233 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+//go:build dev
+// +build dev
+
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"u-control/uc-aom/internal/aom/registry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type federatedTestSetup struct {
+	sourcesPath string
+	originsPath string
+	credentials *registry.CredentialStore
+	registries  map[string]*registry.MockRegistry
+}
+
+func newFederatedTestSetup(t *testing.T) *federatedTestSetup {
+	root := t.TempDir()
+	credentials, err := registry.NewCredentialStore(os.ReadFile, os.WriteFile, filepath.Join(root, "credentials.enc"), filepath.Join(root, "credentials.key"), nil)
+	assert.NoError(t, err)
+	return &federatedTestSetup{
+		sourcesPath: filepath.Join(root, "catalogue-sources.json"),
+		originsPath: filepath.Join(root, "catalogue-origins.json"),
+		credentials: credentials,
+		registries:  make(map[string]*registry.MockRegistry),
+	}
+}
+
+func (s *federatedTestSetup) newRegistry(source *registry.CatalogueSource) (registry.AddOnRegistry, error) {
+	mockRegistry, ok := s.registries[source.Name]
+	if !ok {
+		mockRegistry = &registry.MockRegistry{}
+		s.registries[source.Name] = mockRegistry
+	}
+	return mockRegistry, nil
+}
+
+func (s *federatedTestSetup) create(t *testing.T, sources ...*registry.CatalogueSource) *registry.FederatedAddOnRegistry {
+	uut, err := registry.NewFederatedAddOnRegistry(sources, s.sourcesPath, s.originsPath, os.ReadFile, os.WriteFile, s.credentials, s.newRegistry)
+	assert.NoError(t, err)
+	return uut
+}
+
+func newSource(name string, priority int, prefix string) *registry.CatalogueSource {
+	return &registry.CatalogueSource{
+		Name:             name,
+		Credentials:      registry.Credentials{ServerAddress: name + ":5000"},
+		Priority:         priority,
+		RepositoryPrefix: prefix,
+		Enabled:          true,
+	}
+}
+
+func TestFederatedRepositoriesMergesSources(t *testing.T) {
+	// Arrange
+	setup := newFederatedTestSetup(t)
+	disabled := newSource("disabled", 100, "")
+	disabled.Enabled = false
+	uut := setup.create(t, newSource("weidmueller", 0, ""), newSource("company", 10, "company/"), disabled)
+	setup.registries["weidmueller"].On("Repositories").Return([]string{"addon-a", "company/addon-b"}, nil)
+	setup.registries["company"].On("Repositories").Return([]string{"company/addon-b", "company/addon-c", "other"}, nil)
+
+	// Act
+	repositories, err := uut.Repositories(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"company/addon-b", "company/addon-c", "addon-a"}, repositories)
+	assert.NotContains(t, setup.registries, "disabled")
+}
+
+func TestFederatedRepositoriesWithUnreachableSource(t *testing.T) {
+	// Arrange
+	setup := newFederatedTestSetup(t)
+	uut := setup.create(t, newSource("weidmueller", 0, ""), newSource("company", 10, ""))
+	setup.registries["weidmueller"].On("Repositories").Return([]string{"addon-a"}, nil)
+	setup.registries["company"].On("Repositories").Return([]string{}, errors.New("NETWORK_ERROR"))
+
+	// Act
+	repositories, err := uut.Repositories(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"addon-a"}, repositories)
+}
+
+func TestFederatedTagsPreferHigherPriority(t *testing.T) {
+	// Arrange
+	setup := newFederatedTestSetup(t)
+	uut := setup.create(t, newSource("weidmueller", 0, ""), newSource("company", 10, ""))
+	setup.registries["company"].On("Tags", "addon-a").Return([]string{}, errors.New("Not Found"))
+	setup.registries["weidmueller"].On("Tags", "addon-a").Return([]string{"1.0.0-1"}, nil)
+
+	// Act
+	tags, err := uut.Tags(context.Background(), "addon-a")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0-1"}, tags)
+	setup.registries["company"].AssertExpectations(t)
+}
+
+func TestFederatedPullPrefersRecordedOrigin(t *testing.T) {
+	// Arrange
+	setup := newFederatedTestSetup(t)
+	sources := []*registry.CatalogueSource{newSource("weidmueller", 0, ""), newSource("company", 10, "")}
+	uut := setup.create(t, sources...)
+	setup.registries["company"].On("Pull", "addon-a", "1.0.0-1", mock.Anything).Return(uint64(0), errors.New("Not Found"))
+	setup.registries["weidmueller"].On("Pull", "addon-a", "1.0.0-1", mock.Anything).Return(uint64(42), nil)
+	setup.registries["weidmueller"].On("Tags", "addon-a").Return([]string{"1.0.0-1", "1.1.0-1"}, nil)
+
+	// Act
+	_, pullErr := uut.Pull(context.Background(), "addon-a", "1.0.0-1", nil)
+	recordErr := uut.RecordOrigin("addon-a")
+	restarted := setup.create(t, sources...)
+	tags, tagsErr := restarted.Tags(context.Background(), "addon-a")
+
+	// Assert
+	assert.NoError(t, pullErr)
+	assert.NoError(t, recordErr)
+	assert.NoError(t, tagsErr)
+	assert.Equal(t, "weidmueller", restarted.OriginOf("addon-a"))
+	assert.Equal(t, []string{"1.0.0-1", "1.1.0-1"}, tags)
+	setup.registries["company"].AssertNotCalled(t, "Tags", "addon-a")
+}
+
+func TestFederatedFailsIfRecordedOriginFails(t *testing.T) {
+	// Arrange
+	setup := newFederatedTestSetup(t)
+	uut := setup.create(t, newSource("weidmueller", 0, ""), newSource("company", 10, ""))
+	setup.registries["company"].On("Pull", "addon-a", "1.0.0-1", mock.Anything).Return(uint64(0), nil)
+	uut.Pull(context.Background(), "addon-a", "1.0.0-1", nil)
+	uut.RecordOrigin("addon-a")
+	setup.registries["company"].On("Tags", "addon-a").Return([]string{}, errors.New("NETWORK_ERROR"))
+	setup.registries["weidmueller"].On("Tags", "addon-a").Return([]string{"2.0.0-1"}, nil)
+
+	// Act
+	tags, err := uut.Tags(context.Background(), "addon-a")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Empty(t, tags)
+	setup.registries["weidmueller"].AssertNotCalled(t, "Tags", "addon-a")
+}
+
+func TestFederatedRetainOrigins(t *testing.T) {
+	// Arrange
+	setup := newFederatedTestSetup(t)
+	uut := setup.create(t, newSource("weidmueller", 0, ""))
+	setup.registries["weidmueller"].On("Pull", mock.Anything, "1.0.0-1", mock.Anything).Return(uint64(0), nil)
+	uut.Pull(context.Background(), "addon-a", "1.0.0-1", nil)
+	uut.RecordOrigin("addon-a")
+	uut.Pull(context.Background(), "addon-b", "1.0.0-1", nil)
+	uut.RecordOrigin("addon-b")
+
+	// Act
+	err := uut.RetainOrigins([]string{"addon-b"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, uut.OriginOf("addon-a"))
+	assert.Equal(t, "weidmueller", uut.OriginOf("addon-b"))
+}
+
+func TestFederatedSetSourceKeepsPassword(t *testing.T) {
+	// Arrange
+	setup := newFederatedTestSetup(t)
+	source := newSource("company", 10, "")
+	source.Username = "user"
+	source.Password = "secret"
+	uut := setup.create(t, newSource("weidmueller", 0, ""), source)
+
+	// Act
+	err := uut.SetSource(registry.CatalogueSource{Name: "company", Credentials: registry.Credentials{ServerAddress: "company:5000", Username: "user"}, Priority: 10})
+
+	// Assert
+	assert.NoError(t, err)
+	persisted, err := registry.LoadCatalogueSources(os.ReadFile, setup.sourcesPath, nil, setup.credentials)
+	assert.NoError(t, err)
+	assert.Len(t, persisted, 2)
+	assert.Equal(t, "company", persisted[0].Name)
+	assert.Equal(t, "secret", persisted[0].Password)
+	assert.False(t, persisted[0].Enabled)
+	info, err := os.Stat(setup.sourcesPath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	content, err := os.ReadFile(setup.sourcesPath)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "secret")
+}
+
+func TestFederatedSetSourceInvalid(t *testing.T) {
+	// Arrange
+	setup := newFederatedTestSetup(t)
+	uut := setup.create(t, newSource("weidmueller", 0, ""))
+
+	// Act
+	err := uut.SetSource(registry.CatalogueSource{Name: "company"})
+
+	// Assert
+	assert.IsType(t, &registry.InvalidCatalogueSourceError{}, err)
+	assert.Len(t, uut.Sources(), 1)
+	assert.NoFileExists(t, setup.sourcesPath)
+}
+
+func TestFederatedDeleteSource(t *testing.T) {
+	// Arrange
+	setup := newFederatedTestSetup(t)
+	uut := setup.create(t, newSource("weidmueller", 0, ""), newSource("company", 10, ""))
+
+	// Act
+	err := uut.DeleteSource("company")
+	notFoundErr := uut.DeleteSource("company")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ErrorIs(t, notFoundErr, registry.ErrCatalogueSourceNotFound)
+	sources := uut.Sources()
+	assert.Len(t, sources, 1)
+	assert.Equal(t, "weidmueller", sources[0].Name)
+}
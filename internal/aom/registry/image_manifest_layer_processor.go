@@ -87,3 +87,18 @@ func NewAcceptNoneManifestLayerProcessor(action ImageManifestLayerAction) *accep
 func (p *acceptNoneManifestLayerProcessor) Filter(desc *ocispec.Descriptor) bool {
 	return false
 }
+
+// Decorates a processor and records the descriptors of all manifest layers passed to Filter.
+type LayerDescriptorRecorder struct {
+	ImageManifestLayerProcessor
+	Descriptors []ocispec.Descriptor
+}
+
+func NewLayerDescriptorRecorder(processor ImageManifestLayerProcessor) *LayerDescriptorRecorder {
+	return &LayerDescriptorRecorder{ImageManifestLayerProcessor: processor}
+}
+
+func (r *LayerDescriptorRecorder) Filter(desc *ocispec.Descriptor) bool {
+	r.Descriptors = append(r.Descriptors, *desc)
+	return r.ImageManifestLayerProcessor.Filter(desc)
+}
@@ -87,3 +87,19 @@ func TestAllExceptUcImageLayerProcessor_Apply(t *testing.T) {
 		})
 	}
 }
+
+func TestLayerDescriptorRecorder_Apply(t *testing.T) {
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewLayerDescriptorRecorder(NewUcImageLayerProcessor(nil))
+
+			if got := p.Filter(tt.args.desc); got != tt.want {
+				t.Errorf("LayerDescriptorRecorder.Apply() = %v, want %v", got, tt.want)
+			}
+
+			if len(p.Descriptors) != 1 || p.Descriptors[0].MediaType != tt.args.desc.MediaType {
+				t.Errorf("LayerDescriptorRecorder.Descriptors = %v, want [%v]", p.Descriptors, *tt.args.desc)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"u-control/uc-aom/internal/aom/utils"
+	catalogueindex "u-control/uc-aom/internal/pkg/catalogue-index"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	log "github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Returns the catalogue index of the registry, nil if no index is published.
+type FetchCatalogueIndexFunc func(ctx context.Context) (*catalogueindex.Index, error)
+
+// Returns an error if the fetched catalogue index must not be used, e.g. because it is older than the last accepted index.
+type AcceptCatalogueIndexFunc func(index *catalogueindex.Index) error
+
+// indexedAddOnRegistry reads the repositories, tags and summaries from the catalogue index
+// published by `uc-aop catalogue publish`, so that the registry is not required to provide its catalog API.
+// Repositories and tags which are not listed by the index are read from the decorated registry.
+// Versions listed by the index are pulled by the digest of the index, so the signed digest is enforced.
+type indexedAddOnRegistry struct {
+	registry     AddOnRegistry
+	fetchIndex   FetchCatalogueIndexFunc
+	acceptIndex  AcceptCatalogueIndexFunc
+	architecture string
+	os           string
+
+	mutex sync.RWMutex
+
+	// Whether the index was loaded at least once
+	loaded bool
+
+	// Last accepted index, nil if no index is published
+	index *catalogueindex.Index
+}
+
+func NewIndexedAddOnRegistry(registry AddOnRegistry, fetchIndex FetchCatalogueIndexFunc, acceptIndex AcceptCatalogueIndexFunc, architecture string, os string) AddOnRegistry {
+	return &indexedAddOnRegistry{registry: registry, fetchIndex: fetchIndex, acceptIndex: acceptIndex, architecture: architecture, os: os}
+}
+
+// Reads the public key which verifies the catalogue indexes.
+// Returns nil if the key does not exist, i.e. catalogue indexes are not used.
+func ReadCatalogueIndexPublicKey(readFile utils.ReadFileFunc, path string) (ed25519.PublicKey, error) {
+	content, err := readFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return catalogueindex.ParsePublicKey(content)
+}
+
+// Returns the repositories of the index which provide a version for this device, the index is reloaded on every call.
+// Falls back to the repositories of the registry if no index is published.
+func (r *indexedAddOnRegistry) Repositories(ctx context.Context) ([]string, error) {
+	index, err := r.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if index == nil {
+		return r.registry.Repositories(ctx)
+	}
+
+	repositories := make([]string, 0, len(index.AddOns))
+	for _, addOn := range index.AddOns {
+		if len(r.supportedVersionsOf(addOn)) > 0 {
+			repositories = append(repositories, addOn.Repository)
+		}
+	}
+	return repositories, nil
+}
+
+func (r *indexedAddOnRegistry) Tags(ctx context.Context, repository string) ([]string, error) {
+	addOn, err := r.find(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	if addOn == nil {
+		return r.registry.Tags(ctx, repository)
+	}
+
+	tags := r.supportedVersionsOf(addOn)
+	if len(tags) == 0 {
+		return nil, errors.New("Invalid AddOn")
+	}
+	return tags, nil
+}
+
+// Returns the digest the tag referred to when the index was published.
+func (r *indexedAddOnRegistry) Digest(ctx context.Context, repository string, tag string) (string, error) {
+	version, err := r.findVersion(ctx, repository, tag)
+	if err != nil {
+		return "", err
+	}
+	if version == nil {
+		return r.registry.Digest(ctx, repository, tag)
+	}
+	return version.Digest, nil
+}
+
+// Returns the digest and summary of the tag when the index was published.
+func (r *indexedAddOnRegistry) Summary(ctx context.Context, repository string, tag string) (string, *manifest.AddOnSummary, error) {
+	version, err := r.findVersion(ctx, repository, tag)
+	if err != nil {
+		return "", nil, err
+	}
+	if version == nil {
+		return r.registry.Summary(ctx, repository, tag)
+	}
+	return version.Digest, version.Summary, nil
+}
+
+// Pulls the digest the index lists for the tag, so that the tag cannot be moved to another package after the index was signed.
+func (r *indexedAddOnRegistry) Pull(ctx context.Context, repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
+	version, err := r.findVersion(ctx, repository, tag)
+	if err != nil {
+		return 0, err
+	}
+	if version == nil {
+		return r.registry.Pull(ctx, repository, tag, processor)
+	}
+	if version.Digest == "" {
+		return 0, fmt.Errorf("The catalogue index lists no digest of AddOn '%s' in version '%s'", repository, tag)
+	}
+	return r.registry.Pull(ctx, repository, version.Digest, processor)
+}
+
+// Returns the digest the signed index lists for the tag, ErrUnsignedTag if it lists none.
+func (r *indexedAddOnRegistry) SignedDigest(ctx context.Context, repository string, tag string) (string, error) {
+	version, err := r.findVersion(ctx, repository, tag)
+	if err != nil {
+		return "", err
+	}
+	if version == nil || version.Digest == "" {
+		return "", ErrUnsignedTag
+	}
+	return version.Digest, nil
+}
+
+func (r *indexedAddOnRegistry) Delete(ctx context.Context, repository string, tag string) error {
+	return r.registry.Delete(ctx, repository, tag)
+}
+
+// Returns the registry of the decorated registry, which also stores the catalogue index.
+func (r *indexedAddOnRegistry) MirrorRegistry() registry.Registry {
+	return mirrorRegistryOf(r.registry)
+}
+
+// Fetches the index and returns it, nil if no index is published.
+// Returns an error if the index is invalid or stale instead of falling back to the registry.
+// If the index cannot be fetched, the last accepted index is kept.
+func (r *indexedAddOnRegistry) loadIndex(ctx context.Context) (*catalogueindex.Index, error) {
+	index, err := r.fetchIndex(ctx)
+	if errors.Is(err, catalogueindex.ErrInvalidSignature) {
+		return nil, err
+	}
+	if err != nil {
+		log.Debugf("Unable to fetch the catalogue index: %v", err)
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.loaded = true
+		return r.index, nil
+	}
+
+	if index != nil {
+		if err := r.acceptIndex(index); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.loaded = true
+	r.index = index
+	return index, nil
+}
+
+// Returns the last accepted index, the index is loaded if it was not loaded yet.
+func (r *indexedAddOnRegistry) currentIndex(ctx context.Context) (*catalogueindex.Index, error) {
+	r.mutex.RLock()
+	loaded, index := r.loaded, r.index
+	r.mutex.RUnlock()
+
+	if loaded {
+		return index, nil
+	}
+	return r.loadIndex(ctx)
+}
+
+// Returns the add-on of the repository, nil if the index does not list it.
+func (r *indexedAddOnRegistry) find(ctx context.Context, repository string) (*catalogueindex.AddOn, error) {
+	index, err := r.currentIndex(ctx)
+	if err != nil || index == nil {
+		return nil, err
+	}
+	return index.Find(repository), nil
+}
+
+// Returns the version of the tag, nil if the index does not list a version for this device.
+func (r *indexedAddOnRegistry) findVersion(ctx context.Context, repository string, tag string) (*catalogueindex.Version, error) {
+	addOn, err := r.find(ctx, repository)
+	if err != nil || addOn == nil {
+		return nil, err
+	}
+
+	version := addOn.Find(tag)
+	if version == nil || !version.Supports(r.os, r.architecture) {
+		return nil, nil
+	}
+	return version, nil
+}
+
+func (r *indexedAddOnRegistry) supportedVersionsOf(addOn *catalogueindex.AddOn) []string {
+	tags := make([]string, 0, len(addOn.Versions))
+	for _, version := range addOn.Versions {
+		if version.Supports(r.os, r.architecture) {
+			tags = append(tags, version.Tag)
+		}
+	}
+	return tags
+}
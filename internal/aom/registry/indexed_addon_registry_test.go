@@ -0,0 +1,197 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+//go:build dev
+// +build dev
+
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"u-control/uc-aom/internal/aom/registry"
+	catalogueindex "u-control/uc-aom/internal/pkg/catalogue-index"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestCatalogueIndex() *catalogueindex.Index {
+	arm := []ocispec.Platform{{OS: "linux", Architecture: "arm"}}
+	return &catalogueindex.Index{
+		SchemaVersion: catalogueindex.SchemaVersion,
+		AddOns: []*catalogueindex.AddOn{
+			{
+				Repository: "addon-a",
+				Versions: []*catalogueindex.Version{
+					{Tag: "1.0.0-1", Digest: "sha256:a1", Platforms: arm},
+					{Tag: "1.1.0-1", Digest: "sha256:a2", Platforms: arm, Summary: &manifest.AddOnSummary{Title: "A", Version: "1.1.0-1"}},
+					{Tag: "2.0.0-1", Digest: "sha256:a3", Platforms: []ocispec.Platform{{OS: "linux", Architecture: "amd64"}}},
+				},
+			},
+			{
+				Repository: "addon-amd64",
+				Versions:   []*catalogueindex.Version{{Tag: "1.0.0-1", Platforms: []ocispec.Platform{{OS: "linux", Architecture: "amd64"}}}},
+			},
+		},
+	}
+}
+
+func fetchTestCatalogueIndex(ctx context.Context) (*catalogueindex.Index, error) {
+	return newTestCatalogueIndex(), nil
+}
+
+func acceptAnyCatalogueIndex(index *catalogueindex.Index) error {
+	return nil
+}
+
+func TestIndexedRegistryReadsIndex(t *testing.T) {
+	// Arrange
+	mockRegistry := &registry.MockRegistry{}
+	uut := registry.NewIndexedAddOnRegistry(mockRegistry, fetchTestCatalogueIndex, acceptAnyCatalogueIndex, "arm", "linux")
+
+	// Act
+	repositories, repositoriesErr := uut.Repositories(context.Background())
+	tags, tagsErr := uut.Tags(context.Background(), "addon-a")
+	digest, digestErr := uut.Digest(context.Background(), "addon-a", "1.1.0-1")
+	summaryDigest, summary, summaryErr := uut.Summary(context.Background(), "addon-a", "1.1.0-1")
+
+	// Assert
+	assert.NoError(t, repositoriesErr)
+	assert.NoError(t, tagsErr)
+	assert.NoError(t, digestErr)
+	assert.NoError(t, summaryErr)
+	assert.Equal(t, []string{"addon-a"}, repositories)
+	assert.Equal(t, []string{"1.0.0-1", "1.1.0-1"}, tags)
+	assert.Equal(t, "sha256:a2", digest)
+	assert.Equal(t, "sha256:a2", summaryDigest)
+	assert.Equal(t, "A", summary.Title)
+	mockRegistry.AssertNotCalled(t, "Repositories")
+}
+
+func TestIndexedRegistryFallsBackToRegistry(t *testing.T) {
+	testCases := map[string]registry.FetchCatalogueIndexFunc{
+		"no index published": func(ctx context.Context) (*catalogueindex.Index, error) { return nil, nil },
+		"network error":      func(ctx context.Context) (*catalogueindex.Index, error) { return nil, errors.New("NETWORK_ERROR") },
+	}
+
+	for name, fetchIndex := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRegistry := &registry.MockRegistry{}
+			mockRegistry.On("Repositories").Return([]string{"addon-b"}, nil)
+			mockRegistry.On("Tags", "addon-b").Return([]string{"1.0.0-1"}, nil)
+			mockRegistry.On("Digest", "addon-b", "1.0.0-1").Return("sha256:b1", nil)
+			uut := registry.NewIndexedAddOnRegistry(mockRegistry, fetchIndex, acceptAnyCatalogueIndex, "arm", "linux")
+
+			// Act
+			repositories, repositoriesErr := uut.Repositories(context.Background())
+			tags, tagsErr := uut.Tags(context.Background(), "addon-b")
+			digest, digestErr := uut.Digest(context.Background(), "addon-b", "1.0.0-1")
+
+			// Assert
+			assert.NoError(t, repositoriesErr)
+			assert.NoError(t, tagsErr)
+			assert.NoError(t, digestErr)
+			assert.Equal(t, []string{"addon-b"}, repositories)
+			assert.Equal(t, []string{"1.0.0-1"}, tags)
+			assert.Equal(t, "sha256:b1", digest)
+		})
+	}
+}
+
+func TestIndexedRegistryReadsUnlistedRepositoryFromRegistry(t *testing.T) {
+	// Arrange
+	mockRegistry := &registry.MockRegistry{}
+	mockRegistry.On("Tags", "addon-c").Return([]string{"1.0.0-1"}, nil)
+	uut := registry.NewIndexedAddOnRegistry(mockRegistry, fetchTestCatalogueIndex, acceptAnyCatalogueIndex, "arm", "linux")
+
+	// Act
+	tags, err := uut.Tags(context.Background(), "addon-c")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0-1"}, tags)
+}
+
+func TestIndexedRegistryRejectsIndex(t *testing.T) {
+	testCases := map[string]struct {
+		fetchIndex  registry.FetchCatalogueIndexFunc
+		acceptIndex registry.AcceptCatalogueIndexFunc
+		wantErr     error
+	}{
+		"invalid signature": {
+			fetchIndex: func(ctx context.Context) (*catalogueindex.Index, error) {
+				return nil, catalogueindex.ErrInvalidSignature
+			},
+			acceptIndex: acceptAnyCatalogueIndex,
+			wantErr:     catalogueindex.ErrInvalidSignature,
+		},
+		"stale index": {
+			fetchIndex:  fetchTestCatalogueIndex,
+			acceptIndex: func(index *catalogueindex.Index) error { return registry.ErrStaleCatalogueIndex },
+			wantErr:     registry.ErrStaleCatalogueIndex,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRegistry := &registry.MockRegistry{}
+			uut := registry.NewIndexedAddOnRegistry(mockRegistry, tc.fetchIndex, tc.acceptIndex, "arm", "linux")
+
+			// Act
+			repositories, repositoriesErr := uut.Repositories(context.Background())
+			_, pullErr := uut.Pull(context.Background(), "addon-a", "1.1.0-1", nil)
+
+			// Assert
+			assert.ErrorIs(t, repositoriesErr, tc.wantErr)
+			assert.ErrorIs(t, pullErr, tc.wantErr)
+			assert.Empty(t, repositories)
+			mockRegistry.AssertNotCalled(t, "Repositories")
+			mockRegistry.AssertNotCalled(t, "Pull", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestIndexedRegistryKeepsLastIndexIfFetchFails(t *testing.T) {
+	// Arrange
+	mockRegistry := &registry.MockRegistry{}
+	fetched := false
+	fetchIndex := func(ctx context.Context) (*catalogueindex.Index, error) {
+		if fetched {
+			return nil, errors.New("NETWORK_ERROR")
+		}
+		fetched = true
+		return newTestCatalogueIndex(), nil
+	}
+	uut := registry.NewIndexedAddOnRegistry(mockRegistry, fetchIndex, acceptAnyCatalogueIndex, "arm", "linux")
+	uut.Repositories(context.Background())
+
+	// Act
+	repositories, err := uut.Repositories(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"addon-a"}, repositories)
+	mockRegistry.AssertNotCalled(t, "Repositories")
+}
+
+func TestIndexedRegistryPullsDigestOfIndex(t *testing.T) {
+	// Arrange
+	mockRegistry := &registry.MockRegistry{}
+	mockRegistry.On("Pull", "addon-a", "sha256:a2", mock.Anything).Return(uint64(42), nil)
+	uut := registry.NewIndexedAddOnRegistry(mockRegistry, fetchTestCatalogueIndex, acceptAnyCatalogueIndex, "arm", "linux")
+
+	// Act
+	size, err := uut.Pull(context.Background(), "addon-a", "1.1.0-1", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), size)
+	mockRegistry.AssertExpectations(t)
+}
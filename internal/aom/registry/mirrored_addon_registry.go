@@ -0,0 +1,146 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/registry"
+)
+
+// MirrorSource is implemented by registries whose add-on packages can be mirrored to other devices.
+type MirrorSource interface {
+	// Returns the OCI registry which stores the add-on packages, nil if there is none.
+	MirrorRegistry() registry.Registry
+}
+
+// SignedDigestResolver is implemented by registries which resolve tags by a signed catalogue index.
+type SignedDigestResolver interface {
+	// Returns the digest the signed catalogue index lists for the tag, ErrUnsignedTag if it lists none.
+	SignedDigest(ctx context.Context, repository string, tag string) (string, error)
+}
+
+// Returned if no signed catalogue index lists the digest of a tag.
+var ErrUnsignedTag = errors.New("No signed catalogue index lists the tag")
+
+// mirroredAddOnRegistry tries a mirror in the local network before the upstream registry,
+// so that devices without internet access are fed by a device which has.
+type mirroredAddOnRegistry struct {
+	mirror   AddOnRegistry
+	upstream AddOnRegistry
+}
+
+func NewMirroredAddOnRegistry(mirror AddOnRegistry, upstream AddOnRegistry) AddOnRegistry {
+	return &mirroredAddOnRegistry{mirror: mirror, upstream: upstream}
+}
+
+func (r *mirroredAddOnRegistry) Repositories(ctx context.Context) ([]string, error) {
+	repositories, err := r.mirror.Repositories(ctx)
+	if err == nil {
+		return repositories, nil
+	}
+	log.Debugf("Mirror failed to list the repositories, using the upstream registry: %v", err)
+	return r.upstream.Repositories(ctx)
+}
+
+func (r *mirroredAddOnRegistry) Tags(ctx context.Context, repository string) ([]string, error) {
+	tags, err := r.mirror.Tags(ctx, repository)
+	if err == nil {
+		return tags, nil
+	}
+	log.Debugf("Mirror failed to list the tags of '%s', using the upstream registry: %v", repository, err)
+	return r.upstream.Tags(ctx, repository)
+}
+
+func (r *mirroredAddOnRegistry) Digest(ctx context.Context, repository string, tag string) (string, error) {
+	digest, err := r.mirror.Digest(ctx, repository, tag)
+	if err == nil {
+		return digest, nil
+	}
+	log.Debugf("Mirror failed to resolve '%s:%s', using the upstream registry: %v", repository, tag, err)
+	return r.upstream.Digest(ctx, repository, tag)
+}
+
+func (r *mirroredAddOnRegistry) Summary(ctx context.Context, repository string, tag string) (string, *manifest.AddOnSummary, error) {
+	digest, summary, err := r.mirror.Summary(ctx, repository, tag)
+	if err == nil {
+		return digest, summary, nil
+	}
+	log.Debugf("Mirror failed to read the summary of '%s:%s', using the upstream registry: %v", repository, tag, err)
+	return r.upstream.Summary(ctx, repository, tag)
+}
+
+// Pulls the package from the mirror by the digest the upstream registry or the signed catalogue index of the mirror
+// resolves the tag to, so that the mirror cannot provide another package.
+// The upstream registry is only used if the mirror fails before it passed a layer to the processor,
+// otherwise the processor would receive the layers twice.
+func (r *mirroredAddOnRegistry) Pull(ctx context.Context, repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
+	digest, err := r.verifiedDigest(ctx, repository, tag)
+	if err != nil {
+		log.Warnf("Unable to verify the package '%s:%s' of the mirror, using the upstream registry: %v", repository, tag, err)
+		return r.upstream.Pull(ctx, repository, tag, processor)
+	}
+
+	tracker := &layerProcessorTracker{ImageManifestLayerProcessor: processor}
+	size, err := r.mirror.Pull(ctx, repository, digest, tracker)
+	if err == nil {
+		return size, nil
+	}
+	if tracker.isUsed {
+		return 0, fmt.Errorf("Mirror failed to pull '%s:%s': %w", repository, tag, err)
+	}
+	log.Warnf("Mirror failed to pull '%s:%s', using the upstream registry: %v", repository, tag, err)
+	return r.upstream.Pull(ctx, repository, tag, processor)
+}
+
+// Returns the digest of the tag from the upstream registry,
+// from the signed catalogue index of the mirror if the upstream registry is unreachable.
+func (r *mirroredAddOnRegistry) verifiedDigest(ctx context.Context, repository string, tag string) (string, error) {
+	digest, err := r.upstream.Digest(ctx, repository, tag)
+	if err == nil {
+		return digest, nil
+	}
+	resolver, ok := r.mirror.(SignedDigestResolver)
+	if !ok {
+		return "", err
+	}
+	signedDigest, signedErr := resolver.SignedDigest(ctx, repository, tag)
+	if signedErr != nil {
+		return "", fmt.Errorf("%w, %v", err, signedErr)
+	}
+	return signedDigest, nil
+}
+
+func (r *mirroredAddOnRegistry) Delete(ctx context.Context, repository string, tag string) error {
+	return r.upstream.Delete(ctx, repository, tag)
+}
+
+// Returns the registry of the upstream, a mirror is not mirrored again.
+func (r *mirroredAddOnRegistry) MirrorRegistry() registry.Registry {
+	return mirrorRegistryOf(r.upstream)
+}
+
+func mirrorRegistryOf(addOnRegistry AddOnRegistry) registry.Registry {
+	if source, ok := addOnRegistry.(MirrorSource); ok {
+		return source.MirrorRegistry()
+	}
+	return nil
+}
+
+// Decorates a processor and records whether a layer was passed to it.
+type layerProcessorTracker struct {
+	ImageManifestLayerProcessor
+	isUsed bool
+}
+
+func (t *layerProcessorTracker) Filter(desc *ocispec.Descriptor) bool {
+	t.isUsed = true
+	return t.ImageManifestLayerProcessor.Filter(desc)
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"u-control/uc-aom/internal/aom/registry"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mirror whose catalogue index lists the digests of the tags
+type signedMirrorMock struct {
+	*registry.MockRegistry
+	signedDigests map[string]string
+}
+
+func (m *signedMirrorMock) SignedDigest(ctx context.Context, repository string, tag string) (string, error) {
+	digest, ok := m.signedDigests[repository+":"+tag]
+	if !ok {
+		return "", registry.ErrUnsignedTag
+	}
+	return digest, nil
+}
+
+func TestMirroredAddOnRegistryPrefersMirror(t *testing.T) {
+	// Arrange
+	mirror := &registry.MockRegistry{}
+	upstream := &registry.MockRegistry{}
+	mirror.On("Tags", "addon").Return([]string{"1.0.0-1"}, nil)
+	mirror.On("Pull", "addon", "sha256:1", mock.Anything).Return(uint64(42), nil)
+	upstream.On("Digest", "addon", "1.0.0-1").Return("sha256:1", nil)
+	uut := registry.NewMirroredAddOnRegistry(mirror, upstream)
+
+	// Act
+	tags, tagsErr := uut.Tags(context.Background(), "addon")
+	size, pullErr := uut.Pull(context.Background(), "addon", "1.0.0-1", nil)
+
+	// Assert
+	assert.NoError(t, tagsErr)
+	assert.NoError(t, pullErr)
+	assert.Equal(t, []string{"1.0.0-1"}, tags)
+	assert.Equal(t, uint64(42), size)
+	upstream.AssertNotCalled(t, "Tags", mock.Anything)
+	upstream.AssertNotCalled(t, "Pull", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMirroredAddOnRegistryFallsBackToUpstream(t *testing.T) {
+	// Arrange
+	unreachable := errors.New("connection refused")
+	mirror := &registry.MockRegistry{}
+	upstream := &registry.MockRegistry{}
+	mirror.On("Repositories").Return([]string{}, unreachable)
+	mirror.On("Digest", "addon", "1.0.0-1").Return("", unreachable)
+	mirror.On("Pull", "addon", "sha256:1", mock.Anything).Return(uint64(0), unreachable)
+	upstream.On("Repositories").Return([]string{"addon"}, nil)
+	upstream.On("Digest", "addon", "1.0.0-1").Return("sha256:1", nil)
+	upstream.On("Pull", "addon", "1.0.0-1", mock.Anything).Return(uint64(42), nil)
+	uut := registry.NewMirroredAddOnRegistry(mirror, upstream)
+
+	// Act
+	repositories, repositoriesErr := uut.Repositories(context.Background())
+	digest, digestErr := uut.Digest(context.Background(), "addon", "1.0.0-1")
+	size, pullErr := uut.Pull(context.Background(), "addon", "1.0.0-1", nil)
+
+	// Assert
+	assert.NoError(t, repositoriesErr)
+	assert.NoError(t, digestErr)
+	assert.NoError(t, pullErr)
+	assert.Equal(t, []string{"addon"}, repositories)
+	assert.Equal(t, "sha256:1", digest)
+	assert.Equal(t, uint64(42), size)
+}
+
+func TestMirroredAddOnRegistryPullsSignedDigestWhileUpstreamIsUnreachable(t *testing.T) {
+	// Arrange
+	unreachable := errors.New("connection refused")
+	mirror := &signedMirrorMock{MockRegistry: &registry.MockRegistry{}, signedDigests: map[string]string{"addon:1.0.0-1": "sha256:1"}}
+	upstream := &registry.MockRegistry{}
+	mirror.On("Pull", "addon", "sha256:1", mock.Anything).Return(uint64(42), nil)
+	upstream.On("Digest", mock.Anything, mock.Anything).Return("", unreachable)
+	upstream.On("Pull", mock.Anything, mock.Anything, mock.Anything).Return(uint64(0), unreachable)
+	uut := registry.NewMirroredAddOnRegistry(mirror, upstream)
+
+	// Act
+	size, signedErr := uut.Pull(context.Background(), "addon", "1.0.0-1", nil)
+	_, unsignedErr := uut.Pull(context.Background(), "addon", "2.0.0-1", nil)
+
+	// Assert
+	assert.NoError(t, signedErr)
+	assert.Equal(t, uint64(42), size)
+	assert.ErrorIs(t, unsignedErr, unreachable)
+	mirror.AssertNumberOfCalls(t, "Pull", 1)
+}
+
+func TestMirroredAddOnRegistryDoesNotPassLayersTwice(t *testing.T) {
+	// Arrange
+	interrupted := errors.New("connection reset by peer")
+	mirror := &registry.MockRegistry{}
+	upstream := &registry.MockRegistry{}
+	upstream.On("Digest", "addon", "1.0.0-1").Return("sha256:1", nil)
+	upstream.On("Pull", mock.Anything, mock.Anything, mock.Anything).Return(uint64(42), nil)
+	mirror.On("Pull", "addon", "sha256:1", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(2).(registry.ImageManifestLayerProcessor).Filter(&ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer})
+	}).Return(uint64(0), interrupted)
+	processor := registry.NewLayerDescriptorRecorder(registry.NewAcceptNoneManifestLayerProcessor(nil))
+	uut := registry.NewMirroredAddOnRegistry(mirror, upstream)
+
+	// Act
+	_, err := uut.Pull(context.Background(), "addon", "1.0.0-1", processor)
+
+	// Assert
+	assert.ErrorIs(t, err, interrupted)
+	assert.Len(t, processor.Descriptors, 1)
+	upstream.AssertNotCalled(t, "Pull", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -35,6 +35,11 @@ type DescriptorSizeMapper interface {
 	MapDescriptorSize(ocispec.Descriptor) int64
 }
 
+// Interface which is used to map a descriptor to the descriptor of the blob in the source repository
+type SourceDescriptorMapper interface {
+	MapSourceDescriptor(ocispec.Descriptor) ocispec.Descriptor
+}
+
 type orasAddonMigrateRepository struct {
 	source                         oras.Target
 	migrationStorage               oras.Target
@@ -106,6 +111,15 @@ func (m *orasAddonMigrateRepository) MapDescriptorSize(desc ocispec.Descriptor)
 	return desc.Size
 }
 
+func (m *orasAddonMigrateRepository) MapSourceDescriptor(desc ocispec.Descriptor) ocispec.Descriptor {
+	// The dummy docker images are fetched from the original docker image of the source
+	if orginalDockerImageDesc, isDummyDescriptor := m.isDummyDockerImageDescriptor(desc); isDummyDescriptor {
+		return orginalDockerImageDesc
+	}
+
+	return desc
+}
+
 func (m *orasAddonMigrateRepository) isDummyDockerImageDescriptor(desc ocispec.Descriptor) (originalDescriptor ocispec.Descriptor, isDummyDescriptor bool) {
 	if originalDescriptor, ok := m.dockerImageSourceDescriptorMap[string(desc.Digest)]; ok {
 		return originalDescriptor, ok
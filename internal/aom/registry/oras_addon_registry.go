@@ -5,17 +5,27 @@
 package registry
 
 import (
+	"bytes"
 	"context"
+	"crypto/ed25519"
 	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"sync"
 	"time"
 	"u-control/uc-aom/internal/aom/manifest"
 	"u-control/uc-aom/internal/aom/utils"
+	catalogueindex "u-control/uc-aom/internal/pkg/catalogue-index"
+	model "u-control/uc-aom/internal/pkg/manifest"
 	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
+	sharedRegistry "u-control/uc-aom/internal/pkg/registry"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	log "github.com/sirupsen/logrus"
 	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
@@ -29,14 +39,31 @@ type ORASAddOnRegistry struct {
 	getRepositoryCallback GetRepositoryFn
 	architecture          string
 	os                    string
+
+	// Caches per manifest digest whether the tag refers to an add-on
+	addOnDigests sync.Map
+
+	// Caches the last verified catalogue index per index repository
+	catalogueIndexes sync.Map
+
+	// Keeps the docker image layers until they are installed, nil if the layers are streamed
+	blobCache *BlobCache
+}
+
+type cachedCatalogueIndex struct {
+	digest string
+	index  *catalogueindex.Index
 }
 
 // Function that wraps the GetRepository function so that migration can be executed if required
 func GetRepositoryWithMigration() GetRepositoryFn {
 
 	registryFascade := make(map[string]Repository, 0)
+	var mutex sync.Mutex
 
 	return func(ctx context.Context, registry registry.Registry, name string) (Repository, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
 
 		if target, ok := registryFascade[name]; ok {
 			return target, nil
@@ -59,11 +86,16 @@ func NewORASAddOnRegistry(registry registry.Registry, localfs *manifest.LocalFSR
 	return &ORASAddOnRegistry{registry: registry, getRepositoryCallback: getRepositoryWithMigrationFn, architecture: architecture, os: os}
 }
 
+// Downloads the docker image layers into the blob cache before they are processed,
+// so that an interrupted download is resumed instead of restarted.
+func (r *ORASAddOnRegistry) UseBlobCache(cache *BlobCache) {
+	r.blobCache = cache
+}
+
 // Returns all repositories known to this ORAS registry.
-func (r *ORASAddOnRegistry) Repositories() ([]string, error) {
+func (r *ORASAddOnRegistry) Repositories(ctx context.Context) ([]string, error) {
 	delay := time.Duration(10) * time.Second
-	res, err := utils.Retry(5, delay, func() (interface{}, error) {
-		ctx := context.Background()
+	res, err := utils.RetryWithContext(ctx, 5, delay, func() (interface{}, error) {
 		names, err := registry.Repositories(ctx, r.registry)
 		return names, err
 	})
@@ -75,13 +107,41 @@ func (r *ORASAddOnRegistry) Repositories() ([]string, error) {
 	if !ok {
 		return make([]string, 0), fmt.Errorf("Could not cast %T into string", res)
 	}
-	return repositories, nil
+	return withoutCatalogueIndexes(repositories), nil
+}
+
+// Returns the catalogue index published in the namespace, nil if no index is published.
+// The index is only fetched again if a new index was published and must be signed by the owner of the public key.
+func (r *ORASAddOnRegistry) CatalogueIndex(ctx context.Context, namespace string, publicKey ed25519.PublicKey) (*catalogueindex.Index, error) {
+	name := catalogueindex.RepositoryOf(namespace)
+	repo, err := r.registry.Repository(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := catalogueindex.Resolve(ctx, repo)
+	if errors.Is(err, errdef.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := r.catalogueIndexes.Load(name); ok && cached.(*cachedCatalogueIndex).digest == desc.Digest.String() {
+		return cached.(*cachedCatalogueIndex).index, nil
+	}
+
+	index, err := catalogueindex.Fetch(ctx, repo, desc, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	r.catalogueIndexes.Store(name, &cachedCatalogueIndex{digest: desc.Digest.String(), index: index})
+	return index, nil
 }
 
 // Returns all tags of the given repository or an error should it fail or
 // the tuple repository and tag not represent an AddOn.
-func (r *ORASAddOnRegistry) Tags(repository string) ([]string, error) {
-	ctx := context.Background()
+func (r *ORASAddOnRegistry) Tags(ctx context.Context, repository string) ([]string, error) {
 	repo, err := r.getRepositoryCallback(ctx, r.registry, repository)
 	if err != nil {
 		return nil, err
@@ -92,13 +152,62 @@ func (r *ORASAddOnRegistry) Tags(repository string) ([]string, error) {
 		return nil, err
 	}
 
-	return r.onlyAddOnTags(context.Background(), repo, tags)
+	return r.onlyAddOnTags(ctx, repo, tags)
+}
+
+// Returns the digest of the manifest the tag of the repository refers to,
+// only the descriptor of the tag is requested from the registry.
+func (r *ORASAddOnRegistry) Digest(ctx context.Context, repository string, tag string) (string, error) {
+	repo, err := r.getRepositoryCallback(ctx, r.registry, repository)
+	if err != nil {
+		return "", err
+	}
+
+	desc, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest.String(), nil
+}
+
+// Returns the digest of the manifest the tag refers to and the summary of the add-on
+// from the annotations of the image index, which avoids pulling the uc image layer.
+// The summary is nil if the index is not annotated.
+func (r *ORASAddOnRegistry) Summary(ctx context.Context, repository string, tag string) (string, *model.AddOnSummary, error) {
+	repo, err := r.getRepositoryCallback(ctx, r.registry, repository)
+	if err != nil {
+		return "", nil, err
+	}
+
+	desc, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return "", nil, err
+	}
+	digest := desc.Digest.String()
+	if desc.MediaType != ocispec.MediaTypeImageIndex {
+		return digest, nil, nil
+	}
+
+	imageIndex, err := oraswrapper.FetchImageIndex(ctx, repo, desc)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The add-on must be pullable on this device, as it is required by Pull.
+	if !r.containsSupportedManifest(imageIndex.Manifests) {
+		return "", nil, fmt.Errorf("Support architecture %s and OS %s is not included!", r.architecture, r.os)
+	}
+
+	summary, ok := model.ParseAddOnSummaryAnnotations(imageIndex.Annotations)
+	if !ok {
+		return digest, nil, nil
+	}
+	return digest, summary, nil
 }
 
 // Downloads the artifact from the Registry identified by repository and tag,
 // calls the action on any image manifest layers that pass the predicate.
-func (r *ORASAddOnRegistry) Pull(repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
-	ctx := context.Background()
+func (r *ORASAddOnRegistry) Pull(ctx context.Context, repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
 	repo, err := r.getRepositoryCallback(ctx, r.registry, repository)
 	if err != nil {
 		log.Error("Unable to read repository: ", err)
@@ -110,11 +219,36 @@ func (r *ORASAddOnRegistry) Pull(repository string, tag string, processor ImageM
 		return 0, err
 	}
 
+	// The cached blobs are handed over to the processor and closed by its owner, unless the pull fails.
+	cachedBlobs := make([]*CachedBlob, 0)
 	for _, item := range imageManifest.Layers {
 		if !processor.Filter(&item) {
 			continue
 		}
 
+		if r.blobCache != nil && !IsUcImageLayerMediaType(item.MediaType) {
+			blob, err := r.fetchCached(ctx, repo, item)
+			if err != nil {
+				log.Error("BlobCache.Fetch() error =", err)
+				closeCachedBlobs(cachedBlobs)
+				return 0, err
+			}
+			cachedBlobs = append(cachedBlobs, blob)
+			processor.Action(blob, item.MediaType)
+			continue
+		}
+
+		if IsUcImageLayerMediaType(item.MediaType) {
+			// The uc layer holds the add-on manifest, it is verified against its digest before it is processed
+			data, err := content.FetchAll(ctx, repo, item)
+			if err != nil {
+				log.Error("content.FetchAll() error =", err)
+				continue
+			}
+			processor.Action(bytes.NewReader(data), item.MediaType)
+			continue
+		}
+
 		reader, err := repo.Fetch(ctx, item)
 		if err != nil {
 			log.Error("Store.Fetch() error =", err)
@@ -128,27 +262,104 @@ func (r *ORASAddOnRegistry) Pull(repository string, tag string, processor ImageM
 	return estimatedInstallSizeBytes(cumulativeLayerSize), err
 }
 
-func (r *ORASAddOnRegistry) Delete(repository string, tag string) error {
+func (r *ORASAddOnRegistry) fetchCached(ctx context.Context, repository Repository, desc ocispec.Descriptor) (*CachedBlob, error) {
+	if mapper, ok := repository.(SourceDescriptorMapper); ok {
+		desc = mapper.MapSourceDescriptor(desc)
+	}
+	return r.blobCache.Fetch(ctx, repository, desc)
+}
+
+func closeCachedBlobs(blobs []*CachedBlob) {
+	for _, blob := range blobs {
+		blob.Close()
+	}
+}
+
+// Returns the remote registry, whose repositories are served as they are stored.
+func (r *ORASAddOnRegistry) MirrorRegistry() registry.Registry {
+	return r.registry
+}
+
+func (r *ORASAddOnRegistry) Delete(ctx context.Context, repository string, tag string) error {
 	panic("Not implemented")
 }
 
-// Initialize an ORAS registry instance given the credentials.
-func InitializeRegistry(credentials *Credentials) (registry.Registry, error) {
+// Returns the transport options of all registries.
+// The CA bundle is only used if it exists.
+func DefaultTransportOptions() sharedRegistry.TransportOptions {
+	options := sharedRegistry.TransportOptions{Proxy: REGISTRY_PROXY, NoProxy: REGISTRY_NO_PROXY}
+	if _, err := os.Stat(REGISTRY_CA_BUNDLE_PATH); err == nil {
+		options.CABundlePath = REGISTRY_CA_BUNDLE_PATH
+	}
+	return options
+}
+
+// Initialize an ORAS registry instance given the credentials, which connects according to the transport options.
+// The credentials of the provider take precedence and are looked up on every authentication,
+// so that credentials which are rotated at runtime take effect without a restart. The provider may be nil.
+func InitializeRegistry(credentials *Credentials, provider CredentialProvider, options sharedRegistry.TransportOptions) (*remote.Registry, error) {
 	reg, err := remote.NewRegistry(credentials.ServerAddress)
 	if err != nil {
 		return nil, err
 	}
+	client, err := sharedRegistry.NewHTTPClient(options)
+	if err != nil {
+		return nil, err
+	}
 
-	var creds = auth.Credential{Username: credentials.Username, Password: credentials.Password}
+	currentCredentials := func() *Credentials {
+		if provider != nil {
+			if provided, ok := provider.Credentials(credentials.ServerAddress); ok {
+				return provided
+			}
+		}
+		return credentials
+	}
+	// The scheme is decided per request, so that setting or deleting the credentials takes effect immediately
+	client.Transport = &schemeTransport{
+		base: client.Transport,
+		host: reg.Reference.Registry,
+		plainHTTP: func() bool {
+			return currentCredentials().IsInsecureServer()
+		},
+	}
 	reg.RepositoryOptions.Client = &auth.Client{
+		Client: client,
 		Credential: func(c context.Context, s string) (auth.Credential, error) {
-			return creds, nil
+			return currentCredentials().toAuthCredential(), nil
 		},
 	}
-	reg.PlainHTTP = credentials.IsInsecureServer()
 	return reg, nil
 }
 
+// Sends the requests to the host with plain HTTP while plainHTTP returns true, e.g. while the registry has no credentials.
+type schemeTransport struct {
+	base      http.RoundTripper
+	host      string
+	plainHTTP func() bool
+}
+
+func (t *schemeTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.URL.Scheme == "https" && request.URL.Host == t.host && t.plainHTTP() {
+		request = request.Clone(request.Context())
+		request.URL.Scheme = "http"
+	}
+	return t.base.RoundTrip(request)
+}
+
+// Checks that the registry accepts the credentials.
+func TestCredentials(ctx context.Context, credentials *Credentials) error {
+	if err := validateCredentials(credentials); err != nil {
+		return &InvalidRegistryCredentialsError{message: err.Error()}
+	}
+
+	reg, err := InitializeRegistry(credentials, nil, DefaultTransportOptions())
+	if err != nil {
+		return &InvalidRegistryCredentialsError{message: err.Error()}
+	}
+	return reg.Ping(ctx)
+}
+
 func (r *ORASAddOnRegistry) deserializeImageManifest(ctx context.Context, repository Repository, tag string) (*ocispec.Manifest, error) {
 	log.Debug("repository.Resolve()")
 	desc, err := repository.Resolve(ctx, tag)
@@ -156,7 +367,15 @@ func (r *ORASAddOnRegistry) deserializeImageManifest(ctx context.Context, reposi
 		log.Error("repository.Resolve() error =", err)
 		return nil, err
 	}
+	// The content is verified against the descriptor while it is fetched, a digest reference must not resolve to another descriptor
+	if dgst, err := digest.Parse(tag); err == nil && desc.Digest != dgst {
+		return nil, fmt.Errorf("The registry resolved %s to %s", dgst, desc.Digest)
+	}
+
+	return r.deserializeImageManifestOf(ctx, repository, desc)
+}
 
+func (r *ORASAddOnRegistry) deserializeImageManifestOf(ctx context.Context, repository Repository, desc ocispec.Descriptor) (*ocispec.Manifest, error) {
 	switch desc.MediaType {
 	case ocispec.MediaTypeImageIndex:
 		imageIndex, err := oraswrapper.FetchImageIndex(ctx, repository, desc)
@@ -174,8 +393,7 @@ func (r *ORASAddOnRegistry) deserializeImageManifest(ctx context.Context, reposi
 
 func (r *ORASAddOnRegistry) findSupportedManifest(fetcher content.Fetcher, ctx context.Context, manifestDescriptors ...ocispec.Descriptor) (*ocispec.Manifest, error) {
 	for _, manifestDescriptor := range manifestDescriptors {
-		platform := manifestDescriptor.Platform
-		if platform.OS == r.os && platform.Architecture == r.architecture {
+		if r.isSupportedPlatform(manifestDescriptor.Platform) {
 			return oraswrapper.FetchImageManifest(ctx, fetcher, manifestDescriptor)
 		}
 	}
@@ -183,6 +401,19 @@ func (r *ORASAddOnRegistry) findSupportedManifest(fetcher content.Fetcher, ctx c
 	return nil, errors.New(fmt.Sprintf("Support architecture %s and OS %s is not included!", r.architecture, r.os))
 }
 
+func (r *ORASAddOnRegistry) containsSupportedManifest(manifestDescriptors []ocispec.Descriptor) bool {
+	for _, manifestDescriptor := range manifestDescriptors {
+		if r.isSupportedPlatform(manifestDescriptor.Platform) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ORASAddOnRegistry) isSupportedPlatform(platform *ocispec.Platform) bool {
+	return platform != nil && platform.OS == r.os && platform.Architecture == r.architecture
+}
+
 func (r *ORASAddOnRegistry) onlyAddOnTags(ctx context.Context, target Repository, tags []string) ([]string, error) {
 
 	filtered := make([]string, 0, len(tags))
@@ -205,16 +436,39 @@ func (r *ORASAddOnRegistry) onlyAddOnTags(ctx context.Context, target Repository
 	return filtered, nil
 }
 
+// Validates whether the tag refers to an add-on.
+// The image manifest is only fetched for digests which were not validated before.
 func (r *ORASAddOnRegistry) validateIsAddOn(ctx context.Context, repository Repository, tag string) (bool, error) {
-	imageManifest, err := r.deserializeImageManifest(ctx, repository, tag)
+	desc, err := repository.Resolve(ctx, tag)
+	if err != nil {
+		log.Error("repository.Resolve() error =", err)
+		return false, err
+	}
+
+	if isAddOn, ok := r.addOnDigests.Load(desc.Digest); ok {
+		return isAddOn.(bool), nil
+	}
+
+	imageManifest, err := r.deserializeImageManifestOf(ctx, repository, desc)
 	if err != nil {
 		return false, err
 	}
 
 	hasUcImageLayer := hasUcImageLayer(imageManifest)
+	r.addOnDigests.Store(desc.Digest, hasUcImageLayer)
 	return hasUcImageLayer, nil
 }
 
+func withoutCatalogueIndexes(repositories []string) []string {
+	filtered := make([]string, 0, len(repositories))
+	for _, repository := range repositories {
+		if !catalogueindex.IsIndexRepository(repository) {
+			filtered = append(filtered, repository)
+		}
+	}
+	return filtered
+}
+
 func (r *ORASAddOnRegistry) getTagsFromRemoteRepository(ctx context.Context, name string) ([]string, error) {
 	repository, err := r.registry.Repository(ctx, name)
 	if err != nil {
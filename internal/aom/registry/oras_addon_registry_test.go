@@ -16,6 +16,7 @@ import (
 	"reflect"
 	"testing"
 	"u-control/uc-aom/internal/pkg/config"
+	model "u-control/uc-aom/internal/pkg/manifest"
 	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -203,7 +204,7 @@ func TestORASAddOnRegistry_Tags(t *testing.T) {
 			tt.fields.registry.On("Repository", mock.Anything, tt.args.repository).Return(mockRepo, nil)
 
 			// act
-			got, err := r.Tags(tt.args.repository)
+			got, err := r.Tags(context.Background(), tt.args.repository)
 
 			// assert
 			if (err != nil) != tt.wantErr {
@@ -284,7 +285,7 @@ func TestORASAddOnRegistry_TagsMediaTypes(t *testing.T) {
 			tt.fields.registry.On("Repository", mock.Anything, tt.args.repository).Return(mockRepo, nil)
 
 			// act
-			got, err := r.Tags(tt.args.repository)
+			got, err := r.Tags(context.Background(), tt.args.repository)
 
 			// assert
 			if (err != nil) != tt.wantErr {
@@ -321,7 +322,7 @@ func TestORASAddOnRegistry_TagsFetchError(t *testing.T) {
 	mockRepo.On("Fetch", mock.Anything, convertToJSONDesc(manifestTuple.Desc)).Return(createReaderCloserAsFetchResultFrom(manifestTuple.Blob), wantErr)
 
 	// act
-	got, err := r.Tags("test-repository")
+	got, err := r.Tags(context.Background(), "test-repository")
 
 	// assert
 	if err == nil {
@@ -337,6 +338,123 @@ func TestORASAddOnRegistry_TagsFetchError(t *testing.T) {
 
 }
 
+func TestORASAddOnRegistry_TagsValidatesDigestOnce(t *testing.T) {
+
+	supportedPlatform := platform{architecture: "arm", os: "linux"}
+
+	// arrange
+	tags := []string{"1.0.0-1"}
+	registry := &mockOrasRegistry{}
+	r := &ORASAddOnRegistry{registry: registry, getRepositoryCallback: GetRepositoryTestFn(), architecture: supportedPlatform.architecture, os: supportedPlatform.os}
+	mockRepo := createMockRepository(supportedPlatform, tags)
+	registry.On("Repository", mock.Anything, "test-repository").Return(mockRepo, nil)
+	manifestTuples := createImageManifestsWithPlatformsAndUcManifestLayer([]platform{mockRepo.SupportPlatform})
+	imageIndexTuple, _ := oraswrapper.CreateImageIndexTuple(manifestTuples)
+	mockRepo.On("Resolve", mock.Anything, tags[0]).Return(*imageIndexTuple.Desc, nil)
+	mockRepo.On("Fetch", mock.Anything, *imageIndexTuple.Desc).Return(createReaderCloserAsFetchResultFrom(imageIndexTuple.Blob), nil).Once()
+	manifestTuple := manifestTuples[0]
+	mockRepo.On("Fetch", mock.Anything, convertToJSONDesc(manifestTuple.Desc)).Return(createReaderCloserAsFetchResultFrom(manifestTuple.Blob), nil).Once()
+
+	// act
+	first, firstErr := r.Tags(context.Background(), "test-repository")
+	second, secondErr := r.Tags(context.Background(), "test-repository")
+
+	// assert
+	if firstErr != nil || secondErr != nil {
+		t.Errorf("ORASAddOnRegistry.Tags() unexpected errors = %v, %v", firstErr, secondErr)
+		return
+	}
+	if !reflect.DeepEqual(first, tags) || !reflect.DeepEqual(second, tags) {
+		t.Errorf("ORASAddOnRegistry.Tags() = %v and %v, want %v", first, second, tags)
+	}
+
+	mockRepo.AssertNumberOfCalls(t, "Resolve", 2)
+	mockRepo.AssertNumberOfCalls(t, "Fetch", 2)
+}
+
+func TestORASAddOnRegistry_Digest(t *testing.T) {
+
+	supportedPlatform := platform{architecture: "arm", os: "linux"}
+
+	// arrange
+	registry := &mockOrasRegistry{}
+	r := &ORASAddOnRegistry{registry: registry, getRepositoryCallback: GetRepositoryTestFn(), architecture: supportedPlatform.architecture, os: supportedPlatform.os}
+	mockRepo := &mockRepo{SupportPlatform: supportedPlatform}
+	registry.On("Repository", mock.Anything, "test-repository").Return(mockRepo, nil)
+	manifestTuples := createImageManifestsWithPlatformsAndUcManifestLayer([]platform{mockRepo.SupportPlatform})
+	imageIndexTuple, _ := oraswrapper.CreateImageIndexTuple(manifestTuples)
+	mockRepo.On("Resolve", mock.Anything, "1.0.0-1").Return(*imageIndexTuple.Desc, nil)
+
+	// act
+	got, err := r.Digest(context.Background(), "test-repository", "1.0.0-1")
+
+	// assert
+	if err != nil {
+		t.Errorf("ORASAddOnRegistry.Digest() unexpected error = %v", err)
+		return
+	}
+	if want := imageIndexTuple.Desc.Digest.String(); got != want {
+		t.Errorf("ORASAddOnRegistry.Digest() = %v, want %v", got, want)
+	}
+
+	mockRepo.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+}
+
+func TestORASAddOnRegistry_Summary(t *testing.T) {
+
+	supportedPlatform := platform{architecture: "arm", os: "linux"}
+	want := &model.AddOnSummary{Title: "Test", Version: "1.0.0-1", ManifestVersion: model.ValidManifestVersion}
+	summaryAnnotations, _ := model.CreateAddOnSummaryAnnotations(want)
+
+	type args struct {
+		name        string
+		platforms   []platform
+		annotations []string
+		want        *model.AddOnSummary
+		wantErr     bool
+	}
+	testCases := []args{
+		{name: "annotated index", platforms: []platform{supportedPlatform}, annotations: summaryAnnotations, want: want},
+		{name: "index without annotations", platforms: []platform{supportedPlatform}, want: nil},
+		{name: "unsupported platform", platforms: []platform{{architecture: "amd64", os: "linux"}}, annotations: summaryAnnotations, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			registry := &mockOrasRegistry{}
+			r := &ORASAddOnRegistry{registry: registry, getRepositoryCallback: GetRepositoryTestFn(), architecture: supportedPlatform.architecture, os: supportedPlatform.os}
+			mockRepo := &mockRepo{SupportPlatform: supportedPlatform}
+			registry.On("Repository", mock.Anything, "test-repository").Return(mockRepo, nil)
+			manifestTuples := createImageManifestsWithPlatformsAndUcManifestLayer(tc.platforms)
+			imageIndexTuple, _ := oraswrapper.CreateImageIndexTuple(manifestTuples, tc.annotations...)
+			mockRepo.On("Resolve", mock.Anything, "1.0.0-1").Return(*imageIndexTuple.Desc, nil)
+			mockRepo.On("Fetch", mock.Anything, *imageIndexTuple.Desc).Return(createReaderCloserAsFetchResultFrom(imageIndexTuple.Blob), nil).Once()
+
+			// act
+			digest, got, err := r.Summary(context.Background(), "test-repository", "1.0.0-1")
+
+			// assert
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ORASAddOnRegistry.Summary() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ORASAddOnRegistry.Summary() = %v, want %v", got, tc.want)
+			}
+			if !tc.wantErr && digest != imageIndexTuple.Desc.Digest.String() {
+				t.Errorf("ORASAddOnRegistry.Summary() digest = %v, want %v", digest, imageIndexTuple.Desc.Digest)
+			}
+
+			// The tag is resolved once
+			mockRepo.AssertNumberOfCalls(t, "Resolve", 1)
+
+			// Only the image index is fetched, the uc image layer is not pulled
+			mockRepo.AssertNumberOfCalls(t, "Fetch", 1)
+		})
+	}
+}
+
 func createMockRepository(supportPlatform platform, providedTags []string) *mockRepo {
 	mockRepo := &mockRepo{SupportPlatform: supportPlatform}
 	tagPaginationCallbackFn := func(args mock.Arguments) {
@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -11,4 +11,41 @@ import (
 var (
 	DEV_CREDENTIALS_ROOT = utils.GetEnv("DEV_CREDENTIALS_ROOT", "/var/lib/uc-aom")
 	REL_CREDENTIALS_ROOT = utils.GetEnv("REL_CREDENTIALS_ROOT", "/usr/share/uc-aom")
+
+	// Sources of the federated catalogue, the registry credentials are the only source if the file does not exist.
+	CATALOGUE_SOURCES_PATH = utils.GetEnv("CATALOGUE_SOURCES_PATH", "/var/lib/uc-aom/catalogue-sources.json")
+
+	// Source per installed add-on
+	CATALOGUE_ORIGINS_PATH = utils.GetEnv("CATALOGUE_ORIGINS_PATH", "/var/lib/uc-aom/catalogue-origins.json")
+
+	// Registry credentials set at runtime, encrypted with the key at REGISTRY_CREDENTIALS_KEY_PATH
+	REGISTRY_CREDENTIALS_PATH     = utils.GetEnv("REGISTRY_CREDENTIALS_PATH", "/var/lib/uc-aom/registry-credentials.enc")
+	REGISTRY_CREDENTIALS_KEY_PATH = utils.GetEnv("REGISTRY_CREDENTIALS_KEY_PATH", "/var/lib/uc-aom/registry-credentials.key")
+
+	// Directory of the docker config.json, whose credentials and credential helpers are used
+	// for registries without credentials set at runtime
+	DOCKER_CONFIG_DIR = utils.GetEnv("DOCKER_CONFIG", "/root/.docker")
+
+	// Public key which verifies the catalogue indexes, catalogue indexes are not used if the file does not exist.
+	CATALOGUE_INDEX_PUBLIC_KEY_PATH = utils.GetEnv("CATALOGUE_INDEX_PUBLIC_KEY_PATH", "/usr/share/uc-aom/catalogue-index.pub")
+
+	// Creation time of the last accepted catalogue index per catalogue source
+	CATALOGUE_INDEX_HISTORY_PATH = utils.GetEnv("CATALOGUE_INDEX_HISTORY_PATH", "/var/lib/uc-aom/catalogue-index-history.json")
+
+	// Proxy of all registries, the environment variables HTTP_PROXY, HTTPS_PROXY and NO_PROXY are used if empty.
+	// Catalogue sources may override the proxy.
+	REGISTRY_PROXY    = utils.GetEnv("REGISTRY_PROXY", "")
+	REGISTRY_NO_PROXY = utils.GetEnv("REGISTRY_NO_PROXY", "")
+
+	// PEM file of additionally trusted certificate authorities, ignored if the file does not exist.
+	REGISTRY_CA_BUNDLE_PATH = utils.GetEnv("REGISTRY_CA_BUNDLE_PATH", "/var/lib/uc-aom/registry-ca-bundle.pem")
+
+	// Docker image layers which are downloaded but not installed yet, on the data partition.
+	// Layers of interrupted installs are removed after BLOB_CACHE_TTL.
+	BLOB_CACHE_PATH = utils.GetEnv("BLOB_CACHE_PATH", "/var/lib/uc-aom/blobs")
+	BLOB_CACHE_TTL  = utils.GetEnv("BLOB_CACHE_TTL", "168h")
+
+	// Add-on packages which are downloaded ahead of their update, on the data partition.
+	// A staged package is removed once its add-on is updated.
+	STAGING_PATH = utils.GetEnv("STAGING_PATH", "/var/lib/uc-aom/staging")
 )
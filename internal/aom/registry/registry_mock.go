@@ -7,28 +7,44 @@
 
 package registry
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/mock"
+)
 
 type MockRegistry struct {
 	mock.Mock
 }
 
-func (r *MockRegistry) Repositories() ([]string, error) {
+func (r *MockRegistry) Repositories(ctx context.Context) ([]string, error) {
 	args := r.Called()
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (r *MockRegistry) Tags(repository string) ([]string, error) {
+func (r *MockRegistry) Tags(ctx context.Context, repository string) ([]string, error) {
 	args := r.Called(repository)
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (r *MockRegistry) Pull(repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
+func (r *MockRegistry) Digest(ctx context.Context, repository string, tag string) (string, error) {
+	args := r.Called(repository, tag)
+	return args.String(0), args.Error(1)
+}
+
+func (r *MockRegistry) Summary(ctx context.Context, repository string, tag string) (string, *manifest.AddOnSummary, error) {
+	args := r.Called(repository, tag)
+	summary, _ := args.Get(1).(*manifest.AddOnSummary)
+	return args.String(0), summary, args.Error(2)
+}
+
+func (r *MockRegistry) Pull(ctx context.Context, repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
 	args := r.Called(repository, tag, processor)
 	return args.Get(0).(uint64), args.Error(1)
 }
 
-func (r *MockRegistry) Delete(repository string, tag string) error {
+func (r *MockRegistry) Delete(ctx context.Context, repository string, tag string) error {
 	args := r.Called(repository, tag)
 	return args.Error(0)
 }
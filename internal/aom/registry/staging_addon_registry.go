@@ -0,0 +1,430 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+// Name of the file which lists the layers of a staged package, written once all layers are verified
+const stagedIndexFileName = "index.json"
+
+// Prefix of the directories of packages which are being staged
+const stagingDirPrefix = ".staging-"
+
+// Directory of the kept packages, apart from the staged updates so that staging an update does not remove them
+const keptDirName = ".kept"
+
+// AddOnStager downloads add-on packages ahead of their install.
+type AddOnStager interface {
+	// Downloads and verifies all layers of the add-on package identified by repository and tag,
+	// so that the package is pulled without network access. Replaces a previously staged package of the repository.
+	// Returns the size of the staged layers in bytes.
+	Stage(ctx context.Context, repository string, tag string) (uint64, error)
+
+	// Removes the staged package of the repository, if there is one.
+	Discard(repository string) error
+}
+
+// Returns an error if the disk space is insufficient to stage the add-on package identified by repository and tag.
+type StagingDiskSpaceCheck func(repository string, tag string) error
+
+type stagedIndex struct {
+	EstimatedInstallSize uint64               `json:"estimatedInstallSize"`
+	Layers               []ocispec.Descriptor `json:"layers"`
+}
+
+// StagingAddOnRegistry serves the pull of staged and kept add-on packages from the staging area
+// and passes everything else to the upstream registry.
+// A staged or kept package is removed once the docker images of its add-on are installed.
+type StagingAddOnRegistry struct {
+	root           string
+	upstream       AddOnRegistry
+	diskSpaceCheck StagingDiskSpaceCheck
+
+	// Serializes changes of the staging area
+	mutex sync.Mutex
+}
+
+func NewStagingAddOnRegistry(root string, upstream AddOnRegistry) *StagingAddOnRegistry {
+	return &StagingAddOnRegistry{root: root, upstream: upstream}
+}
+
+// Checks the disk space with check before a package is downloaded into the staging area.
+func (r *StagingAddOnRegistry) UseDiskSpaceCheck(check StagingDiskSpaceCheck) {
+	r.diskSpaceCheck = check
+}
+
+func (r *StagingAddOnRegistry) Repositories(ctx context.Context) ([]string, error) {
+	return r.upstream.Repositories(ctx)
+}
+
+func (r *StagingAddOnRegistry) Tags(ctx context.Context, repository string) ([]string, error) {
+	return r.upstream.Tags(ctx, repository)
+}
+
+func (r *StagingAddOnRegistry) Digest(ctx context.Context, repository string, tag string) (string, error) {
+	return r.upstream.Digest(ctx, repository, tag)
+}
+
+func (r *StagingAddOnRegistry) Summary(ctx context.Context, repository string, tag string) (string, *manifest.AddOnSummary, error) {
+	return r.upstream.Summary(ctx, repository, tag)
+}
+
+func (r *StagingAddOnRegistry) Pull(ctx context.Context, repository string, tag string, processor ImageManifestLayerProcessor) (uint64, error) {
+	dir := r.pathOf(repository, tag)
+	index, ok := r.stagedIndexOf(dir, repository, tag)
+	if !ok {
+		dir = r.keptPathOf(repository, tag)
+		index, ok = r.stagedIndexOf(dir, repository, tag)
+	}
+	if !ok {
+		return r.upstream.Pull(ctx, repository, tag, processor)
+	}
+
+	log.Infof("Pulling '%s:%s' from the staging area", repository, tag)
+
+	// The opened blobs are handed over to the processor and closed by its owner, unless the pull fails.
+	stagedBlobs := make([]*StagedBlob, 0)
+	for i := range index.Layers {
+		layer := index.Layers[i]
+		if !processor.Filter(&layer) {
+			continue
+		}
+
+		path := filepath.Join(dir, layer.Digest.Encoded())
+		if IsUcImageLayerMediaType(layer.MediaType) {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				closeStagedBlobs(stagedBlobs)
+				return 0, err
+			}
+			processor.Action(bytes.NewReader(content), layer.MediaType)
+			continue
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			closeStagedBlobs(stagedBlobs)
+			return 0, err
+		}
+		blob := &StagedBlob{File: file, dir: dir}
+		stagedBlobs = append(stagedBlobs, blob)
+		processor.Action(blob, layer.MediaType)
+	}
+	return index.EstimatedInstallSize, nil
+}
+
+func closeStagedBlobs(blobs []*StagedBlob) {
+	for _, blob := range blobs {
+		blob.Close()
+	}
+}
+
+func (r *StagingAddOnRegistry) Delete(ctx context.Context, repository string, tag string) error {
+	return r.upstream.Delete(ctx, repository, tag)
+}
+
+// Remembers the source of the upstream which served the last pull of the repository.
+// The staged package has been pulled from the same source.
+func (r *StagingAddOnRegistry) RecordOrigin(repository string) error {
+	if recorder, ok := r.upstream.(OriginRecorder); ok {
+		return recorder.RecordOrigin(repository)
+	}
+	return nil
+}
+
+func (r *StagingAddOnRegistry) Stage(ctx context.Context, repository string, tag string) (uint64, error) {
+	log.Tracef("StagingAddOnRegistry.Stage('%s', '%s')", repository, tag)
+	return r.stage(ctx, repository, tag, r.root, true)
+}
+
+// Downloads and verifies the add-on package as Stage does, e.g. to restore the installed version of an add-on
+// without network access. A kept package is neither replaced by staged updates nor discarded with them.
+func (r *StagingAddOnRegistry) Keep(ctx context.Context, repository string, tag string) (uint64, error) {
+	log.Tracef("StagingAddOnRegistry.Keep('%s', '%s')", repository, tag)
+	return r.stage(ctx, repository, tag, filepath.Join(r.root, keptDirName), false)
+}
+
+// Stages the add-on package in the directory of its repository in packagesDir,
+// replacing the other packages of the repository if replace is set.
+func (r *StagingAddOnRegistry) stage(ctx context.Context, repository string, tag string, packagesDir string, replace bool) (uint64, error) {
+	if r.diskSpaceCheck != nil {
+		if err := r.diskSpaceCheck(repository, tag); err != nil {
+			return 0, err
+		}
+	}
+
+	repositoryDir := filepath.Join(packagesDir, repository)
+	if err := os.MkdirAll(repositoryDir, os.ModePerm); err != nil {
+		return 0, err
+	}
+	stagingDir, err := os.MkdirTemp(repositoryDir, stagingDirPrefix+"*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	processor := &stagingProcessor{dir: stagingDir}
+	estimatedInstallSize, err := r.upstream.Pull(ctx, repository, tag, processor)
+	if err != nil {
+		return 0, err
+	}
+	if err := processor.verify(); err != nil {
+		return 0, fmt.Errorf("Unable to stage '%s:%s': %w", repository, tag, err)
+	}
+
+	content, err := json.Marshal(stagedIndex{EstimatedInstallSize: estimatedInstallSize, Layers: processor.layers})
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(filepath.Join(stagingDir, stagedIndexFileName), content, 0644); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	packageDir := filepath.Join(repositoryDir, tag)
+	removeErr := os.RemoveAll(packageDir)
+	if replace {
+		removeErr = r.removeStagedPackages(repositoryDir)
+	}
+	if removeErr != nil {
+		return 0, removeErr
+	}
+	if err := os.Rename(stagingDir, packageDir); err != nil {
+		return 0, err
+	}
+	return processor.size, nil
+}
+
+func (r *StagingAddOnRegistry) Discard(repository string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.removeStagedPackages(filepath.Join(r.root, repository))
+}
+
+// Removes the kept package of the repository and tag, if there is one.
+func (r *StagingAddOnRegistry) Release(repository string, tag string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return os.RemoveAll(r.keptPathOf(repository, tag))
+}
+
+// Removes the staged packages which are not an update of an installed add-on anymore,
+// e.g. of deleted add-ons, the leftovers of interrupted stagings and the kept packages,
+// which are only kept for a running process.
+// The installed versions are given per repository.
+func (r *StagingAddOnRegistry) RetainUpdatesOf(installedVersions map[string]string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	repositories, err := os.ReadDir(r.root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, repository := range repositories {
+		repositoryDir := filepath.Join(r.root, repository.Name())
+		installedVersion, ok := installedVersions[repository.Name()]
+		if !ok || repository.Name() == keptDirName {
+			if err := os.RemoveAll(repositoryDir); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tags, err := os.ReadDir(repositoryDir)
+		if err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if strings.HasPrefix(tag.Name(), stagingDirPrefix) || !manifest.GreaterThan(tag.Name(), installedVersion) {
+				if err := os.RemoveAll(filepath.Join(repositoryDir, tag.Name())); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Returns whether the blob of the descriptor is part of a staged package, so that it is not downloaded on install.
+func (r *StagingAddOnRegistry) Contains(desc ocispec.Descriptor) bool {
+	if err := desc.Digest.Validate(); err != nil {
+		return false
+	}
+
+	paths, err := filepath.Glob(filepath.Join(r.root, "*", "*", desc.Digest.Encoded()))
+	if err != nil {
+		return false
+	}
+	for _, path := range paths {
+		if strings.HasPrefix(filepath.Base(filepath.Dir(path)), stagingDirPrefix) {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && info.Size() == desc.Size {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the index of the package of repository and tag in dir, false if the package is not staged completely.
+func (r *StagingAddOnRegistry) stagedIndexOf(dir string, repository string, tag string) (stagedIndex, bool) {
+	content, err := os.ReadFile(filepath.Join(dir, stagedIndexFileName))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Warnf("Unable to read the staged package '%s:%s': %v", repository, tag, err)
+		}
+		return stagedIndex{}, false
+	}
+
+	index := stagedIndex{}
+	if err := json.Unmarshal(content, &index); err != nil {
+		log.Warnf("Invalid staged package '%s:%s': %v", repository, tag, err)
+		return stagedIndex{}, false
+	}
+	for _, layer := range index.Layers {
+		info, err := os.Stat(filepath.Join(dir, layer.Digest.Encoded()))
+		if err != nil || info.Size() != layer.Size {
+			return stagedIndex{}, false
+		}
+	}
+	return index, true
+}
+
+// Removes all staged packages in the directory of a repository, except the ones being staged.
+// MUST be called under the lock.
+func (r *StagingAddOnRegistry) removeStagedPackages(repositoryDir string) error {
+	entries, err := os.ReadDir(repositoryDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), stagingDirPrefix) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(repositoryDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *StagingAddOnRegistry) pathOf(repository string, tag string) string {
+	return filepath.Join(r.root, repository, tag)
+}
+
+func (r *StagingAddOnRegistry) keptPathOf(repository string, tag string) string {
+	return filepath.Join(r.root, keptDirName, repository, tag)
+}
+
+// StagedBlob reads a docker image layer of a staged package.
+type StagedBlob struct {
+	*os.File
+	dir string
+}
+
+// Closes the blob and removes the staged package, once its content is installed.
+func (b *StagedBlob) Remove() error {
+	b.Close()
+	return os.RemoveAll(b.dir)
+}
+
+// Writes every layer passed to Action to the directory and verifies it against the descriptor passed to Filter before.
+type stagingProcessor struct {
+	dir      string
+	pending  *ocispec.Descriptor
+	filtered int
+	layers   []ocispec.Descriptor
+	size     uint64
+	err      error
+}
+
+func (p *stagingProcessor) Filter(desc *ocispec.Descriptor) bool {
+	pending := *desc
+	p.pending = &pending
+	p.filtered++
+	return true
+}
+
+func (p *stagingProcessor) Action(src io.Reader, mediaType string) {
+	defer releaseLayer(src)
+
+	desc := p.pending
+	p.pending = nil
+	if p.err != nil || desc == nil {
+		return
+	}
+
+	if err := p.write(src, *desc); err != nil {
+		p.err = err
+		return
+	}
+	p.layers = append(p.layers, *desc)
+	p.size += uint64(desc.Size)
+}
+
+func (p *stagingProcessor) write(src io.Reader, desc ocispec.Descriptor) error {
+	if err := desc.Digest.Validate(); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(p.dir, desc.Digest.Encoded()))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, io.LimitReader(src, desc.Size+1)); err != nil {
+		return err
+	}
+	return verifyBlob(file, desc)
+}
+
+// Returns an error unless every layer of the package has been written and verified.
+func (p *stagingProcessor) verify() error {
+	if p.err != nil {
+		return p.err
+	}
+	if len(p.layers) != p.filtered {
+		return fmt.Errorf("%d of %d layers could not be downloaded", p.filtered-len(p.layers), p.filtered)
+	}
+	return nil
+}
+
+// Releases the downloaded layer once it is staged, a cached blob is not kept twice.
+func releaseLayer(src io.Reader) {
+	if cached, ok := src.(*CachedBlob); ok {
+		if err := cached.Remove(); err != nil {
+			log.Warnf("Unable to remove the cached blob: %v", err)
+		}
+		return
+	}
+	if closer, ok := src.(io.Closer); ok {
+		closer.Close()
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"u-control/uc-aom/internal/aom/registry"
+	"u-control/uc-aom/internal/pkg/config"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testLayer struct {
+	descriptor ocispec.Descriptor
+	content    []byte
+}
+
+func newTestLayer(mediaType string, content string) testLayer {
+	return testLayer{
+		descriptor: ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromString(content), Size: int64(len(content))},
+		content:    []byte(content),
+	}
+}
+
+// Serves the layers on pull as the ORAS add-on registry does.
+func servePull(layers ...testLayer) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		processor := args.Get(2).(registry.ImageManifestLayerProcessor)
+		for _, layer := range layers {
+			descriptor := layer.descriptor
+			if processor.Filter(&descriptor) {
+				processor.Action(bytes.NewReader(layer.content), descriptor.MediaType)
+			}
+		}
+	}
+}
+
+type layerCollector struct {
+	contents map[string]string
+	readers  []io.Reader
+}
+
+func (c *layerCollector) Filter(desc *ocispec.Descriptor) bool {
+	return true
+}
+
+func (c *layerCollector) Action(src io.Reader, mediaType string) {
+	content, _ := io.ReadAll(src)
+	c.contents[mediaType] = string(content)
+	c.readers = append(c.readers, src)
+}
+
+func TestStagingAddOnRegistryPullsStagedPackage(t *testing.T) {
+	// Arrange
+	manifestLayer := newTestLayer(config.UcImageLayerMediaType, "manifest")
+	imageLayer := newTestLayer("application/vnd.oci.image.layer.v1.tar", "image")
+	upstream := &registry.MockRegistry{}
+	upstream.On("Pull", "addon", "1.0.0-2", mock.Anything).Run(servePull(manifestLayer, imageLayer)).Return(uint64(42), nil).Once()
+	root := t.TempDir()
+	uut := registry.NewStagingAddOnRegistry(root, upstream)
+
+	// Act
+	stagedSize, stageErr := uut.Stage(context.Background(), "addon", "1.0.0-2")
+	collector := &layerCollector{contents: make(map[string]string)}
+	installSize, pullErr := uut.Pull(context.Background(), "addon", "1.0.0-2", collector)
+
+	// Assert
+	assert.NoError(t, stageErr)
+	assert.NoError(t, pullErr)
+	assert.Equal(t, uint64(len("manifest")+len("image")), stagedSize)
+	assert.Equal(t, uint64(42), installSize)
+	assert.Equal(t, map[string]string{config.UcImageLayerMediaType: "manifest", "application/vnd.oci.image.layer.v1.tar": "image"}, collector.contents)
+	upstream.AssertNumberOfCalls(t, "Pull", 1)
+
+	stagedBlob, ok := collector.readers[1].(*registry.StagedBlob)
+	assert.True(t, ok)
+	assert.NoError(t, stagedBlob.Remove())
+	assert.NoDirExists(t, filepath.Join(root, "addon", "1.0.0-2"))
+}
+
+func TestStagingAddOnRegistryPullsOtherVersionsFromUpstream(t *testing.T) {
+	// Arrange
+	upstream := &registry.MockRegistry{}
+	upstream.On("Pull", "addon", "1.0.0-2", mock.Anything).Run(servePull(newTestLayer("application/vnd.oci.image.layer.v1.tar", "image"))).Return(uint64(42), nil)
+	upstream.On("Pull", "addon", "1.0.0-3", mock.Anything).Return(uint64(7), nil)
+	uut := registry.NewStagingAddOnRegistry(t.TempDir(), upstream)
+	_, err := uut.Stage(context.Background(), "addon", "1.0.0-2")
+	assert.NoError(t, err)
+
+	// Act
+	size, pullErr := uut.Pull(context.Background(), "addon", "1.0.0-3", &layerCollector{contents: make(map[string]string)})
+
+	// Assert
+	assert.NoError(t, pullErr)
+	assert.Equal(t, uint64(7), size)
+	upstream.AssertCalled(t, "Pull", "addon", "1.0.0-3", mock.Anything)
+}
+
+func TestStagingAddOnRegistryRejectsCorruptLayer(t *testing.T) {
+	// Arrange
+	corrupt := newTestLayer("application/vnd.oci.image.layer.v1.tar", "image")
+	corrupt.content = []byte("imagf")
+	upstream := &registry.MockRegistry{}
+	upstream.On("Pull", "addon", "1.0.0-2", mock.Anything).Run(servePull(corrupt)).Return(uint64(42), nil)
+	root := t.TempDir()
+	uut := registry.NewStagingAddOnRegistry(root, upstream)
+
+	// Act
+	_, err := uut.Stage(context.Background(), "addon", "1.0.0-2")
+
+	// Assert
+	assert.Error(t, err)
+	entries, _ := os.ReadDir(filepath.Join(root, "addon"))
+	assert.Empty(t, entries)
+}
+
+func TestStagingAddOnRegistryRejectsIncompleteDownload(t *testing.T) {
+	// Arrange
+	upstream := &registry.MockRegistry{}
+	upstream.On("Pull", "addon", "1.0.0-2", mock.Anything).Run(func(args mock.Arguments) {
+		processor := args.Get(2).(registry.ImageManifestLayerProcessor)
+		skipped := newTestLayer("application/vnd.oci.image.layer.v1.tar", "image").descriptor
+		processor.Filter(&skipped)
+	}).Return(uint64(42), nil)
+	uut := registry.NewStagingAddOnRegistry(t.TempDir(), upstream)
+
+	// Act
+	_, err := uut.Stage(context.Background(), "addon", "1.0.0-2")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestStagingAddOnRegistryReplacesStagedPackage(t *testing.T) {
+	// Arrange
+	upstream := &registry.MockRegistry{}
+	upstream.On("Pull", "addon", mock.Anything, mock.Anything).Run(servePull(newTestLayer("application/vnd.oci.image.layer.v1.tar", "image"))).Return(uint64(42), nil)
+	upstream.On("Pull", "other", "2.0.0-1", mock.Anything).Return(uint64(0), errors.New("unreachable"))
+	root := t.TempDir()
+	uut := registry.NewStagingAddOnRegistry(root, upstream)
+
+	// Act
+	_, firstErr := uut.Stage(context.Background(), "addon", "1.0.0-2")
+	_, secondErr := uut.Stage(context.Background(), "addon", "1.0.0-3")
+	_, failedErr := uut.Stage(context.Background(), "other", "2.0.0-1")
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Error(t, failedErr)
+	assert.NoDirExists(t, filepath.Join(root, "addon", "1.0.0-2"))
+	assert.DirExists(t, filepath.Join(root, "addon", "1.0.0-3"))
+}
+
+func TestStagingAddOnRegistryKeepsPackageApartFromStagedUpdates(t *testing.T) {
+	// Arrange
+	upstream := &registry.MockRegistry{}
+	upstream.On("Pull", "addon", mock.Anything, mock.Anything).Run(servePull(newTestLayer("application/vnd.oci.image.layer.v1.tar", "image"))).Return(uint64(42), nil)
+	root := t.TempDir()
+	uut := registry.NewStagingAddOnRegistry(root, upstream)
+
+	// Act
+	_, keepErr := uut.Keep(context.Background(), "addon", "1.0.0-1")
+	_, stageErr := uut.Stage(context.Background(), "addon", "1.0.0-3")
+	discardErr := uut.Discard("addon")
+	_, pullErr := uut.Pull(context.Background(), "addon", "1.0.0-1", &layerCollector{contents: make(map[string]string)})
+	releaseErr := uut.Release("addon", "1.0.0-1")
+
+	// Assert
+	assert.NoError(t, keepErr)
+	assert.NoError(t, stageErr)
+	assert.NoError(t, discardErr)
+	assert.NoError(t, pullErr)
+	assert.NoError(t, releaseErr)
+	upstream.AssertNumberOfCalls(t, "Pull", 2)
+	assert.NoDirExists(t, filepath.Join(root, "addon", "1.0.0-3"))
+	assert.NoDirExists(t, filepath.Join(root, ".kept", "addon", "1.0.0-1"))
+}
+
+func TestStagingAddOnRegistryChecksDiskSpaceBeforeDownload(t *testing.T) {
+	// Arrange
+	upstream := &registry.MockRegistry{}
+	uut := registry.NewStagingAddOnRegistry(t.TempDir(), upstream)
+	uut.UseDiskSpaceCheck(func(repository string, tag string) error { return errors.New("Insufficient disk space") })
+
+	// Act
+	_, err := uut.Stage(context.Background(), "addon", "1.0.0-2")
+
+	// Assert
+	assert.Error(t, err)
+	upstream.AssertNotCalled(t, "Pull", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStagingAddOnRegistryContainsStagedLayers(t *testing.T) {
+	// Arrange
+	imageLayer := newTestLayer("application/vnd.oci.image.layer.v1.tar", "image")
+	upstream := &registry.MockRegistry{}
+	upstream.On("Pull", "addon", "1.0.0-2", mock.Anything).Run(servePull(imageLayer)).Return(uint64(42), nil)
+	uut := registry.NewStagingAddOnRegistry(t.TempDir(), upstream)
+
+	// Act
+	containsBefore := uut.Contains(imageLayer.descriptor)
+	_, err := uut.Stage(context.Background(), "addon", "1.0.0-2")
+	containsAfter := uut.Contains(imageLayer.descriptor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, containsBefore)
+	assert.True(t, containsAfter)
+	assert.False(t, uut.Contains(newTestLayer("application/vnd.oci.image.layer.v1.tar", "other").descriptor))
+}
+
+func TestStagingAddOnRegistryRetainsUpdatesOfInstalledAddOns(t *testing.T) {
+	// Arrange
+	upstream := &registry.MockRegistry{}
+	upstream.On("Pull", mock.Anything, mock.Anything, mock.Anything).Run(servePull(newTestLayer("application/vnd.oci.image.layer.v1.tar", "image"))).Return(uint64(42), nil)
+	root := t.TempDir()
+	uut := registry.NewStagingAddOnRegistry(root, upstream)
+	for _, repository := range []string{"updated", "pending", "deleted"} {
+		_, err := uut.Stage(context.Background(), repository, "1.0.0-2")
+		assert.NoError(t, err)
+	}
+
+	// Act
+	err := uut.RetainUpdatesOf(map[string]string{"updated": "1.0.0-2", "pending": "1.0.0-1"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(root, "updated", "1.0.0-2"))
+	assert.DirExists(t, filepath.Join(root, "pending", "1.0.0-2"))
+	assert.NoDirExists(t, filepath.Join(root, "deleted"))
+}
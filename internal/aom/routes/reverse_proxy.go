@@ -198,28 +198,62 @@ func NewReverseProxy(
 		reloadTimeout:          reloadTimeout}
 }
 
+// The files of a route as they would be written by Create.
+type RenderedRoute struct {
+	ConfigFilepath string
+	Config         string
+	MapFilepath    string
+	Map            string
+}
+
+// Renders the files of the route without writing them.
+func (r *ReverseProxy) Render(filenameId string, reverseProxyMap *ReverseProxyMap, reverseProxyHttpConf *ReverseProxyHttpConf) (*RenderedRoute, error) {
+	var config, routesMap strings.Builder
+	if err := r.executeConfigTemplate(&config, reverseProxyHttpConf); err != nil {
+		return nil, err
+	}
+
+	mapCopy := *reverseProxyMap
+	if err := r.executeMapTemplate(&routesMap, &mapCopy); err != nil {
+		return nil, err
+	}
+
+	return &RenderedRoute{
+		ConfigFilepath: r.getSitesAvailableFilepath(filenameId),
+		Config:         config.String(),
+		MapFilepath:    r.getRoutesMapAvailableFilepath(filenameId),
+		Map:            routesMap.String(),
+	}, nil
+}
+
+func (r *ReverseProxy) executeConfigTemplate(writer io.Writer, reverseProxyHttpConf *ReverseProxyHttpConf) error {
+	reverseProxyHttpConfWithMetadata := ReverseProxyHttpConfWithMetadata{
+		ReverseProxyHttpConf: reverseProxyHttpConf,
+		metadata:             newMetaData(),
+	}
+	return r.configTemplate.Execute(writer, &reverseProxyHttpConfWithMetadata)
+}
+
+func (r *ReverseProxy) executeMapTemplate(writer io.Writer, reverseProxyMap *ReverseProxyMap) error {
+	reverseProxyMap.To = strings.ReplaceAll(reverseProxyMap.To, "/", "/+")
+	reverseProxyMapWithMetadata := reverseProxyMapWithMetadata{
+		ReverseProxyMap: reverseProxyMap,
+		metadata:        newMetaData(),
+	}
+	return r.mapTemplate.Execute(writer, &reverseProxyMapWithMetadata)
+}
+
 func (r *ReverseProxy) Create(filenameId string, reverseProxyMap *ReverseProxyMap, reverseProxyHttpConf *ReverseProxyHttpConf) error {
 	log.Tracef("ReverseProxy/Create")
 	configFilepath := r.getSitesAvailableFilepath(filenameId)
 	mapFilepath := r.getRoutesMapAvailableFilepath(filenameId)
 
 	executeConfigTemplate := func(writer io.Writer) error {
-		reverseProxyHttpConfWithMetadata := ReverseProxyHttpConfWithMetadata{
-			ReverseProxyHttpConf: reverseProxyHttpConf,
-			metadata:             newMetaData(),
-		}
-		err := r.configTemplate.Execute(writer, &reverseProxyHttpConfWithMetadata)
-		return err
+		return r.executeConfigTemplate(writer, reverseProxyHttpConf)
 	}
 
 	executeMapTemplate := func(writer io.Writer) error {
-		reverseProxyMap.To = strings.ReplaceAll(reverseProxyMap.To, "/", "/+")
-		reverseProxyMapWithMetadata := reverseProxyMapWithMetadata{
-			ReverseProxyMap: reverseProxyMap,
-			metadata:        newMetaData(),
-		}
-		err := r.mapTemplate.Execute(writer, &reverseProxyMapWithMetadata)
-		return err
+		return r.executeMapTemplate(writer, reverseProxyMap)
 	}
 
 	err := r.writeToFile(configFilepath, executeConfigTemplate)
@@ -0,0 +1,114 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"strings"
+	"u-control/uc-aom/internal/aom/catalogue"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+	"u-control/uc-aom/internal/aom/service"
+)
+
+// Selects add-ons by category, search, status, compatibility and available update.
+type addOnFilter struct {
+	category        string
+	words           []string
+	compatibleOnly  bool
+	statuses        []grpc_api.AddOnStatus
+	updateAvailable bool
+}
+
+// Returns the add-ons which match the filter, all add-ons if the filter is empty.
+// updatable holds the names of the add-ons with an update, it is only read if the filter selects them.
+func (f *addOnFilter) filter(addOns []*grpc_api.AddOn, updatable map[string]bool) []*grpc_api.AddOn {
+	filtered := make([]*grpc_api.AddOn, 0, len(addOns))
+	for _, addOn := range addOns {
+		if f.matches(addOn, updatable) {
+			filtered = append(filtered, addOn)
+		}
+	}
+	return filtered
+}
+
+// Returns the installed add-ons which the filter may select,
+// the manifest is required to check the compatibility with the device.
+// Add-ons of the remote catalogue are selected by their evaluated compatibility instead.
+func (f *addOnFilter) selectInstalledAddOns(catalogueAddOns []*catalogue.CatalogueAddOn) []*catalogue.CatalogueAddOn {
+	if !f.compatibleOnly {
+		return catalogueAddOns
+	}
+
+	selected := make([]*catalogue.CatalogueAddOn, 0, len(catalogueAddOns))
+	for _, addOn := range catalogueAddOns {
+		if service.IsPlatformSupported(addOn.Manifest.Platform) {
+			selected = append(selected, addOn)
+		}
+	}
+	return selected
+}
+
+func (f *addOnFilter) matches(addOn *grpc_api.AddOn, updatable map[string]bool) bool {
+	if f.category != "" && !containsFold(addOn.Categories, f.category) {
+		return false
+	}
+	if len(f.statuses) > 0 && !containsStatus(f.statuses, addOn.Status) {
+		return false
+	}
+	// Only add-ons of the remote catalogue carry their compatibility, installed add-ons are selected by selectInstalledAddOns
+	if f.compatibleOnly && addOn.Compatibility != nil && !addOn.Compatibility.Compatible {
+		return false
+	}
+	if f.updateAvailable && !updatable[addOn.Name] {
+		return false
+	}
+	return matchesSearch(addOn, f.words)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStatus(statuses []grpc_api.AddOnStatus, value grpc_api.AddOnStatus) bool {
+	for _, candidate := range statuses {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// The search matches if each of its words is part of the name, title, description, vendor or a keyword of the add-on, ignoring the case.
+func matchesSearch(addOn *grpc_api.AddOn, words []string) bool {
+	if len(words) == 0 {
+		return true
+	}
+
+	texts := append([]string{addOn.Name, addOn.Title, addOn.Description}, addOn.Keywords...)
+	if addOn.Vendor != nil {
+		texts = append(texts, addOn.Vendor.Name)
+	}
+	for i := range texts {
+		texts[i] = strings.ToLower(texts[i])
+	}
+
+	for _, word := range words {
+		found := false
+		for _, text := range texts {
+			if strings.Contains(text, word) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Upper bound of the add-ons per page, larger page sizes are reduced to it
+const maxListAddOnsPageSize = 100
+
+// Query of the ListAddOns request, which filters, sorts and pages the listed add-ons.
+type addOnQuery struct {
+	addOnFilter
+	sortBy     grpc_api.ListAddOnsRequest_SortBy
+	descending bool
+	pageSize   int
+	offset     int
+
+	// Identifies the query of a page token, so that a token is only accepted by the query which issued it
+	fingerprint string
+
+	// Digest of the add-ons the page token was issued for, empty for the first page
+	snapshot string
+}
+
+// Position of the next page, encoded as opaque page token
+type pageToken struct {
+	Offset      int    `json:"offset"`
+	Fingerprint string `json:"query"`
+	Snapshot    string `json:"snapshot"`
+}
+
+// Returns the query of the request, an InvalidArgument error if the request is invalid.
+func newAddOnQuery(request *grpc_api.ListAddOnsRequest) (*addOnQuery, error) {
+	if request.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "The page size must not be negative")
+	}
+	if len(request.Statuses) > 0 && request.Filter != grpc_api.ListAddOnsRequest_INSTALLED {
+		return nil, status.Error(codes.InvalidArgument, "Only installed add-ons have a status")
+	}
+
+	query := &addOnQuery{
+		addOnFilter: addOnFilter{
+			category:        request.Category,
+			words:           strings.Fields(strings.ToLower(request.Search)),
+			compatibleOnly:  request.CompatibleOnly,
+			statuses:        request.Statuses,
+			updateAvailable: request.UpdateAvailable,
+		},
+		sortBy:     request.SortBy,
+		descending: request.Descending,
+		pageSize:   int(request.PageSize),
+	}
+	if query.pageSize > maxListAddOnsPageSize {
+		query.pageSize = maxListAddOnsPageSize
+	}
+
+	fingerprint := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%v|%t|%v|%t|%d|%t",
+		request.Filter, strings.ToLower(query.category), query.words, query.compatibleOnly, query.statuses, query.updateAvailable, query.sortBy, query.descending)))
+	query.fingerprint = hex.EncodeToString(fingerprint[:8])
+
+	if request.PageToken != "" {
+		if err := query.parsePageToken(request.PageToken); err != nil {
+			return nil, err
+		}
+	}
+	return query, nil
+}
+
+func (q *addOnQuery) parsePageToken(token string) error {
+	content, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "Invalid page token")
+	}
+	var parsed pageToken
+	if err := json.Unmarshal(content, &parsed); err != nil || parsed.Offset < 0 || parsed.Snapshot == "" {
+		return status.Error(codes.InvalidArgument, "Invalid page token")
+	}
+	if parsed.Fingerprint != q.fingerprint {
+		return status.Error(codes.InvalidArgument, "The page token belongs to another query")
+	}
+	q.offset = parsed.Offset
+	q.snapshot = parsed.Snapshot
+	return nil
+}
+
+func (q *addOnQuery) createPageToken(offset int, snapshot string) string {
+	content, _ := json.Marshal(pageToken{Offset: offset, Fingerprint: q.fingerprint, Snapshot: snapshot})
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+// Returns the page of the filtered and sorted add-ons.
+// updatable holds the names of the add-ons with an update, it is only read if the query selects them.
+// Returns an Aborted error if the add-ons changed since the page token was issued, because the pages would skip or repeat add-ons.
+func (q *addOnQuery) evaluate(addOns []*grpc_api.AddOn, updatable map[string]bool) (*grpc_api.ListAddOnsResponse, error) {
+	selected := q.filter(addOns, updatable)
+	q.sort(selected)
+
+	snapshot := snapshotOf(selected)
+	if q.snapshot != "" && q.snapshot != snapshot {
+		return nil, status.Error(codes.Aborted, "The add-ons changed since the page token was issued, list them from the first page")
+	}
+
+	response := &grpc_api.ListAddOnsResponse{TotalSize: int32(len(selected))}
+	if q.offset >= len(selected) {
+		response.AddOns = []*grpc_api.AddOn{}
+		return response, nil
+	}
+
+	end := len(selected)
+	if q.pageSize > 0 && q.offset+q.pageSize < end {
+		end = q.offset + q.pageSize
+		response.NextPageToken = q.createPageToken(end, snapshot)
+	}
+	response.AddOns = selected[q.offset:end]
+	return response, nil
+}
+
+// Returns the digest of the names and versions of the add-ons in their order.
+func snapshotOf(addOns []*grpc_api.AddOn) string {
+	hash := sha256.New()
+	for _, addOn := range addOns {
+		fmt.Fprintf(hash, "%s@%s\n", addOn.Name, addOn.Version)
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
+
+// Sorts the add-ons by the sort key ignoring the case, add-ons with the same key by name.
+func (q *addOnQuery) sort(addOns []*grpc_api.AddOn) {
+	key := func(addOn *grpc_api.AddOn) string {
+		switch q.sortBy {
+		case grpc_api.ListAddOnsRequest_NAME:
+			return addOn.Name
+		case grpc_api.ListAddOnsRequest_VENDOR:
+			if addOn.Vendor == nil {
+				return ""
+			}
+			return strings.ToLower(addOn.Vendor.Name)
+		default:
+			return strings.ToLower(addOn.Title)
+		}
+	}
+
+	sort.SliceStable(addOns, func(i, j int) bool {
+		first, second := key(addOns[i]), key(addOns[j])
+		if first == second {
+			first, second = addOns[i].Name, addOns[j].Name
+		}
+		if q.descending {
+			return first > second
+		}
+		return first < second
+	})
+}
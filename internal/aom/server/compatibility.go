@@ -0,0 +1,95 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"u-control/uc-aom/internal/aom/catalogue"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/aom/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var grpcIncompatibilityReasons = map[service.IncompatibilityReason]grpc_api.IncompatibilityReason{
+	service.InvalidManifest:            grpc_api.IncompatibilityReason_INVALID_MANIFEST,
+	service.UnsupportedManifestVersion: grpc_api.IncompatibilityReason_UNSUPPORTED_MANIFEST_VERSION,
+	service.UnsupportedPlatform:        grpc_api.IncompatibilityReason_UNSUPPORTED_PLATFORM,
+	service.MissingFeature:             grpc_api.IncompatibilityReason_MISSING_FEATURE,
+	service.InsufficientDiskSpace:      grpc_api.IncompatibilityReason_INSUFFICIENT_DISK_SPACE,
+	service.ExceedsResourceBudget:      grpc_api.IncompatibilityReason_EXCEEDS_RESOURCE_BUDGET,
+	service.OperationNotPermitted:      grpc_api.IncompatibilityReason_OPERATION_NOT_PERMITTED,
+}
+
+// Maximum number of catalogue add-ons whose compatibility is evaluated at the same time,
+// the evaluation of the disk space fetches the footprint from the registry.
+const maxConcurrentEvaluations = 4
+
+// Returns the compatibility of the catalogue add-on with the device.
+// The disk space is only evaluated if withDiskSpace is set, because the footprint of the add-on is fetched from the registry.
+// An add-on whose compatibility cannot be evaluated is not compatible, the reason is NOT_EVALUATED.
+// allocated are the resources of the installed add-ons, taken for the add-on if nil.
+func (s *AddOnServer) evaluateCompatibility(addOn *catalogue.CatalogueAddOn, allocated *service.AllocatedResources, withDiskSpace bool) *grpc_api.Compatibility {
+	incompatibilities, err := s.service.CheckCompatibilityWith(addOn, allocated)
+	if err != nil {
+		log.Warnf("Unable to evaluate the compatibility of '%s': %v", addOn.Name, err)
+		return notEvaluatedCompatibility(err)
+	}
+
+	if withDiskSpace {
+		incompatibility, err := s.service.CheckDiskSpaceCompatibility(addOn)
+		if err != nil {
+			log.Warnf("Unable to evaluate the disk space of '%s': %v", addOn.Name, err)
+			return notEvaluatedCompatibility(err)
+		}
+		if incompatibility != nil {
+			incompatibilities = append(incompatibilities, incompatibility)
+		}
+	}
+
+	return mapIncompatibilitiesToGrpcCompatibility(incompatibilities)
+}
+
+// Evaluates the compatibility of the catalogue add-ons concurrently and stores it in the add-ons at the same index.
+// The resources of the installed add-ons are taken once for all add-ons.
+func (s *AddOnServer) evaluateCompatibilities(ctx context.Context, catalogueAddOns []*catalogue.CatalogueAddOn, addOns []*grpc_api.AddOn, withDiskSpace bool) error {
+	allocated, err := s.service.AllocatedResources()
+	if err != nil {
+		log.Warnf("Unable to evaluate the compatibility of the catalogue add-ons: %v", err)
+		for _, addOn := range addOns {
+			addOn.Compatibility = notEvaluatedCompatibility(err)
+		}
+		return nil
+	}
+
+	return utils.ForEachConcurrently(ctx, maxConcurrentEvaluations, len(addOns), func(i int) {
+		addOns[i].Compatibility = s.evaluateCompatibility(catalogueAddOns[i], allocated, withDiskSpace)
+	})
+}
+
+func notEvaluatedCompatibility(err error) *grpc_api.Compatibility {
+	return &grpc_api.Compatibility{
+		Compatible: false,
+		Incompatibilities: []*grpc_api.Incompatibility{{
+			Reason:  grpc_api.IncompatibilityReason_NOT_EVALUATED,
+			Message: err.Error(),
+		}},
+	}
+}
+
+func mapIncompatibilitiesToGrpcCompatibility(incompatibilities []*service.Incompatibility) *grpc_api.Compatibility {
+	compatibility := &grpc_api.Compatibility{
+		Compatible:        len(incompatibilities) == 0,
+		Incompatibilities: make([]*grpc_api.Incompatibility, len(incompatibilities)),
+	}
+	for i, incompatibility := range incompatibilities {
+		compatibility.Incompatibilities[i] = &grpc_api.Incompatibility{
+			Reason:  grpcIncompatibilityReasons[incompatibility.Reason],
+			Message: incompatibility.Message,
+		}
+	}
+	return compatibility
+}
@@ -6,7 +6,12 @@ package server
 
 import (
 	"errors"
+	"strconv"
+	"u-control/uc-aom/internal/aom/budget"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/registry"
 	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/aom/update"
 
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
@@ -21,8 +26,44 @@ const (
 	update_downgrade_error             = "UPDATE_DOWNGRADE_ERROR"
 	feature_root_access_not_enabled    = "FEATURE_ROOT_ACCESS_NOT_ENABLED"
 	not_enough_disk_space              = "NOT_ENOUGH_DISK_SPACE"
+	operation_not_permitted            = "OPERATION_NOT_PERMITTED"
+	resource_budget_exceeded           = "RESOURCE_BUDGET_EXCEEDED"
 )
 
+func convertCatalogueSourceError(err error) error {
+	if errors.Is(err, registry.ErrCatalogueSourceNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if _, ok := err.(*registry.InvalidCatalogueSourceError); ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
+
+func convertRegistryCredentialsError(err error) error {
+	if errors.Is(err, registry.ErrRegistryCredentialsNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if _, ok := err.(*registry.InvalidRegistryCredentialsError); ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
+
+func convertReleaseChannelError(err error) error {
+	if _, ok := err.(*catalogue.InvalidReleaseChannelError); ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
+
+func convertUpdatePolicyError(err error) error {
+	if _, ok := err.(*update.InvalidUpdatePolicyError); ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
+
 func convertToGrpcError(err error) error {
 	if errors.Is(err, service.ErrorAddOnAlreadyInstalled) {
 		return status.Error(codes.AlreadyExists, err.Error())
@@ -39,6 +80,12 @@ func convertToGrpcError(err error) error {
 	if notEnoughDiskSpace, ok := err.(*service.NotEnoughDiskSpaceError); ok {
 		return ConvertToGrpcNotEnoughDiskSpaceError(notEnoughDiskSpace)
 	}
+	if operationNotPermitted, ok := err.(*service.OperationNotPermittedError); ok {
+		return ConvertToGrpcOperationNotPermittedError(operationNotPermitted)
+	}
+	if budgetExceeded, ok := err.(*budget.ExceededError); ok {
+		return ConvertToGrpcResourceBudgetExceededError(budgetExceeded)
+	}
 
 	return status.Error(codes.FailedPrecondition, err.Error())
 }
@@ -53,9 +100,20 @@ func ConvertToGrpcInvalidManifestVersionError(err error) error {
 	return createInvalidArgumentGrpcStatusErrorWithReasonAndError(invalid_manifest_version, err)
 }
 
-// Generates a remote registry connection error
+// Generates a remote registry connection error,
+// the error info metadata contains the failed stage of the connection, i.e. DNS, TCP, PROXY, TLS, AUTH or UNKNOWN.
 func ConvertToGrpcRemoteRegistryConnectionError(err error) error {
-	return createInvalidArgumentGrpcStatusErrorWithReasonAndError(remote_registry_connection_problem, err)
+	errorInfo := newErrorInfo(remote_registry_connection_problem)
+	var connectionProblem *catalogue.RemoteRegistryConnectionError
+	if errors.As(err, &connectionProblem) {
+		errorInfo.Metadata = map[string]string{"failure": string(connectionProblem.Failure())}
+	}
+
+	statusWithDetails, detailsErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(errorInfo)
+	if detailsErr != nil {
+		return detailsErr
+	}
+	return statusWithDetails.Err()
 }
 
 // Generates a update downgrade error
@@ -75,8 +133,61 @@ func ConvertToGrpcRootAccessNotEnabledError(err error) error {
 	return createInvalidArgumentGrpcStatusErrorWithReasonAndError(feature_root_access_not_enabled, err)
 }
 
+// Generates a not enough disk space error,
+// the error info metadata contains the breakdown of the required disk space if available.
 func ConvertToGrpcNotEnoughDiskSpaceError(err error) error {
-	return createResourceExhaustedGrpcStatusWithReasonAndError(not_enough_disk_space, err)
+	errorInfo := newErrorInfo(not_enough_disk_space)
+	if notEnoughDiskSpace, ok := err.(*service.NotEnoughDiskSpaceError); ok && notEnoughDiskSpace.Plan != nil {
+		errorInfo.Metadata = newDiskPlanMetadata(notEnoughDiskSpace.AvailableBytes, notEnoughDiskSpace.Plan)
+	}
+
+	statusWithDetails, detailsErr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(errorInfo)
+	if detailsErr != nil {
+		return detailsErr
+	}
+	return statusWithDetails.Err()
+}
+
+func newDiskPlanMetadata(availableBytes uint64, plan *service.DiskPlan) map[string]string {
+	formatBytes := func(value uint64) string {
+		return strconv.FormatUint(value, 10)
+	}
+
+	return map[string]string{
+		"availableBytes":    formatBytes(availableBytes),
+		"requiredBytes":     formatBytes(plan.RequiredBytes()),
+		"imageBytes":        formatBytes(plan.ImageBytes),
+		"presentLayerBytes": formatBytes(plan.PresentLayerBytes),
+		"archiveBytes":      formatBytes(plan.ArchiveBytes),
+		"cacheBytes":        formatBytes(plan.CacheBytes),
+		"stagingBytes":      formatBytes(plan.StagingBytes),
+		"volumeBytes":       formatBytes(plan.VolumeBytes),
+		"headroomBytes":     formatBytes(plan.HeadroomBytes),
+		"isEstimated":       strconv.FormatBool(plan.IsEstimated),
+	}
+}
+
+// Generates an operation not permitted error for protected add-ons.
+// The code stays Unimplemented, which clients already handle for the codesys add-on.
+func ConvertToGrpcOperationNotPermittedError(err error) error {
+	return createUnimplementedGrpcStatusWithReasonAndError(operation_not_permitted, err)
+}
+
+// Generates a resource budget exceeded error,
+// the error info metadata contains the exceeded resource with the requested and available amount.
+func ConvertToGrpcResourceBudgetExceededError(err *budget.ExceededError) error {
+	errorInfo := newErrorInfo(resource_budget_exceeded)
+	errorInfo.Metadata = map[string]string{
+		"resource":  err.Resource,
+		"requested": strconv.FormatUint(err.Requested, 10),
+		"available": strconv.FormatUint(err.Available, 10),
+	}
+
+	statusWithDetails, detailsErr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(errorInfo)
+	if detailsErr != nil {
+		return detailsErr
+	}
+	return statusWithDetails.Err()
 }
 
 func createInvalidArgumentGrpcStatusErrorWithReasonAndError(reason string, err error) error {
@@ -95,8 +206,8 @@ func createInvalidArgumentGrpcStatusWithReasonAndError(reason string, err error)
 	return statusWithDetails, nil
 }
 
-func createResourceExhaustedGrpcStatusWithReasonAndError(reason string, err error) error {
-	statusWithDetails, err := status.New(codes.ResourceExhausted, err.Error()).WithDetails(newErrorInfo(reason))
+func createUnimplementedGrpcStatusWithReasonAndError(reason string, err error) error {
+	statusWithDetails, err := status.New(codes.Unimplemented, err.Error()).WithDetails(newErrorInfo(reason))
 	if err != nil {
 		return err
 	}
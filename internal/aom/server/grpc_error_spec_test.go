@@ -6,10 +6,14 @@ package server
 
 import (
 	"errors"
+	"net"
 	"reflect"
 	"runtime"
 	"strings"
 	"testing"
+	"u-control/uc-aom/internal/aom/budget"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/service"
 
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
@@ -81,6 +85,15 @@ func TestConvertToGrpc(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "OperationNotPermitted",
+			uut:  ConvertToGrpcOperationNotPermittedError,
+			args: args{
+				err:        errors.New("Operation 'delete' is not permitted for the protected add-on 'CODESYS'"),
+				statusCode: codes.Unimplemented,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -93,6 +106,78 @@ func TestConvertToGrpc(t *testing.T) {
 	}
 }
 
+func TestConvertToGrpcNotEnoughDiskSpaceErrorWithDiskPlan(t *testing.T) {
+	// Arrange
+	testError := &service.NotEnoughDiskSpaceError{
+		AvailableBytes: 11,
+		Plan:           &service.DiskPlan{ImageBytes: 10, PresentLayerBytes: 5, ArchiveBytes: 4, VolumeBytes: 2, HeadroomBytes: 1},
+	}
+
+	// Act
+	err := ConvertToGrpcNotEnoughDiskSpaceError(testError)
+
+	// Assert
+	details := status.Convert(err).Details()
+	if len(details) != 1 {
+		t.Fatalf("Expected one detail but got %d", len(details))
+	}
+	info := details[0].(*errdetails.ErrorInfo)
+	want := map[string]string{
+		"availableBytes":    "11",
+		"requiredBytes":     "17",
+		"imageBytes":        "10",
+		"presentLayerBytes": "5",
+		"archiveBytes":      "4",
+		"cacheBytes":        "0",
+		"stagingBytes":      "0",
+		"volumeBytes":       "2",
+		"headroomBytes":     "1",
+		"isEstimated":       "false",
+	}
+	if !reflect.DeepEqual(info.Metadata, want) {
+		t.Errorf("info.Metadata: want %v, got %v", want, info.Metadata)
+	}
+}
+
+func TestConvertToGrpcResourceBudgetExceededError(t *testing.T) {
+	// Arrange
+	resourceBudget := &budget.Budget{MemoryBytes: 100}
+	testError := resourceBudget.Check("Test add-on", []budget.Resources{{MemoryBytes: 60}}, budget.Resources{MemoryBytes: 50})
+
+	// Act
+	err := convertToGrpcError(testError)
+
+	// Assert
+	checkErrorMessage(t, err, testError, codes.ResourceExhausted)
+	info := status.Convert(err).Details()[0].(*errdetails.ErrorInfo)
+	if info.Reason != "RESOURCE_BUDGET_EXCEEDED" {
+		t.Errorf("info.Reason: want %s, got %s", "RESOURCE_BUDGET_EXCEEDED", info.Reason)
+	}
+	want := map[string]string{"resource": "memory", "requested": "50", "available": "40"}
+	if !reflect.DeepEqual(info.Metadata, want) {
+		t.Errorf("info.Metadata: want %v, got %v", want, info.Metadata)
+	}
+}
+
+func TestConvertToGrpcRemoteRegistryConnectionError(t *testing.T) {
+	// Arrange
+	testError := catalogue.NewRemoteRegistryConnectionError(&net.DNSError{Err: "no such host", Name: "registry"})
+
+	// Act
+	err := ConvertToGrpcRemoteRegistryConnectionError(testError)
+
+	// Assert
+	checkErrorMessage(t, err, testError, codes.InvalidArgument)
+	info := status.Convert(err).Details()[0].(*errdetails.ErrorInfo)
+	if info.Reason != "REMOTE_REGISTRY_CONNECTION_PROBLEM" {
+		t.Errorf("info.Reason: want %s, got %s", "REMOTE_REGISTRY_CONNECTION_PROBLEM", info.Reason)
+	}
+	want := map[string]string{"failure": "DNS"}
+	if !reflect.DeepEqual(info.Metadata, want) {
+		t.Errorf("info.Metadata: want %v, got %v", want, info.Metadata)
+	}
+}
+
 func getFunctionName(i interface{}) string {
 	return runtime.FuncForPC(reflect.ValueOf(i).Pointer()).Name()
 }
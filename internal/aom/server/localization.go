@@ -0,0 +1,66 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"sort"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/metadata"
+)
+
+// The language of the untranslated manifest texts
+var defaultLanguage = language.English
+
+// Metadata keys of the Accept-Language header, the gateway prefixes forwarded HTTP headers
+var acceptLanguageKeys = []string{"accept-language", "grpcgateway-accept-language"}
+
+// Returns the languages the client prefers, ordered by preference.
+func getPreferredLanguagesFrom(ctx context.Context) []language.Tag {
+	metadata, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	for _, key := range acceptLanguageKeys {
+		for _, value := range metadata.Get(key) {
+			languages, _, err := language.ParseAcceptLanguage(value)
+			if err == nil && len(languages) > 0 {
+				return languages
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the translation of the text which matches the preferred languages best, the text itself if none matches.
+func localize(text string, translations manifest.Translations, languages []language.Tag) string {
+	if len(translations) == 0 || len(languages) == 0 {
+		return text
+	}
+
+	keys := make([]string, 0, len(translations))
+	for key := range translations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// The matcher falls back to the first supported language
+	supported := []language.Tag{defaultLanguage}
+	texts := []string{text}
+	for _, key := range keys {
+		tag, err := language.Parse(key)
+		if err != nil {
+			continue
+		}
+		supported = append(supported, tag)
+		texts = append(texts, translations[key])
+	}
+
+	_, index, _ := language.NewMatcher(supported).Match(languages...)
+	return texts[index]
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+//go:build dev
+// +build dev
+
+package server
+
+import (
+	"context"
+	"reflect"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/metadata"
+)
+
+// Mock of a server stream of any response type.
+// The typed stream mocks below only add the Send method of their response type.
+type ResponseStreamMock struct {
+	mock.Mock
+
+	// Channel of the response type which receives the sent responses, nil to not capture them
+	capture interface{}
+}
+
+func (r *ResponseStreamMock) Context() context.Context {
+	args := r.Called()
+	return args.Get(0).(context.Context)
+}
+
+func (r *ResponseStreamMock) RecvMsg(m interface{}) error {
+	args := r.Called(m)
+	return args.Error(0)
+}
+
+func (r *ResponseStreamMock) SendHeader(md metadata.MD) error {
+	args := r.Called(md)
+	return args.Error(0)
+}
+
+func (r *ResponseStreamMock) SendMsg(m interface{}) error {
+	args := r.Called(m)
+	return args.Error(0)
+}
+
+func (r *ResponseStreamMock) SetHeader(md metadata.MD) error {
+	args := r.Called(md)
+	return args.Error(0)
+}
+
+func (r *ResponseStreamMock) SetTrailer(md metadata.MD) {
+	r.Called(md)
+}
+
+func (r *ResponseStreamMock) send(response interface{}) error {
+	if capture := reflect.ValueOf(r.capture); capture.IsValid() && !capture.IsNil() {
+		capture.Send(reflect.ValueOf(response))
+	}
+	args := r.MethodCalled("Send", response)
+	return args.Error(0)
+}
+
+// Sets up the stream with the context of a caller who is allowed to manage add-ons.
+func (r *ResponseStreamMock) allowCaller(ctx context.Context, iamClientMock *IamClientMock) {
+	md := metadata.New(map[string]string{"authorization": "Bearer 12345"})
+	ctx = metadata.NewIncomingContext(ctx, md)
+	r.On("Context").Return(ctx)
+	iamClientMock.On("IsAllowed", "12345", mock.AnythingOfType("string")).Return(true, nil)
+}
+
+type AddOnResponseStreamMock struct {
+	ResponseStreamMock
+}
+
+func (r *AddOnResponseStreamMock) Send(addOn *grpc_api.AddOn) error {
+	return r.send(addOn)
+}
+
+func NewAddOnResponseStreamMock(ctx context.Context, iamClientMock *IamClientMock) *AddOnResponseStreamMock {
+	mockObj := &AddOnResponseStreamMock{}
+	mockObj.allowCaller(ctx, iamClientMock)
+	return mockObj
+}
+
+type EmptyResponseStreamMock struct {
+	ResponseStreamMock
+}
+
+func (r *EmptyResponseStreamMock) Send(e *empty.Empty) error {
+	return r.send(e)
+}
+
+func NewEmptyResponseStreamMock(ctx context.Context, iamClientMock *IamClientMock) *EmptyResponseStreamMock {
+	mockObj := &EmptyResponseStreamMock{}
+	mockObj.allowCaller(ctx, iamClientMock)
+	return mockObj
+}
+
+type ListAddOnResponseStreamMock struct {
+	ResponseStreamMock
+}
+
+func (r *ListAddOnResponseStreamMock) Send(listAddOn *grpc_api.ListAddOnsResponse) error {
+	return r.send(listAddOn)
+}
+
+func NewListAddOnResponseStreamMock(ctx context.Context, iamClientMock *IamClientMock, capture chan *grpc_api.ListAddOnsResponse) *ListAddOnResponseStreamMock {
+	mockObj := &ListAddOnResponseStreamMock{ResponseStreamMock{capture: capture}}
+	mockObj.allowCaller(ctx, iamClientMock)
+	return mockObj
+}
+
+type CheckForUpdatesResponseStreamMock struct {
+	ResponseStreamMock
+}
+
+func (r *CheckForUpdatesResponseStreamMock) Send(response *grpc_api.CheckForUpdatesResponse) error {
+	return r.send(response)
+}
+
+func NewCheckForUpdatesResponseStreamMock(ctx context.Context, iamClientMock *IamClientMock, capture chan *grpc_api.CheckForUpdatesResponse) *CheckForUpdatesResponseStreamMock {
+	mockObj := &CheckForUpdatesResponseStreamMock{ResponseStreamMock{capture: capture}}
+	mockObj.allowCaller(ctx, iamClientMock)
+	return mockObj
+}
+
+type DiskUsageResponseStreamMock struct {
+	ResponseStreamMock
+}
+
+func (r *DiskUsageResponseStreamMock) Send(diskUsage *grpc_api.AddOnDiskUsage) error {
+	return r.send(diskUsage)
+}
+
+func NewDiskUsageResponseStreamMock(ctx context.Context, iamClientMock *IamClientMock, capture chan *grpc_api.AddOnDiskUsage) *DiskUsageResponseStreamMock {
+	mockObj := &DiskUsageResponseStreamMock{ResponseStreamMock{capture: capture}}
+	mockObj.allowCaller(ctx, iamClientMock)
+	return mockObj
+}
+
+type PlanResponseStreamMock struct {
+	ResponseStreamMock
+}
+
+func (r *PlanResponseStreamMock) Send(plan *grpc_api.AddOnPlan) error {
+	return r.send(plan)
+}
+
+func NewPlanResponseStreamMock(ctx context.Context, iamClientMock *IamClientMock, capture chan *grpc_api.AddOnPlan) *PlanResponseStreamMock {
+	mockObj := &PlanResponseStreamMock{ResponseStreamMock{capture: capture}}
+	mockObj.allowCaller(ctx, iamClientMock)
+	return mockObj
+}
+
+type StageAddOnUpdateResponseStreamMock struct {
+	ResponseStreamMock
+}
+
+func (r *StageAddOnUpdateResponseStreamMock) Send(response *grpc_api.StageAddOnUpdateResponse) error {
+	return r.send(response)
+}
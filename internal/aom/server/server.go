@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -8,20 +8,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 	"u-control/uc-aom/internal/aom/catalogue"
 	grpc_api "u-control/uc-aom/internal/aom/grpc"
 	"u-control/uc-aom/internal/aom/iam"
+	"u-control/uc-aom/internal/aom/protection"
+	"u-control/uc-aom/internal/aom/registry"
 	"u-control/uc-aom/internal/aom/service"
 	addonstatus "u-control/uc-aom/internal/aom/status"
+	"u-control/uc-aom/internal/aom/update"
 	"u-control/uc-aom/internal/aom/utils"
 	"u-control/uc-aom/internal/pkg/manifest"
+	sharedRegistry "u-control/uc-aom/internal/pkg/registry"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/text/language"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"u-control/uc-aom/internal/aom/env"
 )
@@ -37,11 +46,20 @@ type AddOnServer struct {
 	addonsAssetsRemotePath   string
 	localCatalogue           catalogue.LocalAddOnCatalogue
 	remoteCatalogue          catalogue.RemoteAddOnCatalogue
+	catalogueSources         registry.CatalogueSourceManager
+	registryCredentials      registry.RegistryCredentialManager
+	releaseChannels          catalogue.ReleaseChannelManager
+	testRegistryCredentials  func(ctx context.Context, credentials *registry.Credentials) error
 	iamServiceUcAomClient    iam.IamClient
 	iamServiceUcAuthClient   iam.IamClient
 	addOnStatusResolver      *addonstatus.AddOnStatusResolver
 	addOnEnvironmentResolver *env.AddOnEnvironmentResolver
 	transactionScheduler     *service.TransactionScheduler
+	updateChecker            *update.Checker
+	updatePolicies           *update.PolicyStore
+	updateHistory            *update.History
+	probation                *update.Probation
+	staging                  registry.AddOnStager
 }
 
 // Creates a new gRPC server which provides methods to Create/Delete/List AddOns.
@@ -50,6 +68,8 @@ type AddOnServer struct {
 // addonsAssetsRemotePath - path where the remote add-on assests are stored.
 // localCatalogue - Reference of the local catalogue
 // remoteCatalogue - Reference of the remote catalogue
+// catalogueSources - Manages the sources of the remote catalogue
+// registryCredentials - Manages the registry credentials at runtime
 // iamServiceUcAomClient - IAM client to check uc-aom manage permissions
 // iamServiceUcAuthClient - IAM client to check add-on access permissions
 // addOnStatusResolver - Reference of the status resolver.
@@ -59,6 +79,9 @@ func NewServer(service *service.Service,
 	addonsAssetsRemotePath string,
 	localCatalogue catalogue.LocalAddOnCatalogue,
 	remoteCatalogue catalogue.RemoteAddOnCatalogue,
+	catalogueSources registry.CatalogueSourceManager,
+	registryCredentials registry.RegistryCredentialManager,
+	releaseChannels catalogue.ReleaseChannelManager,
 	iamServiceUcAomClient iam.IamClient,
 	iamServiceUcAuthClient iam.IamClient,
 	addOnStatusResolver *addonstatus.AddOnStatusResolver,
@@ -71,6 +94,10 @@ func NewServer(service *service.Service,
 		addonsAssetsRemotePath:   addonsAssetsRemotePath,
 		localCatalogue:           localCatalogue,
 		remoteCatalogue:          remoteCatalogue,
+		catalogueSources:         catalogueSources,
+		registryCredentials:      registryCredentials,
+		releaseChannels:          releaseChannels,
+		testRegistryCredentials:  registry.TestCredentials,
 		iamServiceUcAomClient:    iamServiceUcAomClient,
 		iamServiceUcAuthClient:   iamServiceUcAuthClient,
 		addOnStatusResolver:      addOnStatusResolver,
@@ -80,6 +107,27 @@ func NewServer(service *service.Service,
 	return s
 }
 
+// Marks the installed add-ons with the results of the update checker and enables CheckForUpdates.
+func (s *AddOnServer) UseUpdateChecker(updateChecker *update.Checker) {
+	s.updateChecker = updateChecker
+}
+
+// Enables StageAddOnUpdate, the local catalogue pulls the staged packages from the stager.
+func (s *AddOnServer) UseStaging(staging registry.AddOnStager) {
+	s.staging = staging
+}
+
+// Puts the add-ons on probation after UpdateAddOn, so that a failing update is rolled back.
+func (s *AddOnServer) UseProbation(probation *update.Probation) {
+	s.probation = probation
+}
+
+// Enables the management of the update policies and the history of the update scheduler.
+func (s *AddOnServer) UseUpdatePolicies(updatePolicies *update.PolicyStore, updateHistory *update.History) {
+	s.updatePolicies = updatePolicies
+	s.updateHistory = updateHistory
+}
+
 func (s *AddOnServer) CreateAddOn(request *grpc_api.CreateAddOnRequest, stream grpc_api.AddOnService_CreateAddOnServer) error {
 	addOn := request.GetAddOn()
 	log.Tracef("CreateAddOn: %+v", addOn)
@@ -149,7 +197,11 @@ func (s *AddOnServer) DeleteAddOn(request *grpc_api.DeleteAddOnRequest, stream g
 	defer tx.Rollback()
 
 	longRunningOperation := func() error {
-		return tx.DeleteAddOnRoutine(request.Name)
+		err := tx.DeleteAddOnRoutine(request.Name)
+		if err == nil {
+			return nil
+		}
+		return convertToGrpcError(err)
 	}
 
 	heartBeatCallback := func() {
@@ -158,7 +210,7 @@ func (s *AddOnServer) DeleteAddOn(request *grpc_api.DeleteAddOnRequest, stream g
 
 	if err := utils.ApplyOperationWithHeartBeat(longRunningOperation, heartBeatCallback, heartBeat); err != nil {
 		log.Errorf("DeleteAddOn failed: %s", err.Error())
-		return status.Error(codes.FailedPrecondition, err.Error())
+		return err
 	}
 
 	if err := stream.Send(&empty.Empty{}); err != nil {
@@ -199,7 +251,12 @@ func (s *AddOnServer) UpdateAddOn(request *grpc_api.UpdateAddOnRequest, stream g
 	}
 	defer tx.Rollback()
 
+	// Taken within the operation, as keeping the package of the installed version takes a while
+	var snapshot *update.Snapshot
+	defer func() { s.releaseSnapshot(snapshot) }()
+
 	longUpdateOperation := func() error {
+		snapshot = s.snapshotForProbation(stream.Context(), addOn.Name)
 		err := tx.ReplaceAddOnRoutine(addOn.Name, addOn.Version, mapGrpcSettingToSetting(addOn.Settings)...)
 		if err == nil {
 			return nil
@@ -222,7 +279,8 @@ func (s *AddOnServer) UpdateAddOn(request *grpc_api.UpdateAddOnRequest, stream g
 		return status.Error(codes.FailedPrecondition, err.Error())
 	}
 
-	addOnWithStatus, err := s.transformCatalogueAddOnToGrpcAddOnWithStatus(catalogueAddOn, nil, grpc_api.AddOnView_FULL, s.addonsAssetsLocalPath)
+	languages := getPreferredLanguagesFrom(stream.Context())
+	addOnWithStatus, err := s.transformCatalogueAddOnToGrpcAddOnWithStatus(catalogueAddOn, nil, grpc_api.AddOnView_FULL, s.addonsAssetsLocalPath, languages)
 	if err != nil {
 		log.Errorf("UpdateAddOn failed: %s", err.Error())
 		return status.Error(codes.FailedPrecondition, err.Error())
@@ -243,6 +301,121 @@ func (s *AddOnServer) UpdateAddOn(request *grpc_api.UpdateAddOnRequest, stream g
 		return status.Error(codes.FailedPrecondition, err.Error())
 	}
 
+	if snapshot != nil {
+		s.probation.Start(snapshot, addOn.Version)
+	}
+
+	return nil
+}
+
+// Returns the snapshot of the installed add-on to restore if the update fails its probation, nil without probation.
+func (s *AddOnServer) snapshotForProbation(ctx context.Context, name string) *update.Snapshot {
+	if s.probation == nil {
+		return nil
+	}
+
+	snapshot, err := s.probation.Snapshot(ctx, name)
+	if err != nil {
+		log.Warnf("UpdateAddOn: unable to take the snapshot of '%s', the update is not put on probation: %s", name, err.Error())
+		return nil
+	}
+	return snapshot
+}
+
+// Releases the snapshot of an update which has not been put on probation.
+func (s *AddOnServer) releaseSnapshot(snapshot *update.Snapshot) {
+	if snapshot != nil {
+		s.probation.Release(snapshot)
+	}
+}
+
+// Plans the install, update or configuration of the add-on without changing anything on the device,
+// so that the changes can be reviewed before they are applied.
+// Fails with the same errors as CreateAddOn and UpdateAddOn.
+func (s *AddOnServer) PlanAddOn(request *grpc_api.PlanAddOnRequest, stream grpc_api.AddOnService_PlanAddOnServer) error {
+	addOn := request.GetAddOn()
+	if addOn == nil {
+		return status.Error(codes.InvalidArgument, "The add-on is required")
+	}
+	log.Tracef("PlanAddOn: %+v", addOn)
+
+	if err := s.checkAllowedToManageAddOns(stream.Context()); err != nil {
+		return err
+	}
+
+	if s.getInstalledVersion(addOn.Name) != "" {
+		if err := s.isValidUpdate(addOn); err != nil {
+			log.Error(err.Error())
+			return err
+		}
+	}
+
+	var plan *grpc_api.AddOnPlan
+	languages := getPreferredLanguagesFrom(stream.Context())
+	longRunningOperation := func() error {
+		addOnPlan, err := s.service.PlanAddOn(addOn.Name, addOn.Version, mapGrpcSettingToSetting(addOn.Settings)...)
+		if err != nil {
+			return convertToGrpcError(err)
+		}
+		plan = mapAddOnPlanToGrpcAddOnPlan(addOnPlan, languages)
+		return nil
+	}
+
+	heartBeatCallback := func() {
+		stream.Send(&grpc_api.AddOnPlan{})
+	}
+
+	if err := utils.ApplyOperationWithHeartBeat(longRunningOperation, heartBeatCallback, heartBeat); err != nil {
+		log.Errorf("PlanAddOn failed: %s", err.Error())
+		return err
+	}
+
+	if err := stream.Send(plan); err != nil {
+		log.Warnf("PlanAddOn: stream.Send() %s", err.Error())
+	}
+
+	return nil
+}
+
+// Checks the installed add-ons for newer versions in the remote catalogue which are compatible with the device
+// and returns the add-ons with an available update.
+func (s *AddOnServer) CheckForUpdates(request *empty.Empty, stream grpc_api.AddOnService_CheckForUpdatesServer) error {
+	log.Trace("CheckForUpdates")
+
+	if err := s.checkAllowedToManageAddOns(stream.Context()); err != nil {
+		return err
+	}
+
+	if s.updateChecker == nil {
+		return status.Error(codes.Unimplemented, "Update checks are not available.")
+	}
+
+	var response *grpc_api.CheckForUpdatesResponse
+	longRunningOperation := func() error {
+		updates, err := s.updateChecker.CheckForUpdates(stream.Context())
+		if err != nil {
+			if ctxErr := stream.Context().Err(); ctxErr != nil {
+				return status.FromContextError(ctxErr).Err()
+			}
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+		response = mapAvailableUpdatesToGrpcCheckForUpdatesResponse(updates)
+		return nil
+	}
+
+	heartBeatCallback := func() {
+		stream.Send(&grpc_api.CheckForUpdatesResponse{})
+	}
+
+	if err := utils.ApplyOperationWithHeartBeat(longRunningOperation, heartBeatCallback, heartBeat); err != nil {
+		log.Errorf("CheckForUpdates failed: %s", err.Error())
+		return err
+	}
+
+	if err := stream.Send(response); err != nil {
+		log.Warnf("CheckForUpdates: stream.Send() %s", err.Error())
+	}
+
 	return nil
 }
 
@@ -250,15 +423,16 @@ func (s *AddOnServer) GetAddOn(request *grpc_api.GetAddOnRequest, stream grpc_ap
 	log.Tracef("GetAddOn: %+v", request)
 
 	var addon *grpc_api.AddOn
+	languages := getPreferredLanguagesFrom(stream.Context())
 
 	longUpdateOperation := func() error {
 		var err error
 		switch filter := request.Filter; filter {
 		case grpc_api.GetAddOnRequest_INSTALLED:
-			addon, err = s.getInstalledAddOn(request.Name)
+			addon, err = s.getInstalledAddOn(request.Name, languages)
 			return err
 		case grpc_api.GetAddOnRequest_FILTER_UNSPECIFIED, grpc_api.GetAddOnRequest_CATALOGUE:
-			addon, err = s.getCatalogueAddOn(request.Name, request.Version, request.View)
+			addon, err = s.getCatalogueAddOn(stream.Context(), request.Name, request.Version, request.View, languages)
 			return err
 		default:
 			return status.Error(codes.Unimplemented, "Unknown Filter.")
@@ -282,7 +456,433 @@ func (s *AddOnServer) GetAddOn(request *grpc_api.GetAddOnRequest, stream grpc_ap
 
 }
 
-func (s *AddOnServer) getInstalledAddOn(name string) (*grpc_api.AddOn, error) {
+func (s *AddOnServer) GetAddOnDiskUsage(request *grpc_api.GetAddOnDiskUsageRequest, stream grpc_api.AddOnService_GetAddOnDiskUsageServer) error {
+	log.Tracef("GetAddOnDiskUsage: %+v", request)
+
+	if err := s.checkAllowedToManageAddOns(stream.Context()); err != nil {
+		return err
+	}
+
+	var diskUsage *grpc_api.AddOnDiskUsage
+	longRunningOperation := func() error {
+		catalogueAddOn, err := s.localCatalogue.GetAddOn(request.Name)
+		if errors.Is(err, catalogue.ErrorAddOnNotFound) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		if err != nil {
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		usage, err := s.service.GetAddOnDiskUsage(catalogueAddOn)
+		if err != nil {
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+		diskUsage = mapDiskUsageToGrpcDiskUsage(usage)
+		return nil
+	}
+
+	heartBeatCallback := func() {
+		stream.Send(&grpc_api.AddOnDiskUsage{})
+	}
+
+	if err := utils.ApplyOperationWithHeartBeat(longRunningOperation, heartBeatCallback, heartBeat); err != nil {
+		log.Errorf("GetAddOnDiskUsage failed: %s", err.Error())
+		return err
+	}
+
+	if err := stream.Send(diskUsage); err != nil {
+		log.Warnf("GetAddOnDiskUsage: stream.Send() %s", err.Error())
+	}
+
+	return nil
+}
+
+// Lists the sources of the remote catalogue, the passwords are never returned.
+func (s *AddOnServer) ListCatalogueSources(ctx context.Context, request *empty.Empty) (*grpc_api.ListCatalogueSourcesResponse, error) {
+	log.Trace("ListCatalogueSources")
+
+	if err := s.checkAllowedToManage(ctx, s.catalogueSources != nil, "catalogue sources"); err != nil {
+		return nil, err
+	}
+
+	sources := s.catalogueSources.Sources()
+	response := &grpc_api.ListCatalogueSourcesResponse{Sources: make([]*grpc_api.CatalogueSource, len(sources))}
+	for i := range sources {
+		response.Sources[i] = mapCatalogueSourceToGrpcCatalogueSource(&sources[i])
+	}
+	return response, nil
+}
+
+// Adds a source to the remote catalogue or replaces the source with the same name.
+// The password of a replaced source is kept if it is omitted and the username did not change,
+// the password of its proxy is kept if it is listed redacted.
+func (s *AddOnServer) SetCatalogueSource(ctx context.Context, request *grpc_api.SetCatalogueSourceRequest) (*grpc_api.CatalogueSource, error) {
+	source := request.GetSource()
+	if source == nil {
+		return nil, status.Error(codes.InvalidArgument, "The catalogue source is required")
+	}
+	log.Tracef("SetCatalogueSource: %s", source.Name)
+
+	if err := s.checkAllowedToManage(ctx, s.catalogueSources != nil, "catalogue sources"); err != nil {
+		return nil, err
+	}
+
+	catalogueSource := registry.CatalogueSource{
+		Name: source.Name,
+		Credentials: registry.Credentials{
+			ServerAddress: source.ServerAddress,
+			Username:      source.Username,
+			Password:      source.Password,
+		},
+		Priority:         int(source.Priority),
+		RepositoryPrefix: source.RepositoryPrefix,
+		Enabled:          source.Enabled,
+		Proxy:            source.Proxy,
+		NoProxy:          source.NoProxy,
+		Mirror:           source.Mirror,
+	}
+	for _, existing := range s.catalogueSources.Sources() {
+		if existing.Name == source.Name {
+			catalogueSource.Proxy = unredactProxy(source.Proxy, existing.Proxy)
+		}
+	}
+	if err := s.catalogueSources.SetSource(catalogueSource); err != nil {
+		return nil, convertCatalogueSourceError(err)
+	}
+
+	s.refreshRemoteCatalogueInBackground()
+	return mapCatalogueSourceToGrpcCatalogueSource(&catalogueSource), nil
+}
+
+// Deletes a source of the remote catalogue.
+// Add-ons installed from the source are updated from the remaining sources.
+func (s *AddOnServer) DeleteCatalogueSource(ctx context.Context, request *grpc_api.DeleteCatalogueSourceRequest) (*empty.Empty, error) {
+	log.Tracef("DeleteCatalogueSource: %s", request.Name)
+
+	if err := s.checkAllowedToManage(ctx, s.catalogueSources != nil, "catalogue sources"); err != nil {
+		return nil, err
+	}
+
+	if err := s.catalogueSources.DeleteSource(request.Name); err != nil {
+		return nil, convertCatalogueSourceError(err)
+	}
+
+	s.refreshRemoteCatalogueInBackground()
+	return &empty.Empty{}, nil
+}
+
+// Returns the stored registry credentials without their secrets.
+func (s *AddOnServer) ListRegistryCredentials(ctx context.Context, request *empty.Empty) (*grpc_api.ListRegistryCredentialsResponse, error) {
+	log.Trace("ListRegistryCredentials")
+
+	if err := s.checkAllowedToManage(ctx, s.registryCredentials != nil, "registry credentials"); err != nil {
+		return nil, err
+	}
+
+	list := s.registryCredentials.List()
+	response := &grpc_api.ListRegistryCredentialsResponse{Credentials: make([]*grpc_api.RegistryCredentials, len(list))}
+	for i := range list {
+		response.Credentials[i] = &grpc_api.RegistryCredentials{ServerAddress: list[i].ServerAddress, Username: list[i].Username}
+	}
+	return response, nil
+}
+
+// Stores or rotates the credentials of a registry, they are used for the next request to the registry.
+func (s *AddOnServer) SetRegistryCredentials(ctx context.Context, request *grpc_api.SetRegistryCredentialsRequest) (*empty.Empty, error) {
+	credentials := request.GetCredentials()
+	if credentials == nil {
+		return nil, status.Error(codes.InvalidArgument, "The registry credentials are required")
+	}
+	log.Tracef("SetRegistryCredentials: %s", credentials.ServerAddress)
+
+	if err := s.checkAllowedToManage(ctx, s.registryCredentials != nil, "registry credentials"); err != nil {
+		return nil, err
+	}
+
+	if err := s.registryCredentials.Set(mapGrpcRegistryCredentialsToCredentials(credentials)); err != nil {
+		return nil, convertRegistryCredentialsError(err)
+	}
+
+	s.refreshRemoteCatalogueInBackground()
+	return &empty.Empty{}, nil
+}
+
+// Deletes the stored credentials of a registry, the credentials of the docker config or the catalogue source are used again.
+func (s *AddOnServer) DeleteRegistryCredentials(ctx context.Context, request *grpc_api.DeleteRegistryCredentialsRequest) (*empty.Empty, error) {
+	log.Tracef("DeleteRegistryCredentials: %s", request.ServerAddress)
+
+	if err := s.checkAllowedToManage(ctx, s.registryCredentials != nil, "registry credentials"); err != nil {
+		return nil, err
+	}
+
+	if err := s.registryCredentials.Delete(request.ServerAddress); err != nil {
+		return nil, convertRegistryCredentialsError(err)
+	}
+
+	s.refreshRemoteCatalogueInBackground()
+	return &empty.Empty{}, nil
+}
+
+// Checks whether the registry of a catalogue source accepts the credentials without storing them.
+// If only the server address is given, the current credentials of the registry are checked.
+// Other registries are rejected, so that the device cannot be used to probe arbitrary hosts.
+func (s *AddOnServer) TestRegistryCredentials(ctx context.Context, request *grpc_api.TestRegistryCredentialsRequest) (*grpc_api.TestRegistryCredentialsResponse, error) {
+	credentials := request.GetCredentials()
+	if credentials == nil {
+		return nil, status.Error(codes.InvalidArgument, "The registry credentials are required")
+	}
+	log.Tracef("TestRegistryCredentials: %s", credentials.ServerAddress)
+
+	if err := s.checkAllowedToManage(ctx, s.registryCredentials != nil, "registry credentials"); err != nil {
+		return nil, err
+	}
+
+	if !s.isCatalogueSourceRegistry(credentials.ServerAddress) {
+		return nil, status.Errorf(codes.InvalidArgument, "The registry '%s' is not the registry of a catalogue source", credentials.ServerAddress)
+	}
+
+	tested := mapGrpcRegistryCredentialsToCredentials(credentials)
+	if tested.IsInsecureServer() {
+		if current, ok := s.registryCredentials.Credentials(tested.ServerAddress); ok {
+			tested = *current
+		}
+	}
+
+	err := s.testRegistryCredentials(ctx, &tested)
+	if _, ok := err.(*registry.InvalidRegistryCredentialsError); ok {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		failure := sharedRegistry.DiagnoseConnectionFailure(err)
+		return &grpc_api.TestRegistryCredentialsResponse{Ok: false, Message: err.Error(), Failure: string(failure)}, nil
+	}
+	return &grpc_api.TestRegistryCredentialsResponse{Ok: true}, nil
+}
+
+// Returns whether the server address is the registry of a configured catalogue source.
+func (s *AddOnServer) isCatalogueSourceRegistry(serverAddress string) bool {
+	if s.catalogueSources == nil {
+		return false
+	}
+	for _, source := range s.catalogueSources.Sources() {
+		if source.ServerAddress == serverAddress {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the release channel of the device and the overrides per add-on.
+func (s *AddOnServer) GetReleaseChannels(ctx context.Context, request *empty.Empty) (*grpc_api.ReleaseChannels, error) {
+	log.Trace("GetReleaseChannels")
+
+	if err := s.checkAllowedToManage(ctx, s.releaseChannels != nil, "release channels"); err != nil {
+		return nil, err
+	}
+	return s.mapReleaseChannelsToGrpcReleaseChannels(), nil
+}
+
+// Sets the release channel of the device, or of the add-on if the name is given.
+// An unspecified channel removes the override of the add-on.
+func (s *AddOnServer) SetReleaseChannel(ctx context.Context, request *grpc_api.SetReleaseChannelRequest) (*grpc_api.ReleaseChannels, error) {
+	log.Tracef("SetReleaseChannel: %+v", request)
+
+	if err := s.checkAllowedToManage(ctx, s.releaseChannels != nil, "release channels"); err != nil {
+		return nil, err
+	}
+
+	channel, ok := grpcReleaseChannels[request.Channel]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Unknown release channel")
+	}
+
+	var err error
+	if request.Name == "" {
+		if channel == "" {
+			return nil, status.Error(codes.InvalidArgument, "The release channel of the device is required")
+		}
+		err = s.releaseChannels.SetDefaultChannel(channel)
+	} else {
+		err = s.releaseChannels.SetAddOnChannel(request.Name, channel)
+	}
+	if err != nil {
+		return nil, convertReleaseChannelError(err)
+	}
+
+	s.refreshRemoteCatalogueInBackground()
+	return s.mapReleaseChannelsToGrpcReleaseChannels(), nil
+}
+
+// Downloads and verifies the package of a newer version of an installed add-on ahead of its update,
+// without changing the running add-on. A following UpdateAddOn to the staged version does not access the network.
+// A previously staged version of the add-on is replaced.
+func (s *AddOnServer) StageAddOnUpdate(request *grpc_api.StageAddOnUpdateRequest, stream grpc_api.AddOnService_StageAddOnUpdateServer) error {
+	log.Tracef("StageAddOnUpdate: %+v", request)
+
+	if err := s.checkAllowedToManageAddOns(stream.Context()); err != nil {
+		return err
+	}
+
+	if s.staging == nil {
+		return status.Error(codes.Unimplemented, "Updates cannot be staged.")
+	}
+
+	if err := s.isValidUpdate(&grpc_api.AddOn{Name: request.Name, Version: request.Version}); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	installedAddOn, err := s.localCatalogue.GetAddOn(request.Name)
+	if errors.Is(err, catalogue.ErrorAddOnNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if installedAddOn.Version == request.Version {
+		return status.Errorf(codes.InvalidArgument, "Version %s of '%s' is already installed.", request.Version, request.Name)
+	}
+
+	var response *grpc_api.StageAddOnUpdateResponse
+	longStageOperation := func() error {
+		size, err := s.staging.Stage(stream.Context(), request.Name, request.Version)
+		if err != nil {
+			return convertToGrpcError(err)
+		}
+		if err := s.verifyStagedAddOn(request.Name, request.Version); err != nil {
+			if discardErr := s.staging.Discard(request.Name); discardErr != nil {
+				log.Warnf("StageAddOnUpdate: unable to discard '%s': %s", request.Name, discardErr.Error())
+			}
+			return err
+		}
+		response = &grpc_api.StageAddOnUpdateResponse{Name: request.Name, Version: request.Version, SizeBytes: size}
+		return nil
+	}
+
+	heartBeatCallback := func() {
+		stream.Send(&grpc_api.StageAddOnUpdateResponse{})
+	}
+
+	if err := utils.ApplyOperationWithHeartBeat(longStageOperation, heartBeatCallback, heartBeat); err != nil {
+		log.Errorf("StageAddOnUpdate failed: %s", err.Error())
+		return err
+	}
+
+	if err := stream.Send(response); err != nil {
+		log.Warnf("StageAddOnUpdate: stream.Send() %s", err.Error())
+	}
+
+	return nil
+}
+
+// Checks the manifest of the staged version as the update does, the manifest is read from the staging area.
+func (s *AddOnServer) verifyStagedAddOn(name string, version string) error {
+	stagedManifest, err := s.localCatalogue.FetchManifest(name, version)
+	if err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	incompatibilities, err := s.service.CheckCompatibility(&catalogue.CatalogueAddOn{Name: name, Version: version, Manifest: *stagedManifest})
+	if err != nil {
+		return convertToGrpcError(err)
+	}
+	if len(incompatibilities) > 0 {
+		messages := make([]string, len(incompatibilities))
+		for i, incompatibility := range incompatibilities {
+			messages[i] = incompatibility.Message
+		}
+		return status.Errorf(codes.FailedPrecondition, "Version %s of '%s' is incompatible with the device: %s", version, name, strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// Returns the update policies of the add-ons and the maintenance windows of the device.
+func (s *AddOnServer) GetUpdatePolicies(ctx context.Context, request *empty.Empty) (*grpc_api.UpdatePolicies, error) {
+	log.Trace("GetUpdatePolicies")
+
+	if err := s.checkAllowedToManage(ctx, s.updatePolicies != nil && s.updateHistory != nil, "update policies"); err != nil {
+		return nil, err
+	}
+	return s.mapUpdatePoliciesToGrpcUpdatePolicies(), nil
+}
+
+// Sets the update policy and the pinned version of the add-on.
+// An unspecified or manual policy without a pinned version removes the policy of the add-on.
+func (s *AddOnServer) SetUpdatePolicy(ctx context.Context, request *grpc_api.SetUpdatePolicyRequest) (*grpc_api.UpdatePolicies, error) {
+	log.Tracef("SetUpdatePolicy: %+v", request)
+
+	if err := s.checkAllowedToManage(ctx, s.updatePolicies != nil && s.updateHistory != nil, "update policies"); err != nil {
+		return nil, err
+	}
+
+	policy, ok := grpcUpdatePolicies[request.Policy]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Unknown update policy")
+	}
+
+	err := s.updatePolicies.SetAddOnPolicy(request.Name, update.AddOnPolicy{Policy: policy, PinnedVersion: request.PinnedVersion})
+	if err != nil {
+		return nil, convertUpdatePolicyError(err)
+	}
+	return s.mapUpdatePoliciesToGrpcUpdatePolicies(), nil
+}
+
+// Replaces the maintenance windows of the device, in which the add-ons are updated by their update policies.
+func (s *AddOnServer) SetMaintenanceWindows(ctx context.Context, request *grpc_api.SetMaintenanceWindowsRequest) (*grpc_api.UpdatePolicies, error) {
+	log.Tracef("SetMaintenanceWindows: %+v", request)
+
+	if err := s.checkAllowedToManage(ctx, s.updatePolicies != nil && s.updateHistory != nil, "update policies"); err != nil {
+		return nil, err
+	}
+
+	windows := make([]update.MaintenanceWindow, len(request.MaintenanceWindows))
+	for i, window := range request.MaintenanceWindows {
+		windows[i] = mapGrpcMaintenanceWindowToMaintenanceWindow(window)
+	}
+	if err := s.updatePolicies.SetMaintenanceWindows(windows); err != nil {
+		return nil, convertUpdatePolicyError(err)
+	}
+	return s.mapUpdatePoliciesToGrpcUpdatePolicies(), nil
+}
+
+// Returns what the update scheduler did, the latest entry first.
+func (s *AddOnServer) GetUpdateHistory(ctx context.Context, request *empty.Empty) (*grpc_api.UpdateHistory, error) {
+	log.Trace("GetUpdateHistory")
+
+	if err := s.checkAllowedToManage(ctx, s.updatePolicies != nil && s.updateHistory != nil, "update policies"); err != nil {
+		return nil, err
+	}
+
+	entries := s.updateHistory.Entries()
+	history := &grpc_api.UpdateHistory{Entries: make([]*grpc_api.UpdateHistoryEntry, len(entries))}
+	for i, entry := range entries {
+		history.Entries[i] = mapHistoryEntryToGrpcUpdateHistoryEntry(entry)
+	}
+	return history, nil
+}
+
+// Checks that the caller is allowed to manage add-ons and that the feature is available on the device.
+func (s *AddOnServer) checkAllowedToManage(ctx context.Context, available bool, feature string) error {
+	if err := s.checkAllowedToManageAddOns(ctx); err != nil {
+		return err
+	}
+
+	if !available {
+		return status.Errorf(codes.Unimplemented, "The %s cannot be managed.", feature)
+	}
+	return nil
+}
+
+// Refreshes the remote catalogue, so that it reflects the changed sources.
+func (s *AddOnServer) refreshRemoteCatalogueInBackground() {
+	go func() {
+		if err := s.remoteCatalogue.Refresh(context.Background()); err != nil {
+			log.Warnf("Failed to refresh the remote catalogue: %v", err)
+		}
+	}()
+}
+
+func (s *AddOnServer) getInstalledAddOn(name string, languages []language.Tag) (*grpc_api.AddOn, error) {
 	addOn := s.tryGetAddOnInTransaction(name)
 	if addOn != nil {
 		return addOn, nil
@@ -296,7 +896,7 @@ func (s *AddOnServer) getInstalledAddOn(name string) (*grpc_api.AddOn, error) {
 		return nil, status.Error(codes.FailedPrecondition, err.Error())
 	}
 
-	addOnWithStatus, err := s.transformCatalogueAddOnToGrpcAddOnWithStatus(catalogueAddOn, nil, grpc_api.AddOnView_FULL, s.addonsAssetsLocalPath)
+	addOnWithStatus, err := s.transformCatalogueAddOnToGrpcAddOnWithStatus(catalogueAddOn, nil, grpc_api.AddOnView_FULL, s.addonsAssetsLocalPath, languages)
 	if err != nil {
 		return nil, err
 	}
@@ -319,57 +919,80 @@ func getIndex(addOnVersions []string, version string) int {
 	return -1
 }
 
-func (s *AddOnServer) getCatalogueAddOn(name string, version string, view grpc_api.AddOnView) (*grpc_api.AddOn, error) {
-	catalogueAddOnVersions, err := s.remoteCatalogue.GetAddOnVersions(name)
+func (s *AddOnServer) getCatalogueAddOn(ctx context.Context, name string, version string, view grpc_api.AddOnView, languages []language.Tag) (*grpc_api.AddOn, error) {
+	catalogueAddOnVersions, err := s.remoteCatalogue.GetAddOnVersions(ctx, name)
 	if err != nil {
 		return nil, status.Error(codes.FailedPrecondition, err.Error())
 	}
 
+	// The installed version is offered even if the release channel does not offer it (anymore)
 	index := getIndex(catalogueAddOnVersions, version)
-	if index == -1 {
+	if index != -1 {
+		version = catalogueAddOnVersions[index]
+	} else if version == "" || version != s.getInstalledVersion(name) {
 		return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("Version '%s' not found", version))
 	}
 
-	catalogueAddOn, err := s.remoteCatalogue.GetAddOn(name, catalogueAddOnVersions[index])
+	catalogueAddOn, err := s.remoteCatalogue.GetAddOn(ctx, name, version)
 	if err != nil {
 		return nil, status.Error(codes.FailedPrecondition, err.Error())
 	}
-	addOn := s.transformCatalogueAddOnToGrpcAddOn(catalogueAddOn, catalogueAddOnVersions, view, s.addonsAssetsRemotePath)
+	addOn := s.transformCatalogueAddOnToGrpcAddOn(catalogueAddOn, catalogueAddOnVersions, view, s.addonsAssetsRemotePath, languages)
+	addOn.Compatibility = s.evaluateCompatibility(&catalogueAddOn, nil, view == grpc_api.AddOnView_FULL)
+	if view == grpc_api.AddOnView_FULL {
+		addOn.Changelog = catalogue.ChangelogBetween(catalogueAddOn.Changelog, s.getInstalledVersion(name), catalogueAddOn.Manifest.Version)
+	}
 	return addOn, nil
 }
 
+// Returns the version of the installed add-on, empty if the add-on is not installed.
+func (s *AddOnServer) getInstalledVersion(name string) string {
+	installedAddOn, err := s.localCatalogue.GetAddOn(name)
+	if err != nil {
+		return ""
+	}
+	return installedAddOn.Manifest.Version
+}
+
 func (s *AddOnServer) ListAddOns(request *grpc_api.ListAddOnsRequest, stream grpc_api.AddOnService_ListAddOnsServer) error {
 	log.Tracef("ListAddOns: %+v", request)
-	capture := make(chan []*grpc_api.AddOn, 1)
+	capture := make(chan *grpc_api.ListAddOnsResponse, 1)
 	heartBeatCallback := func() {
 		stream.Send(&grpc_api.ListAddOnsResponse{})
 	}
 
+	query, err := newAddOnQuery(request)
+	if err != nil {
+		return err
+	}
+
 	switch filter := request.Filter; filter {
 	case grpc_api.ListAddOnsRequest_INSTALLED:
 		listInstalled := func() error {
-			return s.listInstalledAddOns(stream.Context(), capture)
+			return s.listInstalledAddOns(stream.Context(), capture, request.View, query)
 		}
 		err := utils.ApplyOperationWithHeartBeat(listInstalled, heartBeatCallback, heartBeat)
 		if err != nil {
 			return err
 		}
-		return stream.Send(&grpc_api.ListAddOnsResponse{AddOns: <-capture})
+		return stream.Send(<-capture)
 	case grpc_api.ListAddOnsRequest_FILTER_UNSPECIFIED, grpc_api.ListAddOnsRequest_CATALOGUE:
 		listCatalogue := func() error {
-			return s.listCatalogueAddOns(capture)
+			return s.listCatalogueAddOns(stream.Context(), capture, request.Refresh, request.View, query)
 		}
 		err := utils.ApplyOperationWithHeartBeat(listCatalogue, heartBeatCallback, heartBeat)
 		if err != nil {
 			return err
 		}
-		return stream.Send(&grpc_api.ListAddOnsResponse{AddOns: <-capture})
+		return stream.Send(<-capture)
 	default:
 		return status.Error(codes.Unimplemented, "Unknown Filter.")
 	}
 }
 
-func (s *AddOnServer) listInstalledAddOns(ctx context.Context, capture chan []*grpc_api.AddOn) error {
+// Lists the installed add-ons.
+// The disk usage is only evaluated in the full view, because it queries the disk usage of docker.
+func (s *AddOnServer) listInstalledAddOns(ctx context.Context, capture chan *grpc_api.ListAddOnsResponse, view grpc_api.AddOnView, query *addOnQuery) error {
 	installedAddOns, err := s.localCatalogue.GetAddOns()
 	if err != nil {
 		return status.Error(codes.FailedPrecondition, err.Error())
@@ -386,41 +1009,132 @@ func (s *AddOnServer) listInstalledAddOns(ctx context.Context, capture chan []*g
 		}
 	}
 
-	addOns, err := s.transformCatalogueAddOnsToGrpcAddOnsWithStatus(installedAddOns, s.addonsAssetsLocalPath)
+	installedAddOns = query.selectInstalledAddOns(installedAddOns)
+	addOns, err := s.transformCatalogueAddOnsToGrpcAddOnsWithStatus(installedAddOns, s.addonsAssetsLocalPath, getPreferredLanguagesFrom(ctx))
 	if err != nil {
 		return status.Error(codes.FailedPrecondition, err.Error())
 	}
-	capture <- addOns
+
+	updatable := make(map[string]bool)
+	for _, addOn := range addOns {
+		updatable[addOn.Name] = addOn.UpdateAvailable
+	}
+
+	response, err := query.evaluate(addOns, updatable)
+	if err != nil {
+		return err
+	}
+	if view == grpc_api.AddOnView_FULL {
+		s.setDiskUsage(installedAddOns, response.AddOns)
+	}
+	capture <- response
 	return nil
 }
 
-func (s *AddOnServer) listCatalogueAddOns(capture chan []*grpc_api.AddOn) error {
-	catalogueAddOns, err := s.remoteCatalogue.GetLatestAddOns()
+// Sets the disk usage of the installed add-ons on the page, the list is still returned if the disk usage is not available.
+// Add-ons of an open transaction are skipped because their disk usage is in flux.
+func (s *AddOnServer) setDiskUsage(installedAddOns []*catalogue.CatalogueAddOn, addOns []*grpc_api.AddOn) {
+	onPage := make(map[string]bool, len(addOns))
+	for _, addOn := range addOns {
+		onPage[addOn.Name] = true
+	}
+
+	settledAddOns := make([]*catalogue.CatalogueAddOn, 0, len(addOns))
+	for _, addOn := range installedAddOns {
+		if onPage[addOn.Name] && s.tryGetAddOnInTransaction(addOn.Name) == nil {
+			settledAddOns = append(settledAddOns, addOn)
+		}
+	}
+
+	if len(settledAddOns) == 0 {
+		return
+	}
+
+	usages, err := s.service.GetAddOnsDiskUsage(settledAddOns)
 	if err != nil {
-		if connectionProblem, ok := err.(*catalogue.RemoteRegistryConnectionError); ok {
+		log.Warnf("GetAddOnsDiskUsage failed: %s", err.Error())
+		return
+	}
+
+	for _, addOn := range addOns {
+		if usage, ok := usages[addOn.Name]; ok {
+			addOn.DiskUsage = mapDiskUsageToGrpcDiskUsage(usage)
+		}
+	}
+}
+
+// Lists the latest add-ons of the remote catalogue with their compatibility with the device.
+// The disk space is only evaluated in the full view.
+func (s *AddOnServer) listCatalogueAddOns(ctx context.Context, capture chan *grpc_api.ListAddOnsResponse, refresh bool, view grpc_api.AddOnView, query *addOnQuery) error {
+	if refresh {
+		if err := s.remoteCatalogue.Refresh(ctx); err != nil {
+			var connectionProblem *catalogue.RemoteRegistryConnectionError
+			if errors.As(err, &connectionProblem) {
+				return ConvertToGrpcRemoteRegistryConnectionError(connectionProblem)
+			}
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+	}
+
+	catalogueAddOns, err := s.remoteCatalogue.GetLatestAddOns(ctx)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		var connectionProblem *catalogue.RemoteRegistryConnectionError
+		if errors.As(err, &connectionProblem) {
 			return ConvertToGrpcRemoteRegistryConnectionError(connectionProblem)
 		}
 		return status.Error(codes.FailedPrecondition, err.Error())
 	}
-	var validAddOns []*catalogue.CatalogueAddOn
-	for _, addOn := range catalogueAddOns {
-		err := s.service.Validator.Validate(&addOn.Manifest)
+	// Incompatible add-ons are listed with the reasons, so that they can be shown as not installable.
+	addOns := s.transformCatalogueAddOnsToGrpcAddOns(catalogueAddOns, s.addonsAssetsRemotePath, getPreferredLanguagesFrom(ctx))
+	if err := s.evaluateCompatibilities(ctx, catalogueAddOns, addOns, view == grpc_api.AddOnView_FULL); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	var updatable map[string]bool
+	if query.updateAvailable {
+		updatable, err = s.getUpdatableAddOns()
 		if err != nil {
-			// Show info of the addon with an invalid manifest and skip it in the catalogue list
-			log.Info(err)
-			continue
+			return status.Error(codes.FailedPrecondition, err.Error())
 		}
-		validAddOns = append(validAddOns, addOn)
 	}
-	capture <- s.transformCatalogueAddOnsToGrpcAddOns(validAddOns, s.addonsAssetsRemotePath)
+
+	response, err := query.evaluate(addOns, updatable)
+	if err != nil {
+		return err
+	}
+	capture <- response
 	return nil
 }
 
+// Returns the names of the installed add-ons with an update found by the last check for updates,
+// as the installed add-ons report it. See setAvailableUpdate.
+func (s *AddOnServer) getUpdatableAddOns() (map[string]bool, error) {
+	updatable := make(map[string]bool)
+	if s.updateChecker == nil {
+		return updatable, nil
+	}
+
+	installedAddOns, err := s.localCatalogue.GetAddOns()
+	if err != nil {
+		return nil, err
+	}
+	for _, installedAddOn := range installedAddOns {
+		if s.updateChecker.UpdateOf(installedAddOn) != nil {
+			updatable[installedAddOn.Name] = true
+		}
+	}
+	return updatable, nil
+}
+
 func (s *AddOnServer) transformCatalogueAddOnToGrpcAddOn(
 	catalogueAddOn catalogue.CatalogueAddOn,
 	catalogueAddOnVersions []string,
 	view grpc_api.AddOnView,
-	logoPathPrefix string) *grpc_api.AddOn {
+	logoPathPrefix string,
+	languages []language.Tag) *grpc_api.AddOn {
 
 	logoPath := getLogoPath(logoPathPrefix, catalogueAddOn.Name, catalogueAddOn.Manifest.Logo)
 	location := ""
@@ -430,17 +1144,23 @@ func (s *AddOnServer) transformCatalogueAddOnToGrpcAddOn(
 
 	addOn := &grpc_api.AddOn{
 		Name:              catalogueAddOn.Name,
-		Title:             catalogueAddOn.Manifest.Title,
+		Title:             localize(catalogueAddOn.Manifest.Title, catalogueAddOn.Manifest.TitleTranslations, languages),
 		Version:           catalogueAddOn.Manifest.Version,
-		Description:       catalogueAddOn.Manifest.Description,
+		Description:       localize(catalogueAddOn.Manifest.Description, catalogueAddOn.Manifest.DescriptionTranslations, languages),
 		Location:          location,
 		Logo:              logoPath,
 		AvailableVersions: catalogueAddOnVersions,
 		Vendor:            getAddOnVendor(&catalogueAddOn.Manifest),
+		Categories:        catalogueAddOn.Manifest.Categories,
+		Keywords:          catalogueAddOn.Manifest.Keywords,
 	}
 
 	if view == grpc_api.AddOnView_FULL {
-		addOn.Settings = mapSettingToGrpcSetting(catalogueAddOn.Manifest.Settings["environmentVariables"])
+		addOn.Settings = mapSettingToGrpcSetting(catalogueAddOn.Manifest.Settings["environmentVariables"], languages)
+		for _, screenshot := range catalogueAddOn.Manifest.Screenshots {
+			addOn.Screenshots = append(addOn.Screenshots, getLogoPath(logoPathPrefix, catalogueAddOn.Name, screenshot))
+		}
+		addOn.Readme = catalogueAddOn.Readme
 	}
 	return addOn
 }
@@ -449,28 +1169,34 @@ func (s *AddOnServer) transformCatalogueAddOnToGrpcAddOnWithStatus(
 	catalogueAddOn catalogue.CatalogueAddOn,
 	catalogueAddOnVersions []string,
 	view grpc_api.AddOnView,
-	logoPathPrefix string) (*grpc_api.AddOn, error) {
+	logoPathPrefix string,
+	languages []language.Tag) (*grpc_api.AddOn, error) {
 
-	addOn := s.transformCatalogueAddOnToGrpcAddOn(catalogueAddOn, catalogueAddOnVersions, view, logoPathPrefix)
+	addOn := s.transformCatalogueAddOnToGrpcAddOn(catalogueAddOn, catalogueAddOnVersions, view, logoPathPrefix, languages)
+	if s.catalogueSources != nil {
+		addOn.Source = s.catalogueSources.OriginOf(addOn.Name)
+	}
 
 	status, err := s.addOnStatusResolver.GetAddOnStatus(addOn.Name)
 	if err != nil {
 		return nil, err
 	}
 	addOn.Status = grpc_api.AddOnStatus(status)
+	addOn.AllowedOperations = mapOperationsToGrpcOperations(s.service.AllowedOperations(catalogueAddOn))
+	s.setAvailableUpdate(addOn, &catalogueAddOn)
 	return addOn, nil
 }
 
-func (s *AddOnServer) transformCatalogueAddOnsToGrpcAddOns(catalogueAddOns []*catalogue.CatalogueAddOn, logoPathPrefix string) []*grpc_api.AddOn {
+func (s *AddOnServer) transformCatalogueAddOnsToGrpcAddOns(catalogueAddOns []*catalogue.CatalogueAddOn, logoPathPrefix string, languages []language.Tag) []*grpc_api.AddOn {
 	addOns := make([]*grpc_api.AddOn, len(catalogueAddOns))
 	for i, addOn := range catalogueAddOns {
-		addOns[i] = s.transformCatalogueAddOnToGrpcAddOn(*addOn, nil, grpc_api.AddOnView_BASIC, logoPathPrefix)
+		addOns[i] = s.transformCatalogueAddOnToGrpcAddOn(*addOn, nil, grpc_api.AddOnView_BASIC, logoPathPrefix, languages)
 	}
 	return addOns
 }
 
-func (s *AddOnServer) transformCatalogueAddOnsToGrpcAddOnsWithStatus(catalogueAddOns []*catalogue.CatalogueAddOn, logoPathPrefix string) ([]*grpc_api.AddOn, error) {
-	addOns := s.transformCatalogueAddOnsToGrpcAddOns(catalogueAddOns, logoPathPrefix)
+func (s *AddOnServer) transformCatalogueAddOnsToGrpcAddOnsWithStatus(catalogueAddOns []*catalogue.CatalogueAddOn, logoPathPrefix string, languages []language.Tag) ([]*grpc_api.AddOn, error) {
+	addOns := s.transformCatalogueAddOnsToGrpcAddOns(catalogueAddOns, logoPathPrefix, languages)
 
 	for i := range addOns {
 		if replacement := s.tryGetAddOnInTransaction(addOns[i].Name); replacement != nil {
@@ -484,11 +1210,26 @@ func (s *AddOnServer) transformCatalogueAddOnsToGrpcAddOnsWithStatus(catalogueAd
 		}
 
 		addOns[i].Status = grpc_api.AddOnStatus(status)
+		addOns[i].AllowedOperations = mapOperationsToGrpcOperations(s.service.AllowedOperations(*catalogueAddOns[i]))
+		s.setAvailableUpdate(addOns[i], catalogueAddOns[i])
 	}
 
 	return addOns, nil
 }
 
+// Sets the latest compatible version of the installed add-on found by the last check for updates.
+// No update is available until the first check, which runs in the background after the start
+// or, if UPDATE_CHECK_INTERVAL is zero, only on CheckForUpdates.
+func (s *AddOnServer) setAvailableUpdate(addOn *grpc_api.AddOn, installedAddOn *catalogue.CatalogueAddOn) {
+	if s.updateChecker == nil {
+		return
+	}
+	if availableUpdate := s.updateChecker.UpdateOf(installedAddOn); availableUpdate != nil {
+		addOn.UpdateAvailable = true
+		addOn.LatestVersion = availableUpdate.LatestVersion
+	}
+}
+
 func (s *AddOnServer) setCurrentEnvironmentValues(addOn *grpc_api.AddOn) error {
 	if len(addOn.Settings) == 0 {
 		return nil
@@ -516,6 +1257,20 @@ func (s *AddOnServer) setCurrentEnvironmentValues(addOn *grpc_api.AddOn) error {
 	return nil
 }
 
+// Returns PermissionDenied if the caller is not allowed to manage add-ons.
+func (s *AddOnServer) checkAllowedToManageAddOns(ctx context.Context) error {
+	allowed, err := s.isAllowedToManageAddons(ctx)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	if !allowed {
+		return status.Error(codes.PermissionDenied, "Insufficient permission.")
+	}
+	return nil
+}
+
 func (s *AddOnServer) isAllowedToManageAddons(context context.Context) (bool, error) {
 	jwt := getJsonWebTokenFrom(context)
 	allowed, err := s.iamServiceUcAomClient.IsAllowed(jwt, "add-ons.manage")
@@ -602,18 +1357,172 @@ func (s *AddOnServer) isTransactionOpen() bool {
 	return s.transactionScheduler.IsTransactionOpen()
 }
 
+func mapGrpcRegistryCredentialsToCredentials(credentials *grpc_api.RegistryCredentials) registry.Credentials {
+	return registry.Credentials{
+		ServerAddress: credentials.ServerAddress,
+		Username:      credentials.Username,
+		Password:      credentials.Password,
+		IdentityToken: credentials.IdentityToken,
+		RegistryToken: credentials.RegistryToken,
+	}
+}
+
+var grpcReleaseChannels = map[grpc_api.ReleaseChannel]catalogue.ReleaseChannel{
+	grpc_api.ReleaseChannel_RELEASE_CHANNEL_UNSPECIFIED: "",
+	grpc_api.ReleaseChannel_STABLE:                      catalogue.ReleaseChannelStable,
+	grpc_api.ReleaseChannel_RC:                          catalogue.ReleaseChannelRC,
+	grpc_api.ReleaseChannel_BETA:                        catalogue.ReleaseChannelBeta,
+	grpc_api.ReleaseChannel_ALPHA:                       catalogue.ReleaseChannelAlpha,
+}
+
+func mapReleaseChannelToGrpcReleaseChannel(channel catalogue.ReleaseChannel) grpc_api.ReleaseChannel {
+	for grpcChannel, mapped := range grpcReleaseChannels {
+		if mapped == channel {
+			return grpcChannel
+		}
+	}
+	return grpc_api.ReleaseChannel_RELEASE_CHANNEL_UNSPECIFIED
+}
+
+func (s *AddOnServer) mapReleaseChannelsToGrpcReleaseChannels() *grpc_api.ReleaseChannels {
+	addOnChannels := s.releaseChannels.AddOnChannels()
+	releaseChannels := &grpc_api.ReleaseChannels{
+		DefaultChannel: mapReleaseChannelToGrpcReleaseChannel(s.releaseChannels.DefaultChannel()),
+		AddOnChannels:  make(map[string]grpc_api.ReleaseChannel, len(addOnChannels)),
+	}
+	for name, channel := range addOnChannels {
+		releaseChannels.AddOnChannels[name] = mapReleaseChannelToGrpcReleaseChannel(channel)
+	}
+	return releaseChannels
+}
+
+var grpcUpdatePolicies = map[grpc_api.UpdatePolicy]update.Policy{
+	grpc_api.UpdatePolicy_UPDATE_POLICY_UNSPECIFIED: update.PolicyManual,
+	grpc_api.UpdatePolicy_MANUAL:                    update.PolicyManual,
+	grpc_api.UpdatePolicy_NOTIFY:                    update.PolicyNotify,
+	grpc_api.UpdatePolicy_AUTO_PATCH:                update.PolicyAutoPatch,
+	grpc_api.UpdatePolicy_AUTO_MINOR:                update.PolicyAutoMinor,
+}
+
+func mapUpdatePolicyToGrpcUpdatePolicy(policy update.Policy) grpc_api.UpdatePolicy {
+	for grpcPolicy, mapped := range grpcUpdatePolicies {
+		if mapped == policy && grpcPolicy != grpc_api.UpdatePolicy_UPDATE_POLICY_UNSPECIFIED {
+			return grpcPolicy
+		}
+	}
+	return grpc_api.UpdatePolicy_UPDATE_POLICY_UNSPECIFIED
+}
+
+func (s *AddOnServer) mapUpdatePoliciesToGrpcUpdatePolicies() *grpc_api.UpdatePolicies {
+	addOnPolicies := s.updatePolicies.AddOnPolicies()
+	names := make([]string, 0, len(addOnPolicies))
+	for name := range addOnPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	updatePolicies := &grpc_api.UpdatePolicies{}
+	for _, name := range names {
+		updatePolicies.AddOns = append(updatePolicies.AddOns, &grpc_api.AddOnUpdatePolicy{
+			Name:          name,
+			Policy:        mapUpdatePolicyToGrpcUpdatePolicy(addOnPolicies[name].Policy),
+			PinnedVersion: addOnPolicies[name].PinnedVersion,
+		})
+	}
+	for _, window := range s.updatePolicies.MaintenanceWindows() {
+		grpcWindow := &grpc_api.MaintenanceWindow{Start: window.Start, End: window.End}
+		for _, weekday := range window.Weekdays {
+			grpcWindow.Weekdays = append(grpcWindow.Weekdays, uint32(weekday))
+		}
+		updatePolicies.MaintenanceWindows = append(updatePolicies.MaintenanceWindows, grpcWindow)
+	}
+	return updatePolicies
+}
+
+func mapGrpcMaintenanceWindowToMaintenanceWindow(grpcWindow *grpc_api.MaintenanceWindow) update.MaintenanceWindow {
+	window := update.MaintenanceWindow{Start: grpcWindow.Start, End: grpcWindow.End}
+	for _, weekday := range grpcWindow.Weekdays {
+		window.Weekdays = append(window.Weekdays, time.Weekday(weekday))
+	}
+	return window
+}
+
+var grpcUpdateResults = map[update.Result]grpc_api.UpdateResult{
+	update.ResultNotified:       grpc_api.UpdateResult_NOTIFIED,
+	update.ResultUpdated:        grpc_api.UpdateResult_UPDATED,
+	update.ResultFailed:         grpc_api.UpdateResult_FAILED,
+	update.ResultRolledBack:     grpc_api.UpdateResult_ROLLED_BACK,
+	update.ResultRollbackFailed: grpc_api.UpdateResult_ROLLBACK_FAILED,
+}
+
+func mapHistoryEntryToGrpcUpdateHistoryEntry(entry update.HistoryEntry) *grpc_api.UpdateHistoryEntry {
+	return &grpc_api.UpdateHistoryEntry{
+		Time:        timestamppb.New(entry.Time),
+		Name:        entry.Name,
+		FromVersion: entry.FromVersion,
+		ToVersion:   entry.ToVersion,
+		Result:      grpcUpdateResults[entry.Result],
+		Message:     entry.Message,
+	}
+}
+
+func mapCatalogueSourceToGrpcCatalogueSource(source *registry.CatalogueSource) *grpc_api.CatalogueSource {
+	return &grpc_api.CatalogueSource{
+		Name:             source.Name,
+		ServerAddress:    source.ServerAddress,
+		Username:         source.Username,
+		Priority:         int32(source.Priority),
+		RepositoryPrefix: source.RepositoryPrefix,
+		Enabled:          source.Enabled,
+		Proxy:            redactProxy(source.Proxy),
+		NoProxy:          source.NoProxy,
+		Mirror:           source.Mirror,
+	}
+}
+
+// Replaces the password of a listed proxy, as done by url.URL.Redacted.
+const redactedPassword = "xxxxx"
+
+// Returns the proxy without the password, which is only set but never listed.
+func redactProxy(proxy string) string {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return ""
+	}
+	return proxyURL.Redacted()
+}
+
+// Returns the proxy with the password of the current proxy if the proxy is the redacted current proxy,
+// so that a listed source can be set again without the password of its proxy.
+func unredactProxy(proxy string, current string) string {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.User == nil {
+		return proxy
+	}
+	if password, ok := proxyURL.User.Password(); !ok || password != redactedPassword {
+		return proxy
+	}
+
+	currentURL, err := url.Parse(current)
+	if err != nil || currentURL.User == nil || currentURL.Redacted() != proxyURL.Redacted() {
+		return proxy
+	}
+	proxyURL.User = currentURL.User
+	return proxyURL.String()
+}
+
 func getLogoPath(base string, repoistoryName string, logoFilename string) string {
 	return filepath.Join(base, repoistoryName, logoFilename)
 }
 
-func mapSettingToGrpcSetting(settings []*manifest.Setting) []*grpc_api.Setting {
+func mapSettingToGrpcSetting(settings []*manifest.Setting, languages []language.Tag) []*grpc_api.Setting {
 	transformed := make([]*grpc_api.Setting, len(settings))
 
 	for i, setting := range settings {
 
 		grpcSetting := &grpc_api.Setting{
 			Name:     setting.Name,
-			Label:    setting.Label,
+			Label:    localize(setting.Label, setting.LabelTranslations, languages),
 			Required: setting.Required,
 		}
 
@@ -627,7 +1536,7 @@ func mapSettingToGrpcSetting(settings []*manifest.Setting) []*grpc_api.Setting {
 			grpcDropDownList := &grpc_api.DropDownList{}
 			for _, selectItem := range setting.Select {
 				grpcDropDownList.Elements = append(grpcDropDownList.Elements, &grpc_api.DropDownItem{
-					Label:    selectItem.Label,
+					Label:    localize(selectItem.Label, selectItem.LabelTranslations, languages),
 					Value:    selectItem.Value,
 					Selected: selectItem.Selected,
 				})
@@ -680,3 +1589,95 @@ func getAddOnVendor(addOnManifest *manifest.Root) *grpc_api.Vendor {
 	}
 	return vendor
 }
+
+func mapOperationsToGrpcOperations(operations []protection.Operation) []grpc_api.AddOnOperation {
+	grpcOperations := make([]grpc_api.AddOnOperation, 0, len(operations))
+	for _, operation := range operations {
+		switch operation {
+		case protection.Update:
+			grpcOperations = append(grpcOperations, grpc_api.AddOnOperation_UPDATE)
+		case protection.Configure:
+			grpcOperations = append(grpcOperations, grpc_api.AddOnOperation_CONFIGURE)
+		case protection.Delete:
+			grpcOperations = append(grpcOperations, grpc_api.AddOnOperation_DELETE)
+		case protection.Stop:
+			grpcOperations = append(grpcOperations, grpc_api.AddOnOperation_STOP)
+		}
+	}
+	return grpcOperations
+}
+
+func mapDiskUsageToGrpcDiskUsage(usage *service.AddOnDiskUsage) *grpc_api.AddOnDiskUsage {
+	return &grpc_api.AddOnDiskUsage{
+		ImageBytes:          usage.ImageBytes,
+		SharedImageBytes:    usage.SharedImageBytes,
+		VolumeBytes:         usage.VolumeBytes,
+		PublicVolumeBytes:   usage.PublicVolumeBytes,
+		CatalogueAssetBytes: usage.CatalogueAssetBytes,
+		TotalBytes:          usage.TotalBytes(),
+	}
+}
+
+var grpcPlanOperations = map[service.Operation]grpc_api.AddOnPlan_Operation{
+	service.Installing:  grpc_api.AddOnPlan_INSTALL,
+	service.Updating:    grpc_api.AddOnPlan_UPDATE,
+	service.Configuring: grpc_api.AddOnPlan_CONFIGURE,
+}
+
+func mapAddOnPlanToGrpcAddOnPlan(plan *service.AddOnPlan, languages []language.Tag) *grpc_api.AddOnPlan {
+	grpcPlan := &grpc_api.AddOnPlan{
+		Name:            plan.Name,
+		Title:           plan.Title,
+		Operation:       grpcPlanOperations[plan.Operation],
+		CurrentVersion:  plan.CurrentVersion,
+		Version:         plan.Version,
+		Settings:        mapSettingToGrpcSetting(plan.Settings, languages),
+		MissingSettings: plan.MissingSettings,
+		DockerCompose:   plan.DockerCompose,
+		RemovedRoutes:   plan.RemovedRoutes,
+		CreatedVolumes:  plan.CreatedVolumes,
+		RemovedVolumes:  plan.RemovedVolumes,
+	}
+
+	if plan.DiskPlan != nil {
+		grpcPlan.DiskPlan = &grpc_api.DiskPlan{
+			ImageBytes:        plan.DiskPlan.ImageBytes,
+			PresentLayerBytes: plan.DiskPlan.PresentLayerBytes,
+			ArchiveBytes:      plan.DiskPlan.ArchiveBytes,
+			CacheBytes:        plan.DiskPlan.CacheBytes,
+			StagingBytes:      plan.DiskPlan.StagingBytes,
+			VolumeBytes:       plan.DiskPlan.VolumeBytes,
+			HeadroomBytes:     plan.DiskPlan.HeadroomBytes,
+			RequiredBytes:     plan.DiskPlan.RequiredBytes(),
+			AvailableBytes:    plan.AvailableDiskBytes,
+			IsEstimated:       plan.DiskPlan.IsEstimated,
+		}
+	}
+
+	for _, route := range plan.Routes {
+		grpcPlan.Routes = append(grpcPlan.Routes, &grpc_api.PlannedRoute{
+			Id:             route.Id,
+			ConfigFilepath: route.ConfigFilepath,
+			Config:         route.Config,
+			MapFilepath:    route.MapFilepath,
+			Map:            route.Map,
+		})
+	}
+
+	if plan.IamPermission != nil {
+		grpcPlan.IamPermission = &grpc_api.PlannedIamPermission{Filepath: plan.IamPermission.Filepath, Content: plan.IamPermission.Content}
+	}
+	return grpcPlan
+}
+
+func mapAvailableUpdatesToGrpcCheckForUpdatesResponse(updates []*update.AvailableUpdate) *grpc_api.CheckForUpdatesResponse {
+	response := &grpc_api.CheckForUpdatesResponse{Updates: make([]*grpc_api.AvailableUpdate, len(updates))}
+	for i, availableUpdate := range updates {
+		response.Updates[i] = &grpc_api.AvailableUpdate{
+			Name:           availableUpdate.Name,
+			CurrentVersion: availableUpdate.CurrentVersion,
+			LatestVersion:  availableUpdate.LatestVersion,
+		}
+	}
+	return response
+}
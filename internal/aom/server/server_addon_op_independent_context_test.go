@@ -206,6 +206,7 @@ func TestUpdateAddOnStatusContextIndependent(t *testing.T) {
 	mockObj.MockStackService.On("RemoveUnusedVolumes", "addOn", mock.Anything).Return(nil)
 	mockObj.On("AddOnStatusResolver", "addOn").Return([]*status.ListAddOnContainersFuncReturnType{{Status: "(healthy)"}}, nil)
 	mockObj.On("FetchManifest", futureAddOn.AddOn.Name, futureAddOn.AddOn.Version).Return(&futureAddOn.AddOn.Manifest, nil)
+	mockObj.On("FetchDiskFootprint", futureAddOn.AddOn.Name, futureAddOn.AddOn.Version).Return(catalogue.DiskFootprint{}, nil)
 	mockObj.On("Validate", mock.Anything).Return(nil)
 	mockObj.On("AvailableSpaceInBytes").Return(uint64(0x3b), nil)
 
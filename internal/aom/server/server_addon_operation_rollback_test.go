@@ -34,6 +34,9 @@ func TestUpdateAddOnFailTC1(t *testing.T) {
 	iamClientMock.On("IsAllowed", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(true, nil)
 	mockObj.On("GetAddOn", "addOn").Return(currentAddOn, nil)
 	mockObj.On("FetchManifest", futureGrpcAddOn.Name, futureGrpcAddOn.Version).Return(&currentAddOn.Manifest, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+	mockObj.On("FetchDiskFootprint", futureGrpcAddOn.Name, futureGrpcAddOn.Version).Return(catalogue.DiskFootprint{}, nil)
+	mockObj.On("AvailableSpaceInBytes").Return(uint64(0xdeadbeef), nil)
 	updateStreamMock.On("Send", mock.Anything).Return(nil)
 	mockObj.MockStackService.On("DeleteAddOnStack", "addOn").Return(fmt.Errorf("Delete Stack Failed")).Run(func(args mock.Arguments) {
 		time.Sleep(time.Millisecond * 10)
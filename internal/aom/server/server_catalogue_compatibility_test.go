@@ -0,0 +1,105 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListCatalogueAddOnsAnnotatesCompatibility(t *testing.T) {
+	// Arrange
+	catalogueAddOns := []*catalogue.CatalogueAddOn{
+		{Name: "compatible", Manifest: manifest.Root{ManifestVersion: manifest.ValidManifestVersion, Platform: []string{"ucm"}}, IsSummary: true},
+		{Name: "other-platform", Manifest: manifest.Root{ManifestVersion: manifest.ValidManifestVersion, Platform: []string{"ucg"}}, IsSummary: true},
+		{Name: "old-manifest", Manifest: manifest.Root{ManifestVersion: "0.1", Platform: []string{"ucm"}}, IsSummary: true},
+	}
+	remoteCatalogue := &remoteCatalogueMock{}
+	remoteCatalogue.On("GetLatestAddOns").Return(catalogueAddOns, nil)
+	uut := &AddOnServer{service: mockService(t), remoteCatalogue: remoteCatalogue}
+
+	// Act
+	all, err := listCatalogueAddOns(t, uut, &grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_CATALOGUE, SortBy: grpc_api.ListAddOnsRequest_NAME})
+	assert.NoError(t, err)
+	compatible, err := listCatalogueAddOns(t, uut, &grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_CATALOGUE, CompatibleOnly: true})
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, []string{"compatible", "old-manifest", "other-platform"}, namesOf(all.AddOns))
+	assert.True(t, all.AddOns[0].Compatibility.Compatible)
+	assert.False(t, all.AddOns[1].Compatibility.Compatible)
+	assert.Equal(t, grpc_api.IncompatibilityReason_UNSUPPORTED_MANIFEST_VERSION, all.AddOns[1].Compatibility.Incompatibilities[0].Reason)
+	assert.False(t, all.AddOns[2].Compatibility.Compatible)
+	assert.Equal(t, grpc_api.IncompatibilityReason_UNSUPPORTED_PLATFORM, all.AddOns[2].Compatibility.Incompatibilities[0].Reason)
+	assert.Equal(t, []string{"compatible"}, namesOf(compatible.AddOns))
+}
+
+func TestListCatalogueAddOnsExcludesNotEvaluatedAddOnsFromCompatibleOnly(t *testing.T) {
+	// Arrange
+	catalogueAddOns := []*catalogue.CatalogueAddOn{
+		{Name: "unknown", Manifest: manifest.Root{ManifestVersion: manifest.ValidManifestVersion, Platform: []string{"ucm"}}, IsSummary: true},
+	}
+	remoteCatalogue := &remoteCatalogueMock{}
+	remoteCatalogue.On("GetLatestAddOns").Return(catalogueAddOns, nil)
+	serviceMock := &service.ServiceMultiComponentMock{}
+	serviceMock.On("GetAddOn", "unknown").Return(catalogue.CatalogueAddOn{}, errors.New("READ_ERROR"))
+	uut := &AddOnServer{service: serviceMock.NewServiceUsingServiceMultiComponentMock(), remoteCatalogue: remoteCatalogue}
+
+	// Act
+	all, err := listCatalogueAddOns(t, uut, &grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_CATALOGUE})
+	assert.NoError(t, err)
+	compatible, err := listCatalogueAddOns(t, uut, &grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_CATALOGUE, CompatibleOnly: true})
+	assert.NoError(t, err)
+
+	// Assert
+	if assert.Len(t, all.AddOns, 1) {
+		assert.False(t, all.AddOns[0].Compatibility.Compatible)
+		assert.Equal(t, []grpc_api.IncompatibilityReason{grpc_api.IncompatibilityReason_NOT_EVALUATED}, reasonsOf(all.AddOns[0].Compatibility))
+	}
+	assert.Empty(t, compatible.AddOns)
+}
+
+func TestGetCatalogueAddOnEvaluatesDiskSpaceInFullView(t *testing.T) {
+	// Arrange
+	serviceMock := &service.ServiceMultiComponentMock{}
+	serviceMock.On("Validate", mock.Anything).Return(nil)
+	serviceMock.On("FetchDiskFootprint", "mqtt-bridge", "1.2.0-1").Return(catalogue.DiskFootprint{EstimatedInstallSize: 1024}, nil)
+	serviceMock.On("GetAddOn", "mqtt-bridge").Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+	serviceMock.On("AvailableSpaceInBytes").Return(uint64(1023), nil)
+	localCatalogue := &catalogue.CatalogueMock{}
+	localCatalogue.On("GetAddOn", "mqtt-bridge").Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+	uut := &AddOnServer{
+		service:         serviceMock.NewServiceUsingServiceMultiComponentMock(),
+		localCatalogue:  localCatalogue,
+		remoteCatalogue: &documentedRemoteCatalogueFake{},
+	}
+
+	// Act
+	basic, err := uut.getCatalogueAddOn(context.Background(), "mqtt-bridge", "", grpc_api.AddOnView_BASIC, nil)
+	assert.NoError(t, err)
+	full, err := uut.getCatalogueAddOn(context.Background(), "mqtt-bridge", "", grpc_api.AddOnView_FULL, nil)
+	assert.NoError(t, err)
+
+	// Assert
+	assert.NotContains(t, reasonsOf(basic.Compatibility), grpc_api.IncompatibilityReason_INSUFFICIENT_DISK_SPACE)
+	assert.Contains(t, reasonsOf(full.Compatibility), grpc_api.IncompatibilityReason_INSUFFICIENT_DISK_SPACE)
+	serviceMock.AssertNumberOfCalls(t, "FetchDiskFootprint", 1)
+}
+
+func reasonsOf(compatibility *grpc_api.Compatibility) []grpc_api.IncompatibilityReason {
+	reasons := make([]grpc_api.IncompatibilityReason, len(compatibility.Incompatibilities))
+	for i, incompatibility := range compatibility.Incompatibilities {
+		reasons[i] = incompatibility.Reason
+	}
+	return reasons
+}
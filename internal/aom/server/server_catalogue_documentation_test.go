@@ -0,0 +1,99 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type documentedRemoteCatalogueFake struct {
+	remoteCatalogueMock
+}
+
+func (r *documentedRemoteCatalogueFake) GetAddOnVersions(ctx context.Context, name string) ([]string, error) {
+	return []string{"1.0.0-1", "1.1.0-1", "1.2.0-1"}, nil
+}
+
+func (r *documentedRemoteCatalogueFake) GetAddOn(ctx context.Context, name string, version string) (catalogue.CatalogueAddOn, error) {
+	return catalogue.CatalogueAddOn{
+		Name:    name,
+		Version: version,
+		Manifest: manifest.Root{
+			Version:     version,
+			Categories:  []string{"Connectivity"},
+			Keywords:    []string{"mqtt"},
+			Screenshots: []string{"dashboard-1a2b3c.png"},
+		},
+		Readme:    "# MQTT Bridge",
+		Changelog: "# Changelog\n\n## 1.2.0-1\n- Dashboard\n\n## 1.1.0-1\n- Settings\n\n## 1.0.0-1\n- Initial release\n",
+	}, nil
+}
+
+func TestGetCatalogueAddOnReturnsDocumentation(t *testing.T) {
+	testCases := map[string]struct {
+		installedVersion  string
+		expectedChangelog string
+	}{
+		"not installed": {"", "## 1.2.0-1\n- Dashboard\n\n## 1.1.0-1\n- Settings\n\n## 1.0.0-1\n- Initial release"},
+		"installed":     {"1.0.0-1", "## 1.2.0-1\n- Dashboard\n\n## 1.1.0-1\n- Settings"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			localCatalogue := &catalogue.CatalogueMock{}
+			if testCase.installedVersion == "" {
+				localCatalogue.On("GetAddOn", "mqtt-bridge").Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+			} else {
+				localCatalogue.On("GetAddOn", "mqtt-bridge").Return(catalogue.CatalogueAddOn{Manifest: manifest.Root{Version: testCase.installedVersion}}, nil)
+			}
+			serviceMock := &service.ServiceMultiComponentMock{}
+			serviceMock.On("Validate", mock.Anything).Return(nil)
+			serviceMock.On("FetchDiskFootprint", "mqtt-bridge", "1.2.0-1").Return(catalogue.DiskFootprint{}, nil)
+			serviceMock.On("GetAddOn", "mqtt-bridge").Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+			serviceMock.On("AvailableSpaceInBytes").Return(uint64(0), nil)
+			uut := &AddOnServer{
+				service:                serviceMock.NewServiceUsingServiceMultiComponentMock(),
+				localCatalogue:         localCatalogue,
+				remoteCatalogue:        &documentedRemoteCatalogueFake{},
+				addonsAssetsRemotePath: "/add-ons/assets-remote",
+			}
+
+			// Act
+			addOn, err := uut.getCatalogueAddOn(context.Background(), "mqtt-bridge", "", grpc_api.AddOnView_FULL, nil)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Connectivity"}, addOn.Categories)
+			assert.Equal(t, []string{"mqtt"}, addOn.Keywords)
+			assert.Equal(t, []string{"/add-ons/assets-remote/mqtt-bridge/dashboard-1a2b3c.png"}, addOn.Screenshots)
+			assert.Equal(t, "# MQTT Bridge", addOn.Readme)
+			assert.Equal(t, testCase.expectedChangelog, addOn.Changelog)
+		})
+	}
+}
+
+func TestGetCatalogueAddOnOmitsDocumentationInBasicView(t *testing.T) {
+	// Arrange
+	uut := &AddOnServer{service: mockService(t), remoteCatalogue: &documentedRemoteCatalogueFake{}}
+
+	// Act
+	addOn, err := uut.getCatalogueAddOn(context.Background(), "mqtt-bridge", "1.1.0-1", grpc_api.AddOnView_BASIC, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Connectivity"}, addOn.Categories)
+	assert.Empty(t, addOn.Screenshots)
+	assert.Empty(t, addOn.Readme)
+	assert.Empty(t, addOn.Changelog)
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server_test
+
+import (
+	"context"
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+	"u-control/uc-aom/internal/aom/server"
+	"u-control/uc-aom/internal/aom/status"
+	"u-control/uc-aom/internal/aom/update"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+func createUpdateTestAddOn(version string) catalogue.CatalogueAddOn {
+	return catalogue.CatalogueAddOn{
+		Name:    "addon",
+		Version: version,
+		Manifest: manifest.Root{
+			ManifestVersion: manifest.ValidManifestVersion,
+			Title:           "Add-on",
+			Version:         version,
+			Platform:        []string{"ucm"},
+		},
+	}
+}
+
+func TestCheckForUpdates(t *testing.T) {
+	// Arrange
+	uut, mockObj, iamClientMock := server.NewServerUsingServiceMultiComponentMock()
+	installedAddOn := createUpdateTestAddOn("1.0.0-1")
+	mockObj.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{&installedAddOn}, nil)
+	mockObj.On("GetAddOn", "addon").Return(installedAddOn, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+	availableAddOn := createUpdateTestAddOn("1.1.0-1")
+	remoteCatalogue := &catalogue.RemoteCatalogueMock{}
+	remoteCatalogue.On("GetAddOnVersions", "addon").Return([]string{"1.0.0-1", "1.1.0-1"}, nil)
+	remoteCatalogue.On("FetchManifest", "addon", "1.1.0-1").Return(&availableAddOn.Manifest, nil)
+	uut.UseUpdateChecker(update.NewChecker(mockObj, remoteCatalogue, mockObj.NewServiceUsingServiceMultiComponentMock()))
+
+	checkCapture := make(chan *grpc_api.CheckForUpdatesResponse, 10)
+	checkStreamMock := server.NewCheckForUpdatesResponseStreamMock(context.Background(), iamClientMock, checkCapture)
+	checkStreamMock.On("Send", mock.Anything).Return(nil)
+
+	listCapture := make(chan *grpc_api.ListAddOnsResponse, 10)
+	listStreamMock := server.NewListAddOnResponseStreamMock(context.Background(), iamClientMock, listCapture)
+	listStreamMock.On("Send", mock.Anything).Return(nil)
+	mockObj.On("AddOnStatusResolver", "addon").Return([]*status.ListAddOnContainersFuncReturnType{{Status: "(healthy)"}}, nil)
+	mockObj.On("GetAddOnAssetsSize", "addon").Return(uint64(0), nil)
+	mockObj.MockStackService.On("DiskUsage").Return(createDockerDiskUsage(t), nil)
+
+	// Act
+	checkErr := uut.CheckForUpdates(&empty.Empty{}, checkStreamMock)
+	listErr := uut.ListAddOns(&grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_INSTALLED, UpdateAvailable: true}, listStreamMock)
+
+	// Assert
+	assert.Nil(t, checkErr)
+	close(checkCapture)
+	var response *grpc_api.CheckForUpdatesResponse
+	for response = range checkCapture {
+	}
+	expected := []*grpc_api.AvailableUpdate{{Name: "addon", CurrentVersion: "1.0.0-1", LatestVersion: "1.1.0-1"}}
+	assert.Equal(t, expected, response.Updates)
+
+	assert.Nil(t, listErr)
+	var addOns []*grpc_api.AddOn
+	for addOns == nil {
+		addOns = (<-listCapture).GetAddOns()
+	}
+	if assert.Len(t, addOns, 1) {
+		assert.True(t, addOns[0].UpdateAvailable)
+		assert.Equal(t, "1.1.0-1", addOns[0].LatestVersion)
+	}
+}
+
+func TestCheckForUpdatesWithoutChecker(t *testing.T) {
+	// Arrange
+	uut, _, iamClientMock := server.NewServerUsingServiceMultiComponentMock()
+	streamMock := server.NewCheckForUpdatesResponseStreamMock(context.Background(), iamClientMock, nil)
+	streamMock.On("Send", mock.Anything).Return(nil)
+
+	// Act
+	err := uut.CheckForUpdates(&empty.Empty{}, streamMock)
+
+	// Assert
+	assert.Equal(t, codes.Unimplemented, grpcStatus.Code(err))
+}
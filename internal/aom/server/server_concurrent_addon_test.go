@@ -244,6 +244,7 @@ func TestUpdateAddOnStatusConcurrentGetAddOn(t *testing.T) {
 	mockObj.MockStackService.On("RemoveUnusedVolumes", "addOn", mock.Anything).Return(nil)
 	mockObj.On("AddOnStatusResolver", "addOn").Return([]*status.ListAddOnContainersFuncReturnType{{Status: "(healthy)"}}, nil)
 	mockObj.On("FetchManifest", futureAddOn.AddOn.Name, futureAddOn.AddOn.Version).Return(&futureAddOn.AddOn.Manifest, nil)
+	mockObj.On("FetchDiskFootprint", futureAddOn.AddOn.Name, futureAddOn.AddOn.Version).Return(catalogue.DiskFootprint{}, nil)
 	mockObj.On("Validate", mock.Anything).Return(nil)
 	mockObj.On("AvailableSpaceInBytes").Return(uint64(0xdeadbeef), nil)
 
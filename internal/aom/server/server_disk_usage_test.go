@@ -0,0 +1,178 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server_test
+
+import (
+	"context"
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/docker"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+	"u-control/uc-aom/internal/aom/server"
+	"u-control/uc-aom/internal/aom/status"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+func createDockerDiskUsage(t *testing.T) *docker.DiskUsage {
+	dockerClient := &docker.DockerClientMock{}
+	dockerClient.On("DiskUsage", mock.Anything).Return(types.DiskUsage{
+		Images: []*types.ImageSummary{{RepoTags: []string{"test/addon:1.0.0"}, Size: 100, SharedSize: 40}},
+		Volumes: []*types.Volume{
+			{Name: "addon_data", Driver: "local", Labels: map[string]string{"com.docker.compose.project": "addon"}, UsageData: &types.VolumeUsageData{Size: 20}},
+		},
+	}, nil)
+
+	diskUsage, err := docker.NewStackService(dockerClient, nil).DiskUsage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return diskUsage
+}
+
+func createDiskUsageTestAddOn() catalogue.CatalogueAddOn {
+	return catalogue.CatalogueAddOn{
+		Name:    "addon",
+		Version: "1.0.0-1",
+		Manifest: manifest.Root{
+			Title:    "Add-on",
+			Version:  "1.0.0-1",
+			Services: map[string]*manifest.Service{"app": {Config: map[string]interface{}{"image": "test/addon:1.0.0"}}},
+		},
+	}
+}
+
+func TestGetAddOnDiskUsage(t *testing.T) {
+	// Arrange
+	uut, mockObj, iamClientMock := server.NewServerUsingServiceMultiComponentMock()
+	capture := make(chan *grpc_api.AddOnDiskUsage, 10)
+	streamMock := server.NewDiskUsageResponseStreamMock(context.Background(), iamClientMock, capture)
+
+	streamMock.On("Send", mock.Anything).Return(nil)
+	mockObj.On("GetAddOn", "addon").Return(createDiskUsageTestAddOn(), nil)
+	mockObj.On("GetAddOnAssetsSize", "addon").Return(uint64(3), nil)
+	mockObj.MockStackService.On("DiskUsage").Return(createDockerDiskUsage(t), nil)
+
+	// Act
+	err := uut.GetAddOnDiskUsage(&grpc_api.GetAddOnDiskUsageRequest{Name: "addon"}, streamMock)
+
+	// Assert
+	assert.Nil(t, err)
+	close(capture)
+	var diskUsage *grpc_api.AddOnDiskUsage
+	for diskUsage = range capture {
+	}
+
+	expected := &grpc_api.AddOnDiskUsage{ImageBytes: 100, SharedImageBytes: 40, VolumeBytes: 20, CatalogueAssetBytes: 3, TotalBytes: 123}
+	assert.Equal(t, expected, diskUsage)
+	mockObj.AssertExpectations(t)
+}
+
+func TestGetAddOnDiskUsageNotInstalled(t *testing.T) {
+	// Arrange
+	uut, mockObj, iamClientMock := server.NewServerUsingServiceMultiComponentMock()
+	streamMock := server.NewDiskUsageResponseStreamMock(context.Background(), iamClientMock, nil)
+
+	streamMock.On("Send", mock.Anything).Return(nil)
+	mockObj.On("GetAddOn", "addon").Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+
+	// Act
+	err := uut.GetAddOnDiskUsage(&grpc_api.GetAddOnDiskUsageRequest{Name: "addon"}, streamMock)
+
+	// Assert
+	assert.Equal(t, codes.NotFound, grpcStatus.Code(err))
+	mockObj.MockStackService.AssertNotCalled(t, "DiskUsage")
+}
+
+func TestListInstalledAddOnsWithDiskUsage(t *testing.T) {
+	// Arrange
+	uut, mockObj, iamClientMock := server.NewServerUsingServiceMultiComponentMock()
+	capture := make(chan *grpc_api.ListAddOnsResponse, 10)
+	streamMock := server.NewListAddOnResponseStreamMock(context.Background(), iamClientMock, capture)
+	addOn := createDiskUsageTestAddOn()
+
+	streamMock.On("Send", mock.Anything).Return(nil)
+	mockObj.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{&addOn}, nil)
+	mockObj.On("AddOnStatusResolver", "addon").Return([]*status.ListAddOnContainersFuncReturnType{{Status: "(healthy)"}}, nil)
+	mockObj.On("GetAddOnAssetsSize", "addon").Return(uint64(3), nil)
+	mockObj.MockStackService.On("DiskUsage").Return(createDockerDiskUsage(t), nil)
+
+	// Act
+	err := uut.ListAddOns(&grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_INSTALLED, View: grpc_api.AddOnView_FULL}, streamMock)
+
+	// Assert
+	assert.Nil(t, err)
+	var addOns []*grpc_api.AddOn
+	for addOns == nil {
+		addOns = (<-capture).GetAddOns()
+	}
+
+	assert.Len(t, addOns, 1)
+	assert.Equal(t, uint64(123), addOns[0].DiskUsage.TotalBytes)
+	mockObj.AssertExpectations(t)
+}
+
+func TestListInstalledAddOnsWithoutDiskUsage(t *testing.T) {
+	// Arrange
+	uut, mockObj, iamClientMock := server.NewServerUsingServiceMultiComponentMock()
+	capture := make(chan *grpc_api.ListAddOnsResponse, 10)
+	streamMock := server.NewListAddOnResponseStreamMock(context.Background(), iamClientMock, capture)
+	addOn := createDiskUsageTestAddOn()
+
+	streamMock.On("Send", mock.Anything).Return(nil)
+	mockObj.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{&addOn}, nil)
+	mockObj.On("AddOnStatusResolver", "addon").Return([]*status.ListAddOnContainersFuncReturnType{{Status: "(healthy)"}}, nil)
+
+	// Act
+	err := uut.ListAddOns(&grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_INSTALLED}, streamMock)
+
+	// Assert
+	assert.Nil(t, err)
+	var addOns []*grpc_api.AddOn
+	for addOns == nil {
+		addOns = (<-capture).GetAddOns()
+	}
+
+	assert.Len(t, addOns, 1)
+	assert.Nil(t, addOns[0].DiskUsage)
+	mockObj.MockStackService.AssertNotCalled(t, "DiskUsage")
+}
+
+func TestListInstalledAddOnsWithDiskUsageOfPageOnly(t *testing.T) {
+	// Arrange
+	uut, mockObj, iamClientMock := server.NewServerUsingServiceMultiComponentMock()
+	capture := make(chan *grpc_api.ListAddOnsResponse, 10)
+	streamMock := server.NewListAddOnResponseStreamMock(context.Background(), iamClientMock, capture)
+	addOn := createDiskUsageTestAddOn()
+	otherAddOn := createDiskUsageTestAddOn()
+	otherAddOn.Name = "other"
+	otherAddOn.Manifest.Title = "Other add-on"
+
+	streamMock.On("Send", mock.Anything).Return(nil)
+	mockObj.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{&addOn, &otherAddOn}, nil)
+	mockObj.On("AddOnStatusResolver", mock.Anything).Return([]*status.ListAddOnContainersFuncReturnType{{Status: "(healthy)"}}, nil)
+	mockObj.On("GetAddOnAssetsSize", "addon").Return(uint64(3), nil)
+	mockObj.MockStackService.On("DiskUsage").Return(createDockerDiskUsage(t), nil)
+
+	// Act
+	err := uut.ListAddOns(&grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_INSTALLED, View: grpc_api.AddOnView_FULL, PageSize: 1}, streamMock)
+
+	// Assert
+	assert.Nil(t, err)
+	var addOns []*grpc_api.AddOn
+	for addOns == nil {
+		addOns = (<-capture).GetAddOns()
+	}
+
+	assert.Len(t, addOns, 1)
+	assert.Equal(t, "addon", addOns[0].Name)
+	assert.NotNil(t, addOns[0].DiskUsage)
+	mockObj.AssertNotCalled(t, "GetAddOnAssetsSize", "other")
+}
@@ -5,26 +5,36 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
+	"u-control/uc-aom/internal/aom/budget"
 	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/cpuisolation"
 	"u-control/uc-aom/internal/aom/dbus"
 	"u-control/uc-aom/internal/aom/env"
 	grpc_api "u-control/uc-aom/internal/aom/grpc"
 	"u-control/uc-aom/internal/aom/iam"
+	"u-control/uc-aom/internal/aom/protection"
 	"u-control/uc-aom/internal/aom/routes"
 	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/aom/servicedefaults"
 	addonstatus "u-control/uc-aom/internal/aom/status"
+	"u-control/uc-aom/internal/aom/update"
+	"u-control/uc-aom/internal/aom/yaml"
 	"u-control/uc-aom/internal/pkg/manifest"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var manifestJson = `
 {
-  "manifestVersion": "0.1",
+  "manifestVersion": "0.2",
   "version": "%s",
   "title": "%s",
   "description": "Description",
@@ -61,19 +71,28 @@ func (r *remoteCatalogueMock) GetAddOnNames() ([]string, error) {
 	return nil, nil
 }
 
-func (r *remoteCatalogueMock) GetAddOnVersions(name string) ([]string, error) {
+func (r *remoteCatalogueMock) GetAddOnVersions(ctx context.Context, name string) ([]string, error) {
 	return nil, nil
 }
 
-func (r *remoteCatalogueMock) GetAddOn(name string, version string) (catalogue.CatalogueAddOn, error) {
+func (r *remoteCatalogueMock) GetAddOn(ctx context.Context, name string, version string) (catalogue.CatalogueAddOn, error) {
 	return catalogue.CatalogueAddOn{}, nil
 }
 
-func (r *remoteCatalogueMock) GetLatestAddOns() ([]*catalogue.CatalogueAddOn, error) {
+func (r *remoteCatalogueMock) FetchManifest(ctx context.Context, name string, version string) (*manifest.Root, error) {
+	return &manifest.Root{}, nil
+}
+
+func (r *remoteCatalogueMock) GetLatestAddOns(ctx context.Context) ([]*catalogue.CatalogueAddOn, error) {
 	args := r.Called()
 	return args.Get(0).([]*catalogue.CatalogueAddOn), args.Error(1)
 }
 
+func (r *remoteCatalogueMock) Refresh(ctx context.Context) error {
+	args := r.Called()
+	return args.Error(0)
+}
+
 func mockCatalogueAddons(name string, version string) []*catalogue.CatalogueAddOn {
 	manifestJson := fmt.Sprintf(manifestJson, version, name)
 	var root manifest.Root
@@ -92,13 +111,16 @@ func mockService(t *testing.T) *service.Service {
 		t.Fatal(err)
 	}
 	mockObj := &service.ServiceMultiComponentMock{}
+	mockObj.On("GetAddOn", mock.Anything).Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
 	reverseProxy := routes.NewReverseProxy(dbus.Initialize(), "", "", "", "",
 		mockObj.ReverseProxyWrite,
 		mockObj.ReverseProxyDelete,
 		mockObj.ReverseProxyCreateSymbolicLink,
 		mockObj.ReverseProxyRemoveSymbolicLink)
 	iamPermissionWriter := iam.NewIamPermissionWriter("", mockObj.IamPermissionWriterWrite, mockObj.IamPermissionWriterDelete)
-	service := service.NewService(&mockObj.MockStackService, reverseProxy, iamPermissionWriter, mockObj, manifestValidator, mockObj, nil)
+	protectionPolicy, _ := protection.NewPolicy()
+	serviceDefaults, _ := servicedefaults.NewPolicy(yaml.ServiceDefaults{})
+	service := service.NewService(&mockObj.MockStackService, reverseProxy, iamPermissionWriter, mockObj, manifestValidator, mockObj, nil, protectionPolicy, &budget.Budget{}, serviceDefaults, &cpuisolation.Policy{})
 	return service
 }
 
@@ -128,9 +150,7 @@ func TestAddOnServer_ListAddOns(t *testing.T) {
 		request: &grpc_api.ListAddOnsRequest{
 			Filter: grpc_api.ListAddOnsRequest_CATALOGUE,
 		},
-		stream: &ListAddOnResponseStreamMock{
-			capture: captureListAddOnResult,
-		},
+		stream: &ListAddOnResponseStreamMock{ResponseStreamMock{capture: captureListAddOnResult}},
 	}
 
 	uutFields := fields{
@@ -196,6 +216,7 @@ func TestAddOnServer_ListAddOns(t *testing.T) {
 			mockedAddons := mockCatalogueAddons(tt.addOnName, tt.addOnVersion)
 			tt.fields.remoteCatalogue.On("GetLatestAddOns").Return(mockedAddons, nil)
 			tt.args.stream.On("Send", mock.Anything).Return(nil)
+			tt.args.stream.On("Context").Return(context.Background())
 
 			// Act
 			if err := s.ListAddOns(tt.args.request, tt.args.stream); (err != nil) != tt.wantErr {
@@ -223,10 +244,253 @@ func TestAddOnServer_ListAddOns(t *testing.T) {
 			}
 
 			if !tt.isValid {
-				if len(addOns) > 0 {
-					t.Errorf("Expected addon list to be empty but got %s", addOns[0].Name)
+				if len(addOns) == 0 {
+					t.Fatal("Expected the incompatible addon to be listed but got none")
+				}
+				compatibility := addOns[0].Compatibility
+				if compatibility == nil || compatibility.Compatible {
+					t.Fatalf("Expected addon %s to be incompatible", addOns[0].Name)
+				}
+				if compatibility.Incompatibilities[0].Reason != grpc_api.IncompatibilityReason_INVALID_MANIFEST {
+					t.Errorf("Expected an invalid manifest but got %v", compatibility.Incompatibilities[0].Reason)
 				}
 			}
 		})
 	}
 }
+
+func TestAddOnServer_ListAddOnsRefreshesCatalogue(t *testing.T) {
+	// Arrange
+	remoteCatalogue := &remoteCatalogueMock{}
+	s := &AddOnServer{
+		service:         mockService(t),
+		remoteCatalogue: remoteCatalogue,
+	}
+	remoteCatalogue.On("Refresh").Return(nil)
+	remoteCatalogue.On("GetLatestAddOns").Return(mockCatalogueAddons("abc", "0.1.0-1"), nil)
+
+	captureListAddOnResult := make(chan *grpc_api.ListAddOnsResponse, 2)
+	stream := &ListAddOnResponseStreamMock{ResponseStreamMock{capture: captureListAddOnResult}}
+	stream.On("Send", mock.Anything).Return(nil)
+	stream.On("Context").Return(context.Background())
+	request := &grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_CATALOGUE, Refresh: true}
+
+	// Act
+	err := s.ListAddOns(request, stream)
+
+	// Assert
+	if err != nil {
+		t.Errorf("AddOnServer.ListAddOns() error = %v", err)
+	}
+	remoteCatalogue.AssertCalled(t, "Refresh")
+	remoteCatalogue.AssertCalled(t, "GetLatestAddOns")
+}
+
+func TestAddOnServer_ListAddOnsFiltersByCategoryAndSearch(t *testing.T) {
+	// Arrange
+	catalogueAddOns := []*catalogue.CatalogueAddOn{
+		{Name: "mqtt-bridge", Manifest: manifest.Root{Title: "MQTT Bridge", Categories: []string{"Connectivity"}, Keywords: []string{"mqtt", "cloud"}}, IsSummary: true},
+		{Name: "opcua-server", Manifest: manifest.Root{Title: "OPC UA Server", Categories: []string{"Connectivity"}, Keywords: []string{"opc ua"}}, IsSummary: true},
+		{Name: "dashboard", Manifest: manifest.Root{Title: "Dashboard", Categories: []string{"Visualization"}, Keywords: []string{"charts"}}, IsSummary: true},
+	}
+	testCases := map[string]struct {
+		category string
+		search   string
+		expected []string
+	}{
+		"no filter":          {"", "", []string{"dashboard", "mqtt-bridge", "opcua-server"}},
+		"category":           {"connectivity", "", []string{"mqtt-bridge", "opcua-server"}},
+		"keyword":            {"", "Cloud", []string{"mqtt-bridge"}},
+		"title":              {"", "server", []string{"opcua-server"}},
+		"category and words": {"Connectivity", "opc ua", []string{"opcua-server"}},
+		"no match":           {"Visualization", "mqtt", []string{}},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			remoteCatalogue := &remoteCatalogueMock{}
+			remoteCatalogue.On("GetLatestAddOns").Return(catalogueAddOns, nil)
+			s := &AddOnServer{service: mockService(t), remoteCatalogue: remoteCatalogue}
+
+			captureListAddOnResult := make(chan *grpc_api.ListAddOnsResponse, 8)
+			stream := &ListAddOnResponseStreamMock{ResponseStreamMock{capture: captureListAddOnResult}}
+			stream.On("Send", mock.Anything).Return(nil)
+			stream.On("Context").Return(context.Background())
+			request := &grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_CATALOGUE, Category: testCase.category, Search: testCase.search}
+
+			// Act
+			err := s.ListAddOns(request, stream)
+
+			// Assert
+			assert.NoError(t, err)
+			// Skip the heart beats
+			var addOns []*grpc_api.AddOn
+			for addOns == nil {
+				addOns = (<-captureListAddOnResult).GetAddOns()
+			}
+			names := []string{}
+			for _, addOn := range addOns {
+				names = append(names, addOn.Name)
+			}
+			assert.Equal(t, testCase.expected, names)
+		})
+	}
+}
+
+func listCatalogueAddOns(t *testing.T, s *AddOnServer, request *grpc_api.ListAddOnsRequest) (*grpc_api.ListAddOnsResponse, error) {
+	captureListAddOnResult := make(chan *grpc_api.ListAddOnsResponse, 8)
+	stream := &ListAddOnResponseStreamMock{ResponseStreamMock{capture: captureListAddOnResult}}
+	stream.On("Send", mock.Anything).Return(nil)
+	stream.On("Context").Return(context.Background())
+
+	if err := s.ListAddOns(request, stream); err != nil {
+		return nil, err
+	}
+
+	// Skip the heart beats
+	for {
+		response := <-captureListAddOnResult
+		if response.AddOns != nil {
+			return response, nil
+		}
+	}
+}
+
+func namesOf(addOns []*grpc_api.AddOn) []string {
+	names := make([]string, len(addOns))
+	for i, addOn := range addOns {
+		names[i] = addOn.Name
+	}
+	return names
+}
+
+func createQueryTestServer(t *testing.T, installedAddOns []*catalogue.CatalogueAddOn) *AddOnServer {
+	vendor := func(name string) *manifest.Vendor { return &manifest.Vendor{Name: name} }
+	catalogueAddOns := []*catalogue.CatalogueAddOn{
+		{Name: "a", Manifest: manifest.Root{Title: "Echo", Version: "1.0.0-1", ManifestVersion: manifest.ValidManifestVersion, Vendor: vendor("Weidmüller"), Platform: []string{"ucm"}}, IsSummary: true},
+		{Name: "b", Manifest: manifest.Root{Title: "delta", Version: "1.0.0-1", ManifestVersion: manifest.ValidManifestVersion, Vendor: vendor("Acme"), Platform: []string{"ucg"}}, IsSummary: true},
+		{Name: "c", Manifest: manifest.Root{Title: "Charlie", Version: "2.0.0-1", ManifestVersion: manifest.ValidManifestVersion, Vendor: vendor("Acme"), Platform: []string{"ucg", "ucm"}}, IsSummary: true},
+		{Name: "d", Manifest: manifest.Root{Title: "Bravo", Version: "1.0.0-1", ManifestVersion: manifest.ValidManifestVersion, Description: "Collects data of the PLC", Platform: []string{"ucm"}}, IsSummary: true},
+		{Name: "e", Manifest: manifest.Root{Title: "Alpha", Version: "1.0.0-1", ManifestVersion: manifest.ValidManifestVersion, Vendor: vendor("Weidmüller"), Platform: []string{"ucm"}}, IsSummary: true},
+	}
+	remoteCatalogue := &remoteCatalogueMock{}
+	remoteCatalogue.On("GetLatestAddOns").Return(catalogueAddOns, nil)
+	localCatalogue := &catalogue.CatalogueMock{}
+	localCatalogue.On("GetAddOns").Return(installedAddOns, nil)
+	s := &AddOnServer{service: mockService(t), remoteCatalogue: remoteCatalogue, localCatalogue: localCatalogue}
+
+	// Every add-on of the catalogue was released in 1.0.0-1 before its latest version
+	updateRemoteCatalogue := &catalogue.RemoteCatalogueMock{}
+	for _, addOn := range catalogueAddOns {
+		updateRemoteCatalogue.On("GetAddOnVersions", addOn.Name).Return([]string{"1.0.0-1", addOn.Manifest.Version}, nil)
+		updateRemoteCatalogue.On("FetchManifest", addOn.Name, addOn.Manifest.Version).Return(&addOn.Manifest, nil)
+	}
+	s.UseUpdateChecker(update.NewChecker(localCatalogue, updateRemoteCatalogue, s.service))
+	if _, err := s.updateChecker.CheckForUpdates(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestAddOnServer_ListAddOnsPages(t *testing.T) {
+	// Arrange
+	s := createQueryTestServer(t, nil)
+	request := &grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_CATALOGUE, PageSize: 2}
+
+	// Act
+	var pages [][]string
+	var totalSizes []int32
+	for {
+		response, err := listCatalogueAddOns(t, s, request)
+		assert.NoError(t, err)
+		pages = append(pages, namesOf(response.AddOns))
+		totalSizes = append(totalSizes, response.TotalSize)
+		if response.NextPageToken == "" {
+			break
+		}
+		request.PageToken = response.NextPageToken
+	}
+
+	// Assert
+	assert.Equal(t, [][]string{{"e", "d"}, {"c", "b"}, {"a"}}, pages)
+	assert.Equal(t, []int32{5, 5, 5}, totalSizes)
+}
+
+func TestAddOnServer_ListAddOnsRejectsTokenOfChangedCatalogue(t *testing.T) {
+	// Arrange
+	addOn := func(name string, version string) *catalogue.CatalogueAddOn {
+		return &catalogue.CatalogueAddOn{Name: name, Version: version, Manifest: manifest.Root{Title: name, Version: version}, IsSummary: true}
+	}
+	remoteCatalogue := &remoteCatalogueMock{}
+	remoteCatalogue.On("GetLatestAddOns").Return([]*catalogue.CatalogueAddOn{addOn("a", "1.0.0-1"), addOn("b", "1.0.0-1"), addOn("c", "1.0.0-1")}, nil).Once()
+	remoteCatalogue.On("GetLatestAddOns").Return([]*catalogue.CatalogueAddOn{addOn("a", "1.0.0-1"), addOn("aa", "1.0.0-1"), addOn("b", "1.0.0-1"), addOn("c", "1.0.0-1")}, nil)
+	s := &AddOnServer{service: mockService(t), remoteCatalogue: remoteCatalogue}
+	request := &grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_CATALOGUE, PageSize: 2}
+	firstPage, err := listCatalogueAddOns(t, s, request)
+	assert.NoError(t, err)
+	request.PageToken = firstPage.NextPageToken
+
+	// Act
+	_, err = listCatalogueAddOns(t, s, request)
+
+	// Assert
+	assert.Equal(t, codes.Aborted, status.Code(err))
+}
+
+func TestAddOnServer_ListAddOnsRejectsInvalidQueries(t *testing.T) {
+	// Arrange
+	s := createQueryTestServer(t, nil)
+	firstPage, err := listCatalogueAddOns(t, s, &grpc_api.ListAddOnsRequest{Filter: grpc_api.ListAddOnsRequest_CATALOGUE, PageSize: 2})
+	assert.NoError(t, err)
+	testCases := map[string]*grpc_api.ListAddOnsRequest{
+		"token of another query": {Filter: grpc_api.ListAddOnsRequest_CATALOGUE, PageSize: 2, Search: "acme", PageToken: firstPage.NextPageToken},
+		"malformed token":        {Filter: grpc_api.ListAddOnsRequest_CATALOGUE, PageToken: "not-a-token"},
+		"negative page size":     {Filter: grpc_api.ListAddOnsRequest_CATALOGUE, PageSize: -1},
+		"status of catalogue":    {Filter: grpc_api.ListAddOnsRequest_CATALOGUE, Statuses: []grpc_api.AddOnStatus{grpc_api.AddOnStatus_RUNNING}},
+	}
+
+	for name, request := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := listCatalogueAddOns(t, s, request)
+
+			// Assert
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestAddOnServer_ListAddOnsQueries(t *testing.T) {
+	installedAddOns := []*catalogue.CatalogueAddOn{
+		{Name: "a", Manifest: manifest.Root{Version: "1.0.0-1"}},
+		{Name: "c", Manifest: manifest.Root{Version: "1.0.0-1"}},
+		// The newer version of b is not compatible with the device
+		{Name: "b", Manifest: manifest.Root{Version: "0.9.0-1"}},
+	}
+	testCases := map[string]struct {
+		request  *grpc_api.ListAddOnsRequest
+		expected []string
+	}{
+		"search in description": {&grpc_api.ListAddOnsRequest{Search: "plc"}, []string{"d"}},
+		"search in vendor":      {&grpc_api.ListAddOnsRequest{Search: "acme"}, []string{"c", "b"}},
+		"compatible only":       {&grpc_api.ListAddOnsRequest{CompatibleOnly: true}, []string{"e", "d", "c", "a"}},
+		"by name descending":    {&grpc_api.ListAddOnsRequest{SortBy: grpc_api.ListAddOnsRequest_NAME, Descending: true}, []string{"e", "d", "c", "b", "a"}},
+		"by vendor":             {&grpc_api.ListAddOnsRequest{SortBy: grpc_api.ListAddOnsRequest_VENDOR}, []string{"d", "b", "c", "a", "e"}},
+		"update available":      {&grpc_api.ListAddOnsRequest{UpdateAvailable: true}, []string{"c"}},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			s := createQueryTestServer(t, installedAddOns)
+			testCase.request.Filter = grpc_api.ListAddOnsRequest_CATALOGUE
+
+			// Act
+			response, err := listCatalogueAddOns(t, s, testCase.request)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, namesOf(response.AddOns))
+		})
+	}
+}
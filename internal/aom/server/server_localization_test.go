@@ -0,0 +1,116 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/metadata"
+)
+
+func createLocalizedCatalogueAddOn() catalogue.CatalogueAddOn {
+	setting := manifest.NewSettings("mode", "Mode", true)
+	setting.LabelTranslations = manifest.Translations{"de": "Modus"}
+	setting.Select = []*manifest.Item{
+		{Label: "Fast", Value: "fast", Selected: true, LabelTranslations: manifest.Translations{"de": "Schnell"}},
+		{Label: "Safe", Value: "safe"},
+	}
+
+	return catalogue.CatalogueAddOn{
+		Name: "test-uc-addon",
+		Manifest: manifest.Root{
+			Title:                   "Title",
+			TitleTranslations:       manifest.Translations{"de": "Titel", "fr": "Titre"},
+			Description:             "Description",
+			DescriptionTranslations: manifest.Translations{"de-DE": "Beschreibung"},
+			Settings:                map[string][]*manifest.Setting{"environmentVariables": {setting}},
+		},
+	}
+}
+
+func TestGetPreferredLanguagesFrom(t *testing.T) {
+	testCases := map[string]struct {
+		metadata map[string]string
+		expected []language.Tag
+	}{
+		"no metadata":       {nil, nil},
+		"accept-language":   {map[string]string{"accept-language": "de-DE,de;q=0.9,en;q=0.8"}, []language.Tag{language.MustParse("de-DE"), language.German, language.English}},
+		"forwarded header":  {map[string]string{"grpcgateway-accept-language": "en"}, []language.Tag{language.English}},
+		"malformed":         {map[string]string{"accept-language": "%%"}, nil},
+		"other header only": {map[string]string{"authorization": "Bearer abcd"}, nil},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			if testCase.metadata != nil {
+				ctx = metadata.NewIncomingContext(ctx, metadata.New(testCase.metadata))
+			}
+
+			// Act
+			languages := getPreferredLanguagesFrom(ctx)
+
+			// Assert
+			assert.Equal(t, testCase.expected, languages)
+		})
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	translations := manifest.Translations{"de": "Titel", "fr": "Titre", "invalid tag!": "Invalid"}
+	testCases := map[string]struct {
+		languages []language.Tag
+		expected  string
+	}{
+		"no preference":       {nil, "Title"},
+		"german":              {[]language.Tag{language.German}, "Titel"},
+		"regional german":     {[]language.Tag{language.MustParse("de-AT")}, "Titel"},
+		"english over german": {[]language.Tag{language.English, language.German}, "Title"},
+		"unknown language":    {[]language.Tag{language.Japanese}, "Title"},
+		"fallback to french":  {[]language.Tag{language.Japanese, language.French}, "Titre"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			result := localize("Title", translations, testCase.languages)
+
+			// Assert
+			assert.Equal(t, testCase.expected, result)
+		})
+	}
+}
+
+func TestTransformCatalogueAddOnToGrpcAddOnLocalizesTexts(t *testing.T) {
+	// Arrange
+	uut := &AddOnServer{}
+	catalogueAddOn := createLocalizedCatalogueAddOn()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"accept-language": "de-DE,de;q=0.9"}))
+
+	// Act
+	german := uut.transformCatalogueAddOnToGrpcAddOn(catalogueAddOn, nil, grpc_api.AddOnView_FULL, "", getPreferredLanguagesFrom(ctx))
+	english := uut.transformCatalogueAddOnToGrpcAddOn(catalogueAddOn, nil, grpc_api.AddOnView_FULL, "", getPreferredLanguagesFrom(context.Background()))
+
+	// Assert
+	assert.Equal(t, "Titel", german.Title)
+	assert.Equal(t, "Beschreibung", german.Description)
+	assert.Equal(t, "Modus", german.Settings[0].Label)
+	germanItems := german.Settings[0].SettingOneof.(*grpc_api.Setting_DropDownList).DropDownList.Elements
+	assert.Equal(t, "Schnell", germanItems[0].Label)
+	assert.Equal(t, "fast", germanItems[0].Value)
+	assert.Equal(t, "Safe", germanItems[1].Label)
+
+	assert.Equal(t, "Title", english.Title)
+	assert.Equal(t, "Description", english.Description)
+	assert.Equal(t, "Mode", english.Settings[0].Label)
+	assert.Equal(t, "Fast", english.Settings[0].SettingOneof.(*grpc_api.Setting_DropDownList).DropDownList.Elements[0].Label)
+}
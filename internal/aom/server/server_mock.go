@@ -32,6 +32,6 @@ func NewServerUsingServiceMultiComponentMock() (*AddOnServer, *service.ServiceMu
 	envResolver := env.NewAddOnEnvironmentResolver(mockObj)
 	transactionResolver := service.NewTransactionScheduler()
 
-	uut := NewServer(serviceStub, "", "", mockObj, nil, iamClientMock, iamClientMock, statusResolver, envResolver, transactionResolver)
+	uut := NewServer(serviceStub, "", "", mockObj, nil, nil, nil, nil, iamClientMock, iamClientMock, statusResolver, envResolver, transactionResolver)
 	return uut, mockObj, iamClientMock
 }
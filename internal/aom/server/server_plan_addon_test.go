@@ -0,0 +1,82 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server_test
+
+import (
+	"context"
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+	"u-control/uc-aom/internal/aom/server"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+func createPlanTestManifest(version string) *manifest.Root {
+	return &manifest.Root{
+		ManifestVersion: manifest.ValidManifestVersion,
+		Title:           "Add-on",
+		Version:         version,
+		Platform:        []string{"ucm"},
+		Services:        map[string]*manifest.Service{"app": {Type: "docker-compose", Config: map[string]interface{}{"image": "test/addon:" + version}}},
+		Publish:         map[string]*manifest.ProxyRoute{"web": {From: "/web", To: "http://localhost:8080"}},
+	}
+}
+
+func TestPlanAddOnInstall(t *testing.T) {
+	// Arrange
+	uut, mockObj, iamClientMock := server.NewServerUsingServiceMultiComponentMock()
+	capture := make(chan *grpc_api.AddOnPlan, 10)
+	streamMock := server.NewPlanResponseStreamMock(context.Background(), iamClientMock, capture)
+
+	streamMock.On("Send", mock.Anything).Return(nil)
+	mockObj.On("GetAddOn", "addon").Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+	mockObj.On("FetchManifest", "addon", "1.0.0-1").Return(createPlanTestManifest("1.0.0-1"), nil)
+	mockObj.On("FetchDiskFootprint", "addon", "1.0.0-1").Return(catalogue.DiskFootprint{EstimatedInstallSize: 100}, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+	mockObj.On("AvailableSpaceInBytes").Return(uint64(1000), nil)
+
+	// Act
+	err := uut.PlanAddOn(&grpc_api.PlanAddOnRequest{AddOn: &grpc_api.AddOn{Name: "addon", Version: "1.0.0-1"}}, streamMock)
+
+	// Assert
+	assert.Nil(t, err)
+	close(capture)
+	var plan *grpc_api.AddOnPlan
+	for plan = range capture {
+	}
+
+	assert.Equal(t, grpc_api.AddOnPlan_INSTALL, plan.Operation)
+	assert.Equal(t, uint64(100), plan.DiskPlan.RequiredBytes)
+	assert.Equal(t, uint64(1000), plan.DiskPlan.AvailableBytes)
+	assert.Contains(t, plan.DockerCompose, "test/addon:1.0.0-1")
+	if assert.Len(t, plan.Routes, 1) {
+		assert.Equal(t, "addon-web", plan.Routes[0].Id)
+	}
+	assert.NotNil(t, plan.IamPermission)
+	mockObj.AssertNotCalled(t, "PullAddOn", mock.Anything, mock.Anything)
+	mockObj.MockStackService.AssertNotCalled(t, "CreateStackWithDockerCompose", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPlanAddOnRejectsDowngrade(t *testing.T) {
+	// Arrange
+	uut, mockObj, iamClientMock := server.NewServerUsingServiceMultiComponentMock()
+	streamMock := server.NewPlanResponseStreamMock(context.Background(), iamClientMock, nil)
+
+	streamMock.On("Send", mock.Anything).Return(nil)
+	installedAddOn := catalogue.CatalogueAddOn{Name: "addon", Version: "1.0.0-1", Manifest: *createPlanTestManifest("1.0.0-1")}
+	mockObj.On("GetAddOn", "addon").Return(installedAddOn, nil)
+
+	// Act
+	err := uut.PlanAddOn(&grpc_api.PlanAddOnRequest{AddOn: &grpc_api.AddOn{Name: "addon", Version: "0.9.0-1"}}, streamMock)
+
+	// Assert
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code(err))
+	mockObj.AssertNotCalled(t, "FetchManifest", mock.Anything, mock.Anything)
+}
@@ -62,6 +62,7 @@ func TestUpdateAddOnPlatformFail(t *testing.T) {
 	iamClientMock.On("IsAllowed", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(true, nil)
 	mockObj.On("GetAddOn", futureGrpcAddOn.Name).Return(currentAddOn, nil)
 	mockObj.On("FetchManifest", futureGrpcAddOn.Name, futureGrpcAddOn.Version).Return(&currentAddOn.Manifest, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
 	updateStreamMock.On("Send", mock.Anything).Return(nil)
 
 	updateReq := &grpc_api.UpdateAddOnRequest{
@@ -0,0 +1,109 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+	"u-control/uc-aom/internal/aom/registry"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func createRegistryCredentialsTestServer(t *testing.T, allowed bool) *AddOnServer {
+	root := t.TempDir()
+	store, err := registry.NewCredentialStore(os.ReadFile, os.WriteFile, filepath.Join(root, "credentials.enc"), filepath.Join(root, "credentials.key"), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Set(registry.Credentials{ServerAddress: "registry:5000", Username: "user", Password: "secret"}))
+
+	source := &registry.CatalogueSource{Name: registry.DefaultCatalogueSourceName, Credentials: registry.Credentials{ServerAddress: "registry:5000"}, Enabled: true}
+	newRegistry := func(source *registry.CatalogueSource) (registry.AddOnRegistry, error) {
+		return &registry.MockRegistry{}, nil
+	}
+	sources, err := registry.NewFederatedAddOnRegistry([]*registry.CatalogueSource{source}, filepath.Join(root, "sources.json"), filepath.Join(root, "origins.json"), os.ReadFile, os.WriteFile, store, newRegistry)
+	assert.NoError(t, err)
+
+	iamClientMock := &IamClientMock{}
+	iamClientMock.On("IsAllowed", "12345", "add-ons.manage").Return(allowed, nil)
+	return &AddOnServer{registryCredentials: store, catalogueSources: sources, iamServiceUcAomClient: iamClientMock}
+}
+
+func TestListRegistryCredentialsOmitsSecrets(t *testing.T) {
+	// Arrange
+	uut := createRegistryCredentialsTestServer(t, true)
+
+	// Act
+	response, err := uut.ListRegistryCredentials(createCatalogueSourcesTestContext(), &empty.Empty{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, response.Credentials, 1)
+	assert.Equal(t, "registry:5000", response.Credentials[0].ServerAddress)
+	assert.Equal(t, "user", response.Credentials[0].Username)
+	assert.Empty(t, response.Credentials[0].Password)
+}
+
+func TestSetRegistryCredentialsErrors(t *testing.T) {
+	// Arrange
+	uut := createRegistryCredentialsTestServer(t, true)
+	forbidden := createRegistryCredentialsTestServer(t, false)
+	invalid := &grpc_api.SetRegistryCredentialsRequest{Credentials: &grpc_api.RegistryCredentials{ServerAddress: "registry:5000", Password: "secret"}}
+
+	// Act
+	_, invalidErr := uut.SetRegistryCredentials(createCatalogueSourcesTestContext(), invalid)
+	_, missingErr := uut.SetRegistryCredentials(createCatalogueSourcesTestContext(), &grpc_api.SetRegistryCredentialsRequest{})
+	_, forbiddenErr := forbidden.SetRegistryCredentials(createCatalogueSourcesTestContext(), invalid)
+	_, notFoundErr := uut.DeleteRegistryCredentials(createCatalogueSourcesTestContext(), &grpc_api.DeleteRegistryCredentialsRequest{ServerAddress: "other:5000"})
+
+	// Assert
+	assert.Equal(t, codes.InvalidArgument, status.Code(invalidErr))
+	assert.Equal(t, codes.InvalidArgument, status.Code(missingErr))
+	assert.Equal(t, codes.PermissionDenied, status.Code(forbiddenErr))
+	assert.Equal(t, codes.NotFound, status.Code(notFoundErr))
+}
+
+func TestTestRegistryCredentials(t *testing.T) {
+	// Arrange
+	uut := createRegistryCredentialsTestServer(t, true)
+	var tested []registry.Credentials
+	uut.testRegistryCredentials = func(ctx context.Context, credentials *registry.Credentials) error {
+		tested = append(tested, *credentials)
+		if credentials.Password != "secret" {
+			return errors.New("GET \"http://registry:5000/v2/\": unexpected status code 401: unauthorized")
+		}
+		return nil
+	}
+
+	// Act
+	stored, storedErr := uut.TestRegistryCredentials(createCatalogueSourcesTestContext(), &grpc_api.TestRegistryCredentialsRequest{
+		Credentials: &grpc_api.RegistryCredentials{ServerAddress: "registry:5000"},
+	})
+	wrong, wrongErr := uut.TestRegistryCredentials(createCatalogueSourcesTestContext(), &grpc_api.TestRegistryCredentialsRequest{
+		Credentials: &grpc_api.RegistryCredentials{ServerAddress: "registry:5000", Username: "user", Password: "wrong"},
+	})
+	_, otherErr := uut.TestRegistryCredentials(createCatalogueSourcesTestContext(), &grpc_api.TestRegistryCredentialsRequest{
+		Credentials: &grpc_api.RegistryCredentials{ServerAddress: "other:5000", Username: "user", Password: "secret"},
+	})
+
+	// Assert
+	assert.NoError(t, storedErr)
+	assert.NoError(t, wrongErr)
+	assert.True(t, stored.Ok)
+	assert.False(t, wrong.Ok)
+	assert.Contains(t, wrong.Message, "unauthorized")
+	assert.Equal(t, "AUTH", wrong.Failure)
+	assert.Equal(t, codes.InvalidArgument, status.Code(otherErr))
+	assert.Len(t, tested, 2)
+	assert.Equal(t, "secret", tested[0].Password)
+	credentials, _ := uut.registryCredentials.Credentials("registry:5000")
+	assert.Equal(t, "secret", credentials.Password)
+}
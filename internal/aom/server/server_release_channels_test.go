@@ -0,0 +1,77 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"u-control/uc-aom/internal/aom/catalogue"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func createReleaseChannelsTestServer(t *testing.T, allowed bool) (*AddOnServer, *remoteCatalogueMock) {
+	store, err := catalogue.NewReleaseChannelStore(os.ReadFile, os.WriteFile, filepath.Join(t.TempDir(), "release-channels.json"))
+	assert.NoError(t, err)
+
+	iamClientMock := &IamClientMock{}
+	iamClientMock.On("IsAllowed", "12345", "add-ons.manage").Return(allowed, nil)
+	remoteCatalogue := &remoteCatalogueMock{}
+	return &AddOnServer{releaseChannels: store, remoteCatalogue: remoteCatalogue, iamServiceUcAomClient: iamClientMock}, remoteCatalogue
+}
+
+func TestSetReleaseChannel(t *testing.T) {
+	// Arrange
+	uut, remoteCatalogue := createReleaseChannelsTestServer(t, true)
+	refreshed := make(chan struct{}, 2)
+	remoteCatalogue.On("Refresh").Run(func(args mock.Arguments) { refreshed <- struct{}{} }).Return(nil)
+
+	// Act
+	initial, initialErr := uut.GetReleaseChannels(createCatalogueSourcesTestContext(), &empty.Empty{})
+	_, deviceErr := uut.SetReleaseChannel(createCatalogueSourcesTestContext(), &grpc_api.SetReleaseChannelRequest{Channel: grpc_api.ReleaseChannel_RC})
+	channels, addOnErr := uut.SetReleaseChannel(createCatalogueSourcesTestContext(), &grpc_api.SetReleaseChannelRequest{Name: "add-on", Channel: grpc_api.ReleaseChannel_BETA})
+
+	// Assert
+	assert.NoError(t, initialErr)
+	assert.NoError(t, deviceErr)
+	assert.NoError(t, addOnErr)
+	assert.Equal(t, grpc_api.ReleaseChannel_STABLE, initial.DefaultChannel)
+	assert.Empty(t, initial.AddOnChannels)
+	assert.Equal(t, grpc_api.ReleaseChannel_RC, channels.DefaultChannel)
+	assert.Equal(t, map[string]grpc_api.ReleaseChannel{"add-on": grpc_api.ReleaseChannel_BETA}, channels.AddOnChannels)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-refreshed:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the remote catalogue to be refreshed")
+		}
+	}
+}
+
+func TestSetReleaseChannelErrors(t *testing.T) {
+	// Arrange
+	uut, _ := createReleaseChannelsTestServer(t, true)
+	forbidden, _ := createReleaseChannelsTestServer(t, false)
+	unimplemented := &AddOnServer{iamServiceUcAomClient: uut.iamServiceUcAomClient}
+
+	// Act
+	_, unspecifiedErr := uut.SetReleaseChannel(createCatalogueSourcesTestContext(), &grpc_api.SetReleaseChannelRequest{})
+	_, unknownErr := uut.SetReleaseChannel(createCatalogueSourcesTestContext(), &grpc_api.SetReleaseChannelRequest{Channel: 42})
+	_, forbiddenErr := forbidden.SetReleaseChannel(createCatalogueSourcesTestContext(), &grpc_api.SetReleaseChannelRequest{Channel: grpc_api.ReleaseChannel_BETA})
+	_, unimplementedErr := unimplemented.GetReleaseChannels(createCatalogueSourcesTestContext(), &empty.Empty{})
+
+	// Assert
+	assert.Equal(t, codes.InvalidArgument, status.Code(unspecifiedErr))
+	assert.Equal(t, codes.InvalidArgument, status.Code(unknownErr))
+	assert.Equal(t, codes.PermissionDenied, status.Code(forbiddenErr))
+	assert.Equal(t, codes.Unimplemented, status.Code(unimplementedErr))
+}
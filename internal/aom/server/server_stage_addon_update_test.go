@@ -0,0 +1,87 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type stagerMock struct {
+	mock.Mock
+}
+
+func (m *stagerMock) Stage(ctx context.Context, repository string, tag string) (uint64, error) {
+	args := m.Called(repository, tag)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *stagerMock) Discard(repository string) error {
+	args := m.Called(repository)
+	return args.Error(0)
+}
+
+func createStageTestStream(allowed bool) (*StageAddOnUpdateResponseStreamMock, *IamClientMock) {
+	iamClientMock := &IamClientMock{}
+	iamClientMock.On("IsAllowed", "12345", "add-ons.manage").Return(allowed, nil)
+	stream := &StageAddOnUpdateResponseStreamMock{}
+	stream.On("Context").Return(createCatalogueSourcesTestContext())
+	return stream, iamClientMock
+}
+
+func TestStageAddOnUpdateErrors(t *testing.T) {
+	// Arrange
+	localCatalogue := &catalogue.CatalogueMock{}
+	localCatalogue.On("GetAddOn", "add-on").Return(catalogue.CatalogueAddOn{Name: "add-on", Version: "1.0.0-2"}, nil)
+	stager := &stagerMock{}
+	allowedStream, iamClientMock := createStageTestStream(true)
+	forbiddenStream, forbiddenIamClientMock := createStageTestStream(false)
+
+	uut := &AddOnServer{iamServiceUcAomClient: iamClientMock, localCatalogue: localCatalogue}
+	uut.UseStaging(stager)
+	forbidden := &AddOnServer{iamServiceUcAomClient: forbiddenIamClientMock, localCatalogue: localCatalogue}
+	forbidden.UseStaging(stager)
+	unimplemented := &AddOnServer{iamServiceUcAomClient: iamClientMock, localCatalogue: localCatalogue}
+
+	// Act
+	downgradeErr := uut.StageAddOnUpdate(&grpc_api.StageAddOnUpdateRequest{Name: "add-on", Version: "1.0.0-1"}, allowedStream)
+	installedErr := uut.StageAddOnUpdate(&grpc_api.StageAddOnUpdateRequest{Name: "add-on", Version: "1.0.0-2"}, allowedStream)
+	forbiddenErr := forbidden.StageAddOnUpdate(&grpc_api.StageAddOnUpdateRequest{Name: "add-on", Version: "1.0.0-3"}, forbiddenStream)
+	unimplementedErr := unimplemented.StageAddOnUpdate(&grpc_api.StageAddOnUpdateRequest{Name: "add-on", Version: "1.0.0-3"}, allowedStream)
+
+	// Assert
+	assert.Equal(t, codes.InvalidArgument, status.Code(downgradeErr))
+	assert.Equal(t, codes.InvalidArgument, status.Code(installedErr))
+	assert.Equal(t, codes.PermissionDenied, status.Code(forbiddenErr))
+	assert.Equal(t, codes.Unimplemented, status.Code(unimplementedErr))
+	stager.AssertNotCalled(t, "Stage", mock.Anything, mock.Anything)
+}
+
+func TestStageAddOnUpdateFailsIfDownloadFails(t *testing.T) {
+	// Arrange
+	localCatalogue := &catalogue.CatalogueMock{}
+	localCatalogue.On("GetAddOn", "add-on").Return(catalogue.CatalogueAddOn{Name: "add-on", Version: "1.0.0-2"}, nil)
+	stager := &stagerMock{}
+	stager.On("Stage", "add-on", "1.0.0-3").Return(uint64(0), context.DeadlineExceeded)
+	stream, iamClientMock := createStageTestStream(true)
+	stream.On("Send", &grpc_api.StageAddOnUpdateResponse{}).Return(nil)
+	uut := &AddOnServer{iamServiceUcAomClient: iamClientMock, localCatalogue: localCatalogue}
+	uut.UseStaging(stager)
+
+	// Act
+	err := uut.StageAddOnUpdate(&grpc_api.StageAddOnUpdateRequest{Name: "add-on", Version: "1.0.0-3"}, stream)
+
+	// Assert
+	assert.Error(t, err)
+	stager.AssertExpectations(t)
+	stream.AssertNotCalled(t, "Send", mock.MatchedBy(func(response *grpc_api.StageAddOnUpdateResponse) bool { return response.Name != "" }))
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	grpc_api "u-control/uc-aom/internal/aom/grpc"
+	"u-control/uc-aom/internal/aom/update"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func createUpdatePoliciesTestServer(t *testing.T, allowed bool) (*AddOnServer, *update.History) {
+	dir := t.TempDir()
+	policies, err := update.NewPolicyStore(os.ReadFile, os.WriteFile, filepath.Join(dir, "update-policies.json"))
+	assert.NoError(t, err)
+	history, err := update.NewHistory(os.ReadFile, os.WriteFile, filepath.Join(dir, "update-history.json"), filepath.Join(dir, "update-history-state.json"))
+	assert.NoError(t, err)
+
+	iamClientMock := &IamClientMock{}
+	iamClientMock.On("IsAllowed", "12345", "add-ons.manage").Return(allowed, nil)
+	uut := &AddOnServer{iamServiceUcAomClient: iamClientMock}
+	uut.UseUpdatePolicies(policies, history)
+	return uut, history
+}
+
+func TestSetUpdatePolicy(t *testing.T) {
+	// Arrange
+	uut, _ := createUpdatePoliciesTestServer(t, true)
+	window := &grpc_api.MaintenanceWindow{Weekdays: []uint32{uint32(time.Sunday)}, Start: "02:00", End: "04:00"}
+
+	// Act
+	initial, initialErr := uut.GetUpdatePolicies(createCatalogueSourcesTestContext(), &empty.Empty{})
+	_, policyErr := uut.SetUpdatePolicy(createCatalogueSourcesTestContext(), &grpc_api.SetUpdatePolicyRequest{Name: "add-on", Policy: grpc_api.UpdatePolicy_AUTO_PATCH, PinnedVersion: "1.2.3-5"})
+	policies, windowsErr := uut.SetMaintenanceWindows(createCatalogueSourcesTestContext(), &grpc_api.SetMaintenanceWindowsRequest{MaintenanceWindows: []*grpc_api.MaintenanceWindow{window}})
+
+	// Assert
+	assert.NoError(t, initialErr)
+	assert.NoError(t, policyErr)
+	assert.NoError(t, windowsErr)
+	assert.Empty(t, initial.AddOns)
+	assert.Empty(t, initial.MaintenanceWindows)
+	assert.Equal(t, []*grpc_api.AddOnUpdatePolicy{{Name: "add-on", Policy: grpc_api.UpdatePolicy_AUTO_PATCH, PinnedVersion: "1.2.3-5"}}, policies.AddOns)
+	assert.Equal(t, []*grpc_api.MaintenanceWindow{window}, policies.MaintenanceWindows)
+}
+
+func TestSetUpdatePolicyErrors(t *testing.T) {
+	// Arrange
+	uut, _ := createUpdatePoliciesTestServer(t, true)
+	forbidden, _ := createUpdatePoliciesTestServer(t, false)
+	unimplemented := &AddOnServer{iamServiceUcAomClient: uut.iamServiceUcAomClient}
+
+	// Act
+	_, unknownErr := uut.SetUpdatePolicy(createCatalogueSourcesTestContext(), &grpc_api.SetUpdatePolicyRequest{Name: "add-on", Policy: 42})
+	_, invalidPinErr := uut.SetUpdatePolicy(createCatalogueSourcesTestContext(), &grpc_api.SetUpdatePolicyRequest{Name: "add-on", Policy: grpc_api.UpdatePolicy_AUTO_MINOR, PinnedVersion: "latest"})
+	_, invalidWindowErr := uut.SetMaintenanceWindows(createCatalogueSourcesTestContext(), &grpc_api.SetMaintenanceWindowsRequest{MaintenanceWindows: []*grpc_api.MaintenanceWindow{{Start: "2 am", End: "04:00"}}})
+	_, forbiddenErr := forbidden.GetUpdateHistory(createCatalogueSourcesTestContext(), &empty.Empty{})
+	_, unimplementedErr := unimplemented.GetUpdatePolicies(createCatalogueSourcesTestContext(), &empty.Empty{})
+
+	// Assert
+	assert.Equal(t, codes.InvalidArgument, status.Code(unknownErr))
+	assert.Equal(t, codes.InvalidArgument, status.Code(invalidPinErr))
+	assert.Equal(t, codes.InvalidArgument, status.Code(invalidWindowErr))
+	assert.Equal(t, codes.PermissionDenied, status.Code(forbiddenErr))
+	assert.Equal(t, codes.Unimplemented, status.Code(unimplementedErr))
+}
+
+func TestGetUpdateHistory(t *testing.T) {
+	// Arrange
+	uut, history := createUpdatePoliciesTestServer(t, true)
+	updated := time.Date(2023, 6, 10, 2, 30, 0, 0, time.UTC)
+	assert.NoError(t, history.Record(update.HistoryEntry{Time: updated, Name: "add-on", FromVersion: "1.0.0-1", ToVersion: "1.0.0-2", Result: update.ResultUpdated}))
+	assert.NoError(t, history.Record(update.HistoryEntry{Time: updated.Add(time.Hour), Name: "add-on", FromVersion: "1.0.0-2", ToVersion: "1.0.0-3", Result: update.ResultFailed, Message: "pull failed"}))
+
+	// Act
+	got, err := uut.GetUpdateHistory(createCatalogueSourcesTestContext(), &empty.Empty{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, got.Entries, 2)
+	assert.Equal(t, grpc_api.UpdateResult_FAILED, got.Entries[0].Result)
+	assert.Equal(t, "pull failed", got.Entries[0].Message)
+	assert.Equal(t, updated, got.Entries[1].Time.AsTime())
+	assert.Equal(t, grpc_api.UpdateResult_UPDATED, got.Entries[1].Result)
+}
@@ -7,30 +7,36 @@ package service
 import (
 	"u-control/uc-aom/internal/aom/catalogue"
 	"u-control/uc-aom/internal/aom/docker"
+	"u-control/uc-aom/internal/aom/protection"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// StopInstalledAddOns stops all add-ons that are installed.
+// StopInstalledAddOns stops all add-ons that are installed, except protected add-ons which must not be stopped.
 //
 // Normally all add-on containers should be in stopped mode after boot-up.
 // However, this might not always be the case e.g. if the uc-aom service is restart manually.
 // In this scenario the running container would lead to an error while recreating the external network and reconnecting the container.
-func StopInstalledAddOns(localCatalogue catalogue.LocalAddOnCatalogue, stackService docker.StackServiceAPI) error {
+func StopInstalledAddOns(localCatalogue catalogue.LocalAddOnCatalogue, stackService docker.StackServiceAPI, protectionPolicy *protection.Policy) error {
 	log.Traceln("Stop all add-ons")
 	addOns, err := localCatalogue.GetAddOns()
 	if err != nil {
 		return err
 	}
 
-	stop(addOns, stackService)
+	stop(addOns, stackService, protectionPolicy)
 
 	return nil
 }
 
-func stop(addOns []*catalogue.CatalogueAddOn, stackService docker.StackServiceAPI) {
+func stop(addOns []*catalogue.CatalogueAddOn, stackService docker.StackServiceAPI, protectionPolicy *protection.Policy) {
 
 	for _, addOn := range addOns {
+		if !protectionPolicy.IsAllowed(addOn.Name, &addOn.Manifest, protection.Stop) {
+			log.Infof("Not stopping the protected add-on '%s'", addOn.Name)
+			continue
+		}
+
 		err := stackService.StopStack(addOn.Name)
 		if err != nil {
 			log.Errorf("Error stop add-on stack '%s': %v", addOn.Name, err)
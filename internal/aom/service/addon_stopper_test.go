@@ -9,19 +9,30 @@ import (
 	"testing"
 	"u-control/uc-aom/internal/aom/catalogue"
 	"u-control/uc-aom/internal/aom/docker"
+	"u-control/uc-aom/internal/aom/protection"
+	"u-control/uc-aom/internal/aom/utils"
 	"u-control/uc-aom/internal/pkg/manifest"
 )
 
 func newArgs() *args {
+	protectionPolicy, _ := protection.NewPolicy()
 	return &args{
-		localCatalogue: &catalogue.CatalogueMock{},
-		stackService:   &docker.MockStackService{},
+		localCatalogue:   &catalogue.CatalogueMock{},
+		stackService:     &docker.MockStackService{},
+		protectionPolicy: protectionPolicy,
 	}
 }
 
 type args struct {
-	localCatalogue *catalogue.CatalogueMock
-	stackService   *docker.MockStackService
+	localCatalogue   *catalogue.CatalogueMock
+	stackService     *docker.MockStackService
+	protectionPolicy *protection.Policy
+}
+
+func (a *args) withProtectedAddOn(protectedAddOn *catalogue.CatalogueAddOn) *args {
+	a.protectionPolicy, _ = protection.NewPolicy(&protection.Entry{AddOnSelector: utils.AddOnSelector{Name: protectedAddOn.Name}, AllowedOperations: []protection.Operation{protection.Update}})
+	a.localCatalogue.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{protectedAddOn}, nil)
+	return a
 }
 
 func (a *args) withInstalledAddOns(installedAddOns []*catalogue.CatalogueAddOn) *args {
@@ -71,6 +82,11 @@ func TestStopInstalledAddOns(t *testing.T) {
 			}),
 			wantErr: false,
 		},
+		{
+			name:    "shall not stop protected add-ons",
+			args:    newArgs().withProtectedAddOn(&catalogue.CatalogueAddOn{Name: "protectedAddOn", Version: "1.0.0-1"}),
+			wantErr: false,
+		},
 		{
 			name:    "shall not call stop if no apps are installed",
 			args:    newArgs().withInstalledAddOns([]*catalogue.CatalogueAddOn{}),
@@ -84,7 +100,7 @@ func TestStopInstalledAddOns(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := StopInstalledAddOns(tt.args.localCatalogue, tt.args.stackService); (err != nil) != tt.wantErr {
+			if err := StopInstalledAddOns(tt.args.localCatalogue, tt.args.stackService, tt.args.protectionPolicy); (err != nil) != tt.wantErr {
 				t.Errorf("StopInstalledAddOns() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			tt.args.assertExpectations(t)
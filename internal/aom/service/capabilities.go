@@ -54,6 +54,11 @@ func (r *Capabilities) Validate() error {
 	return nil
 }
 
+// Returns true if one of the platforms is the platform of the device.
+func IsPlatformSupported(platforms []string) bool {
+	return NewCapabilities(nil, platforms...).validatePlatform() == nil
+}
+
 func (r *Capabilities) validatePlatform() error {
 	hostname, err := getHostPlatform()
 	if err != nil {
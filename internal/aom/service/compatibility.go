@@ -0,0 +1,172 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package service
+
+import (
+	"errors"
+	"u-control/uc-aom/internal/aom/budget"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/protection"
+	"u-control/uc-aom/internal/pkg/manifest"
+)
+
+// Reason why an add-on cannot be installed on the device.
+type IncompatibilityReason int
+
+const (
+	InvalidManifest IncompatibilityReason = iota + 1
+	UnsupportedManifestVersion
+	UnsupportedPlatform
+	MissingFeature
+	InsufficientDiskSpace
+	ExceedsResourceBudget
+	OperationNotPermitted
+)
+
+// Describes why an add-on cannot be installed on the device.
+type Incompatibility struct {
+	Reason  IncompatibilityReason
+	Message string
+}
+
+// A check of the install of an add-on, whose failure is an incompatibility with the given reason.
+type installCheck struct {
+	reason IncompatibilityReason
+	run    func() error
+
+	// Returns whether the error of run is an incompatibility, nil if every error is one
+	isIncompatibility func(err error) bool
+
+	// Whether the check is skipped for a summary, which lacks the fields the check requires
+	requiresFullManifest bool
+}
+
+// Returns the checks of the install of the add-on identified by name with the manifest,
+// which are run by the install, the update and their plans, and evaluated by CheckCompatibility.
+// installed is the installed add-on, nil if the add-on is not installed.
+// allocated are the resources of the installed add-ons, taken by the budget check if nil.
+func (s *Service) installChecksOf(name string, addOnManifest *manifest.Root, installed *catalogue.CatalogueAddOn, allocated *AllocatedResources) []installCheck {
+	capabilities := NewCapabilities(s.system, addOnManifest.Platform...).WithFeatures(addOnManifest.Features)
+	var installedManifest *manifest.Root
+	if installed != nil {
+		installedManifest = &installed.Manifest
+	}
+
+	checks := make([]installCheck, 0, 6)
+	if installed != nil && installed.Version != addOnManifest.Version {
+		checks = append(checks, installCheck{
+			reason:            OperationNotPermitted,
+			run:               func() error { return s.checkOperationPermitted(*installed, protection.Update) },
+			isIncompatibility: isOperationNotPermitted,
+		})
+	}
+	return append(checks,
+		installCheck{
+			reason:               InvalidManifest,
+			run:                  func() error { return s.Validator.Validate(addOnManifest) },
+			requiresFullManifest: true,
+		},
+		installCheck{
+			reason: UnsupportedManifestVersion,
+			run:    NewManifestVersionValidator(addOnManifest.ManifestVersion).Validate,
+		},
+		installCheck{
+			reason: UnsupportedPlatform,
+			run:    capabilities.validatePlatform,
+		},
+		installCheck{
+			reason:            MissingFeature,
+			run:               capabilities.validateFeatures,
+			isIncompatibility: func(err error) bool { return errors.Is(err, SshRootAccessNotEnabledError) },
+		},
+		// The resources of a summary are unknown, so only the number of add-ons is checked for it
+		installCheck{
+			reason:            ExceedsResourceBudget,
+			run:               func() error { return s.checkResourceBudget(name, addOnManifest, installedManifest, allocated) },
+			isIncompatibility: isResourceBudgetExceeded,
+		},
+	)
+}
+
+// Runs the checks of the install of the add-on identified by name with the manifest.
+// installed is the installed add-on, nil if the add-on is not installed.
+// Returns the error of the first failing check.
+func (s *Service) checkInstall(name string, addOnManifest *manifest.Root, installed *catalogue.CatalogueAddOn) error {
+	for _, check := range s.installChecksOf(name, addOnManifest, installed, nil) {
+		if err := check.run(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Evaluates the checks of the install of the catalogue add-on without side effects.
+// Returns the incompatibilities with the device, none if the add-on is compatible.
+// The schema is only checked if the full manifest is known.
+func (s *Service) CheckCompatibility(addOn *catalogue.CatalogueAddOn) ([]*Incompatibility, error) {
+	return s.CheckCompatibilityWith(addOn, nil)
+}
+
+// Evaluates the checks of the install of the catalogue add-on as CheckCompatibility does,
+// with the allocated resources of the installed add-ons taken once for the checks of several add-ons.
+func (s *Service) CheckCompatibilityWith(addOn *catalogue.CatalogueAddOn, allocated *AllocatedResources) ([]*Incompatibility, error) {
+	var installed *catalogue.CatalogueAddOn
+	installedAddOn, err := s.localCatalogue.GetAddOn(addOn.Name)
+	if err == nil {
+		installed = &installedAddOn
+	} else if !errors.Is(err, catalogue.ErrorAddOnNotFound) {
+		return nil, err
+	}
+
+	incompatibilities := make([]*Incompatibility, 0)
+	for _, check := range s.installChecksOf(addOn.Name, &addOn.Manifest, installed, allocated) {
+		if check.requiresFullManifest && addOn.IsSummary {
+			continue
+		}
+		err := check.run()
+		if err == nil {
+			continue
+		}
+		if check.isIncompatibility != nil && !check.isIncompatibility(err) {
+			return nil, err
+		}
+		incompatibilities = append(incompatibilities, &Incompatibility{Reason: check.reason, Message: err.Error()})
+	}
+	return incompatibilities, nil
+}
+
+// Evaluates the disk space check of the install of the catalogue add-on without side effects.
+// Returns the incompatibility if the disk space is insufficient, otherwise nil.
+// As on update, the previous version of an installed add-on is not credited and its volumes are kept.
+func (s *Service) CheckDiskSpaceCompatibility(addOn *catalogue.CatalogueAddOn) (*Incompatibility, error) {
+	footprint, err := s.localCatalogue.FetchDiskFootprint(addOn.Name, addOn.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := manifest.GetVolumeNames(addOn.Manifest.Environments)
+	installedAddOn, err := s.localCatalogue.GetAddOn(addOn.Name)
+	if err == nil {
+		volumes = getNewVolumeNames(&installedAddOn.Manifest, &addOn.Manifest)
+	} else if !errors.Is(err, catalogue.ErrorAddOnNotFound) {
+		return nil, err
+	}
+
+	err = s.checkDiskSpace(footprint, volumes)
+	if notEnoughDiskSpace, ok := err.(*NotEnoughDiskSpaceError); ok {
+		return &Incompatibility{Reason: InsufficientDiskSpace, Message: notEnoughDiskSpace.Error()}, nil
+	}
+	return nil, err
+}
+
+func isOperationNotPermitted(err error) bool {
+	var notPermitted *OperationNotPermittedError
+	return errors.As(err, &notPermitted)
+}
+
+func isResourceBudgetExceeded(err error) bool {
+	var exceeded *budget.ExceededError
+	return errors.As(err, &exceeded)
+}
@@ -0,0 +1,203 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package service_test
+
+import (
+	"testing"
+	"u-control/uc-aom/internal/aom/budget"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func reasonsOf(incompatibilities []*service.Incompatibility) []service.IncompatibilityReason {
+	reasons := make([]service.IncompatibilityReason, len(incompatibilities))
+	for i, incompatibility := range incompatibilities {
+		reasons[i] = incompatibility.Reason
+	}
+	return reasons
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	rootAccess := []manifest.Feature{{Name: "ucontrol.software.root_access"}}
+	testCases := map[string]struct {
+		prepare          func(addOn *catalogue.CatalogueAddOn)
+		rootAccess       bool
+		expected         []service.IncompatibilityReason
+		expectedValidate bool
+	}{
+		"compatible": {
+			prepare:          func(addOn *catalogue.CatalogueAddOn) {},
+			expected:         []service.IncompatibilityReason{},
+			expectedValidate: true,
+		},
+		"wrong platform and manifest version": {
+			prepare: func(addOn *catalogue.CatalogueAddOn) {
+				addOn.Manifest.Platform = []string{"ucg"}
+				addOn.Manifest.ManifestVersion = "0.1"
+			},
+			expected:         []service.IncompatibilityReason{service.UnsupportedManifestVersion, service.UnsupportedPlatform},
+			expectedValidate: true,
+		},
+		"root access disabled": {
+			prepare:          func(addOn *catalogue.CatalogueAddOn) { addOn.Manifest.Features = rootAccess },
+			expected:         []service.IncompatibilityReason{service.MissingFeature},
+			expectedValidate: true,
+		},
+		"root access enabled": {
+			prepare:          func(addOn *catalogue.CatalogueAddOn) { addOn.Manifest.Features = rootAccess },
+			rootAccess:       true,
+			expected:         []service.IncompatibilityReason{},
+			expectedValidate: true,
+		},
+		"summary": {
+			prepare: func(addOn *catalogue.CatalogueAddOn) {
+				addOn.IsSummary = true
+				addOn.Manifest.Features = rootAccess
+			},
+			expected:         []service.IncompatibilityReason{service.MissingFeature},
+			expectedValidate: false,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockObj := &service.ServiceMultiComponentMock{}
+			mockObj.On("Validate", mock.Anything).Return(nil)
+			mockObj.On("IsSshRootAccessEnabled").Return(testCase.rootAccess, nil)
+			mockObj.On("GetAddOn", "addontest").Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+			addOn := newAddOn("addontest", "add-on-test", "1.0.0-1", "docker-image", "test-volume")
+			testCase.prepare(addOn)
+			uut := createUut(mockObj)
+
+			// Act
+			incompatibilities, err := uut.CheckCompatibility(addOn)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, reasonsOf(incompatibilities))
+			if testCase.expectedValidate {
+				mockObj.AssertCalled(t, "Validate", &addOn.Manifest)
+			} else {
+				mockObj.AssertNotCalled(t, "Validate", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCheckCompatibilityOfResourceBudget(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	otherAddOn := newAddOn("other", "other", "1.0.0-1", "other-image", "other-volume")
+	addOn := newAddOn("addontest", "add-on-test", "1.0.0-1", "docker-image", "test-volume")
+	addOn.IsSummary = true
+	mockObj.On("GetAddOn", addOn.Name).Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+	mockObj.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{otherAddOn}, nil)
+	uut := createUutWithResourceBudget(mockObj, &budget.Budget{MaxRunningAddOns: 1})
+
+	// Act
+	incompatibilities, err := uut.CheckCompatibility(addOn)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []service.IncompatibilityReason{service.ExceedsResourceBudget}, reasonsOf(incompatibilities))
+}
+
+func TestCheckCompatibilityWithAllocatedResources(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	otherAddOn := newAddOn("other", "other", "1.0.0-1", "other-image", "other-volume")
+	addOn := newAddOn("addontest", "add-on-test", "1.0.0-1", "docker-image", "test-volume")
+	addOn.IsSummary = true
+	secondAddOn := newAddOn("second", "second", "1.0.0-1", "second-image", "second-volume")
+	secondAddOn.IsSummary = true
+	mockObj.On("GetAddOn", mock.Anything).Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+	mockObj.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{otherAddOn}, nil)
+	uut := createUutWithResourceBudget(mockObj, &budget.Budget{MaxRunningAddOns: 1})
+
+	// Act
+	allocated, allocatedErr := uut.AllocatedResources()
+	incompatibilities, err := uut.CheckCompatibilityWith(addOn, allocated)
+	secondIncompatibilities, secondErr := uut.CheckCompatibilityWith(secondAddOn, allocated)
+
+	// Assert
+	assert.NoError(t, allocatedErr)
+	assert.NoError(t, err)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, []service.IncompatibilityReason{service.ExceedsResourceBudget}, reasonsOf(incompatibilities))
+	assert.Equal(t, []service.IncompatibilityReason{service.ExceedsResourceBudget}, reasonsOf(secondIncompatibilities))
+	mockObj.AssertNumberOfCalls(t, "GetAddOns", 1)
+}
+
+func TestCheckCompatibilityOfProtectedUpdate(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	installedAddOn := newAddOn("test-uc-addon-codesys-pkg", "test-uc-addon-codesys", "0.1.0-1", "docker-image", "test-volume")
+	installedAddOn.Manifest.Version = installedAddOn.Version
+	addOn := newAddOn("test-uc-addon-codesys-pkg", "test-uc-addon-codesys", "0.2.0-1", "docker-image", "test-volume")
+	addOn.Manifest.Version = addOn.Version
+	addOn.IsSummary = true
+	mockObj.On("GetAddOn", addOn.Name).Return(*installedAddOn, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+	uut := createUutWithProtectionPolicy(mockObj, codesysProtectionPolicy(t))
+
+	// Act
+	incompatibilities, err := uut.CheckCompatibility(addOn)
+	installedIncompatibilities, installedErr := uut.CheckCompatibility(installedAddOn)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, installedErr)
+	assert.Equal(t, []service.IncompatibilityReason{service.OperationNotPermitted}, reasonsOf(incompatibilities))
+	assert.NotContains(t, reasonsOf(installedIncompatibilities), service.OperationNotPermitted)
+}
+
+func TestCheckDiskSpaceCompatibility(t *testing.T) {
+	installSize := uint64(0xdeadbeef)
+	testCases := map[string]struct {
+		availableBytes uint64
+		installed      bool
+		incompatible   bool
+	}{
+		"enough space":            {availableBytes: installSize, installed: false, incompatible: false},
+		"insufficient space":      {availableBytes: installSize - 1, installed: false, incompatible: true},
+		"enough space for update": {availableBytes: installSize, installed: true, incompatible: false},
+		"insufficient for update": {availableBytes: installSize - 1, installed: true, incompatible: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockObj := &service.ServiceMultiComponentMock{}
+			addOn := newAddOn("addontest", "add-on-test", "2.0.0-1", "docker-image", "test-volume")
+			mockObj.On("AvailableSpaceInBytes").Return(testCase.availableBytes, nil)
+			mockObj.On("FetchDiskFootprint", addOn.Name, addOn.Version).Return(catalogue.DiskFootprint{EstimatedInstallSize: installSize}, nil)
+			if testCase.installed {
+				installedAddOn := newAddOn("addontest", "add-on-test", "1.0.0-1", "docker-image", "test-volume")
+				mockObj.On("GetAddOn", addOn.Name).Return(*installedAddOn, nil)
+			} else {
+				mockObj.On("GetAddOn", addOn.Name).Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+			}
+			uut := createUut(mockObj)
+
+			// Act
+			incompatibility, err := uut.CheckDiskSpaceCompatibility(addOn)
+
+			// Assert
+			assert.NoError(t, err)
+			if !testCase.incompatible {
+				assert.Nil(t, incompatibility)
+				return
+			}
+			if assert.NotNil(t, incompatibility) {
+				assert.Equal(t, service.InsufficientDiskSpace, incompatibility.Reason)
+			}
+		})
+	}
+}
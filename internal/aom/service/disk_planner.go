@@ -0,0 +1,124 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package service
+
+import (
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/docker"
+	"u-control/uc-aom/internal/aom/utils"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var (
+	DISK_HEADROOM_BYTES  = utils.GetEnvUint64("DISK_HEADROOM_BYTES", defaultDiskHeadroomBytes)
+	VOLUME_RESERVE_BYTES = utils.GetEnvUint64("VOLUME_RESERVE_BYTES", defaultVolumeReserveBytes)
+)
+
+// Breakdown of the disk space required to install an add-on.
+type DiskPlan struct {
+	ImageBytes        uint64 // uncompressed docker image layers which are not present yet
+	PresentLayerBytes uint64 // uncompressed docker image layers which are already present and therefore not required
+	ArchiveBytes      uint64 // docker image archives which are held during the import
+	CacheBytes        uint64 // docker image archives which are downloaded into the blob cache and not cached yet
+	StagingBytes      uint64 // docker image archives which are downloaded into the staging area
+	VolumeBytes       uint64 // reserve for the named volumes created by the add-on
+	HeadroomBytes     uint64 // headroom which is kept free on the data partition
+	IsEstimated       bool   // true if ImageBytes is estimated from the compressed layer sizes
+}
+
+// Returns the total required disk space in bytes.
+func (p *DiskPlan) RequiredBytes() uint64 {
+	return p.ImageBytes + p.ArchiveBytes + p.CacheBytes + p.StagingBytes + p.VolumeBytes + p.HeadroomBytes
+}
+
+// Tells whether a blob is cached on the data partition already.
+type BlobCache interface {
+	Contains(desc ocispec.Descriptor) bool
+}
+
+// Plans the disk space required to install the docker images and volumes of an add-on.
+type DiskPlanner struct {
+	stackService       docker.StackServiceAPI
+	headroomBytes      uint64
+	volumeReserveBytes uint64
+	blobCache          BlobCache
+	stagingArea        BlobCache
+}
+
+// Creates a new DiskPlanner which keeps headroomBytes free and reserves volumeReserveBytes per new volume.
+func NewDiskPlanner(stackService docker.StackServiceAPI, headroomBytes uint64, volumeReserveBytes uint64) *DiskPlanner {
+	return &DiskPlanner{stackService: stackService, headroomBytes: headroomBytes, volumeReserveBytes: volumeReserveBytes}
+}
+
+// Plans the disk space of the docker image archives, which are downloaded into the blob cache before they are imported.
+func (p *DiskPlanner) UseBlobCache(cache BlobCache) {
+	p.blobCache = cache
+}
+
+// Plans the disk space of the staging area, the archives of staged packages are not downloaded on install.
+func (p *DiskPlanner) UseStagingArea(area BlobCache) {
+	p.stagingArea = area
+}
+
+// Returns the plan to download the docker image archives described by footprint into the staging area.
+// A staged package of the same add-on is replaced only after the download, so its archives are not credited.
+func (p *DiskPlanner) PlanStaging(footprint catalogue.DiskFootprint) *DiskPlan {
+	return &DiskPlan{StagingBytes: footprint.ArchiveSize, HeadroomBytes: p.headroomBytes}
+}
+
+// Returns the plan to install the docker images described by footprint and to create the given volumes.
+// Layers which are already present in docker are not required, neither are archives which are cached already.
+// If the footprint has no layer information, the estimated install size is used instead.
+func (p *DiskPlanner) Plan(footprint catalogue.DiskFootprint, volumes []string) (*DiskPlan, error) {
+	plan := &DiskPlan{
+		VolumeBytes:   uint64(len(volumes)) * p.volumeReserveBytes,
+		HeadroomBytes: p.headroomBytes,
+	}
+	if p.blobCache != nil {
+		for _, archive := range footprint.Archives {
+			if p.stagingArea != nil && p.stagingArea.Contains(archive) {
+				continue
+			}
+			if !p.blobCache.Contains(archive) {
+				plan.CacheBytes += uint64(archive.Size)
+			}
+		}
+	}
+
+	if footprint.DockerImageLayers == nil {
+		plan.ImageBytes = footprint.EstimatedInstallSize
+		plan.IsEstimated = true
+		return plan, nil
+	}
+
+	presentLayers, err := p.stackService.ListImageLayers()
+	if err != nil {
+		return nil, err
+	}
+
+	isPresent := make(map[digest.Digest]bool, len(presentLayers))
+	for _, layer := range presentLayers {
+		isPresent[digest.Digest(layer)] = true
+	}
+
+	isPlanned := make(map[digest.Digest]bool, len(footprint.DockerImageLayers))
+	for _, layer := range footprint.DockerImageLayers {
+		if isPlanned[layer.DiffID] {
+			continue
+		}
+		isPlanned[layer.DiffID] = true
+
+		if isPresent[layer.DiffID] {
+			plan.PresentLayerBytes += uint64(layer.Size)
+			continue
+		}
+		plan.ImageBytes += uint64(layer.Size)
+	}
+
+	plan.ArchiveBytes = footprint.ArchiveSize
+	return plan, nil
+}
@@ -0,0 +1,13 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+//go:build dev
+// +build dev
+
+package service
+
+const (
+	defaultDiskHeadroomBytes  = 0
+	defaultVolumeReserveBytes = 0
+)
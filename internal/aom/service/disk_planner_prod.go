@@ -0,0 +1,13 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+//go:build prod
+// +build prod
+
+package service
+
+const (
+	defaultDiskHeadroomBytes  = 100 * 1024 * 1024
+	defaultVolumeReserveBytes = 10 * 1024 * 1024
+)
@@ -0,0 +1,119 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package service_test
+
+import (
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/docker"
+	"u-control/uc-aom/internal/aom/service"
+	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type blobCacheStub map[digest.Digest]bool
+
+func (s blobCacheStub) Contains(desc ocispec.Descriptor) bool {
+	return s[desc.Digest]
+}
+
+func TestDiskPlannerSubtractsPresentLayers(t *testing.T) {
+	// Arrange
+	stackService := &docker.MockStackService{}
+	baseLayer := oraswrapper.DockerImageLayer{DiffID: digest.FromString("base"), Size: 100}
+	appLayer := oraswrapper.DockerImageLayer{DiffID: digest.FromString("app"), Size: 20}
+	stackService.On("ListImageLayers").Return([]string{baseLayer.DiffID.String()}, nil)
+
+	footprint := catalogue.DiskFootprint{
+		EstimatedInstallSize: 1000,
+		ArchiveSize:          50,
+		// The base layer is shared by two docker images of the add-on.
+		DockerImageLayers: []oraswrapper.DockerImageLayer{baseLayer, appLayer, baseLayer},
+	}
+	uut := service.NewDiskPlanner(stackService, 7, 3)
+
+	// Act
+	plan, err := uut.Plan(footprint, []string{"data", "config"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, &service.DiskPlan{
+		ImageBytes:        20,
+		PresentLayerBytes: 100,
+		ArchiveBytes:      50,
+		VolumeBytes:       6,
+		HeadroomBytes:     7,
+	}, plan)
+	assert.Equal(t, uint64(83), plan.RequiredBytes())
+	stackService.AssertExpectations(t)
+}
+
+func TestDiskPlannerFallsBackToEstimatedInstallSize(t *testing.T) {
+	// Arrange
+	stackService := &docker.MockStackService{}
+	footprint := catalogue.DiskFootprint{EstimatedInstallSize: 1000, ArchiveSize: 400}
+	uut := service.NewDiskPlanner(stackService, 7, 3)
+
+	// Act
+	plan, err := uut.Plan(footprint, nil)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, plan.IsEstimated)
+	assert.Equal(t, uint64(1007), plan.RequiredBytes())
+	stackService.AssertNotCalled(t, "ListImageLayers")
+}
+
+func TestDiskPlannerCountsArchivesNotCachedYet(t *testing.T) {
+	// Arrange
+	stackService := &docker.MockStackService{}
+	cached := ocispec.Descriptor{Digest: digest.FromString("cached"), Size: 30}
+	missing := ocispec.Descriptor{Digest: digest.FromString("missing"), Size: 20}
+	footprint := catalogue.DiskFootprint{EstimatedInstallSize: 1000, ArchiveSize: 50, Archives: []ocispec.Descriptor{cached, missing}}
+	uut := service.NewDiskPlanner(stackService, 7, 3)
+	uut.UseBlobCache(blobCacheStub{cached.Digest: true})
+
+	// Act
+	plan, err := uut.Plan(footprint, nil)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(20), plan.CacheBytes)
+	assert.Equal(t, uint64(1027), plan.RequiredBytes())
+}
+
+func TestDiskPlannerDoesNotCountStagedArchives(t *testing.T) {
+	// Arrange
+	stackService := &docker.MockStackService{}
+	staged := ocispec.Descriptor{Digest: digest.FromString("staged"), Size: 30}
+	missing := ocispec.Descriptor{Digest: digest.FromString("missing"), Size: 20}
+	footprint := catalogue.DiskFootprint{EstimatedInstallSize: 1000, ArchiveSize: 50, Archives: []ocispec.Descriptor{staged, missing}}
+	uut := service.NewDiskPlanner(stackService, 7, 3)
+	uut.UseBlobCache(blobCacheStub{})
+	uut.UseStagingArea(blobCacheStub{staged.Digest: true})
+
+	// Act
+	plan, err := uut.Plan(footprint, nil)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(20), plan.CacheBytes)
+}
+
+func TestDiskPlannerPlansStaging(t *testing.T) {
+	// Arrange
+	footprint := catalogue.DiskFootprint{EstimatedInstallSize: 1000, ArchiveSize: 50}
+	uut := service.NewDiskPlanner(&docker.MockStackService{}, 7, 3)
+
+	// Act
+	plan := uut.PlanStaging(footprint)
+
+	// Assert
+	assert.Equal(t, uint64(50), plan.StagingBytes)
+	assert.Equal(t, uint64(57), plan.RequiredBytes())
+}
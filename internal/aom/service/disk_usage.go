@@ -0,0 +1,60 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package service
+
+import (
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/docker"
+	"u-control/uc-aom/internal/pkg/manifest"
+)
+
+// Breakdown of the disk space used by an installed add-on in bytes.
+type AddOnDiskUsage struct {
+	docker.StackDiskUsage
+
+	// Size of the manifest and assets in the local catalogue
+	CatalogueAssetBytes uint64
+}
+
+// Returns the total disk space used by the add-on in bytes,
+// including image layers shared with other images.
+func (u *AddOnDiskUsage) TotalBytes() uint64 {
+	return u.ImageBytes + u.VolumeBytes + u.PublicVolumeBytes + u.CatalogueAssetBytes
+}
+
+// Returns the disk space used by the installed add-on.
+func (s *Service) GetAddOnDiskUsage(addOn catalogue.CatalogueAddOn) (*AddOnDiskUsage, error) {
+	usages, err := s.GetAddOnsDiskUsage([]*catalogue.CatalogueAddOn{&addOn})
+	if err != nil {
+		return nil, err
+	}
+	return usages[addOn.Name], nil
+}
+
+// Returns the disk space used by each of the installed add-ons mapped by the add-on name.
+// The docker disk usage is only queried once for all add-ons.
+func (s *Service) GetAddOnsDiskUsage(addOns []*catalogue.CatalogueAddOn) (map[string]*AddOnDiskUsage, error) {
+	dockerDiskUsage, err := s.stackService.DiskUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make(map[string]*AddOnDiskUsage, len(addOns))
+	for _, addOn := range addOns {
+		imageReferences := manifest.GetDockerImageReferences(addOn.Manifest.Services)
+		stackUsage, err := dockerDiskUsage.StackUsage(addOn.Name, imageReferences...)
+		if err != nil {
+			return nil, err
+		}
+
+		assetsSize, err := s.localCatalogue.GetAddOnAssetsSize(addOn.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		usages[addOn.Name] = &AddOnDiskUsage{StackDiskUsage: *stackUsage, CatalogueAssetBytes: assetsSize}
+	}
+	return usages, nil
+}
@@ -0,0 +1,253 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package service
+
+import (
+	"errors"
+	"sort"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/iam"
+	aommanifest "u-control/uc-aom/internal/aom/manifest"
+	"u-control/uc-aom/internal/aom/protection"
+	"u-control/uc-aom/internal/aom/routes"
+	"u-control/uc-aom/internal/aom/utils"
+	"u-control/uc-aom/internal/aom/yaml"
+	"u-control/uc-aom/internal/pkg/manifest"
+)
+
+// Describes what installing, updating or configuring an add-on would change on the device.
+type AddOnPlan struct {
+	Name      string
+	Title     string
+	Operation Operation
+
+	// Version of the installed add-on, empty if the add-on is not installed
+	CurrentVersion string
+	Version        string
+
+	// Settings of the add-on, the current values are migrated on update if no settings are given
+	Settings []*manifest.Setting
+
+	// Names of the required settings without a value
+	MissingSettings []string
+
+	// Disk space required by the docker images and volumes, nil if the add-on is only configured
+	DiskPlan           *DiskPlan
+	AvailableDiskBytes uint64
+
+	DockerCompose string
+
+	// Reverse proxy routes which are created, and the ids of the routes which are removed
+	Routes        []*PlannedRoute
+	RemovedRoutes []string
+
+	// IAM permission to access the add-on, nil if the add-on is only configured
+	IamPermission *PlannedIamPermission
+
+	CreatedVolumes []string
+	RemovedVolumes []string
+}
+
+// Reverse proxy route of an add-on with the files which would be written.
+type PlannedRoute struct {
+	Id string
+	*routes.RenderedRoute
+}
+
+// IAM permission file which would be written.
+type PlannedIamPermission struct {
+	Filepath string
+	Content  string
+}
+
+// Plans the install, update or configuration of the add-on identified by name to the version with the given settings.
+// Performs the checks of CreateAddOnRoutine and ReplaceAddOnRoutine and fails with the same errors,
+// but neither pulls the add-on nor changes anything on the device.
+func (s *Service) PlanAddOn(name string, version string, settings ...*manifest.Setting) (*AddOnPlan, error) {
+	plan := &AddOnPlan{Name: name, Version: version, Operation: Installing}
+
+	installedAddOn, err := s.localCatalogue.GetAddOn(name)
+	isInstalled := err == nil
+	if err != nil && !errors.Is(err, catalogue.ErrorAddOnNotFound) {
+		return nil, err
+	}
+
+	if isInstalled {
+		plan.CurrentVersion = installedAddOn.Version
+		operation := protection.Update
+		plan.Operation = Updating
+		if installedAddOn.Version == version {
+			operation = protection.Configure
+			plan.Operation = Configuring
+		}
+		if err := s.checkOperationPermitted(installedAddOn, operation); err != nil {
+			return nil, err
+		}
+	}
+
+	futureManifest := &installedAddOn.Manifest
+	if plan.Operation != Configuring {
+		var installed *catalogue.CatalogueAddOn
+		if isInstalled {
+			installed = &installedAddOn
+		}
+		futureManifest, err = s.planManifest(name, version, installed)
+		if err != nil {
+			return nil, err
+		}
+	}
+	plan.Title = futureManifest.Title
+
+	futureManifest, err = s.planSettings(plan, installedAddOn, futureManifest, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Operation != Configuring {
+		if err := s.planDiskSpace(plan, installedAddOn, futureManifest); err != nil {
+			return nil, err
+		}
+	}
+
+	manifestAdapter := newManifestFeatureToSystemAdapter(s.system)
+	manifestToDeploy, err := manifestAdapter.adaptFeaturesToSystem(futureManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.DockerCompose, err = yaml.GetDockerComposeFromManifest(manifestToDeploy, s.ComposeDefaultsFor(name), s.CpuPlacementFor(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Operation == Configuring {
+		return plan, nil
+	}
+
+	if err := s.planRoutesAndPermission(plan, installedAddOn, futureManifest); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Fetches the manifest of the version and runs the checks of the install on it.
+// On update, installed is the installed add-on.
+func (s *Service) planManifest(name string, version string, installed *catalogue.CatalogueAddOn) (*manifest.Root, error) {
+	futureManifest, err := s.localCatalogue.FetchManifest(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkInstall(name, futureManifest, installed); err != nil {
+		return nil, err
+	}
+	return futureManifest, nil
+}
+
+// Returns a copy of the future manifest with the planned settings,
+// the manifest itself is left unchanged because it may be shared with the catalogue.
+func (s *Service) planSettings(plan *AddOnPlan, installedAddOn catalogue.CatalogueAddOn, futureManifest *manifest.Root, settings []*manifest.Setting) (*manifest.Root, error) {
+	if plan.Operation == Updating && len(settings) == 0 && futureManifest.Settings != nil {
+		currentSettings, err := s.addOnEnvironmentResolver.GetAddOnEnvironment(installedAddOn.Name)
+		if err != nil {
+			return nil, err
+		}
+		settings = aommanifest.CombineManifestSettingsWithSettingsMap(futureManifest.Settings["environmentVariables"], currentSettings)
+	}
+
+	plannedManifest := *futureManifest
+	if len(settings) != 0 {
+		plannedManifest.Settings = make(map[string][]*manifest.Setting, len(futureManifest.Settings)+1)
+		for key, value := range futureManifest.Settings {
+			plannedManifest.Settings[key] = value
+		}
+		plannedManifest.Settings["environmentVariables"] = settings
+	}
+
+	plan.Settings = plannedManifest.Settings["environmentVariables"]
+	plan.MissingSettings = getMissingSettings(plan.Settings)
+	return &plannedManifest, nil
+}
+
+func (s *Service) planDiskSpace(plan *AddOnPlan, installedAddOn catalogue.CatalogueAddOn, futureManifest *manifest.Root) error {
+	footprint, err := s.localCatalogue.FetchDiskFootprint(plan.Name, plan.Version)
+	if err != nil {
+		return err
+	}
+
+	plan.CreatedVolumes = manifest.GetVolumeNames(futureManifest.Environments)
+	if plan.Operation == Updating {
+		plan.CreatedVolumes = getNewVolumeNames(&installedAddOn.Manifest, futureManifest)
+		plan.RemovedVolumes = getNewVolumeNames(futureManifest, &installedAddOn.Manifest)
+	}
+
+	plan.DiskPlan, err = s.diskPlanner.Plan(footprint, plan.CreatedVolumes)
+	if err != nil {
+		return err
+	}
+
+	plan.AvailableDiskBytes, err = s.system.AvailableSpaceInBytes()
+	if err != nil {
+		return err
+	}
+	return CheckDiskSpace(s.system, plan.DiskPlan)
+}
+
+func (s *Service) planRoutesAndPermission(plan *AddOnPlan, installedAddOn catalogue.CatalogueAddOn, futureManifest *manifest.Root) error {
+	permissionId := utils.ReplaceSlashesWithDashes(plan.Name)
+	for _, id := range sortedRouteIds(futureManifest.Publish) {
+		location := futureManifest.Publish[id]
+		reverseProxyHttpConf := routes.NewReverseProxyHttpConf(plan.Name, location)
+		reverseProxyMap := &routes.ReverseProxyMap{AddOnName: plan.Name, AddOnTitle: futureManifest.Title, To: location.To, Id: permissionId}
+		prefixedId := routes.CreatePrefixedRouteFilenameId(plan.Name, id)
+		rendered, err := s.reverseProxy.Render(prefixedId, reverseProxyMap, reverseProxyHttpConf)
+		if err != nil {
+			return err
+		}
+		plan.Routes = append(plan.Routes, &PlannedRoute{Id: prefixedId, RenderedRoute: rendered})
+	}
+
+	for _, id := range sortedRouteIds(installedAddOn.Manifest.Publish) {
+		if _, ok := futureManifest.Publish[id]; !ok {
+			plan.RemovedRoutes = append(plan.RemovedRoutes, routes.CreatePrefixedRouteFilenameId(plan.Name, id))
+		}
+	}
+
+	permission := &iam.IamPermission{AddOnTitle: futureManifest.Title, Id: permissionId, NoAuthOpt: iam.IAM_AUTH_NO_AUTH_OPT}
+	filepath, content, err := s.iamPermissionWriter.Render(permission)
+	if err != nil {
+		return err
+	}
+	plan.IamPermission = &PlannedIamPermission{Filepath: filepath, Content: content}
+	return nil
+}
+
+// Returns the names of the required settings without a value.
+func getMissingSettings(settings []*manifest.Setting) []string {
+	missing := make([]string, 0)
+	for _, setting := range settings {
+		if !setting.Required {
+			continue
+		}
+
+		hasValue := setting.Value != ""
+		for _, item := range setting.Select {
+			hasValue = hasValue || item.Selected
+		}
+		if !hasValue {
+			missing = append(missing, setting.Name)
+		}
+	}
+	return missing
+}
+
+func sortedRouteIds(proxyRoutes map[string]*manifest.ProxyRoute) []string {
+	ids := make([]string, 0, len(proxyRoutes))
+	for id := range proxyRoutes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package service_test
+
+import (
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func assertNoSideEffects(t *testing.T, mockObj *service.ServiceMultiComponentMock) {
+	mockObj.AssertNotCalled(t, "PullAddOn", mock.Anything, mock.Anything)
+	mockObj.AssertNotCalled(t, "DeleteAddOn", mock.Anything)
+	mockObj.AssertNotCalled(t, "ReverseProxyWrite", mock.Anything, mock.Anything)
+	mockObj.AssertNotCalled(t, "IamPermissionWriterWrite", mock.Anything, mock.Anything)
+	mockObj.MockStackService.AssertNotCalled(t, "ImportDockerImage", mock.Anything)
+	mockObj.MockStackService.AssertNotCalled(t, "CreateStackWithDockerCompose", mock.Anything, mock.Anything, mock.Anything)
+	mockObj.MockStackService.AssertNotCalled(t, "DeleteAddOnStack", mock.Anything)
+}
+
+func TestPlanAddOnInstall(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	addOn := newAddOn("addontest", "add-on-test", "1.0.0-1", "docker-image", "test-volume")
+	addOn.Manifest.Settings = map[string][]*manifest.Setting{
+		"environmentVariables": {manifest.NewSettings("URL", "Url", true), manifest.NewSettings("LEVEL", "Level", false)},
+	}
+	mockObj.On("GetAddOn", addOn.Name).Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+	mockObj.On("FetchManifest", addOn.Name, addOn.Version).Return(&addOn.Manifest, nil)
+	mockObj.On("FetchDiskFootprint", addOn.Name, addOn.Version).Return(catalogue.DiskFootprint{EstimatedInstallSize: 100}, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+	mockObj.On("AvailableSpaceInBytes").Return(uint64(1000), nil)
+	uut := createUut(mockObj)
+
+	// Act
+	plan, err := uut.PlanAddOn(addOn.Name, addOn.Version)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, service.Installing, plan.Operation)
+	assert.Empty(t, plan.CurrentVersion)
+	assert.Equal(t, []string{"URL"}, plan.MissingSettings)
+	assert.Equal(t, uint64(100), plan.DiskPlan.RequiredBytes())
+	assert.Equal(t, uint64(1000), plan.AvailableDiskBytes)
+	assert.Contains(t, plan.DockerCompose, "docker-image:1.0.0-1")
+	assert.Equal(t, []string{"test-volume"}, plan.CreatedVolumes)
+	assert.Empty(t, plan.RemovedVolumes)
+	if assert.Len(t, plan.Routes, 1) {
+		assert.Equal(t, "addontest-publish", plan.Routes[0].Id)
+		assert.Equal(t, "/addontest-publish.http.conf", plan.Routes[0].ConfigFilepath)
+		assert.Contains(t, plan.Routes[0].Config, "location /addontest/To")
+		assert.Contains(t, plan.Routes[0].Map, "addontest.access")
+	}
+	assert.Equal(t, "/addontest-proxy.json", plan.IamPermission.Filepath)
+	assert.Contains(t, plan.IamPermission.Content, "Access to add-on-test")
+	assertNoSideEffects(t, mockObj)
+}
+
+func TestPlanAddOnUpdateMigratesSettings(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	oldAddOn := newAddOn("addontest", "add-on-test", "1.0.0-1", "docker-image", "test-volume-old")
+	oldAddOn.Manifest.Publish["legacy"] = &manifest.ProxyRoute{From: "/legacy", To: "/legacy"}
+	newAddOn := newAddOn("addontest", "add-on-test", "2.0.0-1", "docker-image", "test-volume-new")
+	newAddOn.Manifest.Settings = map[string][]*manifest.Setting{
+		"environmentVariables": {manifest.NewSettings("URL", "Url", true).WithTextBoxValue("http://localhost")},
+	}
+	mockObj.On("GetAddOn", oldAddOn.Name).Return(*oldAddOn, nil)
+	mockObj.On("GetAddOnEnvironment", oldAddOn.Name).Return(map[string]string{"URL": "http://plc"}, nil)
+	mockObj.On("FetchManifest", newAddOn.Name, newAddOn.Version).Return(&newAddOn.Manifest, nil)
+	mockObj.On("FetchDiskFootprint", newAddOn.Name, newAddOn.Version).Return(catalogue.DiskFootprint{}, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+	mockObj.On("AvailableSpaceInBytes").Return(uint64(1000), nil)
+	uut := createUut(mockObj)
+
+	// Act
+	plan, err := uut.PlanAddOn(newAddOn.Name, newAddOn.Version)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, service.Updating, plan.Operation)
+	assert.Equal(t, "1.0.0-1", plan.CurrentVersion)
+	assert.Equal(t, "http://plc", plan.Settings[0].Value)
+	assert.Empty(t, plan.MissingSettings)
+	assert.Contains(t, plan.DockerCompose, "URL: http://plc")
+	assert.Equal(t, []string{"test-volume-new"}, plan.CreatedVolumes)
+	assert.Equal(t, []string{"test-volume-old"}, plan.RemovedVolumes)
+	assert.Equal(t, []string{"addontest-legacy"}, plan.RemovedRoutes)
+	assertNoSideEffects(t, mockObj)
+}
+
+func TestPlanAddOnFailsAsUpdate(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	oldAddOn := newAddOn("addontest", "add-on-test", "1.0.0-1", "docker-image", "test-volume")
+	newAddOn := newAddOn("addontest", "add-on-test", "2.0.0-1", "docker-image", "test-volume")
+	mockObj.On("GetAddOn", oldAddOn.Name).Return(*oldAddOn, nil)
+	mockObj.On("FetchManifest", newAddOn.Name, newAddOn.Version).Return(&newAddOn.Manifest, nil)
+	mockObj.On("FetchDiskFootprint", newAddOn.Name, newAddOn.Version).Return(catalogue.DiskFootprint{EstimatedInstallSize: 100}, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+	mockObj.On("AvailableSpaceInBytes").Return(uint64(99), nil)
+	uut := createUut(mockObj)
+
+	// Act
+	_, err := uut.PlanAddOn(newAddOn.Name, newAddOn.Version)
+
+	// Assert
+	_, ok := err.(*service.NotEnoughDiskSpaceError)
+	assert.True(t, ok, "Expected a NotEnoughDiskSpaceError but got %v", err)
+	assertNoSideEffects(t, mockObj)
+}
+
+func TestPlanAddOnConfigure(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	addOn := newAddOn("addontest", "add-on-test", "1.0.0-1", "docker-image", "test-volume")
+	mockObj.On("GetAddOn", addOn.Name).Return(*addOn, nil)
+	uut := createUut(mockObj)
+
+	// Act
+	plan, err := uut.PlanAddOn(addOn.Name, addOn.Version, manifest.NewSettings("URL", "Url", true).WithTextBoxValue("http://plc"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, service.Configuring, plan.Operation)
+	assert.Nil(t, plan.DiskPlan)
+	assert.Contains(t, plan.DockerCompose, "URL: http://plc")
+	assert.Empty(t, plan.Routes)
+	mockObj.AssertNotCalled(t, "FetchManifest", mock.Anything, mock.Anything)
+	assertNoSideEffects(t, mockObj)
+}
+
+func TestPlanAddOnKeepsSettingsOfInstalledManifest(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	addOn := newAddOn("addontest", "add-on-test", "1.0.0-1", "docker-image", "test-volume")
+	installedSetting := manifest.NewSettings("URL", "Url", true).WithTextBoxValue("http://installed")
+	addOn.Manifest.Settings = map[string][]*manifest.Setting{"environmentVariables": {installedSetting}}
+	mockObj.On("GetAddOn", addOn.Name).Return(*addOn, nil)
+	uut := createUut(mockObj)
+
+	// Act
+	plan, err := uut.PlanAddOn(addOn.Name, addOn.Version, manifest.NewSettings("URL", "Url", true).WithTextBoxValue("http://plc"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, plan.DockerCompose, "URL: http://plc")
+	assert.Equal(t, []*manifest.Setting{installedSetting}, addOn.Manifest.Settings["environmentVariables"])
+}
@@ -0,0 +1,34 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package service
+
+import (
+	"fmt"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/protection"
+)
+
+// Represents an operation that is not permitted on a protected add-on.
+type OperationNotPermittedError struct {
+	message string
+}
+
+func (r *OperationNotPermittedError) Error() string {
+	return r.message
+}
+
+// Returns the operations that are permitted on the installed add-on.
+func (s *Service) AllowedOperations(addOn catalogue.CatalogueAddOn) []protection.Operation {
+	return s.protectionPolicy.AllowedOperations(addOn.Name, &addOn.Manifest)
+}
+
+func (s *Service) checkOperationPermitted(addOn catalogue.CatalogueAddOn, operation protection.Operation) error {
+	if s.protectionPolicy.IsAllowed(addOn.Name, &addOn.Manifest, operation) {
+		return nil
+	}
+
+	message := fmt.Sprintf("Operation '%s' is not permitted for the protected add-on '%s'", operation, addOn.Manifest.Title)
+	return &OperationNotPermittedError{message: message}
+}
@@ -17,12 +17,7 @@ func (tx *Tx) updateAction(addOn catalogue.CatalogueAddOn, version string, setti
 		return err
 	}
 
-	manifestVersionValidator := NewManifestVersionValidator(futureManifest.ManifestVersion)
-	if err := manifestVersionValidator.Validate(); err != nil {
-		return err
-	}
-
-	if err := tx.service.checkCapabilities(futureManifest); err != nil {
+	if err := tx.service.checkInstall(addOn.Name, futureManifest, &addOn); err != nil {
 		return err
 	}
 
@@ -36,6 +31,18 @@ func (tx *Tx) updateAction(addOn catalogue.CatalogueAddOn, version string, setti
 		settings = manifest.CombineManifestSettingsWithSettingsMap(settingsOfUpdate, currentSettings)
 	}
 
+	// The previous version is deleted before the update is installed and cannot be restored on failure.
+	// Therefore, the disk space is checked while both versions are held, without crediting the previous version.
+	footprint, err := tx.service.localCatalogue.FetchDiskFootprint(addOn.Name, version)
+	if err != nil {
+		return err
+	}
+
+	newVolumes := getNewVolumeNames(&addOn.Manifest, futureManifest)
+	if err := tx.service.checkDiskSpace(footprint, newVolumes); err != nil {
+		return err
+	}
+
 	if err = tx.service.deleteAddOnExceptVolumes(addOn); err != nil {
 		return err
 	}
@@ -58,7 +65,7 @@ func (tx *Tx) configureAction(addOn catalogue.CatalogueAddOn, settings ...*model
 		return err
 	}
 
-	dockerCompose, err := yaml.GetDockerComposeFromManifest(manifestToDeploy)
+	dockerCompose, err := yaml.GetDockerComposeFromManifest(manifestToDeploy, tx.service.ComposeDefaultsFor(addOn.Name), tx.service.CpuPlacementFor(addOn.Name))
 	if err != nil {
 		return err
 	}
@@ -73,3 +80,18 @@ func (tx *Tx) configureAction(addOn catalogue.CatalogueAddOn, settings ...*model
 	})
 	return tx.service.stackService.CreateStackWithDockerCompose(addOn.Name, dockerCompose)
 }
+
+func getNewVolumeNames(currentManifest *model.Root, futureManifest *model.Root) []string {
+	currentVolumes := make(map[string]bool)
+	for _, name := range model.GetVolumeNames(currentManifest.Environments) {
+		currentVolumes[name] = true
+	}
+
+	newVolumes := make([]string, 0)
+	for _, name := range model.GetVolumeNames(futureManifest.Environments) {
+		if !currentVolumes[name] {
+			newVolumes = append(newVolumes, name)
+		}
+	}
+	return newVolumes
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package service
+
+import (
+	"u-control/uc-aom/internal/aom/budget"
+	"u-control/uc-aom/internal/pkg/manifest"
+)
+
+// Resources allocated by the installed add-ons, taken once for the checks of several add-ons.
+type AllocatedResources struct {
+	byName map[string]budget.Resources
+}
+
+// Returns the resources allocated by the installed add-ons, none if the resource budget is unlimited.
+func (s *Service) AllocatedResources() (*AllocatedResources, error) {
+	allocated := &AllocatedResources{byName: make(map[string]budget.Resources)}
+	if s.resourceBudget.IsUnlimited() {
+		return allocated, nil
+	}
+
+	installedAddOns, err := s.localCatalogue.GetAddOns()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, installedAddOn := range installedAddOns {
+		resources, err := s.resourceBudget.ResourcesOf(&installedAddOn.Manifest, s.ComposeDefaultsFor(installedAddOn.Name))
+		if err != nil {
+			return nil, err
+		}
+		allocated.byName[installedAddOn.Name] = resources
+	}
+	return allocated, nil
+}
+
+// Returns the allocated resources of all installed add-ons except the add-on identified by name.
+func (a *AllocatedResources) except(name string) []budget.Resources {
+	others := make([]budget.Resources, 0, len(a.byName))
+	for installedName, resources := range a.byName {
+		if installedName != name {
+			others = append(others, resources)
+		}
+	}
+	return others
+}
+
+// Checks that the add-on identified by name with the given manifest fits into the resource budget of the device.
+// On update, installedManifest is the manifest of the installed version and only the change against it is checked.
+// All installed add-ons are accounted as running because they are started with the device.
+// allocated is taken from the installed add-ons if it is nil.
+func (s *Service) checkResourceBudget(name string, addOnManifest *manifest.Root, installedManifest *manifest.Root, allocated *AllocatedResources) error {
+	if s.resourceBudget.IsUnlimited() {
+		return nil
+	}
+
+	requested, err := s.resourceBudget.ResourcesOf(addOnManifest, s.ComposeDefaultsFor(name))
+	if err != nil {
+		return err
+	}
+
+	if allocated == nil {
+		allocated, err = s.AllocatedResources()
+		if err != nil {
+			return err
+		}
+	}
+	others := allocated.except(name)
+
+	if installedManifest == nil {
+		return s.resourceBudget.Check(addOnManifest.Title, others, requested)
+	}
+
+	installed, err := s.resourceBudget.ResourcesOf(installedManifest, s.ComposeDefaultsFor(name))
+	if err != nil {
+		return err
+	}
+	return s.resourceBudget.CheckUpdate(addOnManifest.Title, others, requested, installed)
+}
@@ -12,28 +12,35 @@ import (
 // Represents insufficient disk space.
 type NotEnoughDiskSpaceError struct {
 	message string
+
+	// Available disk space in bytes
+	AvailableBytes uint64
+
+	// Breakdown of the required disk space
+	Plan *DiskPlan
 }
 
 func (r *NotEnoughDiskSpaceError) Error() string {
 	return r.message
 }
 
-// Returns an error if the requiredSizeBytes is
-// equal or larger than the space available
+// Returns an error if the bytes required by plan are
+// larger than the space available
 // as defined by uOSSystem.
-func CheckDiskSpace(uOSSystem system.System, requiredSizeBytes uint64) error {
+func CheckDiskSpace(uOSSystem system.System, plan *DiskPlan) error {
 	availableBytes, err := uOSSystem.AvailableSpaceInBytes()
 	if err != nil {
 		return err
 	}
 
-	if availableBytes < requiredSizeBytes {
+	requiredBytes := plan.RequiredBytes()
+	if availableBytes < requiredBytes {
 		message := fmt.Sprintf(
 			"Insufficient disk space. Available %d (bytes), Approx Required %d (bytes)",
 			availableBytes,
-			requiredSizeBytes,
+			requiredBytes,
 		)
-		return &NotEnoughDiskSpaceError{message: message}
+		return &NotEnoughDiskSpaceError{message: message, AvailableBytes: availableBytes, Plan: plan}
 	}
 
 	return nil
@@ -6,26 +6,25 @@ package service
 
 import (
 	"errors"
-	"regexp"
+	"u-control/uc-aom/internal/aom/budget"
 	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/cpuisolation"
 	"u-control/uc-aom/internal/aom/docker"
 	"u-control/uc-aom/internal/aom/env"
 	"u-control/uc-aom/internal/aom/iam"
+	"u-control/uc-aom/internal/aom/protection"
 	"u-control/uc-aom/internal/aom/routes"
+	"u-control/uc-aom/internal/aom/servicedefaults"
 	"u-control/uc-aom/internal/aom/system"
 	"u-control/uc-aom/internal/aom/utils"
 	"u-control/uc-aom/internal/aom/yaml"
 	"u-control/uc-aom/internal/pkg/manifest"
 
-	"google.golang.org/grpc/codes"
-	grpcStatus "google.golang.org/grpc/status"
-
 	log "github.com/sirupsen/logrus"
 )
 
 var (
 	ErrorAddOnAlreadyInstalled = errors.New("Already installed.")
-	ErrorCodesys               = grpcStatus.Error(codes.Unimplemented, "Not supported for the codesys app")
 )
 
 // Abstraction layer over the individual steps
@@ -38,6 +37,11 @@ type Service struct {
 	Validator                manifest.Validator
 	addOnEnvironmentResolver env.EnvResolver
 	system                   system.System
+	protectionPolicy         *protection.Policy
+	diskPlanner              *DiskPlanner
+	resourceBudget           *budget.Budget
+	serviceDefaults          *servicedefaults.Policy
+	cpuPolicy                *cpuisolation.Policy
 }
 
 // Create a new instance of the Service.
@@ -48,12 +52,27 @@ func NewService(
 	localCatalogue catalogue.LocalAddOnCatalogue,
 	validator manifest.Validator,
 	addOnEnvironmentResolver env.EnvResolver,
-	system system.System) *Service {
-	return &Service{stackService, reverseProxy, iamPermissionWriter, localCatalogue, validator, addOnEnvironmentResolver, system}
+	system system.System,
+	protectionPolicy *protection.Policy,
+	resourceBudget *budget.Budget,
+	serviceDefaults *servicedefaults.Policy,
+	cpuPolicy *cpuisolation.Policy) *Service {
+	diskPlanner := NewDiskPlanner(stackService, DISK_HEADROOM_BYTES, VOLUME_RESERVE_BYTES)
+	return &Service{stackService, reverseProxy, iamPermissionWriter, localCatalogue, validator, addOnEnvironmentResolver, system, protectionPolicy, diskPlanner, resourceBudget, serviceDefaults, cpuPolicy}
+}
+
+// Returns the defaults which are injected into the docker-compose services of the add-on with the given name.
+func (s *Service) ComposeDefaultsFor(name string) *yaml.ServiceDefaults {
+	return s.serviceDefaults.DefaultsFor(name)
+}
+
+// Returns the cpu placement which is enforced on the docker-compose services of the add-on with the given name.
+func (s *Service) CpuPlacementFor(name string) *yaml.CpuPlacement {
+	return s.cpuPolicy.PlacementFor(name)
 }
 
 // Create an AddOn.
-func (tx *Tx) CreateAddOnRoutine(name string, version string, settings ...*manifest.Setting) error {
+func (tx *Tx) CreateAddOnRoutine(name string, version string, settings ...*manifest.Setting) (err error) {
 	log.Tracef("CreateAddOnRoutine('%s', '%s', '%v')", name, version, settings)
 	isInstalled, err := tx.service.isAddOnInstalled(name)
 	if err != nil {
@@ -71,26 +90,25 @@ func (tx *Tx) CreateAddOnRoutine(name string, version string, settings ...*manif
 	if err != nil {
 		return err
 	}
+	defer func() {
+		catalogueAddOn.ReleaseDockerImageData(err == nil)
+	}()
+
+	// On update, the disk space and the checks of the install have been run before the previous version was deleted.
+	isUpdate := tx.operation() == Updating
+	if !isUpdate {
+		volumes := manifest.GetVolumeNames(catalogueAddOn.AddOn.Manifest.Environments)
+		if err := tx.service.checkDiskSpace(catalogueAddOn.DiskFootprint, volumes); err != nil {
+			return err
+		}
 
-	if err := CheckDiskSpace(tx.service.system, catalogueAddOn.EstimatedInstallSize); err != nil {
-		return err
-	}
-
-	if err := tx.service.Validator.Validate(&catalogueAddOn.AddOn.Manifest); err != nil {
-		return err
-	}
-
-	manifestVersionValidator := NewManifestVersionValidator(catalogueAddOn.AddOn.Manifest.ManifestVersion)
-	if err := manifestVersionValidator.Validate(); err != nil {
-		return err
+		if err := tx.service.checkInstall(catalogueAddOn.AddOn.Name, &catalogueAddOn.AddOn.Manifest, nil); err != nil {
+			return err
+		}
 	}
 
 	tx.setAddOnContext(catalogueAddOn.AddOn.Name, catalogueAddOn.AddOn.Manifest.Title, Installing)
 
-	if err := tx.service.checkCapabilities(&catalogueAddOn.AddOn.Manifest); err != nil {
-		return err
-	}
-
 	if len(settings) != 0 {
 		catalogueAddOn.AddOn.Manifest.Settings["environmentVariables"] = settings
 	}
@@ -112,7 +130,7 @@ func (tx *Tx) CreateAddOnRoutine(name string, version string, settings ...*manif
 		return err
 	}
 
-	dockerCompose, err := yaml.GetDockerComposeFromManifest(manifestToDeploy)
+	dockerCompose, err := yaml.GetDockerComposeFromManifest(manifestToDeploy, tx.service.ComposeDefaultsFor(catalogueAddOn.AddOn.Name), tx.service.CpuPlacementFor(catalogueAddOn.AddOn.Name))
 	if err != nil {
 		return err
 	}
@@ -140,45 +158,65 @@ func (tx *Tx) CreateAddOnRoutine(name string, version string, settings ...*manif
 
 // Can upgrade or reconfigure an installed add-on.
 func (tx *Tx) ReplaceAddOnRoutine(name string, version string, settings ...*manifest.Setting) error {
-	if isCodesys(name) {
-		return ErrorCodesys
-	}
 	addOn, err := tx.service.localCatalogue.GetAddOn(name)
 	if err != nil {
 		return err
 	}
 
 	if addOn.Version == version {
+		if err := tx.service.checkOperationPermitted(addOn, protection.Configure); err != nil {
+			return err
+		}
 		tx.setAddOnContext(addOn.Name, addOn.Manifest.Title, Configuring)
 		return tx.configureAction(addOn, settings...)
 	}
 
+	// Refused before the manifest of the update is fetched, the checks of the install in updateAction include it as well
+	if err := tx.service.checkOperationPermitted(addOn, protection.Update); err != nil {
+		return err
+	}
 	tx.setAddOnContext(addOn.Name, addOn.Manifest.Title, Updating)
 	return tx.updateAction(addOn, version, settings...)
 }
 
 // Delete an installed AddOn.
 func (tx *Tx) DeleteAddOnRoutine(name string) error {
-	if isCodesys(name) {
-		return ErrorCodesys
-	}
 	addOn, err := tx.service.localCatalogue.GetAddOn(name)
 	if err != nil {
 		return err
 	}
+	if err := tx.service.checkOperationPermitted(addOn, protection.Delete); err != nil {
+		return err
+	}
 	tx.setAddOnContext(addOn.Name, addOn.Manifest.Title, Deleting)
 	return tx.service.deleteAddOnWithVolumes(addOn)
 }
 
-func (s *Service) checkCapabilities(manifest *manifest.Root) error {
-	capabilities := NewCapabilities(s.system, manifest.Platform...)
+// Plans the disk space of the docker image archives, which are downloaded into the blob cache before they are imported.
+func (s *Service) UseBlobCache(cache BlobCache) {
+	s.diskPlanner.UseBlobCache(cache)
+}
 
-	if len(manifest.Features) > 0 {
-		capabilities.WithFeatures(manifest.Features)
+// Plans the disk space of the staging area, see DiskPlanner.UseStagingArea.
+func (s *Service) UseStagingArea(area BlobCache) {
+	s.diskPlanner.UseStagingArea(area)
+}
+
+// Returns a NotEnoughDiskSpaceError if the disk space is insufficient to stage the add-on identified by name and version.
+func (s *Service) CheckStagingDiskSpace(name string, version string) error {
+	footprint, err := s.localCatalogue.FetchDiskFootprint(name, version)
+	if err != nil {
+		return err
 	}
+	return CheckDiskSpace(s.system, s.diskPlanner.PlanStaging(footprint))
+}
 
-	err := capabilities.Validate()
-	return err
+func (s *Service) checkDiskSpace(footprint catalogue.DiskFootprint, volumes []string) error {
+	plan, err := s.diskPlanner.Plan(footprint, volumes)
+	if err != nil {
+		return err
+	}
+	return CheckDiskSpace(s.system, plan)
 }
 
 func (s *Service) isAddOnInstalled(addOnName string) (bool, error) {
@@ -270,8 +308,3 @@ func (s *Service) deleteIamPermission(permissionId string) error {
 	permissionId = utils.ReplaceSlashesWithDashes(permissionId)
 	return s.iamPermissionWriter.Delete(permissionId)
 }
-
-func isCodesys(appName string) bool {
-	r := regexp.MustCompile(`(?i)\bcodesys\b`)
-	return r.MatchString(appName)
-}
@@ -10,12 +10,17 @@ package service
 import (
 	"io"
 	"os/user"
+	"u-control/uc-aom/internal/aom/budget"
 	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/cpuisolation"
 	"u-control/uc-aom/internal/aom/dbus"
 	"u-control/uc-aom/internal/aom/docker"
 	"u-control/uc-aom/internal/aom/iam"
+	"u-control/uc-aom/internal/aom/protection"
 	"u-control/uc-aom/internal/aom/routes"
+	"u-control/uc-aom/internal/aom/servicedefaults"
 	"u-control/uc-aom/internal/aom/status"
+	"u-control/uc-aom/internal/aom/yaml"
 	"u-control/uc-aom/internal/pkg/manifest"
 
 	"github.com/stretchr/testify/mock"
@@ -33,7 +38,9 @@ func (r *ServiceMultiComponentMock) NewServiceUsingServiceMultiComponentMock() *
 		r.ReverseProxyCreateSymbolicLink,
 		r.ReverseProxyRemoveSymbolicLink)
 	iamPermissionWriter := iam.NewIamPermissionWriter("", r.IamPermissionWriterWrite, r.IamPermissionWriterDelete)
-	return NewService(&r.MockStackService, reverseProxy, iamPermissionWriter, r, r, r, r)
+	protectionPolicy, _ := protection.NewPolicy()
+	serviceDefaults, _ := servicedefaults.NewPolicy(yaml.ServiceDefaults{})
+	return NewService(&r.MockStackService, reverseProxy, iamPermissionWriter, r, r, r, r, protectionPolicy, &budget.Budget{}, serviceDefaults, &cpuisolation.Policy{})
 }
 
 func (r *ServiceMultiComponentMock) AddOnStatusResolver(name string) ([]*status.ListAddOnContainersFuncReturnType, error) {
@@ -101,6 +108,16 @@ func (r *ServiceMultiComponentMock) FetchManifest(name string, version string) (
 	return args.Get(0).(*manifest.Root), args.Error(1)
 }
 
+func (r *ServiceMultiComponentMock) FetchDiskFootprint(name string, version string) (catalogue.DiskFootprint, error) {
+	args := r.Called(name, version)
+	return args.Get(0).(catalogue.DiskFootprint), args.Error(1)
+}
+
+func (r *ServiceMultiComponentMock) GetAddOnAssetsSize(name string) (uint64, error) {
+	args := r.Called(name)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
 func (r *ServiceMultiComponentMock) Validate(manifest *manifest.Root) error {
 	args := r.Called(manifest)
 	return args.Error(0)
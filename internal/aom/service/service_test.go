@@ -7,20 +7,24 @@ package service_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"testing"
+	"u-control/uc-aom/internal/aom/budget"
 	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/cpuisolation"
 	"u-control/uc-aom/internal/aom/dbus"
 	"u-control/uc-aom/internal/aom/iam"
+	"u-control/uc-aom/internal/aom/protection"
 	"u-control/uc-aom/internal/aom/routes"
 	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/aom/servicedefaults"
+	"u-control/uc-aom/internal/aom/utils"
+	"u-control/uc-aom/internal/aom/yaml"
 	"u-control/uc-aom/internal/pkg/manifest"
 
-	"google.golang.org/grpc/codes"
-	grpcStatus "google.golang.org/grpc/status"
-
 	"github.com/stretchr/testify/mock"
 )
 
@@ -49,13 +53,36 @@ func dockerImages(content ...string) []io.Reader {
 }
 
 func createUut(tc *service.ServiceMultiComponentMock) *service.Service {
+	protectionPolicy, _ := protection.NewPolicy()
+	return createUutWith(tc, protectionPolicy, &budget.Budget{})
+}
+
+func createUutWithProtectionPolicy(tc *service.ServiceMultiComponentMock, protectionPolicy *protection.Policy) *service.Service {
+	return createUutWith(tc, protectionPolicy, &budget.Budget{})
+}
+
+func createUutWithResourceBudget(tc *service.ServiceMultiComponentMock, resourceBudget *budget.Budget) *service.Service {
+	protectionPolicy, _ := protection.NewPolicy()
+	return createUutWith(tc, protectionPolicy, resourceBudget)
+}
+
+func createUutWith(tc *service.ServiceMultiComponentMock, protectionPolicy *protection.Policy, resourceBudget *budget.Budget) *service.Service {
 	reverseProxy := routes.NewReverseProxy(dbus.Initialize(), "", "", "", "",
 		tc.ReverseProxyWrite,
 		tc.ReverseProxyDelete,
 		tc.ReverseProxyCreateSymbolicLink,
 		tc.ReverseProxyRemoveSymbolicLink)
 	iamPermissionWriter := iam.NewIamPermissionWriter("", tc.IamPermissionWriterWrite, tc.IamPermissionWriterDelete)
-	return service.NewService(tc, reverseProxy, iamPermissionWriter, tc, tc, tc, tc)
+	serviceDefaults, _ := servicedefaults.NewPolicy(yaml.ServiceDefaults{})
+	return service.NewService(tc, reverseProxy, iamPermissionWriter, tc, tc, tc, tc, protectionPolicy, resourceBudget, serviceDefaults, &cpuisolation.Policy{})
+}
+
+func codesysProtectionPolicy(t *testing.T) *protection.Policy {
+	protectionPolicy, err := protection.NewPolicy(&protection.Entry{AddOnSelector: utils.AddOnSelector{Pattern: `(?i)\bcodesys\b`}, AllowedOperations: []protection.Operation{}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return protectionPolicy
 }
 
 func TestCreateAddOnRoutineSuccess(t *testing.T) {
@@ -216,6 +243,7 @@ func TestReplaceAddOnRoutineSuccess(t *testing.T) {
 	mockObj.On("ReverseProxyCreateSymbolicLink", "/addontest-publish.http.conf", "/addontest-publish.http.conf", mock.Anything).Return(nil)
 	mockObj.On("ReverseProxyCreateSymbolicLink", "/addontest-publish-proxy.map", "/addontest-publish-proxy.map", mock.Anything).Return(nil)
 	mockObj.On("FetchManifest", newAddOn.Name, newAddOn.Version).Return(&newAddOn.Manifest, nil)
+	mockObj.On("FetchDiskFootprint", newAddOn.Name, newAddOn.Version).Return(catalogue.DiskFootprint{}, nil)
 
 	mockObj.MockStackService.On("RemoveUnusedVolumes", newAddOn.Name, []string{"test-volume-old"}).Return(nil)
 	mockObj.On("Validate", mock.Anything).Return(nil)
@@ -241,6 +269,7 @@ func TestReplaceAddOnRoutineSuccess(t *testing.T) {
 
 	// Assert
 	mockObj.AssertExpectations(t)
+	mockObj.AssertNumberOfCalls(t, "AvailableSpaceInBytes", 1)
 }
 
 func TestCreateAddOnRoutineFailureNotEnoughSpace(t *testing.T) {
@@ -254,9 +283,9 @@ func TestCreateAddOnRoutineFailureNotEnoughSpace(t *testing.T) {
 	dockerImages := dockerImages("docker-image")
 	uut := createUut(mockObj)
 	addOnWithDockerImages := catalogue.CatalogueAddOnWithImages{
-		AddOn:                *addOn,
-		DockerImageData:      dockerImages,
-		EstimatedInstallSize: addOnInstallSize,
+		AddOn:           *addOn,
+		DockerImageData: dockerImages,
+		DiskFootprint:   catalogue.DiskFootprint{EstimatedInstallSize: addOnInstallSize},
 	}
 
 	mockObj.On("GetAddOn", addOn.Name).Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
@@ -297,42 +326,147 @@ func TestReplaceAddOnRoutineFailureNotEnoughSpace(t *testing.T) {
 	// Arrange
 	addOnInstallSize := uint64(0xdeadbeef)
 	mockObj := &service.ServiceMultiComponentMock{}
+	// TEST CASE: We have run out of space for the update while both versions are held.
+	mockObj.On("AvailableSpaceInBytes").Return(addOnInstallSize-1, nil)
+
+	oldAddOn := newAddOn("addontest", "add-on-test", "4.3.2", "docker-image", "test-volume-old")
+	newAddOn := newAddOn("addontest", "add-on-test", "5.0.0", "docker-image", "test-volume-new")
+	uut := createUut(mockObj)
+
+	mockObj.On("GetAddOn", oldAddOn.Name).Return(*oldAddOn, nil).Once()
+	mockObj.On("FetchManifest", newAddOn.Name, newAddOn.Version).Return(&newAddOn.Manifest, nil)
+	mockObj.On("FetchDiskFootprint", newAddOn.Name, newAddOn.Version).Return(catalogue.DiskFootprint{EstimatedInstallSize: addOnInstallSize}, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+
+	// Act
+	transactionScheduler := service.NewTransactionScheduler()
+	tx, err := transactionScheduler.CreateTransaction(context.Background(), uut)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = tx.ReplaceAddOnRoutine(newAddOn.Name, newAddOn.Version)
+
+	// Assert
+	notEnoughDiskSpaceError, ok := err.(*service.NotEnoughDiskSpaceError)
+	if !ok {
+		t.Fatalf("Expected a NotEnoughDiskSpaceError but got %v", err)
+	}
+
+	if notEnoughDiskSpaceError.Plan.RequiredBytes() != addOnInstallSize {
+		t.Errorf("Expected %d required bytes but got %d", addOnInstallSize, notEnoughDiskSpaceError.Plan.RequiredBytes())
+	}
+
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mockObj.AssertExpectations(t)
+	mockObj.MockStackService.AssertNotCalled(t, "DeleteAddOnStack", oldAddOn.Name)
+	mockObj.AssertNotCalled(t, "PullAddOn", newAddOn.Name, newAddOn.Version)
+}
+
+func TestReplaceAddOnRoutineFailureDiskSpaceLookup(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
 	// TEST CASE: We simulate a disk space look-up error.
-	mockObj.On("AvailableSpaceInBytes").Return(addOnInstallSize-1, bytes.ErrTooLarge)
+	mockObj.On("AvailableSpaceInBytes").Return(uint64(0), bytes.ErrTooLarge)
 
 	oldAddOn := newAddOn("addontest", "add-on-test", "4.3.2", "docker-image", "test-volume-old")
 	newAddOn := newAddOn("addontest", "add-on-test", "5.0.0", "docker-image", "test-volume-new")
-	dockerImages := dockerImages("docker-image")
 	uut := createUut(mockObj)
 
 	mockObj.On("GetAddOn", oldAddOn.Name).Return(*oldAddOn, nil).Once()
-	mockObj.MockStackService.On("DeleteAddOnStack", oldAddOn.Name).Return(nil)
-	mockObj.MockStackService.On("DeleteDockerImages", []string{"docker-image:4.3.2"}).Return(nil)
-	mockObj.On("ReverseProxyDelete", "/addontest-publish.http.conf").Return(nil)
-	mockObj.On("ReverseProxyDelete", "/addontest-publish-proxy.map").Return(nil)
-	mockObj.On("ReverseProxyRemoveSymbolicLink", "/addontest-publish.http.conf").Return(nil)
-	mockObj.On("ReverseProxyRemoveSymbolicLink", "/addontest-publish-proxy.map").Return(nil)
-	mockObj.On("IamPermissionWriterDelete", "/addontest-proxy.json").Return(nil)
-	mockObj.On("DeleteAddOn", oldAddOn.Name).Return(nil).Twice()
+	mockObj.On("FetchManifest", newAddOn.Name, newAddOn.Version).Return(&newAddOn.Manifest, nil)
+	mockObj.On("FetchDiskFootprint", newAddOn.Name, newAddOn.Version).Return(catalogue.DiskFootprint{EstimatedInstallSize: 1}, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
 
-	newAddOnWithDockerImages := catalogue.CatalogueAddOnWithImages{
-		AddOn:                *newAddOn,
-		DockerImageData:      dockerImages,
-		EstimatedInstallSize: addOnInstallSize,
+	// Act
+	transactionScheduler := service.NewTransactionScheduler()
+	tx, err := transactionScheduler.CreateTransaction(context.Background(), uut)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	mockObj.On("GetAddOn", newAddOn.Name).Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
-	mockObj.On("PullAddOn", newAddOn.Name, newAddOn.Version).Return(newAddOnWithDockerImages, nil)
-	mockObj.AssertNotCalled(t, "ImportDockerImage", mock.Anything)
-	mockObj.AssertNotCalled(t, "CreateStackWithDockerCompose", newAddOn.Name, mock.AnythingOfType("string"), mock.Anything)
-	mockObj.AssertNotCalled(t, "IamPermissionWriterWrite", "/addontest-proxy.json", mock.Anything)
-	mockObj.AssertNotCalled(t, "ReverseProxyWrite", "/addontest-publish.http.conf", mock.Anything)
-	mockObj.AssertNotCalled(t, "ReverseProxyWrite", "/addontest-publish-proxy.map", mock.Anything)
-	mockObj.AssertNotCalled(t, "ReverseProxyCreateSymbolicLink", "/addontest-publish.http.conf", "/addontest-publish.http.conf", mock.Anything)
-	mockObj.AssertNotCalled(t, "ReverseProxyCreateSymbolicLink", "/addontest-publish-proxy.map", "/addontest-publish-proxy.map", mock.Anything)
-	mockObj.AssertNotCalled(t, "Validate", mock.Anything)
+	err = tx.ReplaceAddOnRoutine(newAddOn.Name, newAddOn.Version)
+
+	// Assert
+	if !errors.Is(err, bytes.ErrTooLarge) {
+		t.Fatalf("Expected the disk space look-up error but got %v", err)
+	}
+
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mockObj.AssertExpectations(t)
+	mockObj.MockStackService.AssertNotCalled(t, "DeleteAddOnStack", oldAddOn.Name)
+	mockObj.AssertNotCalled(t, "PullAddOn", newAddOn.Name, newAddOn.Version)
+}
+
+func TestCreateAddOnRoutineFailureResourceBudgetExceeded(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	mockObj.On("AvailableSpaceInBytes").Return(uint64(0xdeadbeef), nil)
+
+	installedAddOn := newAddOn("installed", "installed-test", "1.0.0", "installed-image", "installed-volume")
+	installedAddOn.Manifest.Services["test-service"].Config["memLimit"] = "128m"
+	addOn := newAddOn("addontest", "add-on-test", "4.3.2", "docker-image", "test-volume")
+	addOn.Manifest.Services["test-service"].Config["mem_limit"] = "200m"
+	uut := createUutWithResourceBudget(mockObj, &budget.Budget{MemoryBytes: 256 * 1024 * 1024})
 
+	mockObj.On("GetAddOn", addOn.Name).Return(catalogue.CatalogueAddOn{}, catalogue.ErrorAddOnNotFound)
+	mockObj.On("PullAddOn", addOn.Name, addOn.Version).Return(catalogue.CatalogueAddOnWithImages{AddOn: *addOn, DockerImageData: dockerImages("docker-image")}, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+	mockObj.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{installedAddOn, addOn}, nil)
+	mockObj.On("DeleteAddOn", addOn.Name).Return(nil)
+
+	// Act
+	transactionScheduler := service.NewTransactionScheduler()
+	tx, err := transactionScheduler.CreateTransaction(context.Background(), uut)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = tx.CreateAddOnRoutine(addOn.Name, addOn.Version)
+
+	// Assert
+	exceededError, ok := err.(*budget.ExceededError)
+	if !ok {
+		t.Fatalf("Expected an ExceededError but got %v", err)
+	}
+	if exceededError.Resource != "memory" {
+		t.Errorf("Expected the memory budget to be exceeded but got %s", exceededError.Resource)
+	}
+
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mockObj.AssertExpectations(t)
+	mockObj.MockStackService.AssertNotCalled(t, "ImportDockerImage", mock.Anything)
+}
+
+func TestReplaceAddOnRoutineFailureResourceBudgetExceeded(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+
+	otherAddOn := newAddOn("other", "other-test", "1.0.0", "other-image", "other-volume")
+	oldAddOn := newAddOn("addontest", "add-on-test", "4.3.2", "docker-image", "test-volume")
+	newAddOn := newAddOn("addontest", "add-on-test", "5.0.0", "docker-image", "test-volume")
+	newAddOn.Manifest.Services["test-service"].Config["cpus"] = 1.6
+	uut := createUutWithResourceBudget(mockObj, &budget.Budget{
+		Cpus:     2,
+		Defaults: budget.Defaults{Cpus: 0.5},
+	})
+
+	mockObj.On("GetAddOn", oldAddOn.Name).Return(*oldAddOn, nil).Once()
 	mockObj.On("FetchManifest", newAddOn.Name, newAddOn.Version).Return(&newAddOn.Manifest, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+	mockObj.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{otherAddOn, oldAddOn}, nil)
 
 	// Act
 	transactionScheduler := service.NewTransactionScheduler()
@@ -342,8 +476,14 @@ func TestReplaceAddOnRoutineFailureNotEnoughSpace(t *testing.T) {
 	}
 
 	err = tx.ReplaceAddOnRoutine(newAddOn.Name, newAddOn.Version)
-	if err == nil {
-		t.Fatal("Expected error, none received.")
+
+	// Assert
+	exceededError, ok := err.(*budget.ExceededError)
+	if !ok {
+		t.Fatalf("Expected an ExceededError but got %v", err)
+	}
+	if exceededError.Available != 1500 {
+		t.Errorf("Expected 1500 available milli cpus but got %d", exceededError.Available)
 	}
 
 	err = tx.Rollback()
@@ -351,7 +491,45 @@ func TestReplaceAddOnRoutineFailureNotEnoughSpace(t *testing.T) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
+	mockObj.AssertExpectations(t)
+	mockObj.MockStackService.AssertNotCalled(t, "DeleteAddOnStack", oldAddOn.Name)
+}
+
+func TestReplaceAddOnRoutineOverBudgetWithoutIncrease(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	footprintErr := errors.New("footprint not available")
+
+	otherAddOn := newAddOn("other", "other-test", "1.0.0", "other-image", "other-volume")
+	otherAddOn.Manifest.Services["test-service"].Config["cpus"] = 1.0
+	oldAddOn := newAddOn("addontest", "add-on-test", "4.3.2", "docker-image", "test-volume")
+	newAddOn := newAddOn("addontest", "add-on-test", "5.0.0", "docker-image", "test-volume")
+	uut := createUutWithResourceBudget(mockObj, &budget.Budget{
+		Cpus:     1,
+		Defaults: budget.Defaults{Cpus: 0.5},
+	})
+
+	mockObj.On("GetAddOn", oldAddOn.Name).Return(*oldAddOn, nil).Once()
+	mockObj.On("FetchManifest", newAddOn.Name, newAddOn.Version).Return(&newAddOn.Manifest, nil)
+	mockObj.On("Validate", mock.Anything).Return(nil)
+	mockObj.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{otherAddOn, oldAddOn}, nil)
+	mockObj.On("GetAddOnEnvironment", newAddOn.Name).Return(map[string]string{}, nil).Maybe()
+	mockObj.On("FetchDiskFootprint", newAddOn.Name, newAddOn.Version).Return(catalogue.DiskFootprint{}, footprintErr)
+
+	// Act
+	transactionScheduler := service.NewTransactionScheduler()
+	tx, err := transactionScheduler.CreateTransaction(context.Background(), uut)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.ReplaceAddOnRoutine(newAddOn.Name, newAddOn.Version)
+
 	// Assert
+	if err != footprintErr {
+		t.Fatalf("Expected the update to pass the resource budget but got %v", err)
+	}
 	mockObj.AssertExpectations(t)
 }
 
@@ -359,7 +537,7 @@ func TestDeleteAddOnRoutineCodesys(t *testing.T) {
 	// Arrange
 	mockObj := &service.ServiceMultiComponentMock{}
 	addOn := newAddOn("test-uc-addon-codesys-pkg", "test-uc-addon-codesys", "0.1.0-1", "docker-image", "test-uc-addon-codesys")
-	uut := createUut(mockObj)
+	uut := createUutWithProtectionPolicy(mockObj, codesysProtectionPolicy(t))
 
 	mockObj.On("GetAddOn", addOn.Name).Return(*addOn, nil)
 
@@ -378,19 +556,21 @@ func TestDeleteAddOnRoutineCodesys(t *testing.T) {
 		t.Fatal("Expected an error but got none")
 	}
 
-	if grpcErr, ok := grpcStatus.FromError(err); ok {
-		if grpcErr.Code() != codes.Unimplemented {
-			t.Errorf("Expected an unimplemented error but got %s", grpcErr.Code())
-		}
+	if _, ok := err.(*service.OperationNotPermittedError); !ok {
+		t.Errorf("Expected an OperationNotPermittedError but got %T", err)
 	}
+	mockObj.AssertExpectations(t)
 }
 
 func TestReplaceAddOnRoutineCodesys(t *testing.T) {
 	// Arrange
 	mockObj := &service.ServiceMultiComponentMock{}
+	installedAddOn := newAddOn("test-uc-addon-codesys-pkg", "test-uc-addon-codesys", "0.1.0-1", "docker-image", "test-uc-addon-codesys")
 	newAddOn := newAddOn("test-uc-addon-codesys-pkg", "test-uc-addon-codesys", "0.2.0-1", "docker-image", "test-uc-addon-codesys")
 
-	uut := createUut(mockObj)
+	uut := createUutWithProtectionPolicy(mockObj, codesysProtectionPolicy(t))
+
+	mockObj.On("GetAddOn", newAddOn.Name).Return(*installedAddOn, nil)
 
 	// Act
 	transactionScheduler := service.NewTransactionScheduler()
@@ -400,16 +580,44 @@ func TestReplaceAddOnRoutineCodesys(t *testing.T) {
 	}
 	defer tx.Rollback()
 
-	err = tx.ReplaceAddOnRoutine(newAddOn.Name, newAddOn.Version)
+	updateErr := tx.ReplaceAddOnRoutine(newAddOn.Name, newAddOn.Version)
+	configureErr := tx.ReplaceAddOnRoutine(installedAddOn.Name, installedAddOn.Version)
 
 	// Assert
-	if err == nil {
-		t.Fatalf("Expected an error but got none")
+	if _, ok := updateErr.(*service.OperationNotPermittedError); !ok {
+		t.Errorf("Expected an OperationNotPermittedError on update but got %T", updateErr)
 	}
 
-	if grpcErr, ok := grpcStatus.FromError(err); ok {
-		if grpcErr.Code() != codes.Unimplemented {
-			t.Errorf("Expected an unimplemented error but got %s", grpcErr.Code())
-		}
+	if _, ok := configureErr.(*service.OperationNotPermittedError); !ok {
+		t.Errorf("Expected an OperationNotPermittedError on configure but got %T", configureErr)
 	}
+	mockObj.AssertExpectations(t)
+}
+
+func TestReplaceAddOnRoutineProtectedByManifest(t *testing.T) {
+	// Arrange
+	mockObj := &service.ServiceMultiComponentMock{}
+	installedAddOn := newAddOn("test-uc-addon-pkg", "test-uc-addon", "0.1.0-1", "docker-image", "test-uc-addon")
+	installedAddOn.Manifest.Protection = &manifest.Protection{AllowedOperations: []string{"update"}}
+	protectionPolicy, _ := protection.NewPolicy(&protection.Entry{AddOnSelector: utils.AddOnSelector{Name: installedAddOn.Name}, TrustManifest: true})
+
+	uut := createUutWithProtectionPolicy(mockObj, protectionPolicy)
+
+	mockObj.On("GetAddOn", installedAddOn.Name).Return(*installedAddOn, nil)
+
+	// Act
+	transactionScheduler := service.NewTransactionScheduler()
+	tx, err := transactionScheduler.CreateTransaction(context.Background(), uut)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.ReplaceAddOnRoutine(installedAddOn.Name, installedAddOn.Version)
+
+	// Assert
+	if _, ok := err.(*service.OperationNotPermittedError); !ok {
+		t.Errorf("Expected an OperationNotPermittedError but got %T", err)
+	}
+	mockObj.AssertExpectations(t)
 }
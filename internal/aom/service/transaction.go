@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -136,6 +136,17 @@ func (tx *Tx) setAddOnContext(name string, title string, op Operation) {
 	tx.mu.Unlock()
 }
 
+// Returns the operation of the addon in the context of this transaction.
+func (tx *Tx) operation() Operation {
+	tx.mu.RLock()
+	defer tx.mu.RUnlock()
+
+	if tx.affected == nil {
+		return Unspecified
+	}
+	return tx.affected.Operation
+}
+
 // Returns whether this transaction has been committed or rolled back.
 func (tx *Tx) IsDone() bool {
 	return atomic.LoadInt32(&tx.done) != 0
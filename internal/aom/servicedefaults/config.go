@@ -0,0 +1,11 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package servicedefaults
+
+import "u-control/uc-aom/internal/pkg/utils"
+
+var (
+	SERVICE_DEFAULTS_PATH = utils.GetEnv("SERVICE_DEFAULTS_PATH", "/usr/share/uc-aom/service-defaults.json")
+)
@@ -0,0 +1,12 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+//go:build dev
+// +build dev
+
+package servicedefaults
+
+import "u-control/uc-aom/internal/aom/yaml"
+
+var platformDefaults = yaml.ServiceDefaults{}
@@ -0,0 +1,18 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+//go:build prod
+// +build prod
+
+package servicedefaults
+
+import "u-control/uc-aom/internal/aom/yaml"
+
+// Limits which keep a single add-on from exhausting the memory, the process table or the flash storage of the device.
+var platformDefaults = yaml.ServiceDefaults{
+	MemLimit:   "128m",
+	PidsLimit:  256,
+	LogMaxSize: "10m",
+	LogMaxFile: 3,
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package servicedefaults
+
+import (
+	"u-control/uc-aom/internal/aom/utils"
+	"u-control/uc-aom/internal/aom/yaml"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Override of the service defaults for specific add-ons.
+// Either Name or Pattern identifies the add-ons the override applies to.
+type Override struct {
+	utils.AddOnSelector
+	yaml.ServiceDefaults // non-zero values replace the device defaults
+}
+
+// Policy decides which defaults are injected into the docker-compose services of an add-on.
+// The platform defaults are replaced by the device defaults, which are replaced by the first matching override.
+type Policy struct {
+	Defaults yaml.ServiceDefaults `json:"defaults"`
+	AddOns   []*Override          `json:"addOns"`
+}
+
+// Creates a new Policy with the given device defaults and overrides.
+func NewPolicy(defaults yaml.ServiceDefaults, overrides ...*Override) (*Policy, error) {
+	for _, override := range overrides {
+		if err := override.Compile("Service defaults override"); err != nil {
+			return nil, err
+		}
+	}
+	return &Policy{Defaults: defaults, AddOns: overrides}, nil
+}
+
+// Reads the service defaults from path.
+// A missing file results in a policy with the platform defaults only.
+func LoadPolicy(readFile utils.ReadFileFunc, path string) (*Policy, error) {
+	policy := &Policy{}
+	found, err := utils.ReadJSONFile(readFile, path, "service defaults file", policy)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		log.Debugf("No service defaults file at '%s'", path)
+	}
+	return NewPolicy(policy.Defaults, policy.AddOns...)
+}
+
+// Returns the defaults for the docker-compose services of the add-on with the given name.
+func (p *Policy) DefaultsFor(name string) *yaml.ServiceDefaults {
+	defaults := platformDefaults
+	merge(&defaults, &p.Defaults)
+	if override := p.find(name); override != nil {
+		merge(&defaults, &override.ServiceDefaults)
+	}
+	return &defaults
+}
+
+func (p *Policy) find(name string) *Override {
+	for _, override := range p.AddOns {
+		if override.Matches(name) {
+			return override
+		}
+	}
+	return nil
+}
+
+func merge(defaults *yaml.ServiceDefaults, other *yaml.ServiceDefaults) {
+	if other.MemLimit != "" {
+		defaults.MemLimit = other.MemLimit
+	}
+	if other.PidsLimit != 0 {
+		defaults.PidsLimit = other.PidsLimit
+	}
+	if other.LogMaxSize != "" {
+		defaults.LogMaxSize = other.LogMaxSize
+	}
+	if other.LogMaxFile != 0 {
+		defaults.LogMaxFile = other.LogMaxFile
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package servicedefaults_test
+
+import (
+	"io/fs"
+	"testing"
+	"u-control/uc-aom/internal/aom/servicedefaults"
+	"u-control/uc-aom/internal/aom/yaml"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return []byte(`{
+			"defaults": { "memLimit": "128m", "pidsLimit": 256, "logMaxSize": "10m", "logMaxFile": 3 },
+			"addOns": [
+				{ "name": "test-uc-addon-pkg", "memLimit": "512m" },
+				{ "pattern": "(?i)\\blogger\\b", "logMaxSize": "50m", "logMaxFile": 5 }
+			]
+		}`), nil
+	}
+
+	// Act
+	policy, err := servicedefaults.LoadPolicy(readFile, "")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, &yaml.ServiceDefaults{MemLimit: "512m", PidsLimit: 256, LogMaxSize: "10m", LogMaxFile: 3}, policy.DefaultsFor("test-uc-addon-pkg"))
+	assert.Equal(t, &yaml.ServiceDefaults{MemLimit: "128m", PidsLimit: 256, LogMaxSize: "50m", LogMaxFile: 5}, policy.DefaultsFor("test-uc-addon-logger-pkg"))
+	assert.Equal(t, &yaml.ServiceDefaults{MemLimit: "128m", PidsLimit: 256, LogMaxSize: "10m", LogMaxFile: 3}, policy.DefaultsFor("other-uc-addon-pkg"))
+}
+
+func TestLoadPolicyMissingFile(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return nil, fs.ErrNotExist
+	}
+
+	// Act
+	policy, err := servicedefaults.LoadPolicy(readFile, "")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, &yaml.ServiceDefaults{}, policy.DefaultsFor("test-uc-addon-pkg"))
+}
+
+func TestLoadPolicyInvalidOverride(t *testing.T) {
+	// Arrange
+	readFile := func(string) ([]byte, error) {
+		return []byte(`{ "addOns": [{ "memLimit": "512m" }] }`), nil
+	}
+
+	// Act
+	_, err := servicedefaults.LoadPolicy(readFile, "")
+
+	// Assert
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,197 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Checks the compatibility of an add-on with the device, implemented by service.Service.
+type CompatibilityChecker interface {
+	CheckCompatibility(addOn *catalogue.CatalogueAddOn) ([]*service.Incompatibility, error)
+}
+
+// Latest version of an installed add-on which is compatible with the device.
+type AvailableUpdate struct {
+	Name           string
+	CurrentVersion string
+	LatestVersion  string
+}
+
+// Returns true if the latest compatible version is newer than the installed version.
+func (u *AvailableUpdate) IsAvailable() bool {
+	return manifest.GreaterThan(u.LatestVersion, u.CurrentVersion)
+}
+
+// Compares the installed add-ons to the remote catalogue and remembers the latest compatible version of each.
+type Checker struct {
+	localCatalogue  catalogue.LocalAddOnCatalogue
+	remoteCatalogue catalogue.RemoteAddOnCatalogue
+	compatibility   CompatibilityChecker
+
+	// Serializes the checks, so that a requested check does not race the background check
+	checking sync.Mutex
+
+	mu      sync.RWMutex
+	results map[string]*AvailableUpdate
+}
+
+func NewChecker(localCatalogue catalogue.LocalAddOnCatalogue, remoteCatalogue catalogue.RemoteAddOnCatalogue, compatibility CompatibilityChecker) *Checker {
+	return &Checker{
+		localCatalogue:  localCatalogue,
+		remoteCatalogue: remoteCatalogue,
+		compatibility:   compatibility,
+		results:         make(map[string]*AvailableUpdate),
+	}
+}
+
+// Checks all installed add-ons for updates and returns the add-ons with an available update.
+// An add-on whose versions cannot be fetched keeps the result of the previous check.
+// Returns the error of the context if it is done before all add-ons were checked.
+func (c *Checker) CheckForUpdates(ctx context.Context) ([]*AvailableUpdate, error) {
+	c.checking.Lock()
+	defer c.checking.Unlock()
+
+	installedAddOns, err := c.localCatalogue.GetAddOns()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*AvailableUpdate, len(installedAddOns))
+	for _, installedAddOn := range installedAddOns {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		latestVersion, err := c.LatestCompatibleVersion(ctx, installedAddOn, acceptAnyVersion)
+		if err != nil {
+			log.Warnf("Unable to check '%s' for updates: %v", installedAddOn.Name, err)
+			if previous, ok := c.Result(installedAddOn.Name); ok {
+				results[installedAddOn.Name] = previous
+			}
+			continue
+		}
+		results[installedAddOn.Name] = &AvailableUpdate{
+			Name:           installedAddOn.Name,
+			CurrentVersion: installedAddOn.Manifest.Version,
+			LatestVersion:  latestVersion,
+		}
+	}
+
+	c.mu.Lock()
+	c.results = results
+	c.mu.Unlock()
+	return availableUpdates(results), nil
+}
+
+// Returns the result of the last check for the add-on identified by name, false if it has not been checked.
+func (c *Checker) Result(name string) (*AvailableUpdate, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	result, ok := c.results[name]
+	return result, ok
+}
+
+// Returns the available update of the installed add-on, nil if there is none or the add-on has not been checked.
+// The result of the last check is compared to the given add-on, so that an add-on updated in the meantime has no update.
+func (c *Checker) UpdateOf(installedAddOn *catalogue.CatalogueAddOn) *AvailableUpdate {
+	result, ok := c.Result(installedAddOn.Name)
+	if !ok {
+		return nil
+	}
+
+	update := &AvailableUpdate{Name: result.Name, CurrentVersion: installedAddOn.Manifest.Version, LatestVersion: result.LatestVersion}
+	if !update.IsAvailable() {
+		return nil
+	}
+	return update
+}
+
+// Checks for updates every interval until the context is done, the first check runs immediately.
+// The interval must be positive.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		updates, err := c.CheckForUpdates(ctx)
+		if err != nil {
+			log.Warnf("Check for updates failed: %v", err)
+		} else {
+			log.Infof("Check for updates found %d update(s)", len(updates))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns the latest version of the remote catalogue which is accepted and compatible with the device,
+// the installed version if there is no such newer version.
+// Only the accepted versions newer than the installed version are fetched, starting with the latest.
+// Fetching stops when the context is done.
+func (c *Checker) LatestCompatibleVersion(ctx context.Context, installedAddOn *catalogue.CatalogueAddOn, accept func(version string) bool) (string, error) {
+	versions, err := c.remoteCatalogue.GetAddOnVersions(ctx, installedAddOn.Name)
+	if err != nil {
+		return "", err
+	}
+
+	sorted := append([]string(nil), versions...)
+	sort.Sort(manifest.ByAddOnVersion(sorted))
+
+	currentVersion := installedAddOn.Manifest.Version
+	for i := len(sorted) - 1; i >= 0 && manifest.GreaterThan(sorted[i], currentVersion); i-- {
+		if !accept(sorted[i]) {
+			continue
+		}
+
+		// The manifest is fetched without the assets, so the catalogue keeps the assets of the listed version
+		candidateManifest, err := c.remoteCatalogue.FetchManifest(ctx, installedAddOn.Name, sorted[i])
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		if err != nil {
+			log.Warnf("Unable to fetch '%s' in version '%s': %v", installedAddOn.Name, sorted[i], err)
+			continue
+		}
+
+		candidate := catalogue.CatalogueAddOn{Name: installedAddOn.Name, Version: sorted[i], Manifest: *candidateManifest}
+		incompatibilities, err := c.compatibility.CheckCompatibility(&candidate)
+		if err != nil {
+			return "", err
+		}
+		if len(incompatibilities) == 0 {
+			return sorted[i], nil
+		}
+		log.Debugf("Skipping '%s' in version '%s', it is incompatible with the device", installedAddOn.Name, sorted[i])
+	}
+	return currentVersion, nil
+}
+
+func acceptAnyVersion(version string) bool {
+	return true
+}
+
+func availableUpdates(results map[string]*AvailableUpdate) []*AvailableUpdate {
+	updates := make([]*AvailableUpdate, 0, len(results))
+	for _, result := range results {
+		if result.IsAvailable() {
+			updates = append(updates, result)
+		}
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Name < updates[j].Name })
+	return updates
+}
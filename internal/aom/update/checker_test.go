@@ -0,0 +1,169 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/registry"
+	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/aom/update"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type compatibilityMock struct {
+	incompatibleVersions map[string]bool
+}
+
+func (m *compatibilityMock) CheckCompatibility(addOn *catalogue.CatalogueAddOn) ([]*service.Incompatibility, error) {
+	if m.incompatibleVersions[addOn.Version] {
+		return []*service.Incompatibility{{Reason: service.UnsupportedPlatform}}, nil
+	}
+	return []*service.Incompatibility{}, nil
+}
+
+func installedAddOn(name string, version string) *catalogue.CatalogueAddOn {
+	return &catalogue.CatalogueAddOn{Name: name, Version: version, Manifest: manifest.Root{Version: version}}
+}
+
+func remoteManifest(version string) *manifest.Root {
+	return &manifest.Root{Version: version}
+}
+
+func TestCheckForUpdates(t *testing.T) {
+	testCases := map[string]struct {
+		versions             []string
+		incompatibleVersions map[string]bool
+		expectedLatest       string
+	}{
+		"newer version":               {versions: []string{"1.0.0-1", "1.1.0-1"}, expectedLatest: "1.1.0-1"},
+		"newer package version":       {versions: []string{"1.0.0-1", "1.0.0-2"}, expectedLatest: "1.0.0-2"},
+		"unsorted versions":           {versions: []string{"1.10.0-1", "1.0.0-1", "1.9.0-1"}, expectedLatest: "1.10.0-1"},
+		"latest version incompatible": {versions: []string{"1.0.0-1", "1.1.0-1", "2.0.0-1"}, incompatibleVersions: map[string]bool{"2.0.0-1": true}, expectedLatest: "1.1.0-1"},
+		"newer versions incompatible": {versions: []string{"1.0.0-1", "2.0.0-1"}, incompatibleVersions: map[string]bool{"2.0.0-1": true}, expectedLatest: ""},
+		"no newer version":            {versions: []string{"0.9.0-1", "1.0.0-1"}, expectedLatest: ""},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			localCatalogue := &catalogue.CatalogueMock{}
+			localCatalogue.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{installedAddOn("addon", "1.0.0-1")}, nil)
+			remoteCatalogue := &catalogue.RemoteCatalogueMock{}
+			remoteCatalogue.On("GetAddOnVersions", "addon").Return(testCase.versions, nil)
+			for _, version := range testCase.versions {
+				remoteCatalogue.On("FetchManifest", "addon", version).Return(remoteManifest(version), nil)
+			}
+			uut := update.NewChecker(localCatalogue, remoteCatalogue, &compatibilityMock{incompatibleVersions: testCase.incompatibleVersions})
+
+			// Act
+			updates, err := uut.CheckForUpdates(context.Background())
+
+			// Assert
+			assert.NoError(t, err)
+			if testCase.expectedLatest == "" {
+				assert.Empty(t, updates)
+				assert.Nil(t, uut.UpdateOf(installedAddOn("addon", "1.0.0-1")))
+				return
+			}
+			expected := &update.AvailableUpdate{Name: "addon", CurrentVersion: "1.0.0-1", LatestVersion: testCase.expectedLatest}
+			assert.Equal(t, []*update.AvailableUpdate{expected}, updates)
+			assert.Equal(t, expected, uut.UpdateOf(installedAddOn("addon", "1.0.0-1")))
+			remoteCatalogue.AssertNotCalled(t, "FetchManifest", "addon", "1.0.0-1")
+		})
+	}
+}
+
+func TestCheckForUpdatesKeepsPreviousResultOnError(t *testing.T) {
+	// Arrange
+	localCatalogue := &catalogue.CatalogueMock{}
+	localCatalogue.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{installedAddOn("addon", "1.0.0-1")}, nil)
+	remoteCatalogue := &catalogue.RemoteCatalogueMock{}
+	remoteCatalogue.On("GetAddOnVersions", "addon").Return([]string{"1.0.0-1", "1.1.0-1"}, nil).Once()
+	remoteCatalogue.On("GetAddOnVersions", "addon").Return([]string(nil), errors.New("registry unreachable"))
+	remoteCatalogue.On("FetchManifest", "addon", "1.1.0-1").Return(remoteManifest("1.1.0-1"), nil)
+	uut := update.NewChecker(localCatalogue, remoteCatalogue, &compatibilityMock{})
+	_, err := uut.CheckForUpdates(context.Background())
+	assert.NoError(t, err)
+
+	// Act
+	updates, err := uut.CheckForUpdates(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, updates, 1) {
+		assert.Equal(t, "1.1.0-1", updates[0].LatestVersion)
+	}
+}
+
+// Reads the version of the manifest from the logo written by the pull.
+type logoManifestReader struct{}
+
+func (r *logoManifestReader) ReadManifestFrom(directoryOfManifest string) (*manifest.Root, error) {
+	logo, err := os.ReadFile(filepath.Join(directoryOfManifest, "logo.png"))
+	if err != nil {
+		return nil, err
+	}
+	return &manifest.Root{Version: string(logo), Logo: "logo.png"}, nil
+}
+
+func TestCheckForUpdatesKeepsCatalogueAssets(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	addOnRegistry := &registry.MockRegistry{}
+	addOnRegistry.On("Tags", "addon").Return([]string{"1.0.0-1", "1.0.1-1", "1.1.0-1"}, nil)
+	writeLogo := func(args mock.Arguments) {
+		scratch, _ := filepath.Glob(filepath.Join(root, ".scratch-*"))
+		for _, dir := range scratch {
+			os.WriteFile(filepath.Join(dir, "logo.png"), []byte(args.String(1)), 0644)
+		}
+	}
+	addOnRegistry.On("Pull", "addon", mock.Anything, mock.Anything).Run(writeLogo).Return(uint64(0), nil)
+	remoteCatalogue := catalogue.NewORASRemoteAddOnCatalogue(root, addOnRegistry, &logoManifestReader{})
+	_, err := remoteCatalogue.GetAddOn(context.Background(), "addon", "1.1.0-1")
+	assert.NoError(t, err)
+	localCatalogue := &catalogue.CatalogueMock{}
+	localCatalogue.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{installedAddOn("addon", "1.0.0-1")}, nil)
+	uut := update.NewChecker(localCatalogue, remoteCatalogue, &compatibilityMock{incompatibleVersions: map[string]bool{"1.1.0-1": true}})
+
+	// Act
+	updates, err := uut.CheckForUpdates(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, updates, 1) {
+		assert.Equal(t, "1.0.1-1", updates[0].LatestVersion)
+	}
+	logo, _ := os.ReadFile(filepath.Join(root, "addon", "logo.png"))
+	assert.Equal(t, "1.1.0-1", string(logo))
+	scratch, _ := filepath.Glob(filepath.Join(root, ".scratch-*"))
+	assert.Empty(t, scratch)
+}
+
+func TestUpdateOfIgnoresUpdatedAddOn(t *testing.T) {
+	// Arrange
+	localCatalogue := &catalogue.CatalogueMock{}
+	localCatalogue.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{installedAddOn("addon", "1.0.0-1")}, nil)
+	remoteCatalogue := &catalogue.RemoteCatalogueMock{}
+	remoteCatalogue.On("GetAddOnVersions", "addon").Return([]string{"1.0.0-1", "1.1.0-1"}, nil)
+	remoteCatalogue.On("FetchManifest", "addon", mock.Anything).Return(remoteManifest("1.1.0-1"), nil)
+	uut := update.NewChecker(localCatalogue, remoteCatalogue, &compatibilityMock{})
+	_, err := uut.CheckForUpdates(context.Background())
+	assert.NoError(t, err)
+
+	// Act
+	availableUpdate := uut.UpdateOf(installedAddOn("addon", "1.1.0-1"))
+
+	// Assert
+	assert.Nil(t, availableUpdate)
+	assert.Nil(t, uut.UpdateOf(installedAddOn("other", "1.0.0-1")))
+}
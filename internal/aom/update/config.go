@@ -0,0 +1,31 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update
+
+import (
+	"u-control/uc-aom/internal/pkg/utils"
+)
+
+var (
+	// Interval of the background check for updates of the installed add-ons, the check is disabled if zero.
+	// The installed add-ons report no available update until the first check, which runs right after the start,
+	// if disabled only once CheckForUpdates is called.
+	UPDATE_CHECK_INTERVAL = utils.GetEnv("UPDATE_CHECK_INTERVAL", "6h")
+
+	// Interval in which the scheduler applies the updates permitted by the update policies, the scheduler is disabled if zero
+	UPDATE_SCHEDULER_INTERVAL = utils.GetEnv("UPDATE_SCHEDULER_INTERVAL", "5m")
+
+	// Update policies of the add-ons and maintenance windows of the device, the add-ons are updated manually if the file does not exist
+	UPDATE_POLICIES_PATH = utils.GetEnv("UPDATE_POLICIES_PATH", "/var/lib/uc-aom/update-policies.json")
+
+	// History of the updates which the scheduler applied and the probation rolled back
+	UPDATE_HISTORY_PATH = utils.GetEnv("UPDATE_HISTORY_PATH", "/var/lib/uc-aom/update-history.json")
+
+	// Latest update per add-on and version which the scheduler needs to not repeat notifications and failed updates
+	UPDATE_HISTORY_STATE_PATH = utils.GetEnv("UPDATE_HISTORY_STATE_PATH", "/var/lib/uc-aom/update-history-state.json")
+
+	// Period in which the health of an updated add-on is watched and a failing update is rolled back, the probation is disabled if zero
+	UPDATE_PROBATION_PERIOD = utils.GetEnv("UPDATE_PROBATION_PERIOD", "5m")
+)
@@ -0,0 +1,127 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update
+
+import (
+	"sync"
+	"time"
+	"u-control/uc-aom/internal/aom/utils"
+)
+
+// Entries kept in the history, the oldest entries are dropped first
+const maxHistoryEntries = 200
+
+// Outcome of an update recorded by the scheduler.
+type Result string
+
+const (
+	// An update is available, but the policy of the add-on does not apply it
+	ResultNotified Result = "notified"
+
+	// The add-on has been updated
+	ResultUpdated Result = "updated"
+
+	// The update failed and the previous version is still installed
+	ResultFailed Result = "failed"
+
+	// The updated add-on failed its probation and the previous version and settings have been restored
+	ResultRolledBack Result = "rolled-back"
+
+	// The updated add-on failed its probation, but the previous version could not be restored
+	ResultRollbackFailed Result = "rollback-failed"
+)
+
+type HistoryEntry struct {
+	Time        time.Time `json:"time"`
+	Name        string    `json:"name"`
+	FromVersion string    `json:"fromVersion"`
+	ToVersion   string    `json:"toVersion"`
+	Result      Result    `json:"result"`
+	Message     string    `json:"message,omitempty"`
+}
+
+// History persists what the scheduler and the probation did as JSON file.
+// The latest entry per add-on and version is persisted separately at statePath,
+// so the state of the scheduler is kept when the entries are dropped from the history.
+type History struct {
+	mutex     sync.RWMutex
+	path      string
+	statePath string
+	writeFile utils.WriteFileFunc
+	entries   []HistoryEntry
+
+	// Latest entry by name and version of the add-on
+	latest map[string]map[string]HistoryEntry
+}
+
+// NewHistory reads the history at path and its state at statePath, the history is empty if the files do not exist.
+func NewHistory(readFile utils.ReadFileFunc, writeFile utils.WriteFileFunc, path string, statePath string) (*History, error) {
+	h := &History{path: path, statePath: statePath, writeFile: writeFile, latest: make(map[string]map[string]HistoryEntry)}
+
+	if _, err := utils.ReadJSONFile(readFile, path, "update history", &h.entries); err != nil {
+		return nil, err
+	}
+	exists, err := utils.ReadJSONFile(readFile, statePath, "update history state", &h.latest)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		// The history has been written without state, the state is derived from its entries
+		for _, entry := range h.entries {
+			h.setLatest(entry)
+		}
+	}
+	return h, nil
+}
+
+// Appends the entry to the history.
+func (h *History) Record(entry HistoryEntry) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > maxHistoryEntries {
+		h.entries = h.entries[len(h.entries)-maxHistoryEntries:]
+	}
+	h.setLatest(entry)
+	return h.save()
+}
+
+// Returns the entries of the history, the latest first.
+func (h *History) Entries() []HistoryEntry {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	entries := make([]HistoryEntry, len(h.entries))
+	for i, entry := range h.entries {
+		entries[len(h.entries)-1-i] = entry
+	}
+	return entries
+}
+
+// Returns the latest entry of the add-on identified by name for the update to the version, false if there is none.
+func (h *History) LatestEntryOf(name string, toVersion string) (HistoryEntry, bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	entry, ok := h.latest[name][toVersion]
+	return entry, ok
+}
+
+// MUST be called under the write lock.
+func (h *History) setLatest(entry HistoryEntry) {
+	if h.latest[entry.Name] == nil {
+		h.latest[entry.Name] = make(map[string]HistoryEntry)
+	}
+	h.latest[entry.Name][entry.ToVersion] = entry
+}
+
+// MUST be called under the write lock.
+func (h *History) save() error {
+	if err := utils.WriteJSONFile(h.writeFile, h.statePath, h.latest, 0644); err != nil {
+		return err
+	}
+	return utils.WriteJSONFile(h.writeFile, h.path, h.entries, 0644)
+}
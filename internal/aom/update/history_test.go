@@ -0,0 +1,56 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"u-control/uc-aom/internal/aom/update"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryKeepsLatestEntryOfDroppedEntries(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	path := filepath.Join(dir, "update-history.json")
+	statePath := filepath.Join(dir, "update-history-state.json")
+	uut, err := update.NewHistory(os.ReadFile, os.WriteFile, path, statePath)
+	assert.NoError(t, err)
+	rolledBack := update.HistoryEntry{Time: time.Unix(0, 0).UTC(), Name: "addon", FromVersion: "1.0.0-1", ToVersion: "1.0.0-2", Result: update.ResultRolledBack}
+	assert.NoError(t, uut.Record(rolledBack))
+
+	// Act
+	for i := 0; i < 250; i++ {
+		assert.NoError(t, uut.Record(update.HistoryEntry{Name: fmt.Sprintf("addon-%d", i), ToVersion: "1.0.0-1", Result: update.ResultNotified}))
+	}
+	restarted, err := update.NewHistory(os.ReadFile, os.WriteFile, path, statePath)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, restarted.Entries(), 200)
+	entry, ok := restarted.LatestEntryOf("addon", "1.0.0-2")
+	assert.True(t, ok)
+	assert.Equal(t, rolledBack, entry)
+}
+
+func TestHistoryDerivesStateOfHistoryWithoutState(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	path := filepath.Join(dir, "update-history.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`[{"name":"addon","toVersion":"1.0.0-2","result":"failed"},{"name":"addon","toVersion":"1.0.0-2","result":"updated"}]`), 0644))
+
+	// Act
+	uut, err := update.NewHistory(os.ReadFile, os.WriteFile, path, filepath.Join(dir, "update-history-state.json"))
+
+	// Assert
+	assert.NoError(t, err)
+	entry, ok := uut.LatestEntryOf("addon", "1.0.0-2")
+	assert.True(t, ok)
+	assert.Equal(t, update.ResultUpdated, entry.Result)
+}
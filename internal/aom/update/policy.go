@@ -0,0 +1,144 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update
+
+import (
+	"fmt"
+	"time"
+	"u-control/uc-aom/internal/pkg/manifest"
+)
+
+// Policy selects which updates of an add-on the scheduler applies.
+type Policy string
+
+const (
+	// Updates are only applied by the user
+	PolicyManual Policy = "manual"
+
+	// Available updates are recorded in the history, but only applied by the user
+	PolicyNotify Policy = "notify"
+
+	// Newer package versions of the same partner version are applied, e.g. 1.2.3-1 to 1.2.3-2
+	PolicyAutoPatch Policy = "auto-patch"
+
+	// Newer versions of the same major partner version are applied, e.g. 1.2.3-1 to 1.4.0-1
+	PolicyAutoMinor Policy = "auto-minor"
+)
+
+func (p Policy) isValid() bool {
+	return p == PolicyManual || p == PolicyNotify || p == PolicyAutoPatch || p == PolicyAutoMinor
+}
+
+// Returns whether the scheduler applies updates of this policy.
+func (p Policy) IsAutomatic() bool {
+	return p == PolicyAutoPatch || p == PolicyAutoMinor
+}
+
+// Returns whether the policy applies the update of the add-on from the current version to the version.
+func (p Policy) Permits(currentVersion string, version string) bool {
+	if !manifest.GreaterThan(version, currentVersion) {
+		return false
+	}
+
+	switch p {
+	case PolicyAutoPatch:
+		return manifest.HaveSamePartnerVersion(currentVersion, version)
+	case PolicyAutoMinor:
+		return manifest.HaveSameMajorVersion(currentVersion, version)
+	default:
+		return false
+	}
+}
+
+// Update policy of an add-on.
+type AddOnPolicy struct {
+	Policy Policy `json:"policy"`
+
+	// Version which the scheduler does not update beyond, the add-on is not pinned if empty
+	PinnedVersion string `json:"pinnedVersion,omitempty"`
+}
+
+// Returns whether the policy and the pinned version permit the update of the add-on from the current version to the version.
+func (p AddOnPolicy) Permits(currentVersion string, version string) bool {
+	if p.PinnedVersion != "" && manifest.GreaterThan(version, p.PinnedVersion) {
+		return false
+	}
+	return p.Policy.Permits(currentVersion, version)
+}
+
+// Time of the week in which the scheduler may update the add-ons, outside of the production hours.
+// The window ends on the next day if the end is not after the start.
+type MaintenanceWindow struct {
+	// Days on which the window starts, every day if empty
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+
+	// Local time of the day as hh:mm
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Returns whether the local time t is within the window.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	start, err := minuteOfDay(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := minuteOfDay(w.End)
+	if err != nil {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return w.startsOn(t.Weekday()) && minute >= start && minute < end
+	}
+
+	yesterday := (t.Weekday() + 6) % 7
+	return (w.startsOn(t.Weekday()) && minute >= start) || (w.startsOn(yesterday) && minute < end)
+}
+
+func (w MaintenanceWindow) startsOn(weekday time.Weekday) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, day := range w.Weekdays {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func (w MaintenanceWindow) validate() error {
+	if _, err := minuteOfDay(w.Start); err != nil {
+		return err
+	}
+	if _, err := minuteOfDay(w.End); err != nil {
+		return err
+	}
+	for _, day := range w.Weekdays {
+		if day < time.Sunday || day > time.Saturday {
+			return fmt.Errorf("Invalid weekday %d", day)
+		}
+	}
+	return nil
+}
+
+func minuteOfDay(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid time of day '%s', expected hh:mm", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Represents an invalid update policy or maintenance window.
+type InvalidUpdatePolicyError struct {
+	message string
+}
+
+func (e *InvalidUpdatePolicyError) Error() string {
+	return e.message
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update
+
+import (
+	"fmt"
+	"sync"
+	"u-control/uc-aom/internal/aom/utils"
+	"u-control/uc-aom/internal/pkg/manifest"
+)
+
+type policySettings struct {
+	AddOns             map[string]AddOnPolicy `json:"addOns,omitempty"`
+	MaintenanceWindows []MaintenanceWindow    `json:"maintenanceWindows,omitempty"`
+}
+
+// PolicyStore persists the update policies of the add-ons and the maintenance windows of the device as JSON file.
+type PolicyStore struct {
+	mutex     sync.RWMutex
+	path      string
+	writeFile utils.WriteFileFunc
+	settings  policySettings
+}
+
+// NewPolicyStore reads the update policies at path.
+// The add-ons are updated manually and the device has no maintenance window if the file does not exist.
+func NewPolicyStore(readFile utils.ReadFileFunc, writeFile utils.WriteFileFunc, path string) (*PolicyStore, error) {
+	s := &PolicyStore{
+		path:      path,
+		writeFile: writeFile,
+		settings:  policySettings{AddOns: make(map[string]AddOnPolicy)},
+	}
+
+	if _, err := utils.ReadJSONFile(readFile, path, "update policies", &s.settings); err != nil {
+		return nil, err
+	}
+	if s.settings.AddOns == nil {
+		s.settings.AddOns = make(map[string]AddOnPolicy)
+	}
+	for name, policy := range s.settings.AddOns {
+		if !policy.Policy.isValid() {
+			return nil, fmt.Errorf("Invalid update policy '%s' of '%s' in '%s'", policy.Policy, name, path)
+		}
+	}
+	for _, window := range s.settings.MaintenanceWindows {
+		if err := window.validate(); err != nil {
+			return nil, fmt.Errorf("Invalid maintenance window in '%s': %w", path, err)
+		}
+	}
+	return s, nil
+}
+
+// Returns the update policy of the add-on identified by name, manual if the add-on has none.
+func (s *PolicyStore) PolicyOf(name string) AddOnPolicy {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if policy, ok := s.settings.AddOns[name]; ok {
+		return policy
+	}
+	return AddOnPolicy{Policy: PolicyManual}
+}
+
+// Returns the update policies of the add-ons which have one, per add-on name.
+func (s *PolicyStore) AddOnPolicies() map[string]AddOnPolicy {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	policies := make(map[string]AddOnPolicy, len(s.settings.AddOns))
+	for name, policy := range s.settings.AddOns {
+		policies[name] = policy
+	}
+	return policies
+}
+
+func (s *PolicyStore) MaintenanceWindows() []MaintenanceWindow {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]MaintenanceWindow(nil), s.settings.MaintenanceWindows...)
+}
+
+// Sets the update policy of the add-on identified by name.
+// A manual policy without a pinned version removes the policy of the add-on.
+func (s *PolicyStore) SetAddOnPolicy(name string, policy AddOnPolicy) error {
+	if name == "" {
+		return &InvalidUpdatePolicyError{message: "The add-on name is required"}
+	}
+	if !policy.Policy.isValid() {
+		return &InvalidUpdatePolicyError{message: fmt.Sprintf("Unknown update policy '%s'", policy.Policy)}
+	}
+	if policy.PinnedVersion != "" && !manifest.IsAddOnVersion(policy.PinnedVersion) {
+		return &InvalidUpdatePolicyError{message: fmt.Sprintf("Invalid pinned version '%s'", policy.PinnedVersion)}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, existed := s.settings.AddOns[name]
+	if policy == (AddOnPolicy{Policy: PolicyManual}) {
+		delete(s.settings.AddOns, name)
+	} else {
+		s.settings.AddOns[name] = policy
+	}
+	if err := s.save(); err != nil {
+		if existed {
+			s.settings.AddOns[name] = previous
+		} else {
+			delete(s.settings.AddOns, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Replaces the maintenance windows of the device, the add-ons are not updated automatically without a window.
+func (s *PolicyStore) SetMaintenanceWindows(windows []MaintenanceWindow) error {
+	for _, window := range windows {
+		if err := window.validate(); err != nil {
+			return &InvalidUpdatePolicyError{message: err.Error()}
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.settings.MaintenanceWindows
+	s.settings.MaintenanceWindows = append([]MaintenanceWindow(nil), windows...)
+	if err := s.save(); err != nil {
+		s.settings.MaintenanceWindows = previous
+		return err
+	}
+	return nil
+}
+
+// MUST be called under the write lock.
+func (s *PolicyStore) save() error {
+	return utils.WriteJSONFile(s.writeFile, s.path, s.settings, 0644)
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"u-control/uc-aom/internal/aom/update"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyPermits(t *testing.T) {
+	testCases := map[string]struct {
+		policy   update.AddOnPolicy
+		version  string
+		expected bool
+	}{
+		"manual":                     {policy: update.AddOnPolicy{Policy: update.PolicyManual}, version: "1.2.3-2", expected: false},
+		"notify":                     {policy: update.AddOnPolicy{Policy: update.PolicyNotify}, version: "1.2.3-2", expected: false},
+		"auto-patch package version": {policy: update.AddOnPolicy{Policy: update.PolicyAutoPatch}, version: "1.2.3-2", expected: true},
+		"auto-patch partner version": {policy: update.AddOnPolicy{Policy: update.PolicyAutoPatch}, version: "1.2.4-1", expected: false},
+		"auto-minor minor version":   {policy: update.AddOnPolicy{Policy: update.PolicyAutoMinor}, version: "1.4.0-1", expected: true},
+		"auto-minor major version":   {policy: update.AddOnPolicy{Policy: update.PolicyAutoMinor}, version: "2.0.0-1", expected: false},
+		"auto-minor older version":   {policy: update.AddOnPolicy{Policy: update.PolicyAutoMinor}, version: "1.2.0-1", expected: false},
+		"auto-minor up to pin":       {policy: update.AddOnPolicy{Policy: update.PolicyAutoMinor, PinnedVersion: "1.3.0-1"}, version: "1.3.0-1", expected: true},
+		"auto-minor beyond pin":      {policy: update.AddOnPolicy{Policy: update.PolicyAutoMinor, PinnedVersion: "1.3.0-1"}, version: "1.4.0-1", expected: false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			got := testCase.policy.Permits("1.2.3-1", testCase.version)
+
+			// Assert
+			assert.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestMaintenanceWindowContains(t *testing.T) {
+	// Saturday, 22:30
+	saturdayEvening := time.Date(2023, 6, 10, 22, 30, 0, 0, time.Local)
+	testCases := map[string]struct {
+		window   update.MaintenanceWindow
+		time     time.Time
+		expected bool
+	}{
+		"within daily window":       {window: update.MaintenanceWindow{Start: "22:00", End: "23:00"}, time: saturdayEvening, expected: true},
+		"at end of window":          {window: update.MaintenanceWindow{Start: "22:00", End: "22:30"}, time: saturdayEvening, expected: false},
+		"other weekday":             {window: update.MaintenanceWindow{Weekdays: []time.Weekday{time.Sunday}, Start: "22:00", End: "23:00"}, time: saturdayEvening, expected: false},
+		"overnight before midnight": {window: update.MaintenanceWindow{Weekdays: []time.Weekday{time.Saturday}, Start: "22:00", End: "04:00"}, time: saturdayEvening, expected: true},
+		"overnight after midnight":  {window: update.MaintenanceWindow{Weekdays: []time.Weekday{time.Saturday}, Start: "22:00", End: "04:00"}, time: saturdayEvening.Add(5 * time.Hour), expected: true},
+		"overnight next evening":    {window: update.MaintenanceWindow{Weekdays: []time.Weekday{time.Saturday}, Start: "22:00", End: "04:00"}, time: saturdayEvening.Add(24 * time.Hour), expected: false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			got := testCase.window.Contains(testCase.time)
+
+			// Assert
+			assert.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestPolicyStorePersistsPolicies(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "update-policies.json")
+	uut, err := update.NewPolicyStore(os.ReadFile, os.WriteFile, path)
+	assert.NoError(t, err)
+	windows := []update.MaintenanceWindow{{Weekdays: []time.Weekday{time.Sunday}, Start: "02:00", End: "04:00"}}
+
+	// Act
+	defaultPolicy := uut.PolicyOf("add-on")
+	assert.NoError(t, uut.SetAddOnPolicy("add-on", update.AddOnPolicy{Policy: update.PolicyAutoPatch, PinnedVersion: "1.2.3-5"}))
+	assert.NoError(t, uut.SetAddOnPolicy("other", update.AddOnPolicy{Policy: update.PolicyNotify}))
+	assert.NoError(t, uut.SetAddOnPolicy("other", update.AddOnPolicy{Policy: update.PolicyManual}))
+	assert.NoError(t, uut.SetMaintenanceWindows(windows))
+	restarted, err := update.NewPolicyStore(os.ReadFile, os.WriteFile, path)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, update.AddOnPolicy{Policy: update.PolicyManual}, defaultPolicy)
+	assert.Equal(t, map[string]update.AddOnPolicy{"add-on": {Policy: update.PolicyAutoPatch, PinnedVersion: "1.2.3-5"}}, restarted.AddOnPolicies())
+	assert.Equal(t, update.AddOnPolicy{Policy: update.PolicyManual}, restarted.PolicyOf("other"))
+	assert.Equal(t, windows, restarted.MaintenanceWindows())
+}
+
+func TestPolicyStoreErrors(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "update-policies.json")
+	uut, _ := update.NewPolicyStore(os.ReadFile, os.WriteFile, path)
+
+	// Act
+	unknownErr := uut.SetAddOnPolicy("add-on", update.AddOnPolicy{Policy: "nightly"})
+	missingNameErr := uut.SetAddOnPolicy("", update.AddOnPolicy{Policy: update.PolicyNotify})
+	invalidPinErr := uut.SetAddOnPolicy("add-on", update.AddOnPolicy{Policy: update.PolicyAutoMinor, PinnedVersion: "latest"})
+	invalidWindowErr := uut.SetMaintenanceWindows([]update.MaintenanceWindow{{Start: "25:00", End: "04:00"}})
+
+	// Assert
+	assert.IsType(t, &update.InvalidUpdatePolicyError{}, unknownErr)
+	assert.IsType(t, &update.InvalidUpdatePolicyError{}, missingNameErr)
+	assert.IsType(t, &update.InvalidUpdatePolicyError{}, invalidPinErr)
+	assert.IsType(t, &update.InvalidUpdatePolicyError{}, invalidWindowErr)
+	assert.NoFileExists(t, path)
+}
@@ -0,0 +1,311 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/docker"
+	"u-control/uc-aom/internal/aom/env"
+	"u-control/uc-aom/internal/aom/manifest"
+	"u-control/uc-aom/internal/aom/status"
+	model "u-control/uc-aom/internal/pkg/manifest"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Interval in which the health of an add-on on probation is checked
+const ProbationPollInterval = 10 * time.Second
+
+// Restarts of a container during the probation which are considered a crash loop
+const crashLoopRestarts = 3
+
+// Attempts to restore an add-on while another operation is in progress, one attempt per poll interval
+const maxRestoreAttempts = 30
+
+// Restorer restores an add-on to a previous version with its previous settings.
+type Restorer interface {
+	// Returns ErrBusy if another operation is in progress.
+	RestoreAddOn(name string, version string, settings ...*model.Setting) error
+}
+
+// PackageKeeper keeps the package of an add-on version, so that the version is restored without network access.
+type PackageKeeper interface {
+	Keep(ctx context.Context, repository string, tag string) (uint64, error)
+	Release(repository string, tag string) error
+}
+
+type StatusResolver interface {
+	GetAddOnStatus(name string) (status.AddOnStatus, error)
+}
+
+// Version and settings of an installed add-on before its update.
+type Snapshot struct {
+	Name     string
+	Version  string
+	Settings []*model.Setting
+
+	// Whether the package of the version is kept until the snapshot is released or its probation ends
+	kept bool
+}
+
+// Probation watches the health of an add-on after its update for a period.
+// If the add-on goes into error, becomes unhealthy or one of its containers crash-loops,
+// the previous version and settings are restored and the event is recorded in the history.
+type Probation struct {
+	localCatalogue catalogue.LocalAddOnCatalogue
+	envResolver    env.EnvResolver
+	statusResolver StatusResolver
+	stackService   docker.StackServiceAPI
+	restorer       Restorer
+	keeper         PackageKeeper
+	history        *History
+	period         time.Duration
+	pollInterval   time.Duration
+
+	mutex   sync.Mutex
+	watches map[string]*watch
+}
+
+type watch struct {
+	cancel context.CancelFunc
+}
+
+// Creates a new probation, which is disabled if the period is not positive.
+func NewProbation(localCatalogue catalogue.LocalAddOnCatalogue, envResolver env.EnvResolver, statusResolver StatusResolver, stackService docker.StackServiceAPI, restorer Restorer, history *History, period time.Duration, pollInterval time.Duration) *Probation {
+	return &Probation{
+		localCatalogue: localCatalogue,
+		envResolver:    envResolver,
+		statusResolver: statusResolver,
+		stackService:   stackService,
+		restorer:       restorer,
+		history:        history,
+		period:         period,
+		pollInterval:   pollInterval,
+		watches:        make(map[string]*watch),
+	}
+}
+
+// Keeps the package of the version before the update while the add-on is on probation,
+// otherwise the restore pulls the package from the registry.
+func (p *Probation) UsePackageKeeper(keeper PackageKeeper) {
+	p.keeper = keeper
+}
+
+// Returns the version and the current settings of the installed add-on identified by name
+// and keeps the package of the version for its restore.
+// MUST be taken before the add-on is updated and released by Release unless it is put on probation by Start.
+func (p *Probation) Snapshot(ctx context.Context, name string) (*Snapshot, error) {
+	addOn, err := p.localCatalogue.GetAddOn(name)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{Name: name, Version: addOn.Version}
+	if addOn.Manifest.Settings != nil {
+		currentSettings, err := p.envResolver.GetAddOnEnvironment(name)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Settings = manifest.CombineManifestSettingsWithSettingsMap(addOn.Manifest.Settings["environmentVariables"], currentSettings)
+	}
+
+	p.keepPackage(ctx, snapshot)
+	return snapshot, nil
+}
+
+// Removes the kept package of a snapshot which has not been put on probation.
+func (p *Probation) Release(snapshot *Snapshot) {
+	if !snapshot.kept {
+		return
+	}
+
+	snapshot.kept = false
+	p.releasePackage(snapshot.Name, snapshot.Version)
+}
+
+// Puts the add-on of the snapshot, which has been updated to the version, on probation.
+// A previous probation of the add-on ends.
+func (p *Probation) Start(snapshot *Snapshot, version string) {
+	if p.period <= 0 || snapshot.Version == version {
+		return
+	}
+
+	// The probation takes over the kept package of the snapshot
+	kept := snapshot.kept
+	snapshot.kept = false
+
+	// The restore outlives the period and is only canceled if the add-on is put on probation again
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watch{cancel: cancel}
+
+	p.mutex.Lock()
+	if previous, ok := p.watches[snapshot.Name]; ok {
+		previous.cancel()
+	}
+	p.watches[snapshot.Name] = w
+	p.mutex.Unlock()
+
+	log.Infof("'%s' is on probation for %s after its update to '%s'", snapshot.Name, p.period, version)
+	go func() {
+		defer p.end(snapshot.Name, w)
+
+		if kept {
+			defer p.releasePackage(snapshot.Name, snapshot.Version)
+		}
+
+		periodCtx, cancelPeriod := context.WithTimeout(ctx, p.period)
+		defer cancelPeriod()
+		p.watch(ctx, periodCtx, snapshot, version)
+	}()
+}
+
+// Returns whether the add-on identified by name is on probation.
+func (p *Probation) IsOnProbation(name string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	_, ok := p.watches[name]
+	return ok
+}
+
+func (p *Probation) end(name string, w *watch) {
+	w.cancel()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.watches[name] == w {
+		delete(p.watches, name)
+	}
+}
+
+// Keeps the package of the snapshot if the probation is enabled.
+// Without the package a rollback still pulls it from the registry.
+func (p *Probation) keepPackage(ctx context.Context, snapshot *Snapshot) {
+	if p.keeper == nil || p.period <= 0 {
+		return
+	}
+
+	if _, err := p.keeper.Keep(ctx, snapshot.Name, snapshot.Version); err != nil {
+		log.Warnf("Unable to keep the package of '%s:%s', a rollback requires the registry: %v", snapshot.Name, snapshot.Version, err)
+		return
+	}
+	snapshot.kept = true
+}
+
+func (p *Probation) releasePackage(name string, version string) {
+	if err := p.keeper.Release(name, version); err != nil {
+		log.Warnf("Unable to release the package of '%s:%s': %v", name, version, err)
+	}
+}
+
+// Watches the add-on until the period of periodCtx is over, ctx bounds a rollback.
+func (p *Probation) watch(ctx context.Context, periodCtx context.Context, snapshot *Snapshot, version string) {
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-periodCtx.Done():
+			if errors.Is(periodCtx.Err(), context.DeadlineExceeded) {
+				log.Infof("'%s' passed its probation after the update to '%s'", snapshot.Name, version)
+			}
+			return
+		case <-ticker.C:
+		}
+
+		reason, changed := p.check(snapshot.Name, version)
+		if changed {
+			return
+		}
+		if reason != "" {
+			p.rollback(ctx, snapshot, version, reason)
+			return
+		}
+	}
+}
+
+// Returns why the add-on failed its probation, empty if it is healthy,
+// and whether the add-on has been changed since its update.
+func (p *Probation) check(name string, version string) (string, bool) {
+	addOn, err := p.localCatalogue.GetAddOn(name)
+	if err != nil || addOn.Version != version {
+		return "", true
+	}
+
+	addOnStatus, err := p.statusResolver.GetAddOnStatus(name)
+	if err != nil {
+		log.Warnf("Unable to get the status of '%s' on probation: %v", name, err)
+		return "", false
+	}
+	switch addOnStatus {
+	case status.Error:
+		return "The add-on went into error", false
+	case status.Unhealthy:
+		return "The add-on became unhealthy", false
+	}
+
+	containers, err := p.stackService.ListAllStackContainers(name)
+	if err != nil {
+		log.Warnf("Unable to list the containers of '%s' on probation: %v", name, err)
+		return "", false
+	}
+	for _, container := range containers {
+		info, err := p.stackService.InspectContainer(container.ID)
+		if err != nil {
+			log.Warnf("Unable to inspect the container '%s' of '%s' on probation: %v", container.ID, name, err)
+			continue
+		}
+		if info.RestartCount >= crashLoopRestarts {
+			return fmt.Sprintf("A container crash-looped with %d restarts", info.RestartCount), false
+		}
+	}
+	return "", false
+}
+
+// Restores the snapshot, the restore is attempted again while another operation is in progress,
+// up to maxRestoreAttempts times and as long as the add-on is not changed and ctx is not done.
+func (p *Probation) rollback(ctx context.Context, snapshot *Snapshot, version string, reason string) {
+	log.Warnf("'%s' failed its probation after the update to '%s': %s. Restoring '%s'", snapshot.Name, version, reason, snapshot.Version)
+
+	err := p.restore(ctx, snapshot, version)
+
+	entry := HistoryEntry{Time: time.Now(), Name: snapshot.Name, FromVersion: snapshot.Version, ToVersion: version, Result: ResultRolledBack, Message: reason}
+	if err != nil {
+		log.Errorf("Unable to restore '%s' to '%s': %v", snapshot.Name, snapshot.Version, err)
+		entry.Result = ResultRollbackFailed
+		entry.Message = fmt.Sprintf("%s. Restore failed: %v", reason, err)
+	}
+	if err := p.history.Record(entry); err != nil {
+		log.Warnf("Unable to record the rollback of '%s' in the history: %v", snapshot.Name, err)
+	}
+}
+
+func (p *Probation) restore(ctx context.Context, snapshot *Snapshot, version string) error {
+	for attempt := 1; ; attempt++ {
+		err := p.restorer.RestoreAddOn(snapshot.Name, snapshot.Version, snapshot.Settings...)
+		if !errors.Is(err, ErrBusy) || attempt == maxRestoreAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("The add-on has been put on probation again: %w", ctx.Err())
+		case <-time.After(p.pollInterval):
+		}
+
+		addOn, getErr := p.localCatalogue.GetAddOn(snapshot.Name)
+		if getErr != nil {
+			return getErr
+		}
+		if addOn.Version != version {
+			return fmt.Errorf("The add-on has been changed to '%s'", addOn.Version)
+		}
+	}
+}
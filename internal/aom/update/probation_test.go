@@ -0,0 +1,232 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/docker"
+	"u-control/uc-aom/internal/aom/status"
+	"u-control/uc-aom/internal/aom/update"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type envResolverMock struct {
+	environment map[string]string
+}
+
+func (m *envResolverMock) GetAddOnEnvironment(name string) (map[string]string, error) {
+	return m.environment, nil
+}
+
+type statusResolverMock struct {
+	status status.AddOnStatus
+}
+
+func (m *statusResolverMock) GetAddOnStatus(name string) (status.AddOnStatus, error) {
+	return m.status, nil
+}
+
+type restorerMock struct {
+	mock.Mock
+}
+
+func (m *restorerMock) RestoreAddOn(name string, version string, settings ...*manifest.Setting) error {
+	args := m.Called(name, version, settings)
+	return args.Error(0)
+}
+
+type packageKeeperMock struct {
+	mock.Mock
+}
+
+func (m *packageKeeperMock) Keep(ctx context.Context, repository string, tag string) (uint64, error) {
+	args := m.Called(repository, tag)
+	return uint64(args.Int(0)), args.Error(1)
+}
+
+func (m *packageKeeperMock) Release(repository string, tag string) error {
+	args := m.Called(repository, tag)
+	return args.Error(0)
+}
+
+type probationTestCase struct {
+	status       status.AddOnStatus
+	restartCount int
+}
+
+func newProbationUnderTest(t *testing.T, installedVersion string, testCase probationTestCase, restorer update.Restorer) (*update.Probation, *update.History) {
+	t.Helper()
+	installed := installedAddOn("addon", installedVersion)
+	installed.Manifest.Settings = map[string][]*manifest.Setting{
+		"environmentVariables": {manifest.NewSettings("URL", "Url", true).WithTextBoxValue("http://localhost")},
+	}
+	localCatalogue := &catalogue.CatalogueMock{}
+	localCatalogue.On("GetAddOn", "addon").Return(*installed, nil)
+	stackService := &docker.MockStackService{}
+	stackService.On("ListAllStackContainers", "addon").Return([]types.Container{{ID: "container"}}, nil)
+	stackService.On("InspectContainer", "container").Return(&docker.ContainerInfo{RestartCount: testCase.restartCount}, nil)
+	dir := t.TempDir()
+	history, err := update.NewHistory(os.ReadFile, os.WriteFile, filepath.Join(dir, "update-history.json"), filepath.Join(dir, "update-history-state.json"))
+	assert.NoError(t, err)
+
+	envResolver := &envResolverMock{environment: map[string]string{"URL": "http://plc"}}
+	uut := update.NewProbation(localCatalogue, envResolver, &statusResolverMock{status: testCase.status}, stackService, restorer, history, 100*time.Millisecond, 5*time.Millisecond)
+	return uut, history
+}
+
+func TestProbationSnapshot(t *testing.T) {
+	// Arrange
+	uut, _ := newProbationUnderTest(t, "1.0.0-1", probationTestCase{status: status.Running}, &restorerMock{})
+
+	// Act
+	snapshot, err := uut.Snapshot(context.Background(), "addon")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0-1", snapshot.Version)
+	assert.Len(t, snapshot.Settings, 1)
+	assert.Equal(t, "http://plc", snapshot.Settings[0].Value)
+}
+
+func TestProbationRollsBackFailingUpdate(t *testing.T) {
+	testCases := map[string]probationTestCase{
+		"error":      {status: status.Error},
+		"unhealthy":  {status: status.Unhealthy},
+		"crash-loop": {status: status.Running, restartCount: 3},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			restorer := &restorerMock{}
+			restorer.On("RestoreAddOn", "addon", "1.0.0-1", mock.Anything).Return(nil)
+			uut, history := newProbationUnderTest(t, "1.0.0-2", testCase, restorer)
+			snapshot := &update.Snapshot{Name: "addon", Version: "1.0.0-1", Settings: []*manifest.Setting{manifest.NewSettings("URL", "Url", true).WithTextBoxValue("http://plc")}}
+
+			// Act
+			uut.Start(snapshot, "1.0.0-2")
+
+			// Assert
+			assert.Eventually(t, func() bool { return len(history.Entries()) == 1 }, time.Second, 5*time.Millisecond)
+			entry := history.Entries()[0]
+			assert.Equal(t, update.ResultRolledBack, entry.Result)
+			assert.Equal(t, "1.0.0-1", entry.FromVersion)
+			assert.Equal(t, "1.0.0-2", entry.ToVersion)
+			assert.NotEmpty(t, entry.Message)
+			restorer.AssertCalled(t, "RestoreAddOn", "addon", "1.0.0-1", snapshot.Settings)
+		})
+	}
+}
+
+func TestProbationPassesHealthyUpdate(t *testing.T) {
+	// Arrange
+	restorer := &restorerMock{}
+	uut, history := newProbationUnderTest(t, "1.0.0-2", probationTestCase{status: status.Running, restartCount: 1}, restorer)
+
+	// Act
+	uut.Start(&update.Snapshot{Name: "addon", Version: "1.0.0-1"}, "1.0.0-2")
+
+	// Assert
+	assert.True(t, uut.IsOnProbation("addon"))
+	assert.Eventually(t, func() bool { return !uut.IsOnProbation("addon") }, time.Second, 5*time.Millisecond)
+	restorer.AssertNotCalled(t, "RestoreAddOn", mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(t, history.Entries())
+}
+
+func TestProbationEndsIfAddOnChanged(t *testing.T) {
+	// Arrange
+	restorer := &restorerMock{}
+	uut, history := newProbationUnderTest(t, "1.0.0-3", probationTestCase{status: status.Error}, restorer)
+
+	// Act
+	uut.Start(&update.Snapshot{Name: "addon", Version: "1.0.0-1"}, "1.0.0-2")
+
+	// Assert
+	assert.Eventually(t, func() bool { return !uut.IsOnProbation("addon") }, time.Second, 5*time.Millisecond)
+	restorer.AssertNotCalled(t, "RestoreAddOn", mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(t, history.Entries())
+}
+
+func TestProbationRecordsFailedRestore(t *testing.T) {
+	// Arrange
+	restorer := &restorerMock{}
+	restorer.On("RestoreAddOn", "addon", "1.0.0-1", mock.Anything).Return(update.ErrBusy).Once()
+	restorer.On("RestoreAddOn", "addon", "1.0.0-1", mock.Anything).Return(errors.New("pull failed"))
+	uut, history := newProbationUnderTest(t, "1.0.0-2", probationTestCase{status: status.Error}, restorer)
+
+	// Act
+	uut.Start(&update.Snapshot{Name: "addon", Version: "1.0.0-1"}, "1.0.0-2")
+
+	// Assert
+	assert.Eventually(t, func() bool { return len(history.Entries()) == 1 }, time.Second, 5*time.Millisecond)
+	restorer.AssertNumberOfCalls(t, "RestoreAddOn", 2)
+	assert.Equal(t, update.ResultRollbackFailed, history.Entries()[0].Result)
+	assert.Contains(t, history.Entries()[0].Message, "pull failed")
+}
+
+func TestProbationStopsRestoreAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	restorer := &restorerMock{}
+	restorer.On("RestoreAddOn", "addon", "1.0.0-1", mock.Anything).Return(update.ErrBusy)
+	uut, history := newProbationUnderTest(t, "1.0.0-2", probationTestCase{status: status.Error}, restorer)
+
+	// Act
+	uut.Start(&update.Snapshot{Name: "addon", Version: "1.0.0-1"}, "1.0.0-2")
+
+	// Assert
+	assert.Eventually(t, func() bool { return len(history.Entries()) == 1 }, time.Second, 5*time.Millisecond)
+	restorer.AssertNumberOfCalls(t, "RestoreAddOn", 30)
+	assert.Equal(t, update.ResultRollbackFailed, history.Entries()[0].Result)
+}
+
+func TestProbationKeepsPackageOfPreviousVersion(t *testing.T) {
+	// Arrange
+	restorer := &restorerMock{}
+	uut, _ := newProbationUnderTest(t, "1.0.0-1", probationTestCase{status: status.Running}, restorer)
+	keeper := &packageKeeperMock{}
+	keeper.On("Keep", "addon", "1.0.0-1").Return(42, nil)
+	keeper.On("Release", "addon", "1.0.0-1").Return(nil).Once()
+	uut.UsePackageKeeper(keeper)
+
+	// Act
+	snapshot, err := uut.Snapshot(context.Background(), "addon")
+	keeper.AssertCalled(t, "Keep", "addon", "1.0.0-1")
+	uut.Start(snapshot, "1.0.0-2")
+	uut.Release(snapshot)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return !uut.IsOnProbation("addon") }, time.Second, 5*time.Millisecond)
+	keeper.AssertExpectations(t)
+}
+
+func TestProbationReleasesPackageOfSnapshotWithoutProbation(t *testing.T) {
+	// Arrange
+	uut, _ := newProbationUnderTest(t, "1.0.0-1", probationTestCase{status: status.Running}, &restorerMock{})
+	keeper := &packageKeeperMock{}
+	keeper.On("Keep", "addon", "1.0.0-1").Return(42, nil)
+	keeper.On("Release", "addon", "1.0.0-1").Return(nil).Once()
+	uut.UsePackageKeeper(keeper)
+
+	// Act
+	snapshot, err := uut.Snapshot(context.Background(), "addon")
+	uut.Release(snapshot)
+	uut.Release(snapshot)
+
+	// Assert
+	assert.NoError(t, err)
+	keeper.AssertExpectations(t)
+	keeper.AssertNumberOfCalls(t, "Release", 1)
+}
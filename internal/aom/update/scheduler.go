@@ -0,0 +1,247 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update
+
+import (
+	"context"
+	"errors"
+	"time"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/service"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Delay until a failed update to the same version is attempted again
+const failedUpdateRetryDelay = 24 * time.Hour
+
+// Returned by an Updater if another operation is in progress, the update is attempted again later.
+var ErrBusy = errors.New("Another add-on operation is in progress")
+
+// Updater applies the update of an installed add-on to the version.
+type Updater interface {
+	UpdateAddOn(name string, version string) error
+}
+
+// TransactionUpdater applies updates in a transaction of the transaction scheduler, as the gRPC server does.
+// The current settings of the add-on are migrated to the new version.
+type TransactionUpdater struct {
+	transactionScheduler *service.TransactionScheduler
+	service              *service.Service
+}
+
+func NewTransactionUpdater(transactionScheduler *service.TransactionScheduler, service *service.Service) *TransactionUpdater {
+	return &TransactionUpdater{transactionScheduler: transactionScheduler, service: service}
+}
+
+func (u *TransactionUpdater) UpdateAddOn(name string, version string) error {
+	return u.replaceAddOn(name, version)
+}
+
+// Replaces the add-on by the version with the settings, as the update does.
+func (u *TransactionUpdater) RestoreAddOn(name string, version string, settings ...*manifest.Setting) error {
+	return u.replaceAddOn(name, version, settings...)
+}
+
+func (u *TransactionUpdater) replaceAddOn(name string, version string, settings ...*manifest.Setting) error {
+	tx, err := u.transactionScheduler.CreateTransaction(context.Background(), u.service)
+	if err != nil {
+		return ErrBusy
+	}
+
+	defer tx.Rollback()
+	if err := tx.ReplaceAddOnRoutine(name, version, settings...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Scheduler applies the updates permitted by the update policies of the add-ons within the maintenance windows of the device.
+// It relies on the results of the update checker, so that only add-ons with an available update are considered.
+type Scheduler struct {
+	localCatalogue catalogue.LocalAddOnCatalogue
+	checker        *Checker
+	policies       *PolicyStore
+	history        *History
+	updater        Updater
+	probation      *Probation
+	now            func() time.Time
+}
+
+func NewScheduler(localCatalogue catalogue.LocalAddOnCatalogue, checker *Checker, policies *PolicyStore, history *History, updater Updater, now func() time.Time) *Scheduler {
+	return &Scheduler{
+		localCatalogue: localCatalogue,
+		checker:        checker,
+		policies:       policies,
+		history:        history,
+		updater:        updater,
+		now:            now,
+	}
+}
+
+// Puts the add-ons on probation after their scheduled update.
+func (s *Scheduler) UseProbation(probation *Probation) {
+	s.probation = probation
+}
+
+// Runs the scheduler every interval until the context is done.
+// The interval must be positive.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.RunOnce(ctx); err != nil {
+			log.Warnf("Scheduled updates failed: %v", err)
+		}
+	}
+}
+
+// Records the available updates of add-ons with the notify policy once per version and,
+// within a maintenance window, applies the latest update permitted by the policy of each add-on.
+// A failed update to a version is attempted again after a day, a rolled back version is not attempted again.
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	now := s.now()
+	isMaintenanceTime := s.isMaintenanceTime(now)
+
+	installedAddOns, err := s.localCatalogue.GetAddOns()
+	if err != nil {
+		return err
+	}
+
+	for _, installedAddOn := range installedAddOns {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		availableUpdate := s.checker.UpdateOf(installedAddOn)
+		if availableUpdate == nil {
+			continue
+		}
+
+		policy := s.policies.PolicyOf(installedAddOn.Name)
+		if policy.Policy == PolicyNotify {
+			s.notify(availableUpdate, now)
+			continue
+		}
+
+		if !policy.Policy.IsAutomatic() || !isMaintenanceTime {
+			continue
+		}
+
+		if err := s.update(ctx, installedAddOn, policy, now); errors.Is(err, ErrBusy) {
+			log.Infof("Scheduled update of '%s' postponed: %v", installedAddOn.Name, err)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) isMaintenanceTime(t time.Time) bool {
+	for _, window := range s.policies.MaintenanceWindows() {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) notify(availableUpdate *AvailableUpdate, now time.Time) {
+	if _, ok := s.history.LatestEntryOf(availableUpdate.Name, availableUpdate.LatestVersion); ok {
+		return
+	}
+
+	s.record(HistoryEntry{
+		Time:        now,
+		Name:        availableUpdate.Name,
+		FromVersion: availableUpdate.CurrentVersion,
+		ToVersion:   availableUpdate.LatestVersion,
+		Result:      ResultNotified,
+	})
+}
+
+// Applies the latest update permitted by the policy, returns ErrBusy if another operation is in progress.
+func (s *Scheduler) update(ctx context.Context, installedAddOn *catalogue.CatalogueAddOn, policy AddOnPolicy, now time.Time) error {
+	currentVersion := installedAddOn.Manifest.Version
+	permits := func(version string) bool {
+		return policy.Permits(currentVersion, version)
+	}
+
+	version, err := s.checker.LatestCompatibleVersion(ctx, installedAddOn, permits)
+	if err != nil {
+		log.Warnf("Unable to select the update of '%s': %v", installedAddOn.Name, err)
+		return err
+	}
+	if version == currentVersion {
+		return nil
+	}
+
+	if entry, ok := s.history.LatestEntryOf(installedAddOn.Name, version); ok && !isRetryable(entry, now) {
+		return nil
+	}
+
+	snapshot := s.snapshot(ctx, installedAddOn.Name)
+	if snapshot != nil {
+		defer s.probation.Release(snapshot)
+	}
+
+	log.Infof("Updating '%s' from '%s' to '%s' by its update policy '%s'", installedAddOn.Name, currentVersion, version, policy.Policy)
+	err = s.updater.UpdateAddOn(installedAddOn.Name, version)
+	if errors.Is(err, ErrBusy) {
+		return err
+	}
+	if err == nil && snapshot != nil {
+		s.probation.Start(snapshot, version)
+	}
+
+	entry := HistoryEntry{Time: now, Name: installedAddOn.Name, FromVersion: currentVersion, ToVersion: version, Result: ResultUpdated}
+	if err != nil {
+		log.Errorf("Scheduled update of '%s' to '%s' failed: %v", installedAddOn.Name, version, err)
+		entry.Result = ResultFailed
+		entry.Message = err.Error()
+	}
+	s.record(entry)
+	return err
+}
+
+// Returns the snapshot of the add-on for its probation, nil without probation.
+func (s *Scheduler) snapshot(ctx context.Context, name string) *Snapshot {
+	if s.probation == nil {
+		return nil
+	}
+
+	snapshot, err := s.probation.Snapshot(ctx, name)
+	if err != nil {
+		log.Warnf("Unable to take the snapshot of '%s', the update is not put on probation: %v", name, err)
+		return nil
+	}
+	return snapshot
+}
+
+// Returns whether the update of the entry may be attempted again.
+// Versions which have been rolled back after their probation are only applied by the user.
+func isRetryable(entry HistoryEntry, now time.Time) bool {
+	switch entry.Result {
+	case ResultFailed:
+		return now.Sub(entry.Time) >= failedUpdateRetryDelay
+	case ResultRolledBack, ResultRollbackFailed:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *Scheduler) record(entry HistoryEntry) {
+	if err := s.history.Record(entry); err != nil {
+		log.Warnf("Unable to record the update of '%s' in the history: %v", entry.Name, err)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package update_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"u-control/uc-aom/internal/aom/catalogue"
+	"u-control/uc-aom/internal/aom/update"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type updaterMock struct {
+	mock.Mock
+}
+
+func (m *updaterMock) UpdateAddOn(name string, version string) error {
+	args := m.Called(name, version)
+	return args.Error(0)
+}
+
+// Saturday, 02:30
+var maintenanceTime = time.Date(2023, 6, 10, 2, 30, 0, 0, time.Local)
+
+func newSchedulerUnderTest(t *testing.T, policy update.AddOnPolicy, updater update.Updater, now *time.Time) (*update.Scheduler, *update.History) {
+	t.Helper()
+	versions := []string{"1.0.0-1", "1.0.0-2", "1.1.0-1"}
+	localCatalogue := &catalogue.CatalogueMock{}
+	localCatalogue.On("GetAddOns").Return([]*catalogue.CatalogueAddOn{installedAddOn("addon", "1.0.0-1")}, nil)
+	remoteCatalogue := &catalogue.RemoteCatalogueMock{}
+	remoteCatalogue.On("GetAddOnVersions", "addon").Return(versions, nil)
+	for _, version := range versions {
+		remoteCatalogue.On("FetchManifest", "addon", version).Return(remoteManifest(version), nil)
+	}
+	checker := update.NewChecker(localCatalogue, remoteCatalogue, &compatibilityMock{})
+	_, err := checker.CheckForUpdates(context.Background())
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	policies, err := update.NewPolicyStore(os.ReadFile, os.WriteFile, filepath.Join(dir, "update-policies.json"))
+	assert.NoError(t, err)
+	assert.NoError(t, policies.SetAddOnPolicy("addon", policy))
+	assert.NoError(t, policies.SetMaintenanceWindows([]update.MaintenanceWindow{{Start: "02:00", End: "04:00"}}))
+	history, err := update.NewHistory(os.ReadFile, os.WriteFile, filepath.Join(dir, "update-history.json"), filepath.Join(dir, "update-history-state.json"))
+	assert.NoError(t, err)
+
+	return update.NewScheduler(localCatalogue, checker, policies, history, updater, func() time.Time { return *now }), history
+}
+
+func TestSchedulerAppliesPermittedUpdate(t *testing.T) {
+	testCases := map[string]struct {
+		policy          update.AddOnPolicy
+		expectedVersion string
+	}{
+		"auto-patch":        {policy: update.AddOnPolicy{Policy: update.PolicyAutoPatch}, expectedVersion: "1.0.0-2"},
+		"auto-minor":        {policy: update.AddOnPolicy{Policy: update.PolicyAutoMinor}, expectedVersion: "1.1.0-1"},
+		"auto-minor pinned": {policy: update.AddOnPolicy{Policy: update.PolicyAutoMinor, PinnedVersion: "1.0.0-2"}, expectedVersion: "1.0.0-2"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			now := maintenanceTime
+			updater := &updaterMock{}
+			updater.On("UpdateAddOn", "addon", testCase.expectedVersion).Return(nil)
+			uut, history := newSchedulerUnderTest(t, testCase.policy, updater, &now)
+
+			// Act
+			err := uut.RunOnce(context.Background())
+
+			// Assert
+			assert.NoError(t, err)
+			updater.AssertExpectations(t)
+			expected := update.HistoryEntry{Time: now, Name: "addon", FromVersion: "1.0.0-1", ToVersion: testCase.expectedVersion, Result: update.ResultUpdated}
+			assert.Equal(t, []update.HistoryEntry{expected}, history.Entries())
+		})
+	}
+}
+
+func TestSchedulerDoesNotUpdateOutsideMaintenanceWindow(t *testing.T) {
+	// Arrange
+	now := maintenanceTime.Add(2 * time.Hour)
+	updater := &updaterMock{}
+	uut, history := newSchedulerUnderTest(t, update.AddOnPolicy{Policy: update.PolicyAutoMinor}, updater, &now)
+
+	// Act
+	err := uut.RunOnce(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	updater.AssertNotCalled(t, "UpdateAddOn", mock.Anything, mock.Anything)
+	assert.Empty(t, history.Entries())
+}
+
+func TestSchedulerNotifiesOncePerVersion(t *testing.T) {
+	// Arrange
+	now := maintenanceTime
+	updater := &updaterMock{}
+	uut, history := newSchedulerUnderTest(t, update.AddOnPolicy{Policy: update.PolicyNotify}, updater, &now)
+
+	// Act
+	assert.NoError(t, uut.RunOnce(context.Background()))
+	now = now.Add(time.Hour)
+	assert.NoError(t, uut.RunOnce(context.Background()))
+
+	// Assert
+	updater.AssertNotCalled(t, "UpdateAddOn", mock.Anything, mock.Anything)
+	expected := update.HistoryEntry{Time: maintenanceTime, Name: "addon", FromVersion: "1.0.0-1", ToVersion: "1.1.0-1", Result: update.ResultNotified}
+	assert.Equal(t, []update.HistoryEntry{expected}, history.Entries())
+}
+
+func TestSchedulerRetriesFailedUpdateAfterADay(t *testing.T) {
+	// Arrange
+	now := maintenanceTime
+	updater := &updaterMock{}
+	updater.On("UpdateAddOn", "addon", "1.0.0-2").Return(errors.New("pull failed"))
+	uut, history := newSchedulerUnderTest(t, update.AddOnPolicy{Policy: update.PolicyAutoPatch}, updater, &now)
+
+	// Act
+	assert.NoError(t, uut.RunOnce(context.Background()))
+	now = now.Add(time.Hour)
+	assert.NoError(t, uut.RunOnce(context.Background()))
+	now = now.Add(24 * time.Hour)
+	assert.NoError(t, uut.RunOnce(context.Background()))
+
+	// Assert
+	updater.AssertNumberOfCalls(t, "UpdateAddOn", 2)
+	entries := history.Entries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, update.ResultFailed, entries[0].Result)
+	assert.Equal(t, "pull failed", entries[0].Message)
+}
+
+func TestSchedulerDoesNotRetryRolledBackUpdate(t *testing.T) {
+	// Arrange
+	now := maintenanceTime
+	updater := &updaterMock{}
+	uut, history := newSchedulerUnderTest(t, update.AddOnPolicy{Policy: update.PolicyAutoPatch}, updater, &now)
+	assert.NoError(t, history.Record(update.HistoryEntry{Time: now.Add(-48 * time.Hour), Name: "addon", FromVersion: "1.0.0-1", ToVersion: "1.0.0-2", Result: update.ResultRolledBack}))
+
+	// Act
+	err := uut.RunOnce(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	updater.AssertNotCalled(t, "UpdateAddOn", mock.Anything, mock.Anything)
+}
+
+func TestSchedulerPostponesUpdateIfBusy(t *testing.T) {
+	// Arrange
+	now := maintenanceTime
+	updater := &updaterMock{}
+	updater.On("UpdateAddOn", "addon", "1.0.0-2").Return(update.ErrBusy)
+	uut, history := newSchedulerUnderTest(t, update.AddOnPolicy{Policy: update.PolicyAutoPatch}, updater, &now)
+
+	// Act
+	err := uut.RunOnce(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	updater.AssertExpectations(t)
+	assert.Empty(t, history.Entries())
+}
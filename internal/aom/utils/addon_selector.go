@@ -0,0 +1,46 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// AddOnSelector identifies the add-ons an entry of a device-side policy applies to.
+// Either Name or Pattern identifies the add-ons.
+type AddOnSelector struct {
+	Name    string `json:"name,omitempty"`    // exact name of the add-on
+	Pattern string `json:"pattern,omitempty"` // regular expression matched against the add-on name
+
+	regex *regexp.Regexp
+}
+
+// Compiles the pattern of the selector, entry names the kind of policy entry in the error.
+// MUST be called before Matches.
+func (s *AddOnSelector) Compile(entry string) error {
+	if s.Name == "" && s.Pattern == "" {
+		return fmt.Errorf("%s requires a name or a pattern", entry)
+	}
+
+	if s.Pattern == "" {
+		return nil
+	}
+
+	regex, err := regexp.Compile(s.Pattern)
+	if err != nil {
+		return err
+	}
+	s.regex = regex
+	return nil
+}
+
+// Returns true if the add-on with the given name is selected.
+func (s *AddOnSelector) Matches(name string) bool {
+	if s.Name != "" {
+		return s.Name == name
+	}
+	return s.regex.MatchString(name)
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package utils_test
+
+import (
+	"testing"
+	"u-control/uc-aom/internal/aom/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddOnSelectorMatches(t *testing.T) {
+	testCases := map[string]struct {
+		selector utils.AddOnSelector
+		name     string
+		matches  bool
+	}{
+		"name":              {selector: utils.AddOnSelector{Name: "codesys"}, name: "codesys", matches: true},
+		"other name":        {selector: utils.AddOnSelector{Name: "codesys"}, name: "codesys-gateway", matches: false},
+		"pattern":           {selector: utils.AddOnSelector{Pattern: `^node-red`}, name: "node-red-dashboard", matches: true},
+		"other pattern":     {selector: utils.AddOnSelector{Pattern: `^node-red`}, name: "anyviz", matches: false},
+		"name over pattern": {selector: utils.AddOnSelector{Name: "anyviz", Pattern: `.*`}, name: "codesys", matches: false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			selector := testCase.selector
+
+			// Act
+			err := selector.Compile("Test entry")
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.matches, selector.Matches(testCase.name))
+		})
+	}
+}
+
+func TestAddOnSelectorRequiresNameOrPattern(t *testing.T) {
+	// Arrange
+	selector := utils.AddOnSelector{}
+
+	// Act
+	err := selector.Compile("Test entry")
+
+	// Assert
+	assert.EqualError(t, err, "Test entry requires a name or a pattern")
+}
+
+func TestAddOnSelectorRejectsInvalidPattern(t *testing.T) {
+	// Arrange
+	selector := utils.AddOnSelector{Pattern: "("}
+
+	// Act
+	err := selector.Compile("Test entry")
+
+	// Assert
+	assert.Error(t, err)
+}
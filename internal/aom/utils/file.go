@@ -6,11 +6,55 @@ package utils
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 )
 
+// Reads the content of the file at path, e.g. os.ReadFile.
+type ReadFileFunc func(path string) ([]byte, error)
+
+// Writes the content to the file at path, e.g. os.WriteFile.
+type WriteFileFunc func(path string, content []byte, perm os.FileMode) error
+
+// Reads the JSON file at path into value, description names the content in the error of an invalid file.
+// Returns false and leaves value unchanged if the file does not exist.
+func ReadJSONFile(readFile ReadFileFunc, path string, description string, value interface{}) (bool, error) {
+	content, err := readFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(content, value); err != nil {
+		return false, fmt.Errorf("Invalid %s '%s': %w", description, path, err)
+	}
+	return true, nil
+}
+
+// Writes the value as JSON to the file at path, see WriteFileCreatingDirectory.
+func WriteJSONFile(writeFile WriteFileFunc, path string, value interface{}, perm os.FileMode) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileCreatingDirectory(writeFile, path, content, perm)
+}
+
+// Writes the content to the file at path and creates the directory of the file if it does not exist.
+func WriteFileCreatingDirectory(writeFile WriteFileFunc, path string, content []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return writeFile(path, content, perm)
+}
+
 // MkDirAll Creates a directory and set the permissions additionally
 // https://github.com/golang/go/issues/15210
 func MkDirAll(path string, permission os.FileMode) error {
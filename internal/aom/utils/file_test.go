@@ -85,3 +85,36 @@ func TestWriteFileToDestination(t *testing.T) {
 		os.RemoveAll(testPath)
 	})
 }
+
+func TestWriteAndReadJSONFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "missing", "values.json")
+	values := map[string]int{"a": 1}
+
+	// Act
+	existsBefore, errBefore := utils.ReadJSONFile(os.ReadFile, path, "values", &map[string]int{})
+	writeErr := utils.WriteJSONFile(os.WriteFile, path, values, 0600)
+	read := map[string]int{}
+	exists, err := utils.ReadJSONFile(os.ReadFile, path, "values", &read)
+
+	// Assert
+	assert.False(t, existsBefore)
+	assert.NoError(t, errBefore)
+	assert.NoError(t, writeErr)
+	assert.True(t, exists)
+	assert.NoError(t, err)
+	assert.Equal(t, values, read)
+}
+
+func TestReadInvalidJSONFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "values.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+
+	// Act
+	exists, err := utils.ReadJSONFile(os.ReadFile, path, "values", &map[string]int{})
+
+	// Assert
+	assert.False(t, exists)
+	assert.ErrorContains(t, err, "Invalid values")
+}
@@ -5,12 +5,17 @@
 package utils
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
 	"errors"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -60,6 +65,43 @@ func GetEnvBool(key string, fallback bool) bool {
 	return fallback
 }
 
+// check for the environment variable with a given key
+// and return the uint64 value, the fallback is returned if not set or invalid
+func GetEnvUint64(key string, fallback uint64) uint64 {
+	if value, ok := os.LookupEnv(key); ok {
+		if parsed, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// Returns the cumulative size of all regular files below root in bytes.
+// A missing root is reported as empty.
+func DirectorySize(root string) (uint64, error) {
+	var size uint64
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += uint64(info.Size())
+		return nil
+	})
+	return size, err
+}
+
 // Function returns a 5 digit SHA1 hash string for the provided content.
 func GetShortSHA1HashFrom(content []byte) (string, error) {
 	sha1HashFunc := func(content []byte) (string, error) {
@@ -89,6 +131,12 @@ type RetryFunc func() (interface{}, error)
 
 // Retry returns the callback func result or an error if all retry attempts failed
 func Retry(attempts int, delay time.Duration, retryCallbackFunc RetryFunc) (interface{}, error) {
+	return RetryWithContext(context.Background(), attempts, delay, retryCallbackFunc)
+}
+
+// RetryWithContext returns the callback func result or an error if all retry attempts failed.
+// Stops retrying when the context is done and returns the error of the context.
+func RetryWithContext(ctx context.Context, attempts int, delay time.Duration, retryCallbackFunc RetryFunc) (interface{}, error) {
 	if attempts < 0 {
 		return nil, errors.New("Retry attempts should be greater than 0")
 	}
@@ -96,11 +144,49 @@ func Retry(attempts int, delay time.Duration, retryCallbackFunc RetryFunc) (inte
 	var res interface{}
 	for i := 0; i < attempts; i++ {
 		res, err = retryCallbackFunc()
-		if err != nil {
-			time.Sleep(delay)
-			continue
+		if err == nil {
+			return res, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
 		}
-		return res, nil
 	}
 	return nil, err
 }
+
+// Calls fn for the indices 0 to n-1 on at most workers goroutines and waits for all calls to return.
+// No further calls are started once the context is done, in that case the error of the context is returned.
+func ForEachConcurrently(ctx context.Context, workers int, n int, fn func(i int)) error {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	var err error
+schedule:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break schedule
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
@@ -5,9 +5,11 @@
 package utils_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 	"u-control/uc-aom/internal/aom/utils"
@@ -170,6 +172,29 @@ func TestRetry_CallbackError(t *testing.T) {
 	}
 }
 
+func TestRetryWithContext_Canceled(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	retryCounter := 0
+	callbackFunc := func() (interface{}, error) {
+		retryCounter++
+		cancel()
+		return nil, errors.New("UNEXPECTED_ERROR")
+	}
+
+	// Act
+	_, err := utils.RetryWithContext(ctx, 3, time.Hour, callbackFunc)
+
+	// Assert
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the error of the context but got %v", err)
+	}
+
+	if retryCounter != 1 {
+		t.Errorf("Expected 1 retry but got %d", retryCounter)
+	}
+}
+
 func TestRetry_CallbackResult(t *testing.T) {
 	// Arrange
 	expectedName := "ABC"
@@ -238,3 +263,76 @@ func TestGetEnvBool(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEnvUint64(t *testing.T) {
+	type args struct {
+		key           string
+		value         string
+		fallBack      uint64
+		expectedValue uint64
+	}
+
+	testCases := []args{
+		{key: "TEST_VALUE", value: "1024", fallBack: 1, expectedValue: 1024},
+		{key: "TEST_VALUE", value: "invalid", fallBack: 1, expectedValue: 1},
+		{key: "TEST_VALUE", value: "", fallBack: 1, expectedValue: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s=%s should have the value of %v", tc.key, tc.value, tc.expectedValue), func(t *testing.T) {
+			// Arrange
+			if tc.value != "" {
+				os.Setenv(tc.key, tc.value)
+				t.Cleanup(func() {
+					os.Unsetenv(tc.key)
+				})
+			}
+
+			// Act
+			res := utils.GetEnvUint64(tc.key, tc.fallBack)
+
+			// Assert
+			if res != tc.expectedValue {
+				t.Errorf("Expected result to be %v but got %v", tc.expectedValue, res)
+			}
+		})
+	}
+}
+
+func TestDirectorySize(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a"), make([]byte, 3), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "nested", "b"), make([]byte, 5), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	size, err := utils.DirectorySize(root)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if size != 8 {
+		t.Errorf("Expected size to be 8 but got %v", size)
+	}
+}
+
+func TestDirectorySizeOfMissingDirectory(t *testing.T) {
+	// Act
+	size, err := utils.DirectorySize(filepath.Join(t.TempDir(), "missing"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if size != 0 {
+		t.Errorf("Expected size to be 0 but got %v", size)
+	}
+}
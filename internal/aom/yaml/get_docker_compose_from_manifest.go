@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -7,6 +7,7 @@ package yaml
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"u-control/uc-aom/internal/pkg/manifest"
 
@@ -16,17 +17,37 @@ import (
 const (
 	// SUPPORTED_COMPOSE_FILE_VERSION the version of docker-compose that is supported by uc-aom
 	SUPPORTED_COMPOSE_FILE_VERSION = "2"
+
+	// Log driver which supports the rotation of the container logs
+	DEFAULT_LOG_DRIVER = "json-file"
 )
 
+// ServiceDefaults are injected into every docker-compose service which does not declare the option itself.
+// Zero values are not injected.
+type ServiceDefaults struct {
+	MemLimit   string `json:"memLimit,omitempty"`   // memory limit, e.g. 128m
+	PidsLimit  int64  `json:"pidsLimit,omitempty"`  // maximum number of processes
+	LogMaxSize string `json:"logMaxSize,omitempty"` // maximum size of a log file before it is rotated, e.g. 10m
+	LogMaxFile int    `json:"logMaxFile,omitempty"` // maximum number of rotated log files
+}
+
+// CpuPlacement is enforced on every docker-compose service and replaces the values declared in the manifest.
+// Zero values leave the service unchanged.
+type CpuPlacement struct {
+	Cpuset    string // cores the service may run on, e.g. 1-3
+	CpuShares int64  // relative cpu weight of the service
+}
+
 // GetDockerComposeFromManifest returns a docker-compose string from the property values
-// found in the manifest
-func GetDockerComposeFromManifest(manifestRoot *manifest.Root) (string, error) {
+// found in the manifest. The defaults are injected into services which omit the corresponding option
+// and the cpu placement is enforced on all services, nil injects nothing.
+func GetDockerComposeFromManifest(manifestRoot *manifest.Root, defaults *ServiceDefaults, placement *CpuPlacement) (string, error) {
 
 	if manifestRoot == nil {
 		return "", &yaml3.TypeError{Errors: []string{"Argument nil"}}
 	}
 
-	dockerComposeServices := getDockerComposeServicesFrom(manifestRoot.Services, manifestRoot.Settings)
+	dockerComposeServices := getDockerComposeServicesFrom(manifestRoot.Services, manifestRoot.Settings, defaults, placement)
 	dockerComposeVolumes := getDockerComposeVolumesFrom(manifestRoot.Environments)
 	dockerComposeNetworks := getDockerComposeNetworksFrom(manifestRoot.Environments)
 
@@ -41,7 +62,7 @@ func GetDockerComposeFromManifest(manifestRoot *manifest.Root) (string, error) {
 	return string(dockerComposeYAML), err
 }
 
-func getDockerComposeServicesFrom(manifestServices map[string]*manifest.Service, manifestSettings map[string][]*manifest.Setting) map[string]interface{} {
+func getDockerComposeServicesFrom(manifestServices map[string]*manifest.Service, manifestSettings map[string][]*manifest.Setting, defaults *ServiceDefaults, placement *CpuPlacement) map[string]interface{} {
 	dockerComposeServices := make(map[string]interface{})
 
 	for name, service := range manifestServices {
@@ -51,11 +72,86 @@ func getDockerComposeServicesFrom(manifestServices map[string]*manifest.Service,
 
 		config := service.Config
 		mergeEnvironmentVariables(config, manifestSettings)
-		dockerComposeServices[name] = config
+		dockerComposeServices[name] = withCpuPlacement(withServiceDefaults(config, defaults), placement)
 	}
 	return dockerComposeServices
 }
 
+// Returns the config of the docker-compose service as it is deployed with the given defaults.
+func ServiceConfigWithDefaults(service *manifest.Service, defaults *ServiceDefaults) map[string]interface{} {
+	return withServiceDefaults(service.Config, defaults)
+}
+
+// Returns a copy of the service config with the defaults for all options the service does not declare.
+func withServiceDefaults(config map[string]interface{}, defaults *ServiceDefaults) map[string]interface{} {
+	if defaults == nil {
+		return config
+	}
+
+	result := copyConfig(config)
+
+	if defaults.MemLimit != "" && !hasAnyKey(config, "memLimit", "mem_limit") {
+		result["mem_limit"] = defaults.MemLimit
+	}
+
+	if defaults.PidsLimit > 0 && !hasAnyKey(config, "pidsLimit", "pids_limit") {
+		result["pids_limit"] = defaults.PidsLimit
+	}
+
+	if (defaults.LogMaxSize != "" || defaults.LogMaxFile > 0) && !hasAnyKey(config, "logging") {
+		options := make(map[string]interface{})
+		if defaults.LogMaxSize != "" {
+			options["max-size"] = defaults.LogMaxSize
+		}
+		if defaults.LogMaxFile > 0 {
+			// docker-compose expects the log options as strings
+			options["max-file"] = strconv.Itoa(defaults.LogMaxFile)
+		}
+		result["logging"] = map[string]interface{}{
+			"driver":  DEFAULT_LOG_DRIVER,
+			"options": options,
+		}
+	}
+
+	return result
+}
+
+// Returns a copy of the service config with the cpu placement replacing the declared values.
+func withCpuPlacement(config map[string]interface{}, placement *CpuPlacement) map[string]interface{} {
+	if placement == nil {
+		return config
+	}
+
+	result := copyConfig(config)
+	if placement.Cpuset != "" {
+		result["cpuset"] = placement.Cpuset
+	}
+
+	if placement.CpuShares > 0 {
+		delete(result, "cpuShares")
+		result["cpu_shares"] = placement.CpuShares
+	}
+
+	return result
+}
+
+func copyConfig(config map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		result[key] = value
+	}
+	return result
+}
+
+func hasAnyKey(config map[string]interface{}, keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := config[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func getDockerComposeEnvironmentsFrom(manifestEnvironments map[string]*manifest.Environment) map[string]manifest.EnvironmentConfig {
 	dockerComposeEnvironments := make(map[string]manifest.EnvironmentConfig)
 
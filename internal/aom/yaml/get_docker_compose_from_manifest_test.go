@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -26,7 +26,7 @@ func TestConvertDockerComposeWithVersion(t *testing.T) {
 	}
 
 	// act
-	resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(&manifestData)
+	resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(&manifestData, nil, nil)
 
 	// assert
 	if err != nil {
@@ -70,7 +70,7 @@ func TestCreateDockerComposeFromManifest(t *testing.T) {
 	}
 
 	// act
-	resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(&manifestData)
+	resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(&manifestData, nil, nil)
 
 	// assert
 	if err != nil {
@@ -136,7 +136,7 @@ func TestMultipleComposeServiceFromManifest(t *testing.T) {
 	}
 
 	// act
-	resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(&manifestData)
+	resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(&manifestData, nil, nil)
 
 	// assert
 	if err != nil {
@@ -250,7 +250,7 @@ func TestCreateDockerComposeFromManifests(t *testing.T) {
 			}
 
 			// act
-			resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(manifestData)
+			resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(manifestData, nil, nil)
 
 			// assert
 			if err != nil {
@@ -280,6 +280,146 @@ func TestCreateDockerComposeFromManifests(t *testing.T) {
 	}
 }
 
+func TestCreateDockerComposeFromManifestInjectsServiceDefaults(t *testing.T) {
+	// arrange
+	services := make(map[string]*manifest.Service)
+	services["cloudadapter"] = &manifest.Service{
+		Type:   "docker-compose",
+		Config: map[string]interface{}{"image": "anyviz/cloudadapter"},
+	}
+	manifestData := manifest.Root{
+		Version:  "0.1",
+		Services: services,
+	}
+	defaults := &yaml.ServiceDefaults{MemLimit: "128m", PidsLimit: 256, LogMaxSize: "10m", LogMaxFile: 3}
+
+	// act
+	resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(&manifestData, defaults, nil)
+
+	// assert
+	if err != nil {
+		t.Fatalf("Failed creating docker compose from manifest file %v", err)
+	}
+
+	expectedDockerCompose := map[interface{}]interface{}{
+		"version": "2",
+		"services": map[string]interface{}{
+			"cloudadapter": map[string]interface{}{
+				"image":      "anyviz/cloudadapter",
+				"mem_limit":  "128m",
+				"pids_limit": 256,
+				"logging": map[string]interface{}{
+					"driver":  "json-file",
+					"options": map[string]interface{}{"max-size": "10m", "max-file": "3"},
+				},
+			},
+		},
+	}
+
+	resultDockerComposeMap, err := createMapFrom(resultDockerComposeString)
+	if err != nil {
+		t.Fatalf("Failed creating docker compose map %v", err)
+	}
+
+	if !reflect.DeepEqual(resultDockerComposeMap, expectedDockerCompose) {
+		t.Errorf("Expected \n%s but got \n%s", expectedDockerCompose, resultDockerComposeMap)
+	}
+
+	if _, ok := services["cloudadapter"].Config["mem_limit"]; ok {
+		t.Errorf("Expected the manifest service config to be unchanged")
+	}
+}
+
+func TestCreateDockerComposeFromManifestKeepsDeclaredOptions(t *testing.T) {
+	// arrange
+	services := make(map[string]*manifest.Service)
+	services["cloudadapter"] = &manifest.Service{
+		Type: "docker-compose",
+		Config: map[string]interface{}{
+			"image":     "anyviz/cloudadapter",
+			"memLimit":  "512m",
+			"pidsLimit": 1024,
+			"logging":   map[string]interface{}{"driver": "none"},
+		},
+	}
+	manifestData := manifest.Root{
+		Version:  "0.1",
+		Services: services,
+	}
+	defaults := &yaml.ServiceDefaults{MemLimit: "128m", PidsLimit: 256, LogMaxSize: "10m", LogMaxFile: 3}
+
+	// act
+	resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(&manifestData, defaults, nil)
+
+	// assert
+	if err != nil {
+		t.Fatalf("Failed creating docker compose from manifest file %v", err)
+	}
+
+	expectedDockerCompose := map[interface{}]interface{}{
+		"version": "2",
+		"services": map[string]interface{}{
+			"cloudadapter": map[string]interface{}{
+				"image":      "anyviz/cloudadapter",
+				"mem_limit":  "512m",
+				"pids_limit": 1024,
+				"logging":    map[string]interface{}{"driver": "none"},
+			},
+		},
+	}
+
+	resultDockerComposeMap, err := createMapFrom(resultDockerComposeString)
+	if err != nil {
+		t.Fatalf("Failed creating docker compose map %v", err)
+	}
+
+	if !reflect.DeepEqual(resultDockerComposeMap, expectedDockerCompose) {
+		t.Errorf("Expected \n%s but got \n%s", expectedDockerCompose, resultDockerComposeMap)
+	}
+}
+
+func TestCreateDockerComposeFromManifestEnforcesCpuPlacement(t *testing.T) {
+	// arrange
+	services := make(map[string]*manifest.Service)
+	services["cloudadapter"] = &manifest.Service{
+		Type:   "docker-compose",
+		Config: map[string]interface{}{"image": "anyviz/cloudadapter", "cpuset": "0-3", "cpuShares": 2048},
+	}
+	manifestData := manifest.Root{
+		Version:  "0.1",
+		Services: services,
+	}
+	placement := &yaml.CpuPlacement{Cpuset: "1-3", CpuShares: 512}
+
+	// act
+	resultDockerComposeString, err := yaml.GetDockerComposeFromManifest(&manifestData, nil, placement)
+
+	// assert
+	if err != nil {
+		t.Fatalf("Failed creating docker compose from manifest file %v", err)
+	}
+
+	expectedDockerCompose := map[interface{}]interface{}{
+		"version": "2",
+		"services": map[string]interface{}{
+			"cloudadapter": map[string]interface{}{
+				"image":      "anyviz/cloudadapter",
+				"cpuset":     "1-3",
+				"cpu_shares": 512,
+			},
+		},
+	}
+
+	resultDockerComposeMap, err := createMapFrom(resultDockerComposeString)
+	if err != nil {
+		t.Fatalf("Failed creating docker compose map %v", err)
+	}
+
+	if !reflect.DeepEqual(resultDockerComposeMap, expectedDockerCompose) {
+		t.Errorf("Expected \n%s but got \n%s", expectedDockerCompose, resultDockerComposeMap)
+	}
+}
+
 func createMapFrom(resultDockerComposeString string) (map[interface{}]interface{}, error) {
 	resultDockerComposeMap := make(map[interface{}]interface{})
 	err := yaml3.Unmarshal([]byte(resultDockerComposeString), resultDockerComposeMap)
@@ -0,0 +1,172 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"u-control/uc-aom/internal/aop/credentials"
+	"u-control/uc-aom/internal/aop/packager"
+	"u-control/uc-aom/internal/aop/registry"
+	catalogueindex "u-control/uc-aom/internal/pkg/catalogue-index"
+	"u-control/uc-aom/internal/pkg/config"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2"
+	orasregistry "oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+const cataloguePublishExampleFmtStr = `%s catalogue publish \
+    --target-credentials <target credentials> \
+    --signing-key <private key> \
+    --namespace <namespace> \
+    -v
+
+Publishes the signed index of all apps in the namespace as %s:%s within the namespace.
+The index is used by the app manager instead of the catalog API of the registry, which is disabled by many registries.
+
+The --target-credentials file has the following format, the repositoryname is not required:
+{
+    "username": "<username>",
+    "password": "<password>",
+    "serveraddress": "<registry URL>"
+}
+
+The --signing-key file is a PEM encoded ed25519 private key, which can be created with:
+    openssl genpkey -algorithm ed25519 -out catalogue-index.key
+    openssl pkey -in catalogue-index.key -pubout -out catalogue-index.pub
+
+The public key has to be installed on the device to accept the index.`
+
+type cataloguePublishOptions struct {
+	credentialsFilepath string
+	signingKeyFilepath  string
+	namespace           string
+	repositories        []string
+}
+
+func NewCatalogueCmd() *cobra.Command {
+	var catalogueCmd = &cobra.Command{
+		Use:   "catalogue",
+		Short: "Manage the app catalogue of a registry.",
+	}
+	catalogueCmd.AddCommand(newCataloguePublishCmd())
+	return catalogueCmd
+}
+
+func newCataloguePublishCmd() *cobra.Command {
+	publishOptions := cataloguePublishOptions{}
+	var publishCmd = &cobra.Command{
+		Use:          "publish",
+		Short:        "Publish the signed index of all apps in a registry namespace.",
+		Example:      fmt.Sprintf(cataloguePublishExampleFmtStr, filepath.Base(os.Args[0]), config.UcCatalogueIndexRepository, config.UcCatalogueIndexTag),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			setLoggingVerbosity()
+			return executeCataloguePublishCommand(&publishOptions)
+		},
+	}
+
+	publishCmd.Flags().StringVarP(&publishOptions.credentialsFilepath, "target-credentials", "t", "", "path to a file providing credentials for the registry")
+	publishCmd.Flags().StringVarP(&publishOptions.signingKeyFilepath, "signing-key", "k", "", "path to the PEM encoded ed25519 private key used to sign the index")
+	publishCmd.Flags().StringVarP(&publishOptions.namespace, "namespace", "n", "", "namespace of the apps, e.g. a code name. Default is the root of the registry")
+	publishCmd.Flags().StringSliceVarP(&publishOptions.repositories, "repository", "r", nil, "repositories to scan instead of all repositories listed by the registry, required if the registry disables its catalog API")
+
+	publishCmd.MarkFlagRequired("target-credentials")
+	publishCmd.MarkFlagRequired("signing-key")
+
+	return publishCmd
+}
+
+func executeCataloguePublishCommand(publishOptions *cataloguePublishOptions) error {
+	targetCredentials, err := credentials.ParseAndValidate(os.ReadFile, publishOptions.credentialsFilepath)
+	if err != nil {
+		log.Errorf("Invalid credentials file '%s': %v", publishOptions.credentialsFilepath, err)
+		return err
+	}
+	credentials.SetRegistryServerAddress(targetCredentials)
+
+	signingKey, err := os.ReadFile(publishOptions.signingKeyFilepath)
+	if err != nil {
+		log.Errorf("Could not read signing key '%s': %v", publishOptions.signingKeyFilepath, err)
+		return err
+	}
+	privateKey, err := catalogueindex.ParsePrivateKey(signingKey)
+	if err != nil {
+		log.Errorf("Invalid signing key '%s': %v", publishOptions.signingKeyFilepath, err)
+		return err
+	}
+
+	ctx := context.Background()
+	target, err := registry.InitializeRegistry(ctx, targetCredentials, transportOptions)
+	if err != nil {
+		log.Errorf("Could not initialize app host registry: %v", err)
+		return err
+	}
+
+	repositories := publishOptions.repositories
+	if len(repositories) == 0 {
+		log.Info("Listing repositories.")
+		repositories, err = orasregistry.Repositories(ctx, target)
+		if err != nil {
+			log.Errorf("Could not list repositories, pass them with --repository if the catalog API is disabled: %v", err)
+			return err
+		}
+	}
+
+	log.Info("Creating catalogue index.")
+	creator := packager.NewCatalogueIndexCreator(openRemoteAddOnRepository(target), time.Now)
+	index, err := creator.CreateIndex(ctx, repositories, publishOptions.namespace)
+	if err != nil {
+		log.Errorf("Could not create catalogue index: %v", err)
+		return err
+	}
+
+	artifact, err := catalogueindex.NewArtifact(ctx, index, privateKey)
+	if err != nil {
+		log.Errorf("Could not create catalogue index artifact: %v", err)
+		return err
+	}
+
+	indexRepositoryName := catalogueindex.RepositoryOf(publishOptions.namespace)
+	indexRepository, err := target.Repository(ctx, indexRepositoryName)
+	if err != nil {
+		log.Errorf("Could not initialize catalogue index repository: %v", err)
+		return err
+	}
+
+	log.Infof("Pushing catalogue index of %d apps to '%s'.", len(index.AddOns), indexRepositoryName)
+	_, err = oras.Copy(ctx, artifact, config.UcCatalogueIndexTag, indexRepository, "", oras.DefaultCopyOptions)
+	if err != nil {
+		log.Errorf("Could not push catalogue index: %v", err)
+		return err
+	}
+
+	log.Infoln("Succeeded!")
+	return nil
+}
+
+type remoteAddOnRepository struct {
+	orasregistry.Repository
+}
+
+func (r *remoteAddOnRepository) Tags(ctx context.Context) ([]string, error) {
+	return orasregistry.Tags(ctx, r.Repository)
+}
+
+func openRemoteAddOnRepository(target *remote.Registry) packager.OpenRepositoryFunc {
+	return func(ctx context.Context, name string) (packager.AddOnRepository, error) {
+		repository, err := target.Repository(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return &remoteAddOnRepository{Repository: repository}, nil
+	}
+}
@@ -85,7 +85,7 @@ func getAddOnTarget(ctx context.Context, credentialsFilepath string, version str
 
 	credentials.SetRegistryServerAddress(addOnHostCredentials)
 	log.Info("Initializing registries.")
-	addOnHostRegistry, err := registry.InitializeRegistry(ctx, addOnHostCredentials)
+	addOnHostRegistry, err := registry.InitializeRegistry(ctx, addOnHostCredentials, transportOptions)
 	if err != nil {
 		return nil, fmt.Errorf("Could not initialize app host registry: %v", err)
 	}
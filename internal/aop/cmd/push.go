@@ -50,8 +50,11 @@ The --target-credentials file will be provided and has the following format:
 // Use the logo regex from the manifest schema to validate the logo app file
 var logoRegexp = regexp.MustCompile("^.+\\.(jpg|png|jpeg|svg)$")
 
+// Screenshots share the image formats of the logo, the readme and the changelog are markdown files
+var markdownRegexp = regexp.MustCompile("^.+\\.md$")
+
 func filterAppFilesFunc(fileName string) bool {
-	return fileName == config.UcImageManifestFilename || logoRegexp.MatchString(fileName)
+	return fileName == config.UcImageManifestFilename || logoRegexp.MatchString(fileName) || markdownRegexp.MatchString(fileName)
 }
 
 type pushOptions struct {
@@ -74,7 +77,7 @@ func NewPushCommand() *cobra.Command {
 	}
 	pushCmd.Flags().StringVarP(&pushOptions.sourceCredentialsFilepath, "source-credentials", "s", "", "path to a file providing credentials, for the registry hosting docker images, referenced in the app's manifest.json file")
 	pushCmd.Flags().StringVarP(&pushOptions.targetCredentialsFilepath, "target-credentials", "t", "", "path to a file providing credentials for the app's host repository. The target registry is always the Weidmüller development registry")
-	pushCmd.Flags().StringVarP(&pushOptions.contentDirpath, "manifest", "m", "", "a directory path which contains logo and manifest.json files")
+	pushCmd.Flags().StringVarP(&pushOptions.contentDirpath, "manifest", "m", "", "a directory path which contains the manifest.json file and the assets it references, e.g. logo, screenshots, readme and changelog")
 
 	pushCmd.MarkFlagRequired("source-credentials")
 	pushCmd.MarkFlagRequired("target-credentials")
@@ -99,13 +102,13 @@ func executePushCommand(pushOptions *pushOptions) error {
 	credentials.SetRegistryServerAddress(targetCredentials)
 	ctx := context.Background()
 
-	source, err := registry.InitializeRegistry(ctx, sourceCredentials)
+	source, err := registry.InitializeRegistry(ctx, sourceCredentials, transportOptions)
 	if err != nil {
 		log.Errorf("Could not initialize docker image source registry: %v", err)
 		return err
 	}
 
-	target, err := registry.InitializeRegistry(ctx, targetCredentials)
+	target, err := registry.InitializeRegistry(ctx, targetCredentials, transportOptions)
 	if err != nil {
 		log.Errorf("Could not initialize app host registry: %v", err)
 		return err
@@ -202,6 +202,14 @@ func TestFilterAppFilesFunc(t *testing.T) {
 			"icon.jpg",
 			true,
 		},
+		{
+			"README.md",
+			true,
+		},
+		{
+			"CHANGELOG.md",
+			true,
+		},
 		{
 			"manifest.json.license",
 			false,
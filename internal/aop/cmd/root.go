@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"u-control/uc-aom/internal/aop/company"
+	pkgRegistry "u-control/uc-aom/internal/pkg/registry"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -17,6 +18,7 @@ import (
 
 var verbosity *int
 var displayVersionInfo *bool
+var transportOptions pkgRegistry.TransportOptions
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -42,9 +44,13 @@ func init() {
 	rootCmd.AddCommand(NewPushCommand())
 	rootCmd.AddCommand(NewPullCmd())
 	rootCmd.AddCommand(NewExportCmd())
+	rootCmd.AddCommand(NewCatalogueCmd())
 
 	displayVersionInfo = rootCmd.Flags().Bool("version", false, "display version information and exit")
 	verbosity = rootCmd.PersistentFlags().CountP("verbose", "v", "explain what is being done, pass multiple times to increase verbosity")
+	rootCmd.PersistentFlags().StringVar(&transportOptions.Proxy, "proxy", "", "proxy of the registries, HTTP_PROXY and HTTPS_PROXY are used if not set")
+	rootCmd.PersistentFlags().StringVar(&transportOptions.NoProxy, "no-proxy", "", "comma separated hosts which are not reached through the proxy")
+	rootCmd.PersistentFlags().StringVar(&transportOptions.CABundlePath, "ca-bundle", "", "PEM `file` of additionally trusted certificate authorities")
 }
 
 func handleDeprecatedSyntax() {
@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -45,6 +45,12 @@ type Credentials struct {
 	// URL of the an OCI capable registry.
 	ServerAddress string `json:"serveraddress,omitempty"`
 
+	// Optional proxy of the registry, overrides the --proxy flag if provided.
+	Proxy string `json:"proxy,omitempty"`
+
+	// Optional hosts which are not reached through Proxy, with the semantics of NO_PROXY.
+	NoProxy string `json:"noProxy,omitempty"`
+
 	readFileFunc fileio.ReadFileFunc
 }
 
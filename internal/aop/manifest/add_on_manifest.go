@@ -95,11 +95,44 @@ func updateManifestFileContent(manifestPath string, manifestContent []byte) erro
 	return nil
 }
 
+// Returns the file names of the assets the manifest references, which are packaged along with the manifest.
+func (r *AddOnManifest) AssetFileNames() []string {
+	fileNames := []string{r.Logo}
+	fileNames = append(fileNames, r.Screenshots...)
+	for _, fileName := range []string{r.Readme, r.Changelog} {
+		if fileName != "" {
+			fileNames = append(fileNames, fileName)
+		}
+	}
+	return fileNames
+}
+
 // Performs manifests assets validation returning an error should it fail.
 func (r *AddOnManifest) validateAssets() error {
-	logoPath := filepath.Join(r.manifestDirPath, r.Logo)
-	if _, err := r.fileExistsFunc(logoPath); errors.Is(err, os.ErrNotExist) {
-		return &AddOnManifestValidationError{message: fmt.Sprintf("Logo file '%s' does not exist", logoPath)}
+	assets := []struct {
+		kind      string
+		fileNames []string
+	}{
+		{"Logo", []string{r.Logo}},
+		{"Screenshot", r.Screenshots},
+		{"Readme", []string{r.Readme}},
+		{"Changelog", []string{r.Changelog}},
+	}
+
+	for _, asset := range assets {
+		for _, fileName := range asset.fileNames {
+			if fileName == "" {
+				continue
+			}
+			// The assets are packaged flat, next to the manifest
+			if filepath.Base(fileName) != fileName {
+				return &AddOnManifestValidationError{message: fmt.Sprintf("%s file '%s' is not located next to the manifest", asset.kind, fileName)}
+			}
+			assetPath := filepath.Join(r.manifestDirPath, fileName)
+			if _, err := r.fileExistsFunc(assetPath); errors.Is(err, os.ErrNotExist) {
+				return &AddOnManifestValidationError{message: fmt.Sprintf("%s file '%s' does not exist", asset.kind, assetPath)}
+			}
+		}
 	}
 
 	return nil
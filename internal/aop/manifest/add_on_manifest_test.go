@@ -137,6 +137,60 @@ func TestAddOnManifestLogoDoesNotExistFail(t *testing.T) {
 	}
 }
 
+func TestAddOnManifestAssetsFail(t *testing.T) {
+	// Arrange
+	baseDirectory := "manifest-directory-base"
+	testCases := map[string]struct {
+		root          model.Root
+		expectedError string
+	}{
+		"missing readme": {
+			model.Root{Logo: "logo.png", Readme: "README.md"},
+			"Readme file 'manifest-directory-base/README.md' does not exist",
+		},
+		"missing screenshot": {
+			model.Root{Logo: "logo.png", Screenshots: []string{"dashboard.png", "settings.png"}},
+			"Screenshot file 'manifest-directory-base/settings.png' does not exist",
+		},
+		"changelog outside of the manifest directory": {
+			model.Root{Logo: "logo.png", Changelog: "../CHANGELOG.md"},
+			"Changelog file '../CHANGELOG.md' is not located next to the manifest",
+		},
+	}
+	existingFiles := map[string]bool{
+		path.Join(baseDirectory, "logo.png"):        true,
+		path.Join(baseDirectory, "dashboard.png"):   true,
+		path.Join(baseDirectory, "../CHANGELOG.md"): true,
+	}
+	fileExistsFunc := func(name string) (fs.FileInfo, error) {
+		if existingFiles[name] {
+			return nil, nil
+		}
+		return nil, fs.ErrNotExist
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			root := testCase.root
+			root.ManifestVersion = "0.2"
+			root.Version = "0.1-1"
+			root.Services = map[string]*model.Service{"ucAddonTestService": {Type: "docker-compose", Config: map[string]interface{}{"image": "test/image:0.42"}}}
+			root.Platform = []string{"ucm", "ucg"}
+			root.Vendor = &model.Vendor{Name: "abc", Url: "https://www.abc.de", Email: "email@abc.de", Street: "street", Zip: "12345", City: "City", Country: "Country"}
+			mockManifest := &mockManifestReader{}
+			mockManifest.On("ReadManifestFrom", mock.AnythingOfType("string")).Return(&root, nil)
+
+			// Act
+			_, err := manifest.ParseAndValidate(mockManifest, fileExistsFunc, baseDirectory)
+
+			// Assert
+			if err == nil || err.Error() != testCase.expectedError {
+				t.Fatalf("Expected error '%s' but got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
+
 func TestAddOnMigrateManifest(t *testing.T) {
 	// Arrange
 	testDir := t.TempDir()
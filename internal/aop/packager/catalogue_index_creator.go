@@ -0,0 +1,147 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package packager
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	catalogueindex "u-control/uc-aom/internal/pkg/catalogue-index"
+	"u-control/uc-aom/internal/pkg/config"
+	"u-control/uc-aom/internal/pkg/manifest"
+	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/content"
+)
+
+// AddOnRepository provides the tags and the content of an add-on repository.
+type AddOnRepository interface {
+	content.Fetcher
+	content.Resolver
+	Tags(ctx context.Context) ([]string, error)
+}
+
+// Opens the repository with the given name.
+type OpenRepositoryFunc func(ctx context.Context, name string) (AddOnRepository, error)
+
+type catalogueIndexCreator struct {
+	openRepository OpenRepositoryFunc
+	now            func() time.Time
+}
+
+// Create a new instance of the catalogue index creator
+// openRepository will be used to read the tags and packages of the add-on repositories.
+func NewCatalogueIndexCreator(openRepository OpenRepositoryFunc, now func() time.Time) *catalogueIndexCreator {
+	return &catalogueIndexCreator{openRepository: openRepository, now: now}
+}
+
+// Creates the index of all add-on packages in the repositories of the namespace.
+// Tags which do not refer to an add-on package are skipped.
+func (r *catalogueIndexCreator) CreateIndex(ctx context.Context, repositories []string, namespace string) (*catalogueindex.Index, error) {
+	index := &catalogueindex.Index{
+		SchemaVersion: catalogueindex.SchemaVersion,
+		Created:       r.now().UTC(),
+		AddOns:        make([]*catalogueindex.AddOn, 0),
+	}
+
+	prefix := strings.Trim(namespace, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	for _, name := range repositories {
+		if !strings.HasPrefix(name, prefix) || catalogueindex.IsIndexRepository(name) {
+			continue
+		}
+
+		addOn, err := r.createAddOn(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(addOn.Versions) == 0 {
+			log.Infof("Skipping repository '%s', it does not contain add-on packages.", name)
+			continue
+		}
+		index.AddOns = append(index.AddOns, addOn)
+	}
+	return index, nil
+}
+
+func (r *catalogueIndexCreator) createAddOn(ctx context.Context, name string) (*catalogueindex.AddOn, error) {
+	repository, err := r.openRepository(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := repository.Tags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(manifest.ByAddOnVersion(tags))
+
+	addOn := &catalogueindex.AddOn{Repository: name, Versions: make([]*catalogueindex.Version, 0, len(tags))}
+	for _, tag := range tags {
+		version, err := r.createVersion(ctx, repository, tag)
+		if err != nil {
+			return nil, err
+		}
+		if version == nil {
+			log.Infof("Skipping '%s:%s', it is not an add-on package.", name, tag)
+			continue
+		}
+		addOn.Versions = append(addOn.Versions, version)
+	}
+	return addOn, nil
+}
+
+// Returns the version of the tag, nil if the tag does not refer to an add-on package.
+func (r *catalogueIndexCreator) createVersion(ctx context.Context, repository AddOnRepository, tag string) (*catalogueindex.Version, error) {
+	desc, err := repository.Resolve(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	if desc.MediaType != ocispec.MediaTypeImageIndex {
+		return nil, nil
+	}
+
+	imageIndex, err := oraswrapper.FetchImageIndex(ctx, repository, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	platforms := make([]ocispec.Platform, 0, len(imageIndex.Manifests))
+	for _, manifestDesc := range imageIndex.Manifests {
+		if manifestDesc.Platform == nil {
+			continue
+		}
+
+		imageManifest, err := oraswrapper.FetchImageManifest(ctx, repository, manifestDesc)
+		if err != nil {
+			return nil, err
+		}
+		if !containsUcImageLayer(imageManifest) {
+			return nil, nil
+		}
+		platforms = append(platforms, *manifestDesc.Platform)
+	}
+	if len(platforms) == 0 {
+		return nil, nil
+	}
+
+	summary, _ := manifest.ParseAddOnSummaryAnnotations(imageIndex.Annotations)
+	return &catalogueindex.Version{Tag: tag, Digest: desc.Digest.String(), Platforms: platforms, Summary: summary}, nil
+}
+
+func containsUcImageLayer(imageManifest *ocispec.Manifest) bool {
+	for _, layer := range imageManifest.Layers {
+		if layer.MediaType == config.UcImageLayerMediaType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package packager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"u-control/uc-aom/internal/aop/packager"
+	catalogueindex "u-control/uc-aom/internal/pkg/catalogue-index"
+	"u-control/uc-aom/internal/pkg/config"
+	model "u-control/uc-aom/internal/pkg/manifest"
+	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+type memoryAddOnRepository struct {
+	*memory.Store
+	tags []string
+}
+
+func (r *memoryAddOnRepository) Tags(ctx context.Context) ([]string, error) {
+	return r.tags, nil
+}
+
+func (r *memoryAddOnRepository) pushAddOn(t *testing.T, version string, annotations ...string) {
+	builder := oraswrapper.NewGraphBuilder(version)
+	builder.AppendUcManifest([]byte(version), ocispec.AnnotationTitle, config.UcImageLayerAnnotationTitle)
+	builder.AppendDockerImage([]byte("image"), &ocispec.Platform{OS: "linux", Architecture: "arm"})
+	builder.WithIndexAnnotations(annotations...)
+	r.push(t, builder, version)
+}
+
+func (r *memoryAddOnRepository) push(t *testing.T, builder *oraswrapper.GraphBuilder, tag string) {
+	ctx := context.Background()
+	built, err := builder.BuildAndTag(ctx)
+	assert.NoError(t, err)
+	_, err = oras.Copy(ctx, built, tag, r.Store, tag, oras.DefaultCopyOptions)
+	assert.NoError(t, err)
+	r.tags = append(r.tags, tag)
+}
+
+func TestCreateCatalogueIndex(t *testing.T) {
+	// Arrange
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	addOn := &memoryAddOnRepository{Store: memory.New()}
+	summaryAnnotations, _ := model.CreateAddOnSummaryAnnotations(&model.AddOnSummary{Title: "Test", Version: "1.1.0-1", ManifestVersion: "0.2"})
+	addOn.pushAddOn(t, "1.1.0-1", summaryAnnotations...)
+	addOn.pushAddOn(t, "1.0.0-1")
+	otherNamespace := &memoryAddOnRepository{Store: memory.New()}
+	otherNamespace.pushAddOn(t, "1.0.0-1")
+	repositories := map[string]*memoryAddOnRepository{"company/test-addon": addOn, "other/test-addon": otherNamespace}
+
+	openRepository := func(ctx context.Context, name string) (packager.AddOnRepository, error) {
+		return repositories[name], nil
+	}
+	uut := packager.NewCatalogueIndexCreator(openRepository, func() time.Time { return now })
+
+	// Act
+	index, err := uut.CreateIndex(context.Background(), []string{"company/test-addon", "company/" + config.UcCatalogueIndexRepository, "other/test-addon"}, "company")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, catalogueindex.SchemaVersion, index.SchemaVersion)
+	assert.Equal(t, now, index.Created)
+	assert.Len(t, index.AddOns, 1)
+	assert.Equal(t, "company/test-addon", index.AddOns[0].Repository)
+
+	versions := index.AddOns[0].Versions
+	assert.Len(t, versions, 2)
+	assert.Equal(t, "1.0.0-1", versions[0].Tag)
+	assert.Nil(t, versions[0].Summary)
+	assert.Equal(t, "1.1.0-1", versions[1].Tag)
+	assert.Equal(t, "Test", versions[1].Summary.Title)
+	assert.True(t, versions[1].Supports("linux", "arm"))
+
+	desc, _ := addOn.Resolve(context.Background(), "1.1.0-1")
+	assert.Equal(t, desc.Digest.String(), versions[1].Digest)
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"u-control/uc-aom/internal/aop/company"
@@ -20,6 +21,7 @@ import (
 	sharedManifest "u-control/uc-aom/internal/pkg/manifest"
 	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	log "github.com/sirupsen/logrus"
 
@@ -34,7 +36,7 @@ var (
 type PackageCreator struct {
 	exportDockerImageFunc registry.ExportDockerImageFunc
 	gzipTarballFunc       fileio.GzipTarballFunc
-	logoBaseName          string
+	assetBaseNames        map[string]bool
 }
 
 // Create a new instance of the packager
@@ -50,7 +52,10 @@ func (r *PackageCreator) CreateOciArtifact(ctx context.Context, manifest *manife
 	builder := oraswrapper.NewGraphBuilder(manifest.Version)
 	builder.WithAuthor(company.ShortAuthorInfo())
 
-	r.logoBaseName = manifest.Logo
+	r.assetBaseNames = make(map[string]bool)
+	for _, fileName := range manifest.AssetFileNames() {
+		r.assetBaseNames[fileName] = true
+	}
 	ucImageLayerAnnotations := sharedManifest.CreateUcManifestAnnotationsV1_0(manifest.Version, manifest.ManifestVersion)
 	err := r.appendManifestAndLogo(builder, manifest.ManifestBaseDirectory(), ucImageLayerAnnotations)
 	if err != nil {
@@ -63,6 +68,12 @@ func (r *PackageCreator) CreateOciArtifact(ctx context.Context, manifest *manife
 		return nil, err
 	}
 
+	summaryAnnotations, err := createSummaryAnnotations(manifest)
+	if err != nil {
+		return nil, err
+	}
+	builder.WithIndexAnnotations(summaryAnnotations...)
+
 	if orasTarget, err := builder.BuildAndTag(ctx); err == nil {
 		return registry.NewOciTargetDecorator(orasTarget, manifest.Version), nil
 	}
@@ -72,12 +83,7 @@ func (r *PackageCreator) CreateOciArtifact(ctx context.Context, manifest *manife
 
 func (r *PackageCreator) predicate(path string) bool {
 	base := filepath.Base(path)
-	switch base {
-	case config.UcImageManifestFilename, r.logoBaseName:
-		return true
-	default:
-		return false
-	}
+	return base == config.UcImageManifestFilename || r.assetBaseNames[base]
 }
 
 func (r *PackageCreator) appendManifestAndLogo(builder *oraswrapper.GraphBuilder, contentDirPath string, annotations []string) error {
@@ -107,13 +113,51 @@ func (r *PackageCreator) appendDockerImages(builder *oraswrapper.GraphBuilder, c
 				return err
 			}
 
-			builder.AppendDockerImage(ociTarballBytes, platform, ocispec.AnnotationTitle, ref)
+			annotations, err := createDockerImageAnnotations(ref, ociTarballBytes)
+			if err != nil {
+				return err
+			}
+
+			builder.AppendDockerImage(ociTarballBytes, platform, annotations...)
 		}
 	}
 
 	return nil
 }
 
+// Returns the annotations of the docker image layer.
+// Besides the title, the uncompressed layers are annotated to allow an accurate disk space planning on the device.
+func createDockerImageAnnotations(ref string, ociTarballBytes []byte) ([]string, error) {
+	annotations := []string{ocispec.AnnotationTitle, ref}
+
+	layers, err := oraswrapper.ReadDockerImageLayers(ociTarballBytes)
+	if err != nil {
+		log.Warnf("Unable to read the layers of docker image '%s': %v", ref, err)
+		return annotations, nil
+	}
+
+	layersAnnotation, err := oraswrapper.CreateDockerImageLayersAnnotation(layers)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(annotations, config.UcDockerImageLayersAnnotation, layersAnnotation), nil
+}
+
+// Returns the annotations of the image index, which allow to list the add-on without pulling the uc image layer.
+func createSummaryAnnotations(manifest *manifest.AddOnManifest) ([]string, error) {
+	logoDigest := ""
+	logo, err := os.ReadFile(filepath.Join(manifest.ManifestBaseDirectory(), manifest.Logo))
+	if err != nil {
+		log.Warnf("Unable to read the logo '%s', the summary omits its digest: %v", manifest.Logo, err)
+	} else {
+		logoDigest = digest.FromBytes(logo).String()
+	}
+
+	summary := sharedManifest.NewAddOnSummary(manifest.Root, logoDigest)
+	return sharedManifest.CreateAddOnSummaryAnnotations(summary)
+}
+
 func getSupportedPlatforms(ctx context.Context, reg orasRegistry.Registry, ref string) ([]*ocispec.Platform, error) {
 	repository, tag, err := registry.ToRepositoryAndTag(ref)
 	if err != nil {
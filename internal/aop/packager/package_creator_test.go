@@ -9,6 +9,7 @@ import (
 	"context"
 	"io"
 	"io/fs"
+	"reflect"
 	"strings"
 	"testing"
 	"u-control/uc-aom/internal/aop/manifest"
@@ -17,6 +18,7 @@ import (
 	"u-control/uc-aom/internal/pkg/config"
 
 	model "u-control/uc-aom/internal/pkg/manifest"
+	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/mock"
@@ -194,6 +196,23 @@ func TestAddOnOciImageCreationPass(t *testing.T) {
 			if err != nil {
 				t.Fatal("[validator.ValidateLayerContent()] Unexpected error = ", err)
 			}
+
+			indexDesc, err := addOnTarget.Resolve(ctx, tag)
+			if err != nil {
+				t.Fatal("[Resolve] Unexpected error = ", err)
+			}
+			imageIndex, err := oraswrapper.FetchImageIndex(ctx, addOnTarget, indexDesc)
+			if err != nil {
+				t.Fatal("[FetchImageIndex] Unexpected error = ", err)
+			}
+			summary, ok := model.ParseAddOnSummaryAnnotations(imageIndex.Annotations)
+			if !ok {
+				t.Fatal("Expected the image index to be annotated with the add-on summary")
+			}
+			expectedSummary := model.NewAddOnSummary(root, "")
+			if !reflect.DeepEqual(summary, expectedSummary) {
+				t.Errorf("Expected summary %+v but got %+v", expectedSummary, summary)
+			}
 		})
 
 	}
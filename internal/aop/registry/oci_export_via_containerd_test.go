@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -17,6 +17,7 @@ import (
 	"u-control/uc-aom/internal/aop/credentials"
 	"u-control/uc-aom/internal/aop/registry"
 	"u-control/uc-aom/internal/pkg/config"
+	pkgRegistry "u-control/uc-aom/internal/pkg/registry"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -111,7 +112,7 @@ func (testCase *testCase4ContainerdExport) testContainerdExportP(t *testing.T) {
 	ctx := context.Background()
 	credentials := &credentials.Credentials{Username: testCase.username, Password: testCase.password, ServerAddress: testCase.registryAddress}
 
-	orasRegistry, err := registry.InitializeRegistry(ctx, credentials)
+	orasRegistry, err := registry.InitializeRegistry(ctx, credentials, pkgRegistry.TransportOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -235,7 +236,7 @@ func (testCase *testCase4ContainerdExport) testExportUsingContainerdUnknownPlatf
 	ctx := context.Background()
 	credentials := &credentials.Credentials{Username: testCase.username, Password: testCase.password, ServerAddress: testCase.registryAddress}
 
-	orasRegistry, err := registry.InitializeRegistry(ctx, credentials)
+	orasRegistry, err := registry.InitializeRegistry(ctx, credentials, pkgRegistry.TransportOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -6,7 +6,9 @@ package registry
 
 import (
 	"context"
+	"fmt"
 	"u-control/uc-aom/internal/aop/credentials"
+	pkgRegistry "u-control/uc-aom/internal/pkg/registry"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	log "github.com/sirupsen/logrus"
@@ -15,19 +17,31 @@ import (
 	"oras.land/oras-go/v2/registry/remote/auth"
 )
 
-// Initialize a connection to the OCI registry using the given credentials.
-func InitializeRegistry(ctx context.Context, credentials *credentials.Credentials) (*remote.Registry, error) {
+// Initialize a connection to the OCI registry using the given credentials and transport options.
+// The proxy of the credentials overrides the proxy of the options.
+// A failed connection is reported with the failed stage, e.g. DNS, TCP, TLS or AUTH.
+func InitializeRegistry(ctx context.Context, credentials *credentials.Credentials, options pkgRegistry.TransportOptions) (*remote.Registry, error) {
 	reg, err := remote.NewRegistry(credentials.ServerAddress)
 	if err != nil {
 		return nil, err
 	}
 
+	if credentials.Proxy != "" {
+		options.Proxy = credentials.Proxy
+		options.NoProxy = credentials.NoProxy
+	}
+	client, err := pkgRegistry.NewHTTPClient(options)
+	if err != nil {
+		return nil, err
+	}
+
 	creds := auth.Credential{
 		Username: credentials.Username,
 		Password: credentials.Password,
 	}
 
 	reg.RepositoryOptions.Client = &auth.Client{
+		Client: client,
 		Credential: func(c context.Context, s string) (auth.Credential, error) {
 			return creds, nil
 		},
@@ -35,7 +49,10 @@ func InitializeRegistry(ctx context.Context, credentials *credentials.Credential
 
 	reg.PlainHTTP = credentials.IsInsecureServer()
 
-	return reg, reg.Ping(ctx)
+	if err := reg.Ping(ctx); err != nil {
+		return reg, fmt.Errorf("%s failure: %w", pkgRegistry.DiagnoseConnectionFailure(err), err)
+	}
+	return reg, nil
 }
 
 // Given the source, which is a rooted directed acyclic graph (DAG)
@@ -0,0 +1,126 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogueindex
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"time"
+	"u-control/uc-aom/internal/pkg/config"
+	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// Source provides the artifact of the catalogue index, e.g. the repository of the index.
+type Source interface {
+	content.Fetcher
+	content.Resolver
+}
+
+// Creates the signed artifact of the index, tagged with config.UcCatalogueIndexTag.
+// Image Manifest -> [Config, Layer]
+// Layer - the index as JSON, annotated with its signature.
+func NewArtifact(ctx context.Context, index *Index, privateKey ed25519.PrivateKey) (oras.Target, error) {
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	configJSON := []byte("{}")
+
+	layerDesc := newDescriptor(config.UcCatalogueIndexLayerMediaType, indexJSON)
+	layerDesc.Annotations = map[string]string{
+		ocispec.AnnotationTitle:                    "index.json",
+		config.UcCatalogueIndexAnnotationSignature: sign(indexJSON, privateKey),
+	}
+	configDesc := newDescriptor(config.UcCatalogueIndexConfigMediaType, configJSON)
+
+	imageManifest := ocispec.Manifest{
+		// Historical value, does not pertain to OCI or docker version
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{layerDesc},
+		Annotations: map[string]string{
+			ocispec.AnnotationCreated: index.Created.UTC().Format(time.RFC3339),
+		},
+	}
+	manifestJSON, err := json.Marshal(imageManifest)
+	if err != nil {
+		return nil, err
+	}
+	manifestDesc := newDescriptor(ocispec.MediaTypeImageManifest, manifestJSON)
+
+	target := memory.New()
+	err = oraswrapper.PushAll(ctx, target,
+		&oraswrapper.DescriptorBlobTuple{Desc: &layerDesc, Blob: indexJSON},
+		&oraswrapper.DescriptorBlobTuple{Desc: &configDesc, Blob: configJSON},
+		&oraswrapper.DescriptorBlobTuple{Desc: &manifestDesc, Blob: manifestJSON},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := target.Tag(ctx, manifestDesc, config.UcCatalogueIndexTag); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// Returns the descriptor of the index artifact, the digest changes whenever a new index is published.
+func Resolve(ctx context.Context, source Source) (ocispec.Descriptor, error) {
+	return source.Resolve(ctx, config.UcCatalogueIndexTag)
+}
+
+// Fetches the index of the resolved descriptor from the source and verifies its signature with the public key.
+// Returns ErrInvalidSignature if the index is not signed by the owner of the public key.
+func Fetch(ctx context.Context, source Source, manifestDesc ocispec.Descriptor, publicKey ed25519.PublicKey) (*Index, error) {
+	if manifestDesc.MediaType != ocispec.MediaTypeImageManifest {
+		return nil, fmt.Errorf("Unexpected mediatype of the catalogue index: '%s'", manifestDesc.MediaType)
+	}
+
+	imageManifest, err := oraswrapper.FetchImageManifest(ctx, source, manifestDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layer := range imageManifest.Layers {
+		if layer.MediaType != config.UcCatalogueIndexLayerMediaType {
+			continue
+		}
+
+		indexJSON, err := content.FetchAll(ctx, source, layer)
+		if err != nil {
+			return nil, err
+		}
+		if err := verify(indexJSON, layer.Annotations[config.UcCatalogueIndexAnnotationSignature], publicKey); err != nil {
+			return nil, err
+		}
+
+		var index Index
+		if err := json.Unmarshal(indexJSON, &index); err != nil {
+			return nil, err
+		}
+		if index.SchemaVersion != SchemaVersion {
+			return nil, fmt.Errorf("Unsupported catalogue index schema version %d", index.SchemaVersion)
+		}
+		return &index, nil
+	}
+	return nil, fmt.Errorf("The catalogue index does not contain a layer of mediatype '%s'", config.UcCatalogueIndexLayerMediaType)
+}
+
+func newDescriptor(mediaType string, blob []byte) ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+}
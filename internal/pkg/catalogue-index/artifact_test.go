@@ -0,0 +1,113 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogueindex_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+	catalogueindex "u-control/uc-aom/internal/pkg/catalogue-index"
+	"u-control/uc-aom/internal/pkg/config"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2"
+)
+
+func newTestIndex() *catalogueindex.Index {
+	return &catalogueindex.Index{
+		SchemaVersion: catalogueindex.SchemaVersion,
+		Created:       time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC),
+		AddOns: []*catalogueindex.AddOn{
+			{
+				Repository: "test-addon",
+				Versions: []*catalogueindex.Version{
+					{
+						Tag:       "1.0.0-1",
+						Digest:    "sha256:1234",
+						Platforms: []ocispec.Platform{{OS: "linux", Architecture: "arm"}},
+						Summary:   &manifest.AddOnSummary{Title: "Test", Version: "1.0.0-1", ManifestVersion: "0.2"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestArtifact(t *testing.T, index *catalogueindex.Index, privateKey ed25519.PrivateKey) oras.Target {
+	artifact, err := catalogueindex.NewArtifact(context.Background(), index, privateKey)
+	assert.NoError(t, err)
+	return artifact
+}
+
+func TestFetchSignedIndex(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	index := newTestIndex()
+	artifact := newTestArtifact(t, index, privateKey)
+
+	// Act
+	desc, resolveErr := catalogueindex.Resolve(ctx, artifact)
+	fetched, fetchErr := catalogueindex.Fetch(ctx, artifact, desc, publicKey)
+
+	// Assert
+	assert.NoError(t, resolveErr)
+	assert.NoError(t, fetchErr)
+	assert.Equal(t, index, fetched)
+	assert.NotNil(t, fetched.Find("test-addon").Find("1.0.0-1"))
+	assert.True(t, fetched.Find("test-addon").Find("1.0.0-1").Supports("linux", "arm"))
+	assert.False(t, fetched.Find("test-addon").Find("1.0.0-1").Supports("linux", "amd64"))
+	assert.Nil(t, fetched.Find("other"))
+}
+
+func TestFetchIndexSignedByOtherKey(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	_, otherPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	artifact := newTestArtifact(t, newTestIndex(), otherPrivateKey)
+	desc, _ := catalogueindex.Resolve(ctx, artifact)
+
+	// Act
+	fetched, err := catalogueindex.Fetch(ctx, artifact, desc, publicKey)
+
+	// Assert
+	assert.ErrorIs(t, err, catalogueindex.ErrInvalidSignature)
+	assert.Nil(t, fetched)
+}
+
+func TestParseKeys(t *testing.T) {
+	// Arrange
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	privateKeyDER, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	publicKeyDER, _ := x509.MarshalPKIXPublicKey(publicKey)
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER})
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+
+	// Act
+	parsedPrivateKey, privateErr := catalogueindex.ParsePrivateKey(privateKeyPEM)
+	parsedPublicKey, publicErr := catalogueindex.ParsePublicKey(publicKeyPEM)
+	_, invalidErr := catalogueindex.ParsePublicKey([]byte("invalid"))
+
+	// Assert
+	assert.NoError(t, privateErr)
+	assert.NoError(t, publicErr)
+	assert.Equal(t, privateKey, parsedPrivateKey)
+	assert.Equal(t, publicKey, parsedPublicKey)
+	assert.Error(t, invalidErr)
+}
+
+func TestRepositoryOf(t *testing.T) {
+	assert.Equal(t, config.UcCatalogueIndexRepository, catalogueindex.RepositoryOf(""))
+	assert.Equal(t, "company/"+config.UcCatalogueIndexRepository, catalogueindex.RepositoryOf("company/"))
+	assert.True(t, catalogueindex.IsIndexRepository("company/"+config.UcCatalogueIndexRepository))
+	assert.False(t, catalogueindex.IsIndexRepository("company/test-addon"))
+}
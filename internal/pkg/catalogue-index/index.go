@@ -0,0 +1,85 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogueindex
+
+import (
+	"path"
+	"strings"
+	"time"
+	"u-control/uc-aom/internal/pkg/config"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Version of the index format, incremented on incompatible changes.
+const SchemaVersion = 1
+
+// Index lists all add-ons of a registry namespace with their versions,
+// so that the catalogue can be read without the catalog API of the registry.
+type Index struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Created       time.Time `json:"created"`
+	AddOns        []*AddOn  `json:"addOns"`
+}
+
+// AddOn lists the versions of an add-on repository.
+type AddOn struct {
+	Repository string     `json:"repository"`
+	Versions   []*Version `json:"versions"`
+}
+
+// Version describes a tag of an add-on repository.
+type Version struct {
+	Tag string `json:"tag"`
+
+	// Digest of the image index the tag referred to when the index was published
+	Digest string `json:"digest"`
+
+	Platforms []ocispec.Platform `json:"platforms"`
+
+	// Summary of the add-on, nil if the package does not provide one
+	Summary *manifest.AddOnSummary `json:"summary,omitempty"`
+}
+
+// Returns the repository of the catalogue index of the namespace, the namespace may be empty.
+func RepositoryOf(namespace string) string {
+	return path.Join(strings.Trim(namespace, "/"), config.UcCatalogueIndexRepository)
+}
+
+// Returns whether the repository holds a catalogue index.
+func IsIndexRepository(repository string) bool {
+	return path.Base(repository) == config.UcCatalogueIndexRepository
+}
+
+// Returns the add-on of the repository, nil if the index does not list it.
+func (i *Index) Find(repository string) *AddOn {
+	for _, addOn := range i.AddOns {
+		if addOn.Repository == repository {
+			return addOn
+		}
+	}
+	return nil
+}
+
+// Returns the version of the tag, nil if the add-on does not list it.
+func (a *AddOn) Find(tag string) *Version {
+	for _, version := range a.Versions {
+		if version.Tag == tag {
+			return version
+		}
+	}
+	return nil
+}
+
+// Returns whether the version provides an image for the platform.
+func (v *Version) Supports(os string, architecture string) bool {
+	for _, platform := range v.Platforms {
+		if platform.OS == os && platform.Architecture == architecture {
+			return true
+		}
+	}
+	return false
+}
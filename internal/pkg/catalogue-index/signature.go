@@ -0,0 +1,67 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package catalogueindex
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Returned if the signature of the catalogue index is missing or does not match the content.
+var ErrInvalidSignature = errors.New("Invalid catalogue index signature")
+
+// Parses a PEM encoded PKCS #8 ed25519 private key, as created by `openssl genpkey -algorithm ed25519`.
+func ParsePrivateKey(content []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("No PEM encoded private key found")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported private key type %T, expected ed25519", key)
+	}
+	return privateKey, nil
+}
+
+// Parses a PEM encoded PKIX ed25519 public key, as created by `openssl pkey -pubout`.
+func ParsePublicKey(content []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("No PEM encoded public key found")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported public key type %T, expected ed25519", key)
+	}
+	return publicKey, nil
+}
+
+func sign(content []byte, privateKey ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, content))
+}
+
+func verify(content []byte, signature string, publicKey ed25519.PublicKey) error {
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(publicKey, content, decoded) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -38,6 +38,42 @@ const (
 	// Annotation schema version to identify our schema version
 	UcImageLayerAnnotationSchemaVersion = "com.weidmueller.uc.image.schema.version"
 
+	// Annotation of a docker image layer listing the diff ID and uncompressed size of each of its layers
+	UcDockerImageLayersAnnotation = "com.weidmueller.uc.docker.image.layers"
+
+	// Annotations of the image index summarizing the add-on,
+	// so that it can be listed without pulling the uc image layer.
+	UcAddOnSummaryAnnotationTitle           = "com.weidmueller.uc.addon.title"
+	UcAddOnSummaryAnnotationDescription     = "com.weidmueller.uc.addon.description"
+	UcAddOnSummaryAnnotationVersion         = "com.weidmueller.uc.addon.version"
+	UcAddOnSummaryAnnotationPlatforms       = "com.weidmueller.uc.addon.platforms"
+	UcAddOnSummaryAnnotationVendor          = "com.weidmueller.uc.addon.vendor"
+	UcAddOnSummaryAnnotationLogoDigest      = "com.weidmueller.uc.addon.logo.digest"
+	UcAddOnSummaryAnnotationManifestVersion = "com.weidmueller.uc.addon.manifest.version"
+
+	// Annotations of the image index with the translations of the title and description as JSON objects
+	UcAddOnSummaryAnnotationTitleTranslations       = "com.weidmueller.uc.addon.title.translations"
+	UcAddOnSummaryAnnotationDescriptionTranslations = "com.weidmueller.uc.addon.description.translations"
+
+	// Annotations of the image index with the categories and keywords as JSON arrays
+	UcAddOnSummaryAnnotationCategories = "com.weidmueller.uc.addon.categories"
+	UcAddOnSummaryAnnotationKeywords   = "com.weidmueller.uc.addon.keywords"
+
+	// Annotation of the image index with the features as JSON array, so that they are checked before the install
+	UcAddOnSummaryAnnotationFeatures = "com.weidmueller.uc.addon.features"
+
+	// Repository and tag of the catalogue index within a registry namespace,
+	// the index lists all add-ons of the namespace so that the registry catalog API is not required.
+	UcCatalogueIndexRepository = "uc-catalogue-index"
+	UcCatalogueIndexTag        = "latest"
+
+	// MediaType used to identify the config and the layer of the catalogue index
+	UcCatalogueIndexConfigMediaType = "application/vnd.weidmueller.uc.catalogue.config.v1+json"
+	UcCatalogueIndexLayerMediaType  = "application/vnd.weidmueller.uc.catalogue.index.v1+json"
+
+	// Annotation of the catalogue index layer holding the base64 encoded ed25519 signature of the layer
+	UcCatalogueIndexAnnotationSignature = "com.weidmueller.uc.catalogue.index.signature"
+
 	// Used as the filename when the image layer is created, compressed or decompressed.
 	UcImageManifestFilename = "manifest.json"
 
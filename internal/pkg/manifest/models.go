@@ -35,8 +35,21 @@ type Root struct {
 	Vendor          *Vendor                 `json:"vendor,omitempty"`       // vendor information, see Vendor for details.
 	Features        []Feature               `json:"features,omitempty"`     // features that the app depends on, see Feature for details.
 	Platform        []string                `json:"platform"`               // optional platforms that this add-on requires.
+	Protection      *Protection             `json:"protection,omitempty"`   // optional protection, see Protection for details.
+
+	TitleTranslations       Translations `json:"titleTranslations,omitempty"`       // optional translations of the title, see Translations for details.
+	DescriptionTranslations Translations `json:"descriptionTranslations,omitempty"` // optional translations of the description, see Translations for details.
+
+	Categories  []string `json:"categories,omitempty"`  // optional categories of the add-on, used to filter the catalogue.
+	Keywords    []string `json:"keywords,omitempty"`    // optional keywords of the add-on, used to search the catalogue.
+	Screenshots []string `json:"screenshots,omitempty"` // optional screenshot files of the add-on, are presented in the ui
+	Readme      string   `json:"readme,omitempty"`      // optional markdown file which describes the add-on in detail
+	Changelog   string   `json:"changelog,omitempty"`   // optional markdown file with a "## <version>" section per add-on version
 }
 
+// Translations of an English text per language, identified by a BCP 47 language tag such as "de" or "de-CH".
+type Translations map[string]string
+
 // UnmarshalManifestVersionFrom return the ManifestVersion from the byte content or error if not possible
 func UnmarshalManifestVersionFrom(manifestRawByteContent []byte) (string, error) {
 
@@ -97,7 +110,8 @@ type EnvironmentConfig manifestV0_1.EnvironmentConfig
 
 type Setting struct {
 	manifestV0_1.Setting
-	Select []*Item `json:"select,omitempty"` // Variant - a DropDownList
+	Select            []*Item      `json:"select,omitempty"`            // Variant - a DropDownList
+	LabelTranslations Translations `json:"labelTranslations,omitempty"` // optional translations of the label
 }
 
 func NewSettings(name string, label string, required bool) *Setting {
@@ -131,7 +145,12 @@ func (s *Setting) IsTextBox() bool {
 	return s.Value != "" && len(s.Select) == 0
 }
 
-type Item manifestV0_1.Item
+type Item struct {
+	Label             string       `json:"label"`                       // the label of the drop-down item
+	Value             string       `json:"value"`                       // the value of the drop-down item
+	Selected          bool         `json:"default"`                     // Whether this drop-down item should be selected
+	LabelTranslations Translations `json:"labelTranslations,omitempty"` // optional translations of the label
+}
 
 type ProxyRoute manifestV0_1.ProxyRoute
 
@@ -145,3 +164,10 @@ type Feature struct {
 	Name     string `json:"name"`               // Specifies a single hardware or software feature used by the application, as a descriptor string.
 	Required *bool  `json:"required,omitempty"` // Boolean value that indicates whether the application requires the feature specified in `name`. The default value if not declared is `true`.
 }
+
+// Declares the add-on as protected.
+// Only the listed operations are permitted on an installed protected add-on.
+// The declaration is only honoured for add-ons which the device-side allow-list trusts.
+type Protection struct {
+	AllowedOperations []string `json:"allowedOperations"` // Operations that are permitted, i.e. "update", "configure", "delete" or "stop".
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"u-control/uc-aom/internal/pkg/config"
+)
+
+// AddOnSummary holds the fields of the manifest which are required to list an add-on in the catalogue.
+type AddOnSummary struct {
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	Version         string   `json:"version"`
+	Platforms       []string `json:"platforms,omitempty"`
+	Vendor          *Vendor  `json:"vendor,omitempty"`
+	LogoDigest      string   `json:"logoDigest,omitempty"`
+	ManifestVersion string   `json:"manifestVersion"`
+
+	TitleTranslations       Translations `json:"titleTranslations,omitempty"`
+	DescriptionTranslations Translations `json:"descriptionTranslations,omitempty"`
+
+	Categories []string `json:"categories,omitempty"`
+	Keywords   []string `json:"keywords,omitempty"`
+
+	Features []Feature `json:"features,omitempty"`
+}
+
+// NewAddOnSummary returns the summary of the manifest, logoDigest is the digest of the logo file content.
+func NewAddOnSummary(root *Root, logoDigest string) *AddOnSummary {
+	return &AddOnSummary{
+		Title:           root.Title,
+		Description:     root.Description,
+		Version:         root.Version,
+		Platforms:       root.Platform,
+		Vendor:          root.Vendor,
+		LogoDigest:      logoDigest,
+		ManifestVersion: root.ManifestVersion,
+
+		TitleTranslations:       root.TitleTranslations,
+		DescriptionTranslations: root.DescriptionTranslations,
+
+		Categories: root.Categories,
+		Keywords:   root.Keywords,
+
+		Features: root.Features,
+	}
+}
+
+// Create annotations for the image index descriptor which summarize the add-on.
+func CreateAddOnSummaryAnnotations(summary *AddOnSummary) ([]string, error) {
+	annotations := []string{
+		config.UcAddOnSummaryAnnotationTitle, summary.Title,
+		config.UcAddOnSummaryAnnotationDescription, summary.Description,
+		config.UcAddOnSummaryAnnotationVersion, summary.Version,
+		config.UcAddOnSummaryAnnotationManifestVersion, summary.ManifestVersion,
+	}
+
+	if len(summary.Platforms) > 0 {
+		annotations = append(annotations, config.UcAddOnSummaryAnnotationPlatforms, strings.Join(summary.Platforms, ","))
+	}
+
+	if summary.Vendor != nil {
+		vendor, err := json.Marshal(summary.Vendor)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, config.UcAddOnSummaryAnnotationVendor, string(vendor))
+	}
+
+	if summary.LogoDigest != "" {
+		annotations = append(annotations, config.UcAddOnSummaryAnnotationLogoDigest, summary.LogoDigest)
+	}
+
+	translations := map[string]Translations{
+		config.UcAddOnSummaryAnnotationTitleTranslations:       summary.TitleTranslations,
+		config.UcAddOnSummaryAnnotationDescriptionTranslations: summary.DescriptionTranslations,
+	}
+	for _, annotation := range []string{config.UcAddOnSummaryAnnotationTitleTranslations, config.UcAddOnSummaryAnnotationDescriptionTranslations} {
+		if len(translations[annotation]) == 0 {
+			continue
+		}
+		content, err := json.Marshal(translations[annotation])
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, annotation, string(content))
+	}
+
+	lists := map[string][]string{
+		config.UcAddOnSummaryAnnotationCategories: summary.Categories,
+		config.UcAddOnSummaryAnnotationKeywords:   summary.Keywords,
+	}
+	for _, annotation := range []string{config.UcAddOnSummaryAnnotationCategories, config.UcAddOnSummaryAnnotationKeywords} {
+		if len(lists[annotation]) == 0 {
+			continue
+		}
+		content, err := json.Marshal(lists[annotation])
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, annotation, string(content))
+	}
+
+	if len(summary.Features) > 0 {
+		features, err := json.Marshal(summary.Features)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, config.UcAddOnSummaryAnnotationFeatures, string(features))
+	}
+	return annotations, nil
+}
+
+// ParseAddOnSummaryAnnotations returns the summary from the annotations of the image index.
+// Returns false if the annotations do not contain a summary, e.g. the package was created by an older version.
+func ParseAddOnSummaryAnnotations(annotations map[string]string) (*AddOnSummary, bool) {
+	version, ok := annotations[config.UcAddOnSummaryAnnotationVersion]
+	if !ok {
+		return nil, false
+	}
+	manifestVersion, ok := annotations[config.UcAddOnSummaryAnnotationManifestVersion]
+	if !ok {
+		return nil, false
+	}
+
+	summary := &AddOnSummary{
+		Title:           annotations[config.UcAddOnSummaryAnnotationTitle],
+		Description:     annotations[config.UcAddOnSummaryAnnotationDescription],
+		Version:         version,
+		LogoDigest:      annotations[config.UcAddOnSummaryAnnotationLogoDigest],
+		ManifestVersion: manifestVersion,
+	}
+
+	if platforms := annotations[config.UcAddOnSummaryAnnotationPlatforms]; platforms != "" {
+		summary.Platforms = strings.Split(platforms, ",")
+	}
+
+	if vendor, ok := annotations[config.UcAddOnSummaryAnnotationVendor]; ok {
+		summary.Vendor = &Vendor{}
+		if err := json.Unmarshal([]byte(vendor), summary.Vendor); err != nil {
+			return nil, false
+		}
+	}
+
+	if titleTranslations, ok := annotations[config.UcAddOnSummaryAnnotationTitleTranslations]; ok {
+		if err := json.Unmarshal([]byte(titleTranslations), &summary.TitleTranslations); err != nil {
+			return nil, false
+		}
+	}
+	if descriptionTranslations, ok := annotations[config.UcAddOnSummaryAnnotationDescriptionTranslations]; ok {
+		if err := json.Unmarshal([]byte(descriptionTranslations), &summary.DescriptionTranslations); err != nil {
+			return nil, false
+		}
+	}
+
+	if categories, ok := annotations[config.UcAddOnSummaryAnnotationCategories]; ok {
+		if err := json.Unmarshal([]byte(categories), &summary.Categories); err != nil {
+			return nil, false
+		}
+	}
+	if keywords, ok := annotations[config.UcAddOnSummaryAnnotationKeywords]; ok {
+		if err := json.Unmarshal([]byte(keywords), &summary.Keywords); err != nil {
+			return nil, false
+		}
+	}
+
+	if features, ok := annotations[config.UcAddOnSummaryAnnotationFeatures]; ok {
+		if err := json.Unmarshal([]byte(features), &summary.Features); err != nil {
+			return nil, false
+		}
+	}
+	return summary, true
+}
+
+// Validate returns an error if the summary lacks the fields which the manifest schema requires of them.
+// A summary is only listed in the catalogue if it is valid, the full manifest is validated on install.
+func (s *AddOnSummary) Validate() error {
+	if s.Title == "" {
+		return errors.New("The summary has no title")
+	}
+	if !IsAddOnVersion(s.Version) {
+		return fmt.Errorf("The summary has the invalid version '%s'", s.Version)
+	}
+	if s.ManifestVersion == "" {
+		return errors.New("The summary has no manifest version")
+	}
+	for _, platform := range s.Platforms {
+		if platform == "" {
+			return errors.New("The summary has an empty platform")
+		}
+	}
+	return nil
+}
+
+// ToManifest returns a manifest which only holds the fields of the summary.
+func (s *AddOnSummary) ToManifest() Root {
+	return Root{
+		ManifestVersion: s.ManifestVersion,
+		Version:         s.Version,
+		Title:           s.Title,
+		Description:     s.Description,
+		Vendor:          s.Vendor,
+		Platform:        s.Platforms,
+
+		TitleTranslations:       s.TitleTranslations,
+		DescriptionTranslations: s.DescriptionTranslations,
+
+		Categories: s.Categories,
+		Keywords:   s.Keywords,
+
+		Features: s.Features,
+	}
+}
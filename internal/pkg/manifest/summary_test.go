@@ -0,0 +1,115 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package manifest_test
+
+import (
+	"testing"
+	"u-control/uc-aom/internal/pkg/config"
+	"u-control/uc-aom/internal/pkg/manifest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddOnSummaryAnnotationsRoundTrip(t *testing.T) {
+	// Arrange
+	root := &manifest.Root{
+		ManifestVersion: manifest.ValidManifestVersion,
+		Version:         "1.0.0-1",
+		Title:           "Test Add-On",
+		Description:     "Description, with a comma",
+		Platform:        []string{"ucg", "ucm"},
+		Vendor:          &manifest.Vendor{Name: "Weidmueller", Url: "https://www.weidmueller.com"},
+
+		TitleTranslations:       manifest.Translations{"de": "Test-Add-On"},
+		DescriptionTranslations: manifest.Translations{"de": "Beschreibung, mit einem Komma"},
+		Categories:              []string{"Connectivity"},
+		Keywords:                []string{"mqtt", "cloud, iot"},
+		Features:                []manifest.Feature{{Name: "ucontrol.software.root_access"}},
+	}
+	summary := manifest.NewAddOnSummary(root, "sha256:123")
+
+	// Act
+	annotations, err := manifest.CreateAddOnSummaryAnnotations(summary)
+	assert.NoError(t, err)
+	annotationMap := make(map[string]string)
+	for i := 0; i < len(annotations); i += 2 {
+		annotationMap[annotations[i]] = annotations[i+1]
+	}
+	parsed, ok := manifest.ParseAddOnSummaryAnnotations(annotationMap)
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, summary, parsed)
+	parsedManifest := parsed.ToManifest()
+	assert.Equal(t, root.Title, parsedManifest.Title)
+	assert.Equal(t, root.Vendor, parsedManifest.Vendor)
+	assert.Equal(t, root.Platform, parsedManifest.Platform)
+	assert.Equal(t, root.TitleTranslations, parsedManifest.TitleTranslations)
+	assert.Equal(t, root.DescriptionTranslations, parsedManifest.DescriptionTranslations)
+	assert.Equal(t, root.Categories, parsedManifest.Categories)
+	assert.Equal(t, root.Keywords, parsedManifest.Keywords)
+	assert.Equal(t, root.Features, parsedManifest.Features)
+}
+
+func TestParseAddOnSummaryAnnotationsWithoutSummary(t *testing.T) {
+	// Arrange
+	annotations := map[string]string{"org.opencontainers.image.version": config.UcPackageVersion}
+
+	// Act
+	summary, ok := manifest.ParseAddOnSummaryAnnotations(annotations)
+
+	// Assert
+	assert.False(t, ok)
+	assert.Nil(t, summary)
+}
+
+func TestParseAddOnSummaryAnnotationsWithInvalidVendor(t *testing.T) {
+	// Arrange
+	annotations := map[string]string{
+		config.UcAddOnSummaryAnnotationVersion:         "1.0.0-1",
+		config.UcAddOnSummaryAnnotationManifestVersion: manifest.ValidManifestVersion,
+		config.UcAddOnSummaryAnnotationVendor:          "{",
+	}
+
+	// Act
+	summary, ok := manifest.ParseAddOnSummaryAnnotations(annotations)
+
+	// Assert
+	assert.False(t, ok)
+	assert.Nil(t, summary)
+}
+
+func TestValidateAddOnSummary(t *testing.T) {
+	valid := manifest.AddOnSummary{Title: "Test", Version: "1.0.0-1", ManifestVersion: manifest.ValidManifestVersion, Platforms: []string{"ucg"}}
+	withoutTitle := valid
+	withoutTitle.Title = ""
+	invalidVersion := valid
+	invalidVersion.Version = "latest"
+	withoutManifestVersion := valid
+	withoutManifestVersion.ManifestVersion = ""
+	emptyPlatform := valid
+	emptyPlatform.Platforms = []string{""}
+
+	testCases := map[string]struct {
+		summary manifest.AddOnSummary
+		wantErr bool
+	}{
+		"valid":                    {summary: valid},
+		"without title":            {summary: withoutTitle, wantErr: true},
+		"invalid version":          {summary: invalidVersion, wantErr: true},
+		"without manifest version": {summary: withoutManifestVersion, wantErr: true},
+		"empty platform":           {summary: emptyPlatform, wantErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			err := tc.summary.Validate()
+
+			// Assert
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
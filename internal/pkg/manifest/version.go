@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"strings"
 	"u-control/uc-aom/internal/pkg/config"
 
 	"github.com/hashicorp/go-version"
@@ -81,6 +82,56 @@ func (v ByAddOnVersion) createAddOnPackageVersion(manifestVersion string) (*vers
 	return &version.Version{}, fmt.Errorf("Can't find package version in %s", manifestVersion)
 }
 
+// Returns the pre-release of the add-on version, e.g. alpha, beta or rc.
+// The pre-release of the package version takes precedence over the pre-release of the partner version.
+// Returns an empty string for a release and an error if the version is no add-on version.
+func PreReleaseOf(addOnVersion string) (string, error) {
+	partnerVersion, err := ByAddOnVersion{}.createAddOnPartnerVersion(addOnVersion)
+	if err != nil {
+		return "", err
+	}
+
+	match := addOnPackageVersionRegExp.FindStringSubmatch(addOnVersion)
+	if match != nil && match[2] != "" {
+		return match[2], nil
+	}
+	return strings.SplitN(partnerVersion.Prerelease(), ".", 2)[0], nil
+}
+
+// Returns whether the version consists of a partner version and a package version, e.g. 1.2.3-1.
+func IsAddOnVersion(addOnVersion string) bool {
+	_, err := ByAddOnVersion{}.createAddOnPartnerVersion(addOnVersion)
+	return err == nil
+}
+
+// Returns whether both add-on versions have the same partner version, e.g. 1.2.3-1 and 1.2.3-2.
+// Returns false if a version is no add-on version.
+func HaveSamePartnerVersion(first string, second string) bool {
+	firstPartnerVersion, err := ByAddOnVersion{}.createAddOnPartnerVersion(first)
+	if err != nil {
+		return false
+	}
+	secondPartnerVersion, err := ByAddOnVersion{}.createAddOnPartnerVersion(second)
+	if err != nil {
+		return false
+	}
+	return firstPartnerVersion.Equal(secondPartnerVersion)
+}
+
+// Returns whether both add-on versions have the same major partner version, e.g. 1.2.3-1 and 1.4.0-1.
+// Returns false if a version is no add-on version.
+func HaveSameMajorVersion(first string, second string) bool {
+	firstPartnerVersion, err := ByAddOnVersion{}.createAddOnPartnerVersion(first)
+	if err != nil {
+		return false
+	}
+	secondPartnerVersion, err := ByAddOnVersion{}.createAddOnPartnerVersion(second)
+	if err != nil {
+		return false
+	}
+	return firstPartnerVersion.Segments()[0] == secondPartnerVersion.Segments()[0]
+}
+
 // check if the first version is greater or equal than the second version
 func GreaterThanOrEqual(first string, second string) bool {
 
@@ -1,4 +1,4 @@
-// Copyright 2022 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+// Copyright 2022 - 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
 //
 // SPDX-License-Identifier: MIT
 
@@ -150,3 +150,74 @@ func TestGreaterThanOrEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestPreReleaseOf(t *testing.T) {
+	testCases := map[string]string{
+		"1.0.0-1":             "",
+		"1.0.0-1-rc.1":        "rc",
+		"1.0.0-1-beta.2":      "beta",
+		"1.0.0-rc.4-1":        "rc",
+		"0.9.9.3-alpha.1-1":   "alpha",
+		"1.0.0-rc.1-1-beta.1": "beta",
+	}
+
+	for version, expected := range testCases {
+		t.Run(version, func(t *testing.T) {
+			// Act
+			got, err := manifest.PreReleaseOf(version)
+
+			// Assert
+			if err != nil {
+				t.Errorf("Unexpected error %v", err)
+			}
+			if got != expected {
+				t.Errorf("Expected pre-release '%s' but got '%s'", expected, got)
+			}
+		})
+	}
+}
+
+func TestPreReleaseOfInvalidVersion(t *testing.T) {
+	for _, version := range []string{"latest", "1.0.0"} {
+		t.Run(version, func(t *testing.T) {
+			// Act
+			_, err := manifest.PreReleaseOf(version)
+
+			// Assert
+			if err == nil {
+				t.Error("Expected an error for an invalid version")
+			}
+		})
+	}
+}
+
+func TestHaveSamePartnerAndMajorVersion(t *testing.T) {
+	testCases := []struct {
+		first       string
+		second      string
+		samePartner bool
+		sameMajor   bool
+	}{
+		{"1.2.3-1", "1.2.3-2", true, true},
+		{"1.2.3-1", "1.2.4-1", false, true},
+		{"1.2.3-1", "1.4.0-1", false, true},
+		{"1.2.3-1", "2.0.0-1", false, false},
+		{"1.2.3-1", "1.2.3", false, false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.first+" "+testCase.second, func(t *testing.T) {
+			// Act
+			samePartner := manifest.HaveSamePartnerVersion(testCase.first, testCase.second)
+			sameMajor := manifest.HaveSameMajorVersion(testCase.first, testCase.second)
+
+			// Assert
+			if samePartner != testCase.samePartner {
+				t.Errorf("Expected same partner version %t but got %t", testCase.samePartner, samePartner)
+			}
+			if sameMajor != testCase.sameMajor {
+				t.Errorf("Expected same major version %t but got %t", testCase.sameMajor, sameMajor)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package oraswrapper
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"u-control/uc-aom/internal/pkg/config"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	dockerMediaTypeManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerMediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	dockerMediaTypeLayer        = "application/vnd.docker.image.rootfs.diff.tar"
+	dockerMediaTypeLayerGzip    = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+
+	// Reference: https://github.com/opencontainers/image-spec/blob/v1.1.0-rc2/image-layout.md#blobs
+	ociImageBlobsDirectory = "blobs"
+)
+
+// Uncompressed layer of a docker image.
+type DockerImageLayer struct {
+	DiffID digest.Digest `json:"diffID"` // digest of the uncompressed layer as listed in the rootfs of the image config
+	Size   int64         `json:"size"`   // size of the uncompressed layer in bytes
+}
+
+// Returns the uncompressed layers of the docker image contained in the OCI image layout tarball.
+func ReadDockerImageLayers(ociTarball []byte) ([]DockerImageLayer, error) {
+	blobs, index, err := readOciImageLayoutTarball(ociTarball)
+	if err != nil {
+		return nil, err
+	}
+
+	imageManifest, err := findImageManifest(blobs, index.Manifests...)
+	if err != nil {
+		return nil, err
+	}
+
+	var imageConfig ocispec.Image
+	if err := unmarshalBlob(blobs, imageManifest.Config, &imageConfig); err != nil {
+		return nil, err
+	}
+
+	diffIDs := imageConfig.RootFS.DiffIDs
+	if len(diffIDs) != len(imageManifest.Layers) {
+		return nil, fmt.Errorf("Image config lists %d diff IDs for %d layers", len(diffIDs), len(imageManifest.Layers))
+	}
+
+	layers := make([]DockerImageLayer, 0, len(diffIDs))
+	for i, layerDescriptor := range imageManifest.Layers {
+		size, err := uncompressedLayerSize(blobs, layerDescriptor)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, DockerImageLayer{DiffID: diffIDs[i], Size: size})
+	}
+
+	return layers, nil
+}
+
+// Returns the annotation value listing the given layers.
+func CreateDockerImageLayersAnnotation(layers []DockerImageLayer) (string, error) {
+	value, err := json.Marshal(layers)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// Returns the layers listed by the annotations or false if the annotation is missing or invalid.
+func ParseDockerImageLayersAnnotation(annotations map[string]string) ([]DockerImageLayer, bool) {
+	value, ok := annotations[config.UcDockerImageLayersAnnotation]
+	if !ok {
+		return nil, false
+	}
+
+	layers := []DockerImageLayer{}
+	if err := json.Unmarshal([]byte(value), &layers); err != nil {
+		log.Warnf("Invalid docker image layers annotation: %v", err)
+		return nil, false
+	}
+	return layers, true
+}
+
+func readOciImageLayoutTarball(ociTarball []byte) (map[digest.Digest][]byte, *ocispec.Index, error) {
+	blobs := make(map[digest.Digest][]byte)
+	var index *ocispec.Index
+
+	tarReader := tar.NewReader(bytes.NewReader(ociTarball))
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		switch {
+		case name == config.OciImageIndexFilename:
+			index = &ocispec.Index{}
+			if err := json.NewDecoder(tarReader).Decode(index); err != nil {
+				return nil, nil, err
+			}
+		case strings.HasPrefix(name, ociImageBlobsDirectory+"/"):
+			fields := strings.Split(strings.TrimPrefix(name, ociImageBlobsDirectory+"/"), "/")
+			if len(fields) != 2 {
+				continue
+			}
+			content, err := io.ReadAll(tarReader)
+			if err != nil {
+				return nil, nil, err
+			}
+			blobs[digest.NewDigestFromEncoded(digest.Algorithm(fields[0]), fields[1])] = content
+		}
+	}
+
+	if index == nil {
+		return nil, nil, fmt.Errorf("Missing '%s' in OCI image layout", config.OciImageIndexFilename)
+	}
+	return blobs, index, nil
+}
+
+func findImageManifest(blobs map[digest.Digest][]byte, descriptors ...ocispec.Descriptor) (*ocispec.Manifest, error) {
+	for _, descriptor := range descriptors {
+		if _, ok := blobs[descriptor.Digest]; !ok {
+			continue
+		}
+
+		switch descriptor.MediaType {
+		case ocispec.MediaTypeImageManifest, dockerMediaTypeManifest:
+			var imageManifest ocispec.Manifest
+			if err := unmarshalBlob(blobs, descriptor, &imageManifest); err != nil {
+				return nil, err
+			}
+			return &imageManifest, nil
+
+		case ocispec.MediaTypeImageIndex, dockerMediaTypeManifestList:
+			var imageIndex ocispec.Index
+			if err := unmarshalBlob(blobs, descriptor, &imageIndex); err != nil {
+				return nil, err
+			}
+			if imageManifest, err := findImageManifest(blobs, imageIndex.Manifests...); err == nil {
+				return imageManifest, nil
+			}
+		}
+	}
+
+	return nil, errors.New("No image manifest found in OCI image layout")
+}
+
+func unmarshalBlob(blobs map[digest.Digest][]byte, descriptor ocispec.Descriptor, v interface{}) error {
+	content, ok := blobs[descriptor.Digest]
+	if !ok {
+		return fmt.Errorf("Missing blob '%s' in OCI image layout", descriptor.Digest)
+	}
+	return json.Unmarshal(content, v)
+}
+
+func uncompressedLayerSize(blobs map[digest.Digest][]byte, descriptor ocispec.Descriptor) (int64, error) {
+	content, ok := blobs[descriptor.Digest]
+	if !ok {
+		return 0, fmt.Errorf("Missing blob '%s' in OCI image layout", descriptor.Digest)
+	}
+
+	switch descriptor.MediaType {
+	case ocispec.MediaTypeImageLayer, dockerMediaTypeLayer:
+		return int64(len(content)), nil
+
+	case ocispec.MediaTypeImageLayerGzip, dockerMediaTypeLayerGzip:
+		gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return 0, err
+		}
+		defer gzipReader.Close()
+		return io.Copy(io.Discard, gzipReader)
+
+	default:
+		return 0, fmt.Errorf("Unsupported layer media type '%s'", descriptor.MediaType)
+	}
+}
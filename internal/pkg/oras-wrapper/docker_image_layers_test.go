@@ -0,0 +1,132 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package oraswrapper_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"testing"
+	"u-control/uc-aom/internal/pkg/config"
+	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type ociImageLayoutWriter struct {
+	tarWriter *tar.Writer
+}
+
+func (w *ociImageLayoutWriter) writeFile(t *testing.T, name string, content []byte) {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+	if err := w.tarWriter.WriteHeader(header); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.tarWriter.Write(content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (w *ociImageLayoutWriter) writeBlob(t *testing.T, mediaType string, content []byte) ocispec.Descriptor {
+	blobDigest := digest.FromBytes(content)
+	w.writeFile(t, "blobs/sha256/"+blobDigest.Encoded(), content)
+	return ocispec.Descriptor{MediaType: mediaType, Digest: blobDigest, Size: int64(len(content))}
+}
+
+func (w *ociImageLayoutWriter) writeJsonBlob(t *testing.T, mediaType string, v interface{}) ocispec.Descriptor {
+	content, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.writeBlob(t, mediaType, content)
+}
+
+func gzipContent(t *testing.T, content []byte) []byte {
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	if _, err := gzipWriter.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	gzipWriter.Close()
+	return buffer.Bytes()
+}
+
+func createOciImageLayoutTarball(t *testing.T, uncompressedLayers ...[]byte) []byte {
+	var buffer bytes.Buffer
+	writer := &ociImageLayoutWriter{tarWriter: tar.NewWriter(&buffer)}
+
+	layerDescriptors := make([]ocispec.Descriptor, 0, len(uncompressedLayers))
+	diffIDs := make([]digest.Digest, 0, len(uncompressedLayers))
+	for _, layer := range uncompressedLayers {
+		layerDescriptors = append(layerDescriptors, writer.writeBlob(t, ocispec.MediaTypeImageLayerGzip, gzipContent(t, layer)))
+		diffIDs = append(diffIDs, digest.FromBytes(layer))
+	}
+
+	imageConfig := ocispec.Image{RootFS: ocispec.RootFS{Type: "layers", DiffIDs: diffIDs}}
+	configDescriptor := writer.writeJsonBlob(t, ocispec.MediaTypeImageConfig, imageConfig)
+
+	imageManifest := ocispec.Manifest{Config: configDescriptor, Layers: layerDescriptors}
+	manifestDescriptor := writer.writeJsonBlob(t, ocispec.MediaTypeImageManifest, imageManifest)
+
+	missingManifestDescriptor := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("other platform")}
+	imageIndex := ocispec.Index{Manifests: []ocispec.Descriptor{missingManifestDescriptor, manifestDescriptor}}
+	indexDescriptor := writer.writeJsonBlob(t, ocispec.MediaTypeImageIndex, imageIndex)
+
+	index, err := json.Marshal(ocispec.Index{Manifests: []ocispec.Descriptor{indexDescriptor}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.writeFile(t, config.OciImageIndexFilename, index)
+	writer.tarWriter.Close()
+	return buffer.Bytes()
+}
+
+func TestReadDockerImageLayers(t *testing.T) {
+	// Arrange
+	baseLayer := bytes.Repeat([]byte("base"), 1024)
+	appLayer := []byte("app")
+	ociTarball := createOciImageLayoutTarball(t, baseLayer, appLayer)
+
+	// Act
+	layers, err := oraswrapper.ReadDockerImageLayers(ociTarball)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []oraswrapper.DockerImageLayer{
+		{DiffID: digest.FromBytes(baseLayer), Size: int64(len(baseLayer))},
+		{DiffID: digest.FromBytes(appLayer), Size: int64(len(appLayer))},
+	}, layers)
+}
+
+func TestReadDockerImageLayersMissingIndex(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	tar.NewWriter(&buffer).Close()
+
+	// Act
+	_, err := oraswrapper.ReadDockerImageLayers(buffer.Bytes())
+
+	// Assert
+	assert.NotNil(t, err)
+}
+
+func TestDockerImageLayersAnnotation(t *testing.T) {
+	// Arrange
+	layers := []oraswrapper.DockerImageLayer{{DiffID: digest.FromString("layer"), Size: 42}}
+
+	// Act
+	value, err := oraswrapper.CreateDockerImageLayersAnnotation(layers)
+	parsedLayers, ok := oraswrapper.ParseDockerImageLayersAnnotation(map[string]string{config.UcDockerImageLayersAnnotation: value})
+	_, okWithoutAnnotation := oraswrapper.ParseDockerImageLayersAnnotation(map[string]string{})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, layers, parsedLayers)
+	assert.False(t, okWithoutAnnotation)
+}
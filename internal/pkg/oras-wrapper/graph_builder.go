@@ -59,6 +59,7 @@ type GraphBuilder struct {
 	ImageManifestTuples []*DescriptorBlobTuple
 	Tag                 string
 	author              string
+	indexAnnotations    []string
 }
 
 // Creates a new instance of GraphBuilder
@@ -77,6 +78,11 @@ func (r *GraphBuilder) WithAuthor(author string) {
 	r.author = author
 }
 
+// Add annotations to the image index, given as pairs of key and value
+func (r *GraphBuilder) WithIndexAnnotations(annotations ...string) {
+	r.indexAnnotations = append(r.indexAnnotations, annotations...)
+}
+
 // Append the uc manifest the internal datastructure
 func (r *GraphBuilder) AppendUcManifest(blob []byte, annotations ...string) {
 	descriptor := createDescriptorFromBlob(config.UcImageLayerMediaType, blob, annotations...)
@@ -142,7 +148,7 @@ func (r *GraphBuilder) BuildAndTag(ctx context.Context) (oras.Target, error) {
 		}
 	}
 
-	imageIndexTuple, err := CreateImageIndexTuple(r.ImageManifestTuples)
+	imageIndexTuple, err := CreateImageIndexTuple(r.ImageManifestTuples, r.indexAnnotations...)
 	if err != nil {
 		return nil, err
 	}
@@ -183,8 +189,8 @@ func CreateImageConfigTupleAndImageManifestTuple(platformKey PlatformKey, author
 	return imageConfigTuple, imageManifestTuple, nil
 }
 
-// Helper function to create an image index
-func CreateImageIndexTuple(imageManifests []*DescriptorBlobTuple) (*DescriptorBlobTuple, error) {
+// Helper function to create an image index, the annotations are given as pairs of key and value
+func CreateImageIndexTuple(imageManifests []*DescriptorBlobTuple, annotations ...string) (*DescriptorBlobTuple, error) {
 	// https://github.com/opencontainers/image-spec/blob/main/media-types.md
 
 	// Image Index -> Image Manifest -> [Image Config, Layer_1, Layer_2, ..., Layer_n]
@@ -196,12 +202,17 @@ func CreateImageIndexTuple(imageManifests []*DescriptorBlobTuple) (*DescriptorBl
 		manifestDesc = append(manifestDesc, *im.Desc)
 	}
 
-	annotations := map[string]string{ocispec.AnnotationVersion: config.UcPackageVersion}
+	annotationMap := make(map[string]string, len(annotations)/2+1)
+	for i := 0; i+1 < len(annotations); i += 2 {
+		annotationMap[annotations[i]] = annotations[i+1]
+	}
+	annotationMap[ocispec.AnnotationVersion] = config.UcPackageVersion
+
 	index := ocispec.Index{
 		// Historical value, does not pertain to OCI or docker version
 		Versioned:   specs.Versioned{SchemaVersion: 2},
 		Manifests:   manifestDesc,
-		Annotations: annotations,
+		Annotations: annotationMap,
 	}
 
 	indexJSON, err := json.Marshal(index)
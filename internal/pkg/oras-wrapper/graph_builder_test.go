@@ -5,8 +5,10 @@
 package oraswrapper_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
+	"u-control/uc-aom/internal/pkg/config"
 	oraswrapper "u-control/uc-aom/internal/pkg/oras-wrapper"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -130,3 +132,18 @@ func TestPlatformAsMapKey(t *testing.T) {
 		t.Errorf("Expected value to be %s but got %s", expectedValue, gotValue)
 	}
 }
+
+func TestCreateImageIndexTupleWithAnnotations(t *testing.T) {
+	// Arrange
+	annotations := []string{config.UcAddOnSummaryAnnotationTitle, "Test", ocispec.AnnotationVersion, "overwritten"}
+
+	// Act
+	imageIndexTuple, err := oraswrapper.CreateImageIndexTuple(nil, annotations...)
+
+	// Assert
+	assert.NoError(t, err)
+	var index ocispec.Index
+	assert.NoError(t, json.Unmarshal(imageIndexTuple.Blob, &index))
+	assert.Equal(t, "Test", index.Annotations[config.UcAddOnSummaryAnnotationTitle])
+	assert.Equal(t, config.UcPackageVersion, index.Annotations[ocispec.AnnotationVersion])
+}
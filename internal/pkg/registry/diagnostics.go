@@ -0,0 +1,97 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ConnectionFailure names the stage at which a connection to a registry failed.
+type ConnectionFailure string
+
+const (
+	// The host name of the registry or the proxy could not be resolved
+	ConnectionFailureDNS ConnectionFailure = "DNS"
+
+	// The registry could not be reached, e.g. the connection was refused or timed out
+	ConnectionFailureTCP ConnectionFailure = "TCP"
+
+	// The proxy refused the connection, e.g. because it requires authentication
+	ConnectionFailureProxy ConnectionFailure = "PROXY"
+
+	// The certificate of the registry or the proxy is not trusted
+	ConnectionFailureTLS ConnectionFailure = "TLS"
+
+	// The registry rejected the credentials
+	ConnectionFailureAuth ConnectionFailure = "AUTH"
+
+	ConnectionFailureUnknown ConnectionFailure = "UNKNOWN"
+)
+
+// Returns the stage at which the connection to the registry failed with the error.
+func DiagnoseConnectionFailure(err error) ConnectionFailure {
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) {
+		return ConnectionFailureDNS
+	}
+
+	var opError *net.OpError
+	if errors.As(err, &opError) && opError.Op == "proxyconnect" {
+		return ConnectionFailureProxy
+	}
+	if strings.Contains(err.Error(), "Proxy Authentication Required") {
+		return ConnectionFailureProxy
+	}
+
+	if isTLSError(err) {
+		return ConnectionFailureTLS
+	}
+
+	if statusCode, ok := StatusCodeOf(err); ok && (statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden) {
+		return ConnectionFailureAuth
+	}
+
+	if opError != nil {
+		return ConnectionFailureTCP
+	}
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return ConnectionFailureTCP
+	}
+	return ConnectionFailureUnknown
+}
+
+// Format of the error responses of the registry, see errutil.ParseErrorResponse of oras-go.
+// The oras-go version in use does not expose the error response as a type, so the status code is parsed.
+var errorResponsePattern = regexp.MustCompile(`^[A-Z]+ "[^"]*": unexpected status code (\d{3}):`)
+
+// Returns the HTTP status code of the error response of the registry which caused the error.
+func StatusCodeOf(err error) (int, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if match := errorResponsePattern.FindStringSubmatch(err.Error()); match != nil {
+			statusCode, _ := strconv.Atoi(match[1])
+			return statusCode, true
+		}
+	}
+	return 0, false
+}
+
+func isTLSError(err error) bool {
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var certificateInvalidError x509.CertificateInvalidError
+	var recordHeaderError tls.RecordHeaderError
+	return errors.As(err, &unknownAuthorityError) ||
+		errors.As(err, &hostnameError) ||
+		errors.As(err, &certificateInvalidError) ||
+		errors.As(err, &recordHeaderError)
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry_test
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+	"u-control/uc-aom/internal/pkg/registry"
+)
+
+func TestDiagnoseConnectionFailure(t *testing.T) {
+	wrap := func(err error) error {
+		return fmt.Errorf("ping: %w", &url.Error{Op: "Get", URL: "https://registry/v2/", Err: err})
+	}
+
+	testCases := []struct {
+		name string
+		err  error
+		want registry.ConnectionFailure
+	}{
+		{
+			name: "unresolvable host",
+			err:  wrap(&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "registry"}}),
+			want: registry.ConnectionFailureDNS,
+		},
+		{
+			name: "refused connection",
+			err:  wrap(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}),
+			want: registry.ConnectionFailureTCP,
+		},
+		{
+			name: "unreachable proxy",
+			err:  wrap(&net.OpError{Op: "proxyconnect", Net: "tcp", Err: syscall.ECONNREFUSED}),
+			want: registry.ConnectionFailureProxy,
+		},
+		{
+			name: "proxy authentication",
+			err:  wrap(errors.New("Proxy Authentication Required")),
+			want: registry.ConnectionFailureProxy,
+		},
+		{
+			name: "untrusted certificate",
+			err:  wrap(x509.UnknownAuthorityError{}),
+			want: registry.ConnectionFailureTLS,
+		},
+		{
+			name: "rejected credentials",
+			err:  errors.New("GET \"https://registry/v2/\": unexpected status code 401: unauthorized"),
+			want: registry.ConnectionFailureAuth,
+		},
+		{
+			name: "forbidden wrapped",
+			err:  fmt.Errorf("list tags: %w", errors.New("GET \"https://registry/v2/addon/tags/list\": unexpected status code 403: denied")),
+			want: registry.ConnectionFailureAuth,
+		},
+		{
+			name: "status code in message",
+			err:  errors.New("GET \"https://registry/v2/\": unexpected status code 500: status code 401 from upstream"),
+			want: registry.ConnectionFailureUnknown,
+		},
+		{
+			name: "other",
+			err:  errors.New("unexpected EOF"),
+			want: registry.ConnectionFailureUnknown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			got := registry.DiagnoseConnectionFailure(tc.err)
+
+			// Assert
+			if got != tc.want {
+				t.Errorf("Expected %s but got %s", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Weidmueller Interface GmbH & Co. KG <oss@weidmueller.com>
+//
+// SPDX-License-Identifier: MIT
+
+package registry_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"u-control/uc-aom/internal/pkg/registry"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPClientTrustsCABundle(t *testing.T) {
+	// Arrange
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	bundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	os.WriteFile(bundlePath, bundle, 0644)
+
+	withBundle, err := registry.NewHTTPClient(registry.TransportOptions{CABundlePath: bundlePath})
+	assert.NoError(t, err)
+	withoutBundle, err := registry.NewHTTPClient(registry.TransportOptions{})
+	assert.NoError(t, err)
+
+	// Act
+	_, trustedErr := withBundle.Get(server.URL)
+	_, untrustedErr := withoutBundle.Get(server.URL)
+
+	// Assert
+	assert.NoError(t, trustedErr)
+	assert.Equal(t, registry.ConnectionFailureTLS, registry.DiagnoseConnectionFailure(untrustedErr))
+}
+
+func TestNewHTTPClientRejectsInvalidCABundle(t *testing.T) {
+	// Arrange
+	bundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(bundlePath, []byte("no certificate"), 0644)
+
+	// Act
+	_, invalidErr := registry.NewHTTPClient(registry.TransportOptions{CABundlePath: bundlePath})
+	_, missingErr := registry.NewHTTPClient(registry.TransportOptions{CABundlePath: filepath.Join(t.TempDir(), "missing.pem")})
+
+	// Assert
+	assert.Error(t, invalidErr)
+	assert.Error(t, missingErr)
+}
+
+func TestNewHTTPClientRejectsMissingClientCertificate(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	options := registry.TransportOptions{ClientCertPath: filepath.Join(root, "client.crt"), ClientKeyPath: filepath.Join(root, "client.key")}
+
+	// Act
+	_, err := registry.NewHTTPClient(options)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestNewHTTPClientUsesProxy(t *testing.T) {
+	// Arrange
+	var proxiedHosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHosts = append(proxiedHosts, r.Host)
+	}))
+	defer proxy.Close()
+	uut, err := registry.NewHTTPClient(registry.TransportOptions{Proxy: proxy.URL, NoProxy: "mirror.local"})
+	assert.NoError(t, err)
+
+	// Act
+	response, err := uut.Get("http://registry.example.com/v2/")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{"registry.example.com"}, proxiedHosts)
+}